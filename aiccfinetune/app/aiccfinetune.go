@@ -2,6 +2,9 @@ package app
 
 import (
 	"errors"
+	"io"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-server/aiccfinetune/domain"
 	"github.com/opensourceways/xihe-server/aiccfinetune/domain/aiccfinetune"
@@ -11,7 +14,9 @@ import (
 	"github.com/opensourceways/xihe-server/app"
 
 	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	orepo "github.com/opensourceways/xihe-server/domain/repository"
+	jobapp "github.com/opensourceways/xihe-server/job/app"
 	"github.com/opensourceways/xihe-server/utils"
 )
 
@@ -25,17 +30,22 @@ type AICCFinetuneIndex = domain.AICCFinetuneIndex
 type AICCFinetuneConfig = domain.AICCFinetuneConfig
 
 type AICCFinetuneService interface {
-	Create(*AICCFinetuneCreateCmd) (string, error)
+	Create(*AICCFinetuneCreateCmd) (string, string, error)
 	UpdateJobDetail(*AICCFinetuneIndex, *JobDetail) error
 	List(user types.Account, model domain.ModelName) ([]AICCFinetuneSummaryDTO, error)
+	Leaderboard(*LeaderboardCmd) (LeaderboardDTO, error)
 	Get(*AICCFinetuneIndex) (AICCFinetuneDTO, string, error)
 	Delete(*AICCFinetuneIndex) error
 	Terminate(*AICCFinetuneIndex) error
 	GetLogDownloadURL(*AICCFinetuneIndex) (string, string, error)
 	GetOutputDownloadURL(*AICCFinetuneIndex) (string, string, error)
+	GetLog(*AICCFinetuneIndex, *app.JobLogQuery) (app.JobLogDTO, string, error)
+	GetFullLog(*AICCFinetuneIndex) ([]byte, string, error)
 	CreateAICCFinetuneJob(*AICCFinetuneIndex, string, bool) (bool, error)
 
-	UploadData(*UploadDataCmd) (UploadDataDTO, error)
+	UploadData(*UploadDataCmd) (UploadDataDTO, string, error)
+	PreviewData(*UploadDataCmd) (DataReportDTO, string, error)
+	GetDataUpload(types.Account, domain.ModelName, domain.FinetuneTask) (DataUploadDTO, string, error)
 }
 
 func NewAICCFinetuneService(
@@ -43,6 +53,9 @@ func NewAICCFinetuneService(
 	sender message.AICCFinetuneMessageProducer,
 	uploader uploader.DataFileUploader,
 	repo repository.AICCFinetune,
+	downloader joblog.Downloader,
+	jobs jobapp.JobNotifier,
+	validation *domain.DataValidationConfig,
 	maxTrainingRecordNum int,
 ) AICCFinetuneService {
 	return aiccFinetuneService{
@@ -50,6 +63,9 @@ func NewAICCFinetuneService(
 		sender:               sender,
 		uploader:             domain.NewUploadService(uploader),
 		repo:                 repo,
+		downloader:           downloader,
+		jobs:                 jobs,
+		validation:           validation,
 		maxTrainingRecordNum: maxTrainingRecordNum,
 	}
 }
@@ -59,6 +75,9 @@ type aiccFinetuneService struct {
 	sender               message.AICCFinetuneMessageProducer
 	uploader             domain.UploadService
 	repo                 repository.AICCFinetune
+	downloader           joblog.Downloader
+	jobs                 jobapp.JobNotifier
+	validation           *domain.DataValidationConfig
 	maxTrainingRecordNum int
 }
 
@@ -66,12 +85,94 @@ func (s aiccFinetuneService) isJobDone(status string) bool {
 	return status != "" && (s.af.IsJobDone(status) || status == trainingStatusScheduleFailed)
 }
 
-func (s aiccFinetuneService) Create(cmd *AICCFinetuneCreateCmd) (string, error) {
-	return s.create(cmd.User, cmd.Model, cmd.Task, cmd.toAICCFinetuneConfig())
+func (s aiccFinetuneService) Create(cmd *AICCFinetuneCreateCmd) (string, string, error) {
+	if code, err := s.checkData(cmd); err != nil {
+		return "", code, err
+	}
+
+	var source *domain.EvaluationSource
+
+	if cmd.Task.IsEvaluate() {
+		v, code, err := s.evaluationSource(cmd)
+		if err != nil {
+			return "", code, err
+		}
+
+		source = &v
+	}
+
+	r, err := s.create(cmd.User, cmd.Model, cmd.Task, cmd.toAICCFinetuneConfig(), source)
+
+	return r, "", err
+}
+
+// checkData checks that the data of task has been uploaded and is valid,
+// so that the job will not fail because of the malformed data.
+func (s aiccFinetuneService) checkData(cmd *AICCFinetuneCreateCmd) (code string, err error) {
+	if _, ok := domain.NewDataValidator(cmd.Model, cmd.Task, s.validation); !ok {
+		return
+	}
+
+	v, err := s.repo.GetDataUpload(cmd.User, cmd.Model, cmd.Task)
+	if err != nil {
+		if orepo.IsErrorResourceNotExists(err) {
+			code = app.ErrorAICCFinetuneNoData
+			err = errors.New("upload the data first")
+		}
+
+		return
+	}
+
+	if !v.Report.Valid {
+		code = app.ErrorAICCFinetuneInvalidData
+		err = errors.New("the data is invalid")
+	}
+
+	return
+}
+
+// evaluationSource checks that the finetune to be evaluated has finished
+// with the output.
+func (s aiccFinetuneService) evaluationSource(cmd *AICCFinetuneCreateCmd) (
+	v domain.EvaluationSource, code string, err error,
+) {
+	data, err := s.repo.Get(&AICCFinetuneIndex{
+		User:       cmd.User,
+		Model:      cmd.Model,
+		FinetuneId: cmd.Source,
+	})
+	if err != nil {
+		if orepo.IsErrorResourceNotExists(err) {
+			code = app.ErrorAICCFinetuneInvalidSource
+			err = errors.New("the finetune to be evaluated is not found")
+		}
+
+		return
+	}
+
+	if !data.Task.IsFinetune() {
+		code = app.ErrorAICCFinetuneInvalidSource
+		err = errors.New("only the finetune can be evaluated")
+
+		return
+	}
+
+	if !s.isJobDone(data.JobDetail.Status) || !data.JobDetail.HasOutput() {
+		code = app.ErrorAICCFinetuneInvalidSource
+		err = errors.New("the finetune has no output to be evaluated")
+
+		return
+	}
+
+	v.FinetuneId = data.Id
+	v.OutputPath = data.JobDetail.OutputPath
+
+	return
 }
 
 func (s aiccFinetuneService) create(
-	user types.Account, model domain.ModelName, task domain.FinetuneTask, config *AICCFinetuneConfig,
+	user types.Account, model domain.ModelName, task domain.FinetuneTask,
+	config *AICCFinetuneConfig, source *domain.EvaluationSource,
 ) (string, error) {
 	v, version, err := s.repo.List(user, model)
 	if err != nil {
@@ -93,6 +194,7 @@ func (s aiccFinetuneService) create(
 		CreatedAt: utils.Now(),
 		Model:     model,
 		Task:      task,
+		Source:    source,
 
 		AICCFinetuneConfig: *config,
 	}
@@ -102,6 +204,8 @@ func (s aiccFinetuneService) create(
 		return "", err
 	}
 
+	s.jobs.Notify(user)
+
 	if err = s.sender.SendAICCFinetuneCreateMsg(&domain.AICCFinetuneCreateEvent{
 		Id:    r,
 		User:  user,
@@ -128,6 +232,33 @@ func (s aiccFinetuneService) List(user types.Account, model domain.ModelName) ([
 	return r, nil
 }
 
+func (s aiccFinetuneService) Leaderboard(cmd *LeaderboardCmd) (dto LeaderboardDTO, err error) {
+	v, _, err := s.repo.List(cmd.User, cmd.Model)
+	if err != nil {
+		return
+	}
+
+	items := domain.NewLeaderboard(v)
+
+	dto.Metrics = domain.LeaderboardMetrics(items)
+
+	metric := cmd.Metric
+	if metric == "" && len(dto.Metrics) > 0 {
+		metric = dto.Metrics[0]
+	}
+
+	if metric != "" {
+		domain.SortLeaderboard(items, metric, cmd.Asc)
+	}
+
+	dto.Items = make([]LeaderboardItemDTO, len(items))
+	for i := range items {
+		dto.Items[i] = toLeaderboardItemDTO(&items[i])
+	}
+
+	return
+}
+
 func (s aiccFinetuneService) Get(info *AICCFinetuneIndex) (dto AICCFinetuneDTO, code string, err error) {
 	data, err := s.repo.Get(info)
 	if err != nil {
@@ -152,7 +283,50 @@ func (s aiccFinetuneService) Get(info *AICCFinetuneIndex) (dto AICCFinetuneDTO,
 }
 
 func (s aiccFinetuneService) UpdateJobDetail(info *AICCFinetuneIndex, v *JobDetail) error {
-	return s.repo.UpdateJobDetail(info, v)
+	return updateJobDetail(s.af, s.repo, s.jobs, info, v)
+}
+
+// updateJobDetail saves the detail reported by the backend of job. The
+// metrics of evaluation are read from its output when it is done.
+func updateJobDetail(
+	af aiccfinetune.AICCFinetuneServer, repo repository.AICCFinetune,
+	jobs jobapp.JobNotifier, info *AICCFinetuneIndex, v *JobDetail,
+) error {
+	if len(v.Metrics) == 0 && v.Error == "" && af.IsJobDone(v.Status) {
+		if err := ingestMetrics(af, repo, info, v); err != nil {
+			// the status should be saved even if the metrics is unavailable.
+			logrus.Errorf(
+				"ingest metrics of evaluation(%s) failed, err:%s",
+				info.FinetuneId, err.Error(),
+			)
+		}
+	}
+
+	if err := repo.UpdateJobDetail(info, v); err != nil {
+		return err
+	}
+
+	jobs.Notify(info.User)
+
+	return nil
+}
+
+func ingestMetrics(
+	af aiccfinetune.AICCFinetuneServer, repo repository.AICCFinetune,
+	info *AICCFinetuneIndex, v *JobDetail,
+) error {
+	data, err := repo.Get(info)
+	if err != nil {
+		return err
+	}
+
+	if !data.Task.IsEvaluate() || data.Job.OutputDir == "" {
+		return nil
+	}
+
+	v.Metrics, err = af.GetEvaluationMetrics(data.Job.Endpoint, data.Job.OutputDir)
+
+	return err
 }
 
 func (s aiccFinetuneService) Delete(info *AICCFinetuneIndex) error {
@@ -172,7 +346,13 @@ func (s aiccFinetuneService) Delete(info *AICCFinetuneIndex) error {
 		}
 	}
 
-	return s.repo.Delete(info)
+	if err := s.repo.Delete(info); err != nil {
+		return err
+	}
+
+	s.jobs.Notify(info.User)
+
+	return nil
 }
 
 func (s aiccFinetuneService) Terminate(info *AICCFinetuneIndex) error {
@@ -202,6 +382,70 @@ func (s aiccFinetuneService) GetLogDownloadURL(info *AICCFinetuneIndex) (
 	return
 }
 
+func (s aiccFinetuneService) GetLog(info *AICCFinetuneIndex, q *app.JobLogQuery) (
+	dto app.JobLogDTO, code string, err error,
+) {
+	link, isPreview, isDone, code, err := s.getLogLink(info)
+	if err != nil {
+		return
+	}
+
+	if isPreview {
+		dto, err = app.ReadJobLogPreview(s.downloader, link, isDone, q)
+	} else {
+		dto, err = app.ReadJobLog(s.downloader, link, isDone, q)
+	}
+
+	return
+}
+
+func (s aiccFinetuneService) GetFullLog(info *AICCFinetuneIndex) (
+	[]byte, string, error,
+) {
+	link, isPreview, isDone, code, err := s.getLogLink(info)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if !isDone || isPreview || link == "" {
+		return nil, app.ErrorAICCFinetuneNoLog, errors.New("not ready")
+	}
+
+	return app.DownloadFullJobLog(s.downloader, link)
+}
+
+// getLogLink returns the link of full log if the backend has provided it,
+// otherwise the link of log preview.
+func (s aiccFinetuneService) getLogLink(info *AICCFinetuneIndex) (
+	link string, isPreview, isDone bool, code string, err error,
+) {
+	data, err := s.repo.Get(info)
+	if err != nil {
+		if orepo.IsErrorResourceNotExists(err) {
+			code = app.ErrorAICCFinetuneNotFound
+		}
+
+		return
+	}
+
+	detail := &data.JobDetail
+	isDone = s.isJobDone(detail.Status)
+
+	job := &data.Job
+	if job.Endpoint == "" || job.JobId == "" {
+		return
+	}
+
+	if detail.LogPath != "" {
+		link, err = s.af.GetFileDownloadURL(job.Endpoint, detail.LogPath)
+	} else {
+		isPreview = true
+		link, err = s.af.GetLogPreviewURL(job.Endpoint, job.JobId)
+	}
+
+	return
+}
+
 func (s aiccFinetuneService) GetOutputDownloadURL(info *AICCFinetuneIndex) (
 	link string, code string, err error,
 ) {
@@ -229,13 +473,16 @@ func (s aiccFinetuneService) CreateAICCFinetuneJob(
 	}
 
 	if lastChance {
-		err = s.repo.UpdateJobDetail(info, &JobDetail{
+		detail := JobDetail{
 			Status: trainingStatusScheduleFailed,
 			Error:  err.Error(),
-		})
-		if err != nil {
+		}
+
+		if err = s.repo.UpdateJobDetail(info, &detail); err != nil {
 			return
 		}
+
+		s.jobs.Notify(info.User)
 	}
 
 	return
@@ -273,16 +520,85 @@ func (s aiccFinetuneService) createAICCFinetuneJob(info *AICCFinetuneIndex, endp
 	return
 }
 
-func (s aiccFinetuneService) UploadData(cmd *UploadDataCmd) (dto UploadDataDTO, err error) {
-	err = s.uploader.Upload(cmd.Data, cmd.FileName, cmd.User.Account(), cmd.Model.ModelName(), cmd.Task.FinetuneTask())
-
+func (s aiccFinetuneService) UploadData(cmd *UploadDataCmd) (
+	dto UploadDataDTO, code string, err error,
+) {
 	dto.FileName = cmd.FileName
 	dto.UploadAt = utils.Now()
 	dto.Status = "failed"
-	if err == nil {
-		dto.Status = "success"
+
+	validator, needValidation := domain.NewDataValidator(cmd.Model, cmd.Task, s.validation)
+
+	var report domain.DataValidationReport
+	if needValidation {
+		report = validator.Validate(cmd.Data, cmd.Size)
+
+		v := toDataReportDTO(&report)
+		dto.Report = &v
+
+		if !report.Valid {
+			code = app.ErrorAICCFinetuneInvalidData
+			err = errors.New("the data is invalid")
+
+			return
+		}
+
+		if _, err = cmd.Data.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+	}
+
+	err = s.uploader.Upload(cmd.Data, cmd.FileName, cmd.User.Account(), cmd.Model.ModelName(), cmd.Task.FinetuneTask())
+	if err != nil {
 		return
 	}
+
+	dto.Status = "success"
+
+	if needValidation {
+		err = s.repo.SaveDataUpload(cmd.User, cmd.Model, cmd.Task, &domain.DataUpload{
+			FileName:   cmd.FileName,
+			UploadedAt: dto.UploadAt,
+			Report:     report,
+		})
+	}
+
+	return
+}
+
+func (s aiccFinetuneService) PreviewData(cmd *UploadDataCmd) (
+	dto DataReportDTO, code string, err error,
+) {
+	validator, ok := domain.NewDataValidator(cmd.Model, cmd.Task, s.validation)
+	if !ok {
+		code = app.ErrorAICCFinetuneInvalidData
+		err = errors.New("the data of task can't be previewed")
+
+		return
+	}
+
+	report := validator.Validate(cmd.Data, cmd.Size)
+	dto = toDataReportDTO(&report)
+
+	return
+}
+
+func (s aiccFinetuneService) GetDataUpload(
+	user types.Account, model domain.ModelName, task domain.FinetuneTask,
+) (dto DataUploadDTO, code string, err error) {
+	v, err := s.repo.GetDataUpload(user, model, task)
+	if err != nil {
+		if orepo.IsErrorResourceNotExists(err) {
+			code = app.ErrorAICCFinetuneNoData
+		}
+
+		return
+	}
+
+	dto.FileName = v.FileName
+	dto.UploadedAt = v.UploadedAt
+	dto.Report = toDataReportDTO(&v.Report)
+
 	return
 }
 
@@ -291,17 +607,23 @@ type AICCFinetuneInternalService interface {
 }
 
 type aiccfinetuneInternalService struct {
+	af   aiccfinetune.AICCFinetuneServer
 	repo repository.AICCFinetune
+	jobs jobapp.JobNotifier
 }
 
 func NewAICCFinetuneInternalService(
+	af aiccfinetune.AICCFinetuneServer,
 	repo repository.AICCFinetune,
+	jobs jobapp.JobNotifier,
 ) AICCFinetuneInternalService {
 	return aiccfinetuneInternalService{
+		af:   af,
 		repo: repo,
+		jobs: jobs,
 	}
 }
 
 func (s aiccfinetuneInternalService) UpdateJobDetails(info *AICCFinetuneIndex, v *JobDetail) error {
-	return s.repo.UpdateJobDetail(info, v)
+	return updateJobDetail(s.af, s.repo, s.jobs, info, v)
 }
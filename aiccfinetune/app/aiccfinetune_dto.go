@@ -2,7 +2,6 @@ package app
 
 import (
 	"errors"
-	"io"
 
 	"github.com/opensourceways/xihe-server/aiccfinetune/domain"
 	types "github.com/opensourceways/xihe-server/domain"
@@ -14,6 +13,10 @@ type AICCFinetuneCreateCmd struct {
 	Model domain.ModelName
 	Task  domain.FinetuneTask
 
+	// Source is the id of finetune to be evaluated,
+	// it is required only when the task is evaluate.
+	Source string
+
 	domain.AICCFinetuneConfig
 }
 
@@ -21,12 +24,17 @@ func (cmd *AICCFinetuneCreateCmd) Validate() error {
 	err := errors.New("invalid cmd of creating aicc finetune")
 
 	b := cmd.User != nil &&
-		cmd.Name != nil
+		cmd.Name != nil &&
+		cmd.Task != nil
 
 	if !b {
 		return err
 	}
 
+	if cmd.Task.IsEvaluate() != (cmd.Source != "") {
+		return errors.New("the source is required only by the evaluation")
+	}
+
 	f := func(kv []domain.KeyValue) error {
 		for i := range kv {
 			if kv[i].Key == nil {
@@ -62,6 +70,9 @@ type AICCFinetuneSummaryDTO struct {
 	IsDone    bool   `json:"is_done"`
 	Duration  int    `json:"duration"`
 	Task      string `json:"task"`
+
+	Source  string             `json:"source,omitempty"`
+	Metrics map[string]float64 `json:"metrics,omitempty"`
 }
 
 func (s aiccFinetuneService) toAICCFinetuneSummaryDTO(
@@ -81,6 +92,8 @@ func (s aiccFinetuneService) toAICCFinetuneSummaryDTO(
 		Duration:  t.Duration,
 		CreatedAt: utils.ToDate(t.CreatedAt),
 		Task:      t.Task,
+		Source:    t.Source,
+		Metrics:   t.Metrics,
 	}
 
 	if t.Desc != nil {
@@ -100,6 +113,10 @@ type AICCFinetuneDTO struct {
 	Duration  int    `json:"duration"`
 	CreatedAt string `json:"created_at"`
 
+	Task    string             `json:"task"`
+	Source  string             `json:"source,omitempty"`
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+
 	LogPreviewURL string `json:"-"`
 }
 
@@ -125,17 +142,71 @@ func (s aiccFinetuneService) toAICCFinetuneDTO(dto *AICCFinetuneDTO, ut *domain.
 		Status:        status,
 		Duration:      detail.Duration,
 		CreatedAt:     utils.ToDate(ut.CreatedAt),
+		Task:          ut.Task.FinetuneTask(),
+		Metrics:       detail.Metrics,
 		LogPreviewURL: link,
 	}
 
 	if t.Desc != nil {
 		dto.Desc = t.Desc.FinetuneDesc()
 	}
+
+	if ut.Source != nil {
+		dto.Source = ut.Source.FinetuneId
+	}
+}
+
+type LeaderboardCmd struct {
+	User   types.Account
+	Model  domain.ModelName
+	Metric string
+	Asc    bool
+}
+
+type LeaderboardItemDTO struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+
+	EvaluationId string             `json:"evaluation_id,omitempty"`
+	EvaluatedAt  string             `json:"evaluated_at,omitempty"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+}
+
+type LeaderboardDTO struct {
+	Metrics []string             `json:"metrics"`
+	Items   []LeaderboardItemDTO `json:"items"`
+}
+
+func toLeaderboardItemDTO(item *domain.LeaderboardItem) LeaderboardItemDTO {
+	f := item.Finetune
+
+	status := f.Status
+	if status == "" {
+		status = trainingStatusScheduling
+	}
+
+	dto := LeaderboardItemDTO{
+		Id:        f.Id,
+		Name:      f.Name.FinetuneName(),
+		Status:    status,
+		CreatedAt: utils.ToDate(f.CreatedAt),
+	}
+
+	if e := item.Evaluation; e != nil {
+		dto.EvaluationId = e.Id
+		dto.EvaluatedAt = utils.ToDate(e.CreatedAt)
+		dto.Metrics = e.Metrics
+	}
+
+	return dto
 }
 
 type UploadDataCmd struct {
 	FileName string
-	Data     io.Reader
+	Data     domain.DataFile
+	Size     int64
 	User     types.Account
 	Model    domain.ModelName
 	Task     domain.FinetuneTask
@@ -145,4 +216,34 @@ type UploadDataDTO struct {
 	UploadAt int64  `json:"upload_at"`
 	FileName string `json:"file_name"`
 	Status   string `json:"status"`
+
+	Report *DataReportDTO `json:"report,omitempty"`
+}
+
+type DataReportDTO struct {
+	Valid       bool                `json:"valid"`
+	Format      string              `json:"format"`
+	Size        int64               `json:"size"`
+	FileCount   int                 `json:"file_count"`
+	RecordCount int                 `json:"record_count"`
+	Errors      []string            `json:"errors"`
+	Samples     []domain.DataSample `json:"samples"`
+}
+
+func toDataReportDTO(r *domain.DataValidationReport) DataReportDTO {
+	return DataReportDTO{
+		Valid:       r.Valid,
+		Format:      r.Format,
+		Size:        r.Size,
+		FileCount:   r.FileCount,
+		RecordCount: r.RecordCount,
+		Errors:      r.Errors,
+		Samples:     r.Samples,
+	}
+}
+
+type DataUploadDTO struct {
+	FileName   string        `json:"file_name"`
+	UploadedAt int64         `json:"uploaded_at"`
+	Report     DataReportDTO `json:"report"`
 }
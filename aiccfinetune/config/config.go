@@ -1,6 +1,7 @@
 package config
 
 import (
+	"github.com/opensourceways/xihe-server/aiccfinetune/domain"
 	"github.com/opensourceways/xihe-server/aiccfinetune/infrastructure/aiccfinetuneimpl"
 	"github.com/opensourceways/xihe-server/aiccfinetune/infrastructure/messageadapter"
 )
@@ -9,11 +10,15 @@ type Config struct {
 	aiccfinetuneimpl.Config
 
 	Message messageadapter.Config `json:"message"`
+
+	// Data is used to validate the data uploaded by user.
+	Data domain.DataValidationConfig `json:"data"`
 }
 
 func (cfg *Config) ConfigItems() []interface{} {
 	return []interface{}{
 		&cfg.Config,
 		&cfg.Message,
+		&cfg.Data,
 	}
 }
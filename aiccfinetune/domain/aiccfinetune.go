@@ -12,6 +12,10 @@ type AICCFinetune struct {
 
 	AICCFinetuneConfig
 
+	// Source is the finetune evaluated by this one, it is set only
+	// when the task is evaluate.
+	Source *EvaluationSource
+
 	CreatedAt int64
 
 	// following fields is not under the controlling of version
@@ -27,6 +31,11 @@ type AICCFinetuneConfig struct {
 	Env             []KeyValue
 }
 
+type EvaluationSource struct {
+	FinetuneId string
+	OutputPath string
+}
+
 type KeyValue struct {
 	Key   CustomizedKey
 	Value CustomizedValue
@@ -45,6 +54,15 @@ type JobDetail struct {
 	LogPath    string
 	OutputPath string
 	Duration   int
+
+	// Metrics are reported by the evaluation job when it is done.
+	Metrics map[string]float64
+}
+
+// HasOutput checks whether the job finished with the output which can
+// be used by other tasks.
+func (d *JobDetail) HasOutput() bool {
+	return d.OutputPath != "" && d.Error == ""
 }
 
 type AICCFinetuneSummary struct {
@@ -56,6 +74,18 @@ type AICCFinetuneSummary struct {
 	Duration  int
 	CreatedAt int64
 	Task      string
+
+	// Source and Metrics are only available for the evaluation.
+	Source  string
+	Metrics map[string]float64
+}
+
+// ModelAICCFinetuneSummary is the summary of aicc finetune with the model
+// it belongs to.
+type ModelAICCFinetuneSummary struct {
+	Model ModelName
+
+	AICCFinetuneSummary
 }
 
 type AICCFinetuneIndex struct {
@@ -11,4 +11,7 @@ type AICCFinetuneServer interface {
 	GetLogPreviewURL(endpoint, jobId string) (string, error)
 	IsJobDone(status string) bool
 	GetFileDownloadURL(endpoint, file string) (string, error)
+	// GetEvaluationMetrics reads the metrics written by the evaluation job
+	// in its output directory.
+	GetEvaluationMetrics(endpoint, outputDir string) (map[string]float64, error)
 }
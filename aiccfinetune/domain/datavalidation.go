@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DataFormatImageCaption   = "image_caption"
+	DataFormatPromptResponse = "prompt_response"
+
+	maxDataValidationErrors = 20
+	maxDataSampleFieldLen   = 200
+)
+
+var (
+	errDataNotUTF8              = errors.New("not encoded in UTF-8")
+	errDataEmptyText            = errors.New("empty text")
+	errDataTooLongText          = errors.New("too long text")
+	errDataUnmatchedImageFormat = errors.New("the content does not match the extension")
+)
+
+type DataValidationConfig struct {
+	// Formats is the format of data of each model. The data of model
+	// which is not in it will not be validated.
+	Formats map[string]string `json:"formats"`
+
+	MinRecordNum int `json:"min_record_num"`
+	MaxRecordNum int `json:"max_record_num"`
+
+	// MaxFileSize is the max size of a file in the archive.
+	MaxFileSize int64 `json:"max_file_size"`
+
+	// MaxTotalSize is the max uncompressed size of all the files in the archive.
+	MaxTotalSize int64 `json:"max_total_size"`
+
+	MaxTextLength int `json:"max_text_length"`
+	SampleNum     int `json:"sample_num"`
+}
+
+func (cfg *DataValidationConfig) SetDefault() {
+	if cfg.Formats == nil {
+		cfg.Formats = map[string]string{
+			modelNameWukong: DataFormatImageCaption,
+		}
+	}
+
+	if cfg.MinRecordNum <= 0 {
+		cfg.MinRecordNum = 1
+	}
+
+	if cfg.MaxRecordNum <= 0 {
+		cfg.MaxRecordNum = 10000
+	}
+
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = 10 * 1024 * 1024
+	}
+
+	if cfg.MaxTotalSize <= 0 {
+		cfg.MaxTotalSize = 500 * 1024 * 1024
+	}
+
+	if cfg.MaxTextLength <= 0 {
+		cfg.MaxTextLength = 4096
+	}
+
+	if cfg.SampleNum <= 0 {
+		cfg.SampleNum = 5
+	}
+}
+
+func (cfg *DataValidationConfig) Validate() error {
+	for k, v := range cfg.Formats {
+		if v != DataFormatImageCaption && v != DataFormatPromptResponse {
+			return fmt.Errorf("unknown data format %s of model %s", v, k)
+		}
+	}
+
+	if cfg.MinRecordNum > cfg.MaxRecordNum {
+		return fmt.Errorf("min_record_num should not be greater than max_record_num")
+	}
+
+	return nil
+}
+
+// DataSample is a record of data, such as an image with its caption.
+type DataSample map[string]string
+
+type DataValidationReport struct {
+	Valid       bool
+	Format      string
+	Size        int64
+	FileCount   int
+	RecordCount int
+	Errors      []string
+	Samples     []DataSample
+}
+
+func (r *DataValidationReport) addError(format string, args ...interface{}) {
+	if len(r.Errors) < maxDataValidationErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	}
+
+	r.Valid = false
+}
+
+func (r *DataValidationReport) checkRecordNum(cfg *DataValidationConfig) {
+	if n := r.RecordCount; n < cfg.MinRecordNum || n > cfg.MaxRecordNum {
+		r.addError(
+			"the num of records is %d, it should be between %d and %d",
+			n, cfg.MinRecordNum, cfg.MaxRecordNum,
+		)
+	}
+}
+
+// DataUpload is the latest data uploaded by user for a task.
+type DataUpload struct {
+	FileName   string
+	UploadedAt int64
+	Report     DataValidationReport
+}
+
+// DataFile is the uploaded file which can be read randomly,
+// such as the zip archive.
+type DataFile interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+type DataValidator interface {
+	Validate(data io.ReaderAt, size int64) DataValidationReport
+}
+
+// NewDataValidator returns the validator of the data for the task of model.
+// It returns false if the data needs not to be validated.
+func NewDataValidator(model ModelName, task FinetuneTask, cfg *DataValidationConfig) (
+	DataValidator, bool,
+) {
+	if !task.IsFinetune() && !task.IsEvaluate() {
+		return nil, false
+	}
+
+	switch cfg.Formats[model.ModelName()] {
+	case DataFormatImageCaption:
+		return imageCaptionValidator{cfg}, true
+
+	case DataFormatPromptResponse:
+		return promptResponseValidator{cfg}, true
+	}
+
+	return nil, false
+}
+
+// pickSamples picks n items evenly from the total ones.
+func pickSamples(total, n int) []int {
+	if total <= n {
+		r := make([]int, total)
+		for i := range r {
+			r[i] = i
+		}
+
+		return r
+	}
+
+	r := make([]int, n)
+	for i := range r {
+		r[i] = i * total / n
+	}
+
+	return r
+}
+
+func truncateSampleField(v string) string {
+	if s := []rune(v); len(s) > maxDataSampleFieldLen {
+		return string(s[:maxDataSampleFieldLen]) + "..."
+	}
+
+	return v
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var imageMagics = map[string][][]byte{
+	".jpg":  {{0xFF, 0xD8, 0xFF}},
+	".jpeg": {{0xFF, 0xD8, 0xFF}},
+	".png":  {{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}},
+}
+
+// imageCaptionValidator validates the zip archive of images and captions.
+// Each image must have a caption file of the same name with suffix .txt,
+// such as 1.jpg and 1.txt.
+type imageCaptionValidator struct {
+	cfg *DataValidationConfig
+}
+
+type imageCaptionRecord struct {
+	image   string
+	caption string
+}
+
+func (v imageCaptionValidator) Validate(data io.ReaderAt, size int64) (r DataValidationReport) {
+	r.Valid = true
+	r.Format = DataFormatImageCaption
+	r.Size = size
+
+	zr, err := zip.NewReader(data, size)
+	if err != nil {
+		r.addError("the data should be a zip archive")
+
+		return
+	}
+
+	images := map[string]*zip.File{}
+	captions := map[string]*zip.File{}
+	total := int64(0)
+
+	for _, f := range zr.File {
+		name := f.Name
+		if f.FileInfo().IsDir() || isIgnoredFile(name) {
+			continue
+		}
+
+		if strings.HasPrefix(name, "/") || strings.Contains(name, "..") {
+			r.addError("invalid file path %s", name)
+
+			continue
+		}
+
+		r.FileCount++
+
+		if int64(f.UncompressedSize64) > v.cfg.MaxFileSize {
+			r.addError("the file %s exceeds the max size %d", name, v.cfg.MaxFileSize)
+
+			continue
+		}
+
+		if total += int64(f.UncompressedSize64); total > v.cfg.MaxTotalSize {
+			r.addError("the total size of files exceeds %d", v.cfg.MaxTotalSize)
+
+			return
+		}
+
+		ext := strings.ToLower(path.Ext(name))
+		stem := strings.TrimSuffix(name, path.Ext(name))
+
+		switch {
+		case imageMagics[ext] != nil:
+			images[stem] = f
+
+		case ext == ".txt":
+			captions[stem] = f
+
+		default:
+			r.addError("unsupported file %s", name)
+		}
+	}
+
+	stems := make([]string, 0, len(images))
+	for stem := range images {
+		stems = append(stems, stem)
+	}
+
+	sort.Strings(stems)
+
+	records := make([]imageCaptionRecord, 0, len(stems))
+
+	for _, stem := range stems {
+		image := images[stem]
+
+		cf, ok := captions[stem]
+		if !ok {
+			r.addError("the image %s has no caption", image.Name)
+
+			continue
+		}
+
+		if err := checkImage(image); err != nil {
+			r.addError("the image %s is invalid, %s", image.Name, err.Error())
+
+			continue
+		}
+
+		caption, err := v.readCaption(cf)
+		if err != nil {
+			r.addError("the caption %s is invalid, %s", cf.Name, err.Error())
+
+			continue
+		}
+
+		records = append(records, imageCaptionRecord{image.Name, caption})
+	}
+
+	for stem, f := range captions {
+		if _, ok := images[stem]; !ok {
+			r.addError("the caption %s has no image", f.Name)
+		}
+	}
+
+	r.RecordCount = len(records)
+	r.checkRecordNum(v.cfg)
+
+	for _, i := range pickSamples(len(records), v.cfg.SampleNum) {
+		r.Samples = append(r.Samples, DataSample{
+			"image":   records[i].image,
+			"caption": truncateSampleField(records[i].caption),
+		})
+	}
+
+	return
+}
+
+func (v imageCaptionValidator) readCaption(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+
+	defer rc.Close()
+
+	b, err := io.ReadAll(io.LimitReader(rc, v.cfg.MaxFileSize))
+	if err != nil {
+		return "", err
+	}
+
+	return checkText(b, v.cfg.MaxTextLength)
+}
+
+func checkImage(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+
+	defer rc.Close()
+
+	header := make([]byte, 8)
+	n, _ := io.ReadFull(rc, header)
+	header = header[:n]
+
+	for _, magic := range imageMagics[strings.ToLower(path.Ext(f.Name))] {
+		if bytes.HasPrefix(header, magic) {
+			return nil
+		}
+	}
+
+	return errDataUnmatchedImageFormat
+}
+
+func checkText(b []byte, maxLen int) (string, error) {
+	if !utf8.Valid(b) {
+		return "", errDataNotUTF8
+	}
+
+	s := strings.TrimSpace(strings.TrimPrefix(string(b), "\ufeff"))
+	if s == "" {
+		return "", errDataEmptyText
+	}
+
+	if utf8.RuneCountInString(s) > maxLen {
+		return "", errDataTooLongText
+	}
+
+	return s, nil
+}
+
+// isIgnoredFile checks whether the file is generated by the system,
+// such as the files under __MACOSX.
+func isIgnoredFile(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".")
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// promptResponseValidator validates the JSONL file of which each line
+// is an object with the string fields of prompt and response.
+type promptResponseValidator struct {
+	cfg *DataValidationConfig
+}
+
+type promptResponseRecord struct {
+	Prompt   *string `json:"prompt"`
+	Response *string `json:"response"`
+}
+
+func (v promptResponseValidator) Validate(data io.ReaderAt, size int64) (r DataValidationReport) {
+	r.Valid = true
+	r.Format = DataFormatPromptResponse
+	r.Size = size
+	r.FileCount = 1
+
+	if size > v.cfg.MaxTotalSize {
+		r.addError("the size of file exceeds %d", v.cfg.MaxTotalSize)
+
+		return
+	}
+
+	scanner := bufio.NewScanner(io.NewSectionReader(data, 0, size))
+	scanner.Buffer(make([]byte, 64*1024), int(v.cfg.MaxFileSize))
+
+	var records []promptResponseRecord
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		item, err := v.parse(line)
+		if err != nil {
+			r.addError("line %d is invalid, %s", lineNum, err.Error())
+
+			continue
+		}
+
+		if len(records) < v.cfg.MaxRecordNum+1 {
+			records = append(records, item)
+		}
+
+		r.RecordCount++
+	}
+
+	if err := scanner.Err(); err != nil {
+		r.addError("can't read the file, %s", err.Error())
+
+		return
+	}
+
+	r.checkRecordNum(v.cfg)
+
+	for _, i := range pickSamples(len(records), v.cfg.SampleNum) {
+		r.Samples = append(r.Samples, DataSample{
+			"prompt":   truncateSampleField(*records[i].Prompt),
+			"response": truncateSampleField(*records[i].Response),
+		})
+	}
+
+	return
+}
+
+func (v promptResponseValidator) parse(line []byte) (item promptResponseRecord, err error) {
+	if _, err = checkText(line, len(line)); err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(line, &item); err != nil {
+		err = errors.New("not a json object")
+
+		return
+	}
+
+	if item.Prompt == nil || item.Response == nil {
+		err = errors.New("missing prompt or response")
+
+		return
+	}
+
+	if _, err = checkText([]byte(*item.Prompt), v.cfg.MaxTextLength); err != nil {
+		err = errors.New("prompt is " + err.Error())
+
+		return
+	}
+
+	if _, err = checkText([]byte(*item.Response), v.cfg.MaxTextLength); err != nil {
+		err = errors.New("response is " + err.Error())
+	}
+
+	return
+}
@@ -15,6 +15,7 @@ const (
 
 	finetuneTaskFinetune  = "finetune"
 	finetuneTaskInference = "inference"
+	finetuneTaskEvaluate  = "evaluate"
 
 	modelNameWukong = "wukong"
 )
@@ -220,11 +221,14 @@ func (r inputeFilePath) InputeFilePath() string {
 // FinetuneTask
 type FinetuneTask interface {
 	FinetuneTask() string
+	IsFinetune() bool
+	IsEvaluate() bool
 }
 
 func NewFinetuneTask(v string) (FinetuneTask, error) {
 	b := v == finetuneTaskFinetune ||
-		v == finetuneTaskInference
+		v == finetuneTaskInference ||
+		v == finetuneTaskEvaluate
 	if !b {
 		return nil, fmt.Errorf("invalid task %s", v)
 	}
@@ -238,6 +242,14 @@ func (r finetuneTask) FinetuneTask() string {
 	return string(r)
 }
 
+func (r finetuneTask) IsFinetune() bool {
+	return string(r) == finetuneTaskFinetune
+}
+
+func (r finetuneTask) IsEvaluate() bool {
+	return string(r) == finetuneTaskEvaluate
+}
+
 // FileName
 type FileName interface {
 	FileName() string
@@ -250,11 +262,15 @@ func NewFileName(v string, model string, task string) error {
 	fileInference := map[string]string{
 		modelNameWukong: "lora.ckpt",
 	}
+	fileEvaluate := map[string]string{
+		modelNameWukong: "eval.zip",
+	}
 
 	b1 := task == finetuneTaskFinetune && v == fileFinetune[model]
 	b2 := task == finetuneTaskInference && v == fileInference[model]
+	b3 := task == finetuneTaskEvaluate && v == fileEvaluate[model]
 
-	if b1 || b2 {
+	if b1 || b2 || b3 {
 		return nil
 	}
 
@@ -0,0 +1,93 @@
+package domain
+
+import "sort"
+
+// LeaderboardItem is a finetune with the metrics of its latest evaluation.
+type LeaderboardItem struct {
+	Finetune   *AICCFinetuneSummary
+	Evaluation *AICCFinetuneSummary
+}
+
+func (item *LeaderboardItem) Metric(name string) (float64, bool) {
+	if item.Evaluation == nil {
+		return 0, false
+	}
+
+	v, ok := item.Evaluation.Metrics[name]
+
+	return v, ok
+}
+
+// NewLeaderboard pairs each finetune with its latest evaluation which
+// has reported the metrics. The finetunes without evaluation are kept.
+func NewLeaderboard(v []AICCFinetuneSummary) []LeaderboardItem {
+	evaluations := map[string]*AICCFinetuneSummary{}
+
+	for i := range v {
+		item := &v[i]
+		if item.Source == "" || len(item.Metrics) == 0 {
+			continue
+		}
+
+		if e, ok := evaluations[item.Source]; !ok || e.CreatedAt < item.CreatedAt {
+			evaluations[item.Source] = item
+		}
+	}
+
+	r := make([]LeaderboardItem, 0, len(v))
+
+	for i := range v {
+		item := &v[i]
+		if item.Task != finetuneTaskFinetune {
+			continue
+		}
+
+		r = append(r, LeaderboardItem{
+			Finetune:   item,
+			Evaluation: evaluations[item.Id],
+		})
+	}
+
+	return r
+}
+
+// SortLeaderboard sorts the items by the metric. The items without
+// the metric are put at the end.
+func SortLeaderboard(items []LeaderboardItem, metric string, asc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, oka := items[i].Metric(metric)
+		b, okb := items[j].Metric(metric)
+
+		if !oka || !okb {
+			return oka && !okb
+		}
+
+		if asc {
+			return a < b
+		}
+
+		return a > b
+	})
+}
+
+// LeaderboardMetrics returns all the names of metrics on the leaderboard.
+func LeaderboardMetrics(items []LeaderboardItem) []string {
+	m := map[string]struct{}{}
+
+	for i := range items {
+		if e := items[i].Evaluation; e != nil {
+			for k := range e.Metrics {
+				m[k] = struct{}{}
+			}
+		}
+	}
+
+	r := make([]string, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+
+	sort.Strings(r)
+
+	return r
+}
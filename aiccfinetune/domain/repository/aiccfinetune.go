@@ -10,10 +10,14 @@ type AICCFinetune interface {
 	Get(*domain.AICCFinetuneIndex) (domain.AICCFinetune, error)
 	Delete(*domain.AICCFinetuneIndex) error
 	List(user types.Account, model domain.ModelName) ([]domain.AICCFinetuneSummary, int, error)
+	ListAll(user types.Account) ([]domain.ModelAICCFinetuneSummary, error)
 
 	SaveJob(*domain.AICCFinetuneIndex, *domain.JobInfo) error
 	GetJob(*domain.AICCFinetuneIndex) (domain.JobInfo, error)
 
 	UpdateJobDetail(*domain.AICCFinetuneIndex, *domain.JobDetail) error
 	GetJobDetail(*domain.AICCFinetuneIndex) (domain.JobDetail, string, error)
+
+	SaveDataUpload(types.Account, domain.ModelName, domain.FinetuneTask, *domain.DataUpload) error
+	GetDataUpload(types.Account, domain.ModelName, domain.FinetuneTask) (domain.DataUpload, error)
 }
@@ -9,16 +9,26 @@ import (
 
 	"github.com/opensourceways/xihe-server/aiccfinetune/domain"
 	"github.com/opensourceways/xihe-server/aiccfinetune/domain/aiccfinetune"
+	"github.com/opensourceways/xihe-server/domain/joblog"
+	"github.com/opensourceways/xihe-server/infrastructure/joblogimpl"
+)
+
+const (
+	envEvalSourceId     = "EVAL_SOURCE_ID"
+	envEvalSourceOutput = "EVAL_SOURCE_OUTPUT"
+	envEvalMetricsFile  = "EVAL_METRICS_FILE"
 )
 
 func NewAICCFinetune(cfg *Config) aiccfinetune.AICCFinetuneServer {
 	return &aiccFinetuneImpl{
+		downloader: joblogimpl.NewDownloader(),
 		doneStatus: sets.New[string](cfg.JobDoneStatus...),
 		endpoint:   cfg.Endpoint,
 	}
 }
 
 type aiccFinetuneImpl struct {
+	downloader joblog.Downloader
 	doneStatus sets.Set[string]
 	endpoint   string
 }
@@ -41,6 +51,16 @@ func (impl *aiccFinetuneImpl) CreateJob(endpoint string, info *domain.AICCFinetu
 		Hyperparameters: impl.toKeyValue(t.Hyperparameters),
 	}
 
+	// the sdk has no field for the finetune to be evaluated.
+	if v := t.Source; v != nil {
+		opt.Env = append(
+			opt.Env,
+			sdk.KeyValue{Key: envEvalSourceId, Value: v.FinetuneId},
+			sdk.KeyValue{Key: envEvalSourceOutput, Value: v.OutputPath},
+			sdk.KeyValue{Key: envEvalMetricsFile, Value: evaluationMetricsFile},
+		)
+	}
+
 	logrus.Debugf(
 		"create job, endpoint:%s, training:%s, opt:%#v",
 		endpoint, info.FinetuneId, opt,
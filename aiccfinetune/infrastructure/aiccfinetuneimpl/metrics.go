@@ -0,0 +1,57 @@
+package aiccfinetuneimpl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+)
+
+const (
+	// evaluationMetricsFile is the file in the output directory where the
+	// evaluation job writes its metrics, such as {"bleu": 0.32}.
+	evaluationMetricsFile = "metrics.json"
+
+	maxMetricsFileSize = 64 << 10
+	maxMetricsNum      = 32
+)
+
+func (impl *aiccFinetuneImpl) GetEvaluationMetrics(endpoint, outputDir string) (
+	map[string]float64, error,
+) {
+	link, err := impl.GetFileDownloadURL(endpoint, path.Join(outputDir, evaluationMetricsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := impl.downloader.Download(link)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxMetricsFileSize {
+		return nil, errors.New("the metrics file is too large")
+	}
+
+	return parseMetrics(b)
+}
+
+func parseMetrics(b []byte) (map[string]float64, error) {
+	var v map[string]float64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("invalid metrics file, %s", err.Error())
+	}
+
+	if len(v) > maxMetricsNum {
+		return nil, errors.New("too many metrics")
+	}
+
+	for k, item := range v {
+		if k == "" || math.IsNaN(item) || math.IsInf(item, 0) {
+			return nil, fmt.Errorf("invalid metric %q", k)
+		}
+	}
+
+	return v, nil
+}
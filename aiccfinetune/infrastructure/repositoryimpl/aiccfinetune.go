@@ -10,6 +10,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func NewAICCFinetuneRepo(m mongodbClient) repository.AICCFinetune {
@@ -149,6 +150,7 @@ func (impl aiccFinetuneRepoImpl) List(user types.Account, Model domain.ModelName
 				subfieldOfItems(fieldCreatedAt): 1,
 				subfieldOfItems(fieldDetail):    1,
 				subfieldOfItems(fieldTask):      1,
+				subfieldOfItems(fieldSource):    1,
 			}, &v)
 	}
 
@@ -172,6 +174,54 @@ func (impl aiccFinetuneRepoImpl) List(user types.Account, Model domain.ModelName
 	return r, v.Version, nil
 }
 
+func (impl aiccFinetuneRepoImpl) ListAll(user types.Account) (
+	r []domain.ModelAICCFinetuneSummary, err error,
+) {
+	var v []dAICCFinetune
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx,
+			bson.M{fieldUser: user.Account()},
+			options.Find().SetProjection(bson.M{
+				fieldModel:                      1,
+				subfieldOfItems(fieldId):        1,
+				subfieldOfItems(fieldName):      1,
+				subfieldOfItems(fieldDesc):      1,
+				subfieldOfItems(fieldCreatedAt): 1,
+				subfieldOfItems(fieldDetail):    1,
+				subfieldOfItems(fieldTask):      1,
+				subfieldOfItems(fieldSource):    1,
+			}), &v,
+		)
+	}
+
+	if err = withContext(f); err != nil {
+		return
+	}
+
+	for i := range v {
+		model, err := domain.NewModelName(v[i].Model)
+		if err != nil {
+			return nil, err
+		}
+
+		t := v[i].Items
+
+		for j := range t {
+			item := domain.ModelAICCFinetuneSummary{Model: model}
+
+			if err := t[j].toAICCFinetuneSummary(&item.AICCFinetuneSummary); err != nil {
+				return nil, err
+			}
+
+			r = append(r, item)
+		}
+	}
+
+	return
+}
+
 func (impl aiccFinetuneRepoImpl) SaveJob(info *domain.AICCFinetuneIndex, job *domain.JobInfo) error {
 	v := dJobInfo{
 
@@ -271,6 +321,7 @@ func (impl aiccFinetuneRepoImpl) UpdateJobDetail(info *domain.AICCFinetuneIndex,
 		Status:     detail.Status,
 		LogPath:    detail.LogPath,
 		OutputPath: detail.OutputPath,
+		Metrics:    detail.Metrics,
 	}
 
 	doc, err := genDoc(v)
@@ -312,6 +363,14 @@ func (repo aiccFinetuneRepoImpl) genAICCFinetuneDoc(p *domain.AICCFinetune) (bso
 		Env:             repo.toKeyValueDoc(p.Env),
 		Hyperparameters: repo.toKeyValueDoc(p.Hyperparameters),
 	}
+
+	if v := p.Source; v != nil {
+		docObj.Source = &dSource{
+			FinetuneId: v.FinetuneId,
+			OutputPath: v.OutputPath,
+		}
+	}
+
 	return genDoc(docObj)
 }
 
@@ -20,6 +20,11 @@ func (doc aiccFinetuneItem) toAICCFinetuneSummary(s *domain.AICCFinetuneSummary)
 	s.Id = doc.Id
 	s.Error = doc.JobDetail.Error
 	s.Task = doc.Task
+	s.Metrics = doc.JobDetail.Metrics
+
+	if doc.Source != nil {
+		s.Source = doc.Source.FinetuneId
+	}
 
 	return
 }
@@ -38,6 +43,7 @@ func (doc dJobDetail) toAICCFinetuneJobDetail(s *domain.JobDetail) (err error) {
 	s.Error = doc.Error
 	s.OutputPath = doc.OutputPath
 	s.LogPath = doc.LogPath
+	s.Metrics = doc.Metrics
 	return
 }
 
@@ -79,6 +85,13 @@ func (doc dAICCFinetune) toAICCFinetuneDO(f *domain.AICCFinetune) (err error) {
 
 	f.CreatedAt = doc.Items[0].CreatedAt
 
+	if v := doc.Items[0].Source; v != nil {
+		f.Source = &domain.EvaluationSource{
+			FinetuneId: v.FinetuneId,
+			OutputPath: v.OutputPath,
+		}
+	}
+
 	if f.Hyperparameters, err = toKeyValues(doc.Items[0].Hyperparameters); err != nil {
 		return
 	}
@@ -0,0 +1,120 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/opensourceways/xihe-server/aiccfinetune/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/infrastructure/repositories"
+)
+
+func (impl aiccFinetuneRepoImpl) SaveDataUpload(
+	user types.Account, model domain.ModelName, task domain.FinetuneTask, v *domain.DataUpload,
+) error {
+	filter := aiccFinetuneDocFilter(user.Account(), model.ModelName())
+
+	doc, err := genDoc(toDataUploadDoc(v))
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		// the data may be uploaded before any finetune is created.
+		_, err := impl.cli.NewDocIfNotExist(ctx, filter, bson.M{
+			fieldUser:    user.Account(),
+			fieldModel:   model.ModelName(),
+			fieldItems:   bson.A{},
+			fieldVersion: 0,
+		})
+		if err != nil && !impl.cli.IsDocExists(err) {
+			return err
+		}
+
+		_, err = impl.cli.Collection().UpdateOne(
+			ctx, filter,
+			bson.M{mongoCmdSet: bson.M{fieldData + "." + task.FinetuneTask(): doc}},
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (impl aiccFinetuneRepoImpl) GetDataUpload(
+	user types.Account, model domain.ModelName, task domain.FinetuneTask,
+) (r domain.DataUpload, err error) {
+	var v dAICCFinetune
+
+	key := fieldData + "." + task.FinetuneTask()
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(
+			ctx, aiccFinetuneDocFilter(user.Account(), model.ModelName()),
+			bson.M{key: 1}, &v,
+		)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repositories.NewErrorDataNotExists(err)
+		}
+
+		return
+	}
+
+	doc, ok := v.Data[task.FinetuneTask()]
+	if !ok {
+		err = repositories.NewErrorDataNotExists(errDocNotExists)
+
+		return
+	}
+
+	doc.toDataUpload(&r)
+
+	return
+}
+
+func toDataUploadDoc(v *domain.DataUpload) dDataUpload {
+	report := &v.Report
+
+	samples := make([]map[string]string, len(report.Samples))
+	for i := range report.Samples {
+		samples[i] = report.Samples[i]
+	}
+
+	return dDataUpload{
+		FileName:    v.FileName,
+		UploadedAt:  v.UploadedAt,
+		Valid:       report.Valid,
+		Format:      report.Format,
+		Size:        report.Size,
+		FileCount:   report.FileCount,
+		RecordCount: report.RecordCount,
+		Errors:      report.Errors,
+		Samples:     samples,
+	}
+}
+
+func (doc *dDataUpload) toDataUpload(v *domain.DataUpload) {
+	samples := make([]domain.DataSample, len(doc.Samples))
+	for i := range doc.Samples {
+		samples[i] = doc.Samples[i]
+	}
+
+	*v = domain.DataUpload{
+		FileName:   doc.FileName,
+		UploadedAt: doc.UploadedAt,
+		Report: domain.DataValidationReport{
+			Valid:       doc.Valid,
+			Format:      doc.Format,
+			Size:        doc.Size,
+			FileCount:   doc.FileCount,
+			RecordCount: doc.RecordCount,
+			Errors:      doc.Errors,
+			Samples:     samples,
+		},
+	}
+}
@@ -13,6 +13,8 @@ const (
 	fieldStatus    = "status"
 	fieldItems     = "items"
 	fieldTask      = "task"
+	fieldSource    = "source"
+	fieldData      = "data"
 )
 
 type dAICCFinetune struct {
@@ -21,6 +23,21 @@ type dAICCFinetune struct {
 	Version int    `bson:"version" json:"-"`
 
 	Items []aiccFinetuneItem `bson:"items"   json:"-"`
+
+	// Data is the latest data uploaded for each task.
+	Data map[string]dDataUpload `bson:"data"    json:"-"`
+}
+
+type dDataUpload struct {
+	FileName    string              `bson:"file_name"    json:"file_name"`
+	UploadedAt  int64               `bson:"uploaded_at"  json:"uploaded_at"`
+	Valid       bool                `bson:"valid"        json:"valid"`
+	Format      string              `bson:"format"       json:"format"`
+	Size        int64               `bson:"size"         json:"size"`
+	FileCount   int                 `bson:"file_count"   json:"file_count"`
+	RecordCount int                 `bson:"record_count" json:"record_count"`
+	Errors      []string            `bson:"errors"       json:"errors"`
+	Samples     []map[string]string `bson:"samples"      json:"samples"`
 }
 
 type aiccFinetuneItem struct {
@@ -31,11 +48,17 @@ type aiccFinetuneItem struct {
 	Task            string      `bson:"task"         json:"task"`
 	Env             []dKeyValue `bson:"env"           json:"env"`
 	Hyperparameters []dKeyValue `bson:"parameters"    json:"parameters"`
+	Source          *dSource    `bson:"source"        json:"source,omitempty"`
 	CreatedAt       int64       `bson:"created_at"    json:"created_at"`
 	Job             dJobInfo    `bson:"job"           json:"-"`
 	JobDetail       dJobDetail  `bson:"detail"        json:"-"`
 }
 
+type dSource struct {
+	FinetuneId string `bson:"id"          json:"id"`
+	OutputPath string `bson:"output"      json:"output"`
+}
+
 type dKeyValue struct {
 	Key   string `bson:"key"             json:"key"`
 	Value string `bson:"value"           json:"value"`
@@ -54,4 +77,6 @@ type dJobDetail struct {
 	Status     string `bson:"status"     json:"status,omitempty"`
 	LogPath    string `bson:"log"        json:"log,omitempty"`
 	OutputPath string `bson:"output"     json:"output,omitempty"`
+
+	Metrics map[string]float64 `bson:"metrics"    json:"metrics,omitempty"`
 }
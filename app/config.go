@@ -9,6 +9,8 @@ func Init(cfg *Config) {
 type Config struct {
 	WuKongMaxLikeNum int `json:"wukong_max_like_num"     required:"true"`
 	FinetuneMaxNum   int `json:"finetune_max_num"        required:"true"`
+
+	InferenceGateway InferenceGatewayConfig `json:"inference_gateway"`
 }
 
 func (cfg *Config) SetDefault() {
@@ -19,4 +21,41 @@ func (cfg *Config) SetDefault() {
 	if cfg.FinetuneMaxNum <= 0 {
 		cfg.FinetuneMaxNum = 5
 	}
+
+	cfg.InferenceGateway.setDefault()
+}
+
+// InferenceGatewayConfig limits the requests of each user to
+// all the inference instances through the gateway.
+type InferenceGatewayConfig struct {
+	// Endpoint is the prefix of the url of inference through the gateway.
+	// Set it to a host without the cookies of the site in production.
+	Endpoint string `json:"endpoint"`
+
+	// RateLimit is the max num of requests per minute.
+	RateLimit int `json:"rate_limit"`
+
+	// DailyLimit is the max num of requests per day.
+	DailyLimit int `json:"daily_limit"`
+
+	// MaxUsageDays is the max num of days of usage which can be queried.
+	MaxUsageDays int `json:"max_usage_days"`
+}
+
+func (cfg *InferenceGatewayConfig) setDefault() {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "/api/v1/inference/project"
+	}
+
+	if cfg.RateLimit <= 0 {
+		cfg.RateLimit = 60
+	}
+
+	if cfg.DailyLimit <= 0 {
+		cfg.DailyLimit = 2000
+	}
+
+	if cfg.MaxUsageDays <= 0 {
+		cfg.MaxUsageDays = 30
+	}
 }
@@ -42,6 +42,10 @@ type ErrorDuplicateTrainingName struct {
 	error
 }
 
+type ErrorExccedMaxTrainingSchedule struct {
+	error
+}
+
 const (
 	ErrorCodeSystem = "system"
 
@@ -57,11 +61,16 @@ const (
 	ErrorBigModelSensitiveInfo = "bigmodel_sensitive_info"
 	ErrorBigModelRecourseBusy  = "bigmodel_resource_busy"
 
+	ErrorJobLogTooLarge = "job_log_too_large"
+
 	ErrorTrainNoLog        = "train_no_log"
 	ErrorTrainNoOutput     = "train_no_output"
 	ErrorTrainNotFound     = "train_not_found"
 	ErrorTrainExccedMaxNum = "train_excced_max_num" // excced max training num for a user
 
+	ErrorTrainScheduleNotFound     = "train_schedule_not_found"
+	ErrorTrainScheduleExccedMaxNum = "train_schedule_excced_max_num"
+
 	ErrorWuKongInvalidId        = "wukong_invalid_id"
 	ErrorWuKongInvalidOwner     = "wukong_invalid_owner"
 	ErrorWuKongInvalidPath      = "wukong_invalid_path"
@@ -78,4 +87,13 @@ const (
 
 	ErrorAICCFinetuneNoLog    = "aicc_finetune_no_log"
 	ErrorAICCFinetuneNotFound = "aicc_finetune_not_found"
+
+	ErrorAICCFinetuneInvalidSource = "aicc_finetune_invalid_source"
+	ErrorAICCFinetuneInvalidData   = "aicc_finetune_invalid_data"
+	ErrorAICCFinetuneNoData        = "aicc_finetune_no_data"
+
+	ErrorInferenceNoLog       = "inference_no_log"
+	ErrorInferenceNotFound    = "inference_not_found"
+	ErrorInferenceNotActive   = "inference_not_active"
+	ErrorInferenceRateLimited = "inference_rate_limited"
 )
@@ -2,12 +2,17 @@ package app
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/opensourceways/xihe-server/domain"
 	"github.com/opensourceways/xihe-server/domain/finetune"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/repository"
+	jobapp "github.com/opensourceways/xihe-server/job/app"
 	"github.com/opensourceways/xihe-server/utils"
+	webhookapp "github.com/opensourceways/xihe-server/webhook/app"
+	webhookdomain "github.com/opensourceways/xihe-server/webhook/domain"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,24 +26,51 @@ type FinetuneService interface {
 	Delete(*FinetuneIndex) error
 	Terminate(*FinetuneIndex) error
 	GetJobInfo(*FinetuneIndex) (FinetuneJobDTO, string, error)
+	GetLog(*FinetuneIndex, *JobLogQuery) (JobLogDTO, string, error)
+	ListModels() []FinetuneModelDTO
 }
 
 func NewFinetuneService(
 	fs finetune.Finetune,
 	repo repository.Finetune,
 	sender message.Sender,
+	downloader joblog.Downloader,
+	jobs jobapp.JobNotifier,
 ) FinetuneService {
 	return finetuneService{
-		fs:     fs,
-		repo:   repo,
-		sender: sender,
+		fs:         fs,
+		repo:       repo,
+		sender:     sender,
+		downloader: downloader,
+		jobs:       jobs,
 	}
 }
 
 type finetuneService struct {
-	fs     finetune.Finetune
-	repo   repository.Finetune
-	sender message.Sender
+	fs         finetune.Finetune
+	repo       repository.Finetune
+	sender     message.Sender
+	downloader joblog.Downloader
+	jobs       jobapp.JobNotifier
+}
+
+func (s finetuneService) ListModels() []FinetuneModelDTO {
+	r := make([]FinetuneModelDTO, 0, len(domain.DomainConfig.Finetunes))
+
+	for k, v := range domain.DomainConfig.Finetunes {
+		r = append(r, FinetuneModelDTO{
+			Model:           k,
+			Tasks:           v.Tasks,
+			EnableDataset:   v.EnableDataset,
+			Hyperparameters: v.Schemas(),
+		})
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Model < r[j].Model
+	})
+
+	return r
 }
 
 func (s finetuneService) isJobDone(status string) bool {
@@ -91,6 +123,8 @@ func (s finetuneService) Create(cmd *FinetuneCreateCmd) (
 		return
 	}
 
+	s.jobs.Notify(user)
+
 	// send message
 	err1 := s.sender.CreateFinetune(&FinetuneIndex{
 		Owner: user,
@@ -146,7 +180,13 @@ func (s finetuneService) Delete(info *FinetuneIndex) error {
 		}
 	}
 
-	return s.repo.Delete(info)
+	if err := s.repo.Delete(info); err != nil {
+		return err
+	}
+
+	s.jobs.Notify(info.Owner)
+
+	return nil
 }
 
 func (s finetuneService) Terminate(info *FinetuneIndex) error {
@@ -183,6 +223,20 @@ func (s finetuneService) GetJobInfo(index *FinetuneIndex) (
 	return
 }
 
+// GetLog reads the log from the preview, because it is the only log
+// the finetune backend provides. For the same reason, there is no full
+// log of finetune to download.
+func (s finetuneService) GetLog(index *FinetuneIndex, q *JobLogQuery) (
+	dto JobLogDTO, code string, err error,
+) {
+	v, code, err := s.GetJobInfo(index)
+	if err == nil {
+		dto, err = ReadJobLogPreview(s.downloader, v.LogPreviewURL, v.IsDone, q)
+	}
+
+	return
+}
+
 // FinetuneInternalService
 type FinetuneInternalService interface {
 	UpdateJobDetail(*FinetuneIndex, *FinetuneJobDetail) error
@@ -190,18 +244,58 @@ type FinetuneInternalService interface {
 
 func NewFinetuneInternalService(
 	repo repository.Finetune,
+	webhook webhookapp.WebhookEventService,
+	jobs jobapp.JobNotifier,
 ) FinetuneInternalService {
 	return finetuneInternalService{
-		repo: repo,
+		repo:    repo,
+		webhook: webhook,
+		jobs:    jobs,
 	}
 }
 
 type finetuneInternalService struct {
-	repo repository.Finetune
+	repo    repository.Finetune
+	webhook webhookapp.WebhookEventService
+	jobs    jobapp.JobNotifier
 }
 
 func (s finetuneInternalService) UpdateJobDetail(info *FinetuneIndex, v *FinetuneJobDetail) error {
-	return s.repo.UpdateJobDetail(info, v)
+	job, err := s.repo.GetJob(info)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateJobDetail(info, v); err != nil {
+		return err
+	}
+
+	if job.Status != v.Status {
+		notifyFinetuneWebhook(s.webhook, info, v)
+	}
+
+	s.jobs.Notify(info.Owner)
+
+	return nil
+}
+
+func notifyFinetuneWebhook(
+	webhook webhookapp.WebhookEventService,
+	info *FinetuneIndex, v *FinetuneJobDetail,
+) {
+	err := webhook.Notify(&webhookapp.WebhookEventCmd{
+		Type:       webhookdomain.WebhookEventFinetune,
+		Owner:      info.Owner,
+		ResourceId: info.Id,
+		Status:     v.Status,
+		Error:      v.Error,
+	})
+	if err != nil {
+		logrus.Errorf(
+			"notify webhook of finetune(%s) failed, err:%s",
+			info.Id, err.Error(),
+		)
+	}
 }
 
 // FinetuneMessageService
@@ -212,16 +306,22 @@ type FinetuneMessageService interface {
 func NewFinetuneMessageService(
 	fs finetune.Finetune,
 	repo repository.Finetune,
+	webhook webhookapp.WebhookEventService,
+	jobs jobapp.JobNotifier,
 ) FinetuneMessageService {
 	return finetuneMessageService{
-		fs:   fs,
-		repo: repo,
+		fs:      fs,
+		repo:    repo,
+		webhook: webhook,
+		jobs:    jobs,
 	}
 }
 
 type finetuneMessageService struct {
-	fs   finetune.Finetune
-	repo repository.Finetune
+	fs      finetune.Finetune
+	repo    repository.Finetune
+	webhook webhookapp.WebhookEventService
+	jobs    jobapp.JobNotifier
 }
 
 func (s finetuneMessageService) CreateFinetuneJob(
@@ -231,10 +331,15 @@ func (s finetuneMessageService) CreateFinetuneJob(
 		return
 	}
 
-	err = s.repo.UpdateJobDetail(info, &FinetuneJobDetail{
+	detail := FinetuneJobDetail{
 		Status: trainingStatusScheduleFailed,
 		Error:  err.Error(),
-	})
+	}
+
+	if err = s.repo.UpdateJobDetail(info, &detail); err == nil {
+		notifyFinetuneWebhook(s.webhook, info, &detail)
+		s.jobs.Notify(info.Owner)
+	}
 
 	return
 }
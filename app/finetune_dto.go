@@ -22,6 +22,10 @@ func (cmd *FinetuneCreateCmd) Validate() error {
 		return errors.New("invalid cmd of creating finetune")
 	}
 
+	if cmd.Dataset != nil && !domain.IsFinetuneDatasetEnabled(cmd.Param.Model()) {
+		return errors.New("the model can't be finetuned by dataset")
+	}
+
 	return nil
 }
 
@@ -65,3 +69,10 @@ type FinetuneJobDTO struct {
 	IsDone        bool
 	LogPreviewURL string
 }
+
+type FinetuneModelDTO struct {
+	Model           string                        `json:"model"`
+	Tasks           []string                      `json:"tasks"`
+	EnableDataset   bool                          `json:"enable_dataset"`
+	Hyperparameters []domain.HyperparameterSchema `json:"hyperparameters"`
+}
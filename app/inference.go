@@ -2,14 +2,20 @@ package app
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"sort"
 
 	"github.com/opensourceways/xihe-server/domain"
 	"github.com/opensourceways/xihe-server/domain/inference"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/platform"
 	"github.com/opensourceways/xihe-server/domain/repository"
 	userrepo "github.com/opensourceways/xihe-server/user/domain/repository"
 	"github.com/opensourceways/xihe-server/utils"
+	webhookapp "github.com/opensourceways/xihe-server/webhook/app"
+	webhookdomain "github.com/opensourceways/xihe-server/webhook/domain"
 	"github.com/sirupsen/logrus"
 )
 
@@ -49,21 +55,115 @@ func (cmd *InferenceCreateCmd) toInference(v *domain.Inference, lastCommit, requ
 	v.Requester = requester
 }
 
+// InferenceInstanceCmd selects an instance of the project.
+type InferenceInstanceCmd struct {
+	Project     domain.ResourceIndex
+	ProjectName domain.ResourceName
+	Id          string
+}
+
+// InferenceRestartCmd stops the instance and creates a new one
+// on the latest commit of project.
+type InferenceRestartCmd struct {
+	InferenceCreateCmd
+
+	Id string
+}
+
+type InferenceLogCmd struct {
+	Project domain.ResourceIndex
+	Id      string
+	Type    string
+}
+
+func (cmd *InferenceLogCmd) Validate() error {
+	if !domain.IsValidInferenceLogType(cmd.Type) {
+		return errors.New("invalid log type")
+	}
+
+	return nil
+}
+
+// InferenceUpdateCmd is the detail of instance reported by the container
+// manager through the internal api. FailureStage and FailureCode are set
+// along with Error when the instance fails, such as build:oom.
+type InferenceUpdateCmd struct {
+	InferenceIndex
+
+	Expiry        int64
+	AccessURL     string
+	StartupLogURL string
+	RuntimeLogURL string
+	Error         string
+	FailureStage  string
+	FailureCode   string
+}
+
+func (cmd *InferenceUpdateCmd) Validate() error {
+	if cmd.Project.Owner == nil || cmd.Project.Id == "" || cmd.Id == "" {
+		return errors.New("invalid cmd")
+	}
+
+	for _, v := range []string{cmd.AccessURL, cmd.StartupLogURL, cmd.RuntimeLogURL} {
+		if v != "" && !isHTTPURL(v) {
+			return fmt.Errorf("invalid url: %s", v)
+		}
+	}
+
+	if (cmd.FailureStage != "" || cmd.FailureCode != "") && cmd.Error == "" {
+		return errors.New("missing error of failure")
+	}
+
+	return nil
+}
+
+func (cmd *InferenceUpdateCmd) toInferenceDetail() InferenceDetail {
+	v := InferenceDetail{
+		Expiry:        cmd.Expiry,
+		Error:         cmd.Error,
+		AccessURL:     cmd.AccessURL,
+		StartupLogURL: cmd.StartupLogURL,
+		RuntimeLogURL: cmd.RuntimeLogURL,
+	}
+
+	if cmd.FailureStage != "" || cmd.FailureCode != "" {
+		v.Failure = &domain.InferenceError{
+			Stage:   cmd.FailureStage,
+			Code:    cmd.FailureCode,
+			Message: cmd.Error,
+		}
+	}
+
+	return v
+}
+
+func isHTTPURL(v string) bool {
+	u, err := url.Parse(v)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
 type InferenceService interface {
 	Create(string, *UserInfo, *InferenceCreateCmd) (InferenceDTO, string, error)
 	Get(info *InferenceIndex) (InferenceDTO, error)
+	List(*domain.ResourceIndex) ([]InferenceInstanceDTO, error)
+	Stop(*InferenceInstanceCmd) (string, error)
+	Restart(string, *UserInfo, *InferenceRestartCmd) (InferenceDTO, string, string, error)
+	GetLog(*InferenceLogCmd, *JobLogQuery) (JobLogDTO, string, error)
 }
 
 func NewInferenceService(
 	p platform.RepoFile,
 	repo repository.Inference,
 	sender message.Sender,
+	downloader joblog.Downloader,
 	minSurvivalTime int,
 ) InferenceService {
 	return inferenceService{
 		p:               p,
 		repo:            repo,
 		sender:          sender,
+		downloader:      downloader,
 		minSurvivalTime: int64(minSurvivalTime),
 	}
 }
@@ -72,14 +172,48 @@ type inferenceService struct {
 	p               platform.RepoFile
 	repo            repository.Inference
 	sender          message.Sender
+	downloader      joblog.Downloader
 	minSurvivalTime int64
 }
 
+// InferenceDTO is the inference instance for the user. URL is the url
+// through the gateway, the access url of instance is never exposed.
 type InferenceDTO struct {
 	expiry     int64
-	Error      string `json:"error"`
-	AccessURL  string `json:"access_url"`
-	InstanceId string `json:"inference_id"`
+	accessURL  string
+	Error      string             `json:"error"`
+	Failure    *InferenceErrorDTO `json:"failure,omitempty"`
+	URL        string             `json:"url"`
+	InstanceId string             `json:"inference_id"`
+}
+
+type InferenceErrorDTO struct {
+	Stage   string `json:"stage"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func toInferenceErrorDTO(v *domain.InferenceError) *InferenceErrorDTO {
+	if v == nil {
+		return nil
+	}
+
+	return &InferenceErrorDTO{
+		Stage:   v.Stage,
+		Code:    v.Code,
+		Message: v.Message,
+	}
+}
+
+type InferenceInstanceDTO struct {
+	Id         string             `json:"id"`
+	LastCommit string             `json:"commit"`
+	Status     string             `json:"status"`
+	Expiry     int64              `json:"expiry"`
+	CreatedAt  int64              `json:"created_at"`
+	URL        string             `json:"url,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Failure    *InferenceErrorDTO `json:"failure,omitempty"`
 }
 
 func (dto *InferenceDTO) hasResult() bool {
@@ -87,7 +221,13 @@ func (dto *InferenceDTO) hasResult() bool {
 }
 
 func (dto *InferenceDTO) canReuseCurrent() bool {
-	return dto.AccessURL != ""
+	return dto.accessURL != ""
+}
+
+func (dto *InferenceDTO) setAccessURL(project *domain.ResourceIndex, accessURL string) {
+	if dto.accessURL = accessURL; accessURL != "" {
+		dto.URL = inferenceGatewayURL(project, dto.InstanceId)
+	}
 }
 
 func (s inferenceService) Create(user string, owner *UserInfo, cmd *InferenceCreateCmd) (
@@ -156,8 +296,164 @@ func (s inferenceService) Get(index *InferenceIndex) (dto InferenceDTO, err erro
 	v, err := s.repo.FindInstance(index)
 
 	dto.Error = v.Error
-	dto.AccessURL = v.AccessURL
+	dto.Failure = toInferenceErrorDTO(v.Failure)
 	dto.InstanceId = v.Id
+	dto.setAccessURL(&index.Project, v.AccessURL)
+
+	return
+}
+
+func (s inferenceService) List(index *domain.ResourceIndex) ([]InferenceInstanceDTO, error) {
+	v, err := s.repo.FindProjectInstances(index)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	sort.SliceStable(v, func(i, j int) bool {
+		return v[i].CreatedAt > v[j].CreatedAt
+	})
+
+	now := utils.Now()
+	r := make([]InferenceInstanceDTO, len(v))
+
+	for i := range v {
+		item := &v[i]
+
+		r[i] = InferenceInstanceDTO{
+			Id:         item.Id,
+			LastCommit: item.LastCommit,
+			Status:     item.StatusAt(now),
+			Expiry:     item.Expiry,
+			CreatedAt:  item.CreatedAt,
+			Error:      item.Error,
+			Failure:    toInferenceErrorDTO(item.Failure),
+		}
+
+		if item.AccessURL != "" {
+			r[i].URL = inferenceGatewayURL(index, item.Id)
+		}
+	}
+
+	return r, nil
+}
+
+func (s inferenceService) Stop(cmd *InferenceInstanceCmd) (code string, err error) {
+	v, code, err := s.findInstance(&cmd.Project, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	if !v.IsActive(utils.Now()) {
+		code = ErrorInferenceNotActive
+		err = errors.New("the inference instance is not active")
+
+		return
+	}
+
+	err = s.stop(cmd, v.LastCommit, true)
+
+	return
+}
+
+// stop marks the instance as stopped and notifies the manager to stop it if it is active.
+func (s inferenceService) stop(cmd *InferenceInstanceCmd, lastCommit string, active bool) error {
+	info := domain.InferenceInfo{
+		InferenceIndex: domain.InferenceIndex{
+			Project:    cmd.Project,
+			Id:         cmd.Id,
+			LastCommit: lastCommit,
+		},
+		ProjectName: cmd.ProjectName,
+	}
+
+	detail := domain.InferenceDetail{
+		Expiry:  utils.Now(),
+		Stopped: true,
+	}
+
+	if err := s.repo.UpdateDetail(&info.InferenceIndex, &detail); err != nil || !active {
+		return err
+	}
+
+	return s.sender.StopInference(&info)
+}
+
+func (s inferenceService) Restart(user string, owner *UserInfo, cmd *InferenceRestartCmd) (
+	dto InferenceDTO, sha, code string, err error,
+) {
+	index := domain.ResourceIndex{
+		Owner: cmd.ProjectOwner,
+		Id:    cmd.ProjectId,
+	}
+
+	v, code, err := s.findInstance(&index, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	// the failed instance is also marked as stopped, so that
+	// it can be created again on the same commit.
+	if !v.Stopped {
+		err = s.stop(&InferenceInstanceCmd{
+			Project:     index,
+			ProjectName: cmd.ProjectName,
+			Id:          cmd.Id,
+		}, v.LastCommit, v.IsActive(utils.Now()))
+		if err != nil {
+			return
+		}
+	}
+
+	dto, sha, err = s.Create(user, owner, &cmd.InferenceCreateCmd)
+
+	return
+}
+
+func (s inferenceService) GetLog(cmd *InferenceLogCmd, q *JobLogQuery) (
+	dto JobLogDTO, code string, err error,
+) {
+	v, code, err := s.findInstance(&cmd.Project, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	link, done := v.LogURL(cmd.Type, utils.Now())
+	if link == "" && done {
+		code = ErrorInferenceNoLog
+		err = errors.New("no log")
+
+		return
+	}
+
+	dto, err = ReadJobLog(s.downloader, link, done, q)
+
+	return
+}
+
+func (s inferenceService) findInstance(index *domain.ResourceIndex, id string) (
+	repository.InferenceInstance, string, error,
+) {
+	return findInferenceInstance(s.repo, index, id)
+}
+
+func findInferenceInstance(repo repository.Inference, index *domain.ResourceIndex, id string) (
+	r repository.InferenceInstance, code string, err error,
+) {
+	v, err := repo.FindProjectInstances(index)
+	if err != nil {
+		return
+	}
+
+	for i := range v {
+		if v[i].Id == id {
+			r = v[i]
+
+			return
+		}
+	}
+
+	code = ErrorInferenceNotFound
+	err = errors.New("the inference instance is not found")
 
 	return
 }
@@ -175,8 +471,14 @@ func (s inferenceService) check(instance *domain.Inference) (
 	for i := range v {
 		item := &v[i]
 
+		// the stopped instance is not reused and does not block creating a new one.
+		if item.Stopped {
+			continue
+		}
+
 		if item.Error != "" {
 			dto.Error = item.Error
+			dto.Failure = toInferenceErrorDTO(item.Failure)
 			dto.InstanceId = item.Id
 
 			return
@@ -194,34 +496,98 @@ func (s inferenceService) check(instance *domain.Inference) (
 	e, n := target.Expiry, utils.Now()
 	if n < e && n+s.minSurvivalTime <= e {
 		dto.expiry = target.Expiry
-		dto.AccessURL = target.AccessURL
 		dto.InstanceId = target.Id
+		dto.setAccessURL(&instance.Project, target.AccessURL)
 	}
 
 	return
 }
 
+// InferenceInternalService handles the detail reported by the container
+// manager. Update is for the internal api of remote manager and
+// UpdateDetail is for the local manager in the same process.
 type InferenceInternalService interface {
+	Update(*InferenceUpdateCmd) error
 	UpdateDetail(*InferenceIndex, *InferenceDetail) error
 }
 
-func NewInferenceInternalService(repo repository.Inference) InferenceInternalService {
+// InferenceReplicaUpdater updates the instance which is a replica of
+// deployment rather than the one created for trying the project.
+// It returns false if the instance is not a replica.
+type InferenceReplicaUpdater interface {
+	UpdateReplica(*InferenceIndex, *InferenceDetail) (bool, error)
+}
+
+func NewInferenceInternalService(
+	repo repository.Inference,
+	webhook webhookapp.WebhookEventService,
+	replicas InferenceReplicaUpdater,
+) InferenceInternalService {
 	return inferenceInternalService{
-		repo: repo,
+		repo:     repo,
+		webhook:  webhook,
+		replicas: replicas,
 	}
 }
 
 type inferenceInternalService struct {
-	repo repository.Inference
+	repo     repository.Inference
+	webhook  webhookapp.WebhookEventService
+	replicas InferenceReplicaUpdater
+}
+
+func (s inferenceInternalService) Update(cmd *InferenceUpdateCmd) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
+	detail := cmd.toInferenceDetail()
+
+	return s.UpdateDetail(&cmd.InferenceIndex, &detail)
 }
 
 func (s inferenceInternalService) UpdateDetail(index *InferenceIndex, detail *InferenceDetail) error {
-	return s.repo.UpdateDetail(index, detail)
+	if detail.Failure != nil && detail.Error == "" {
+		detail.Error = detail.Failure.Message
+	}
+
+	if ok, err := s.replicas.UpdateReplica(index, detail); ok || err != nil {
+		return err
+	}
+
+	old, err := s.repo.FindInstance(index)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateDetail(index, detail); err != nil {
+		return err
+	}
+
+	if status := detail.Status(); status != old.Status() {
+		err := s.webhook.Notify(&webhookapp.WebhookEventCmd{
+			Type:       webhookdomain.WebhookEventInference,
+			Owner:      index.Project.Owner,
+			ProjectId:  index.Project.Id,
+			ResourceId: index.Id,
+			Status:     status,
+			Error:      detail.Error,
+		})
+		if err != nil {
+			logrus.Errorf(
+				"notify webhook of inference(%s) failed, err:%s",
+				index.Id, err.Error(),
+			)
+		}
+	}
+
+	return nil
 }
 
 type InferenceMessageService interface {
 	CreateInferenceInstance(*domain.InferenceInfo) error
 	ExtendSurvivalTime(*message.InferenceExtendInfo) error
+	StopInstance(*domain.InferenceInfo) error
 }
 
 func NewInferenceMessageService(
@@ -291,3 +657,7 @@ func (s inferenceMessageService) ExtendSurvivalTime(info *message.InferenceExten
 
 	return s.repo.UpdateDetail(&info.InferenceIndex, &domain.InferenceDetail{Expiry: n})
 }
+
+func (s inferenceMessageService) StopInstance(info *domain.InferenceInfo) error {
+	return s.manager.Stop(&info.InferenceIndex)
+}
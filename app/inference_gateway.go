@@ -0,0 +1,232 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	common "github.com/opensourceways/xihe-server/common/domain"
+	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// InferenceRouteCmd is the request of user to the path of an instance
+// of the project.
+type InferenceRouteCmd struct {
+	User    domain.Account
+	Project domain.ResourceIndex
+	Id      string
+	Path    string
+}
+
+// InferenceRouteDTO is the instance which will serve the request.
+// Metered is false if the request is not a call of the inference,
+// such as loading the assets of page, which is neither limited nor
+// accounted.
+type InferenceRouteDTO struct {
+	AccessURL string
+	Metered   bool
+}
+
+type InferenceRecordCmd struct {
+	User    domain.Account
+	Project domain.ResourceIndex
+	Failed  bool
+	Latency int64
+}
+
+type InferenceUsageQueryCmd struct {
+	Project domain.ResourceIndex
+	Days    int
+}
+
+func (cmd *InferenceUsageQueryCmd) Validate() error {
+	if n := appConfig.InferenceGateway.MaxUsageDays; cmd.Days <= 0 || cmd.Days > n {
+		return fmt.Errorf("days should be between 1 and %d", n)
+	}
+
+	return nil
+}
+
+type InferenceUsageDTO struct {
+	Date       string `json:"date"`
+	User       string `json:"user"`
+	Requests   int    `json:"requests"`
+	Failures   int    `json:"failures"`
+	AvgLatency int64  `json:"avg_latency"`
+}
+
+type InferenceUsageStatsDTO struct {
+	Users      int                 `json:"users"`
+	Requests   int                 `json:"requests"`
+	Failures   int                 `json:"failures"`
+	AvgLatency int64               `json:"avg_latency"`
+	Items      []InferenceUsageDTO `json:"items"`
+}
+
+// InferenceGatewayService routes the requests of users to the inference
+// instances and meters the usage of each user and project.
+type InferenceGatewayService interface {
+	Route(*InferenceRouteCmd) (InferenceRouteDTO, string, error)
+	Record(*InferenceRecordCmd) error
+	GetUsage(*InferenceUsageQueryCmd) (InferenceUsageStatsDTO, error)
+}
+
+func NewInferenceGatewayService(
+	repo repository.Inference,
+	usage repository.InferenceUsage,
+	counter commonrepo.UsageCounter,
+) InferenceGatewayService {
+	return inferenceGatewayService{
+		repo:    repo,
+		usage:   usage,
+		counter: counter,
+	}
+}
+
+type inferenceGatewayService struct {
+	repo    repository.Inference
+	usage   repository.InferenceUsage
+	counter commonrepo.UsageCounter
+}
+
+func (s inferenceGatewayService) Route(cmd *InferenceRouteCmd) (
+	dto InferenceRouteDTO, code string, err error,
+) {
+	v, code, err := findInferenceInstance(s.repo, &cmd.Project, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	now := utils.Now()
+
+	if !v.IsActive(now) || v.AccessURL == "" {
+		code = ErrorInferenceNotActive
+		err = errors.New("the inference instance is not active")
+
+		return
+	}
+
+	metered := isInferenceCall(cmd.Path)
+
+	if metered {
+		if code, err = s.admit(cmd.User, now); err != nil {
+			return
+		}
+	}
+
+	dto.AccessURL = v.AccessURL
+	dto.Metered = metered
+
+	return
+}
+
+// admit counts the request only if the user has not reached the limits.
+func (s inferenceGatewayService) admit(user domain.Account, now int64) (string, error) {
+	cfg := &appConfig.InferenceGateway
+
+	i, err := s.counter.Admit(
+		domain.InferenceUsageLimits(user, now, cfg.RateLimit, cfg.DailyLimit),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if i >= 0 {
+		return ErrorInferenceRateLimited, errors.New("exceed the limit of requests")
+	}
+
+	return "", nil
+}
+
+func (s inferenceGatewayService) Record(cmd *InferenceRecordCmd) error {
+	u := domain.InferenceUsage{
+		Project:  cmd.Project,
+		User:     cmd.User,
+		Date:     common.UsageDate(utils.Now()),
+		Requests: 1,
+		Latency:  cmd.Latency,
+	}
+
+	if cmd.Failed {
+		u.Failures = 1
+	}
+
+	return s.usage.Record(&u)
+}
+
+func (s inferenceGatewayService) GetUsage(cmd *InferenceUsageQueryCmd) (
+	dto InferenceUsageStatsDTO, err error,
+) {
+	since := common.UsageDate(utils.Now() - int64(cmd.Days-1)*86400)
+
+	v, err := s.usage.FindAll(&cmd.Project, since)
+	if err != nil {
+		return
+	}
+
+	users := map[string]struct{}{}
+	latency := int64(0)
+
+	dto.Items = make([]InferenceUsageDTO, len(v))
+	for i := range v {
+		item := &v[i]
+
+		dto.Items[i] = InferenceUsageDTO{
+			Date:       item.Date,
+			User:       item.User.Account(),
+			Requests:   item.Requests,
+			Failures:   item.Failures,
+			AvgLatency: avgLatency(item.Latency, item.Requests),
+		}
+
+		users[item.User.Account()] = struct{}{}
+		dto.Requests += item.Requests
+		dto.Failures += item.Failures
+		latency += item.Latency
+	}
+
+	dto.Users = len(users)
+	dto.AvgLatency = avgLatency(latency, dto.Requests)
+
+	return
+}
+
+// staticAssetPaths are the paths of the static assets of gradio and
+// streamlit, which are loaded many times by a page, so they are not
+// limited. All the other requests, whatever the app is, are limited.
+var staticAssetPaths = []string{
+	"/assets/", "/static/", "/file=", "/favicon.ico",
+	"/theme.css", "/manifest.json", "/robots.txt",
+}
+
+func isInferenceCall(path string) bool {
+	path = strings.TrimPrefix(path, "/gradio_api")
+
+	for _, v := range staticAssetPaths {
+		if strings.HasPrefix(path, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inferenceGatewayURL returns the url of the instance through the gateway.
+func inferenceGatewayURL(project *domain.ResourceIndex, id string) string {
+	return fmt.Sprintf(
+		"%s/%s/%s/instances/%s/serve/",
+		strings.TrimSuffix(appConfig.InferenceGateway.Endpoint, "/"),
+		project.Owner.Account(), project.Id, id,
+	)
+}
+
+func avgLatency(total int64, n int) int64 {
+	if n == 0 {
+		return 0
+	}
+
+	return total / int64(n)
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/opensourceways/xihe-server/domain/joblog"
+)
+
+// jobLogChunkSize is the max bytes of log downloaded by each read.
+const jobLogChunkSize = 1 << 20
+
+type JobLogQuery = joblog.Query
+
+// JobLogDTO is the log read by the query.
+// Truncated means the log was truncated or rotated, so the client should
+// drop the log it has got and read again from NextOffset.
+// IsPreview means the log is the latest part of log instead of the full one,
+// so the client should replace the log it has got with it.
+type JobLogDTO struct {
+	Log        string `json:"log"`
+	Offset     int64  `json:"offset"`
+	NextOffset int64  `json:"next_offset"`
+	IsDone     bool   `json:"is_done"`
+	Truncated  bool   `json:"truncated"`
+	IsPreview  bool   `json:"is_preview"`
+}
+
+func (dto *JobLogDTO) setLines(lines []string) {
+	if len(lines) > 0 {
+		dto.Log = strings.Join(lines, "\n") + "\n"
+	}
+}
+
+// ReadJobLog reads the full log by the link from the offset of query.
+// Only the part of log needed is downloaded.
+// The log is regarded as complete only after the job is done.
+func ReadJobLog(
+	downloader joblog.Downloader, link string, isDone bool, q *JobLogQuery,
+) (dto JobLogDTO, err error) {
+	dto.IsDone = isDone
+	dto.Offset = q.Offset
+	dto.NextOffset = q.Offset
+
+	if link == "" {
+		return
+	}
+
+	content, size, err := downloader.DownloadRange(link, q.Offset, jobLogChunkSize)
+	if err != nil {
+		return
+	}
+
+	chunk := joblog.Read(content, size, isDone, q)
+
+	dto.Offset = chunk.Offset
+	dto.NextOffset = chunk.NextOffset
+	dto.Truncated = chunk.Truncated
+	dto.setLines(chunk.Lines)
+
+	return
+}
+
+// ReadJobLogPreview reads the preview of log by the link. The preview is
+// the latest part of log which slides as the job runs, so the offset of
+// query is meaningless and the whole preview is read every time.
+func ReadJobLogPreview(
+	downloader joblog.Downloader, link string, isDone bool, q *JobLogQuery,
+) (dto JobLogDTO, err error) {
+	dto.IsDone = isDone
+	dto.IsPreview = true
+
+	if link == "" {
+		return
+	}
+
+	content, size, err := downloader.DownloadRange(link, 0, jobLogChunkSize)
+	if err != nil {
+		return
+	}
+
+	chunk := joblog.Read(content, size, isDone, &JobLogQuery{
+		Grep:  q.Grep,
+		Since: q.Since,
+		Until: q.Until,
+	})
+
+	dto.setLines(chunk.Lines)
+
+	return
+}
+
+// DownloadFullJobLog downloads the whole log for archiving.
+func DownloadFullJobLog(downloader joblog.Downloader, link string) ([]byte, string, error) {
+	v, err := downloader.Download(link)
+	if err != nil && errors.Is(err, joblog.ErrTooLarge) {
+		return nil, ErrorJobLogTooLarge, err
+	}
+
+	return v, "", err
+}
@@ -5,10 +5,14 @@ import (
 	"strconv"
 
 	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/repository"
 	"github.com/opensourceways/xihe-server/domain/training"
+	jobapp "github.com/opensourceways/xihe-server/job/app"
 	"github.com/opensourceways/xihe-server/utils"
+	webhookapp "github.com/opensourceways/xihe-server/webhook/app"
+	webhookdomain "github.com/opensourceways/xihe-server/webhook/domain"
 	"github.com/sirupsen/logrus"
 )
 
@@ -32,6 +36,8 @@ type TrainingService interface {
 	Terminate(*TrainingIndex) error
 	GetLogDownloadURL(*TrainingIndex) (string, string, error)
 	GetOutputDownloadURL(*TrainingIndex) (string, string, error)
+	GetLog(*TrainingIndex, *JobLogQuery) (JobLogDTO, string, error)
+	GetFullLog(*TrainingIndex) ([]byte, string, error)
 	CreateTrainingJob(*TrainingIndex, string, bool) (bool, error)
 }
 
@@ -39,28 +45,41 @@ func NewTrainingService(
 	train training.Training,
 	repo repository.Training,
 	sender message.MessageProducer,
+	downloader joblog.Downloader,
+	webhook webhookapp.WebhookEventService,
+	jobs jobapp.JobNotifier,
 	maxTrainingRecordNum int,
 ) TrainingService {
 	return trainingService{
-		train:  train,
-		repo:   repo,
-		sender: sender,
+		train:      train,
+		repo:       repo,
+		sender:     sender,
+		downloader: downloader,
+		webhook:    webhook,
+		jobs:       jobs,
 
 		maxTrainingRecordNum: maxTrainingRecordNum,
 	}
 }
 
 type trainingService struct {
-	log    *logrus.Entry
-	train  training.Training
-	repo   repository.Training
-	sender message.MessageProducer
+	log        *logrus.Entry
+	train      training.Training
+	repo       repository.Training
+	sender     message.MessageProducer
+	downloader joblog.Downloader
+	webhook    webhookapp.WebhookEventService
+	jobs       jobapp.JobNotifier
 
 	maxTrainingRecordNum int
 }
 
 func (s trainingService) isJobDone(status string) bool {
-	return status != "" && (s.train.IsJobDone(status) || status == trainingStatusScheduleFailed)
+	return isTrainingJobDone(s.train, status)
+}
+
+func isTrainingJobDone(train training.Training, status string) bool {
+	return status != "" && (train.IsJobDone(status) || status == trainingStatusScheduleFailed)
 }
 
 func (s trainingService) Create(cmd *TrainingCreateCmd) (string, error) {
@@ -123,6 +142,8 @@ func (s trainingService) create(
 		return "", err
 	}
 
+	s.jobs.Notify(user)
+
 	// send message
 	index := TrainingIndex{
 		Project: domain.ResourceIndex{
@@ -201,7 +222,39 @@ func (s trainingService) GetLastTrainingConfig(cmd *ResourceIndexCmd) (dto Train
 }
 
 func (s trainingService) UpdateJobDetail(info *TrainingIndex, v *JobDetail) error {
-	return s.repo.UpdateJobDetail(info, v)
+	old, _, err := s.repo.GetJobDetail(info)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateJobDetail(info, v); err != nil {
+		return err
+	}
+
+	if old.Status != v.Status {
+		s.notifyWebhook(info, v)
+	}
+
+	s.jobs.Notify(info.Project.Owner)
+
+	return nil
+}
+
+func (s trainingService) notifyWebhook(info *TrainingIndex, v *JobDetail) {
+	err := s.webhook.Notify(&webhookapp.WebhookEventCmd{
+		Type:       webhookdomain.WebhookEventTraining,
+		Owner:      info.Project.Owner,
+		ProjectId:  info.Project.Id,
+		ResourceId: info.TrainingId,
+		Status:     v.Status,
+		Error:      v.Error,
+	})
+	if err != nil {
+		logrus.Errorf(
+			"notify webhook of training(%s) failed, err:%s",
+			info.TrainingId, err.Error(),
+		)
+	}
 }
 
 func (s trainingService) Delete(info *TrainingIndex) error {
@@ -224,7 +277,13 @@ func (s trainingService) Delete(info *TrainingIndex) error {
 		}
 	}
 
-	return s.repo.Delete(info)
+	if err := s.repo.Delete(info); err != nil {
+		return err
+	}
+
+	s.jobs.Notify(info.Project.Owner)
+
+	return nil
 }
 
 func (s trainingService) Terminate(info *TrainingIndex) error {
@@ -272,6 +331,70 @@ func (s trainingService) GetOutputDownloadURL(info *TrainingIndex) (
 	return
 }
 
+func (s trainingService) GetLog(info *TrainingIndex, q *JobLogQuery) (
+	dto JobLogDTO, code string, err error,
+) {
+	link, isPreview, isDone, code, err := s.getLogLink(info)
+	if err != nil {
+		return
+	}
+
+	if isPreview {
+		dto, err = ReadJobLogPreview(s.downloader, link, isDone, q)
+	} else {
+		dto, err = ReadJobLog(s.downloader, link, isDone, q)
+	}
+
+	return
+}
+
+func (s trainingService) GetFullLog(info *TrainingIndex) (
+	[]byte, string, error,
+) {
+	link, isPreview, isDone, code, err := s.getLogLink(info)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if !isDone || isPreview || link == "" {
+		return nil, ErrorTrainNoLog, errors.New("not ready")
+	}
+
+	return DownloadFullJobLog(s.downloader, link)
+}
+
+// getLogLink returns the link of full log if the backend has provided it,
+// otherwise the link of log preview.
+func (s trainingService) getLogLink(info *TrainingIndex) (
+	link string, isPreview, isDone bool, code string, err error,
+) {
+	data, err := s.repo.Get(info)
+	if err != nil {
+		if repository.IsErrorResourceNotExists(err) {
+			code = ErrorTrainNotFound
+		}
+
+		return
+	}
+
+	detail := &data.JobDetail
+	isDone = s.isJobDone(detail.Status)
+
+	job := &data.Job
+	if job.Endpoint == "" || job.JobId == "" {
+		return
+	}
+
+	if detail.LogPath != "" {
+		link, err = s.train.GetFileDownloadURL(job.Endpoint, detail.LogPath)
+	} else {
+		isPreview = true
+		link, err = s.train.GetLogPreviewURL(job.Endpoint, job.JobId)
+	}
+
+	return
+}
+
 func (s trainingService) CreateTrainingJob(
 	info *TrainingIndex, endpoint string, lastChance bool,
 ) (retry bool, err error) {
@@ -281,13 +404,17 @@ func (s trainingService) CreateTrainingJob(
 	}
 
 	if lastChance {
-		err = s.repo.UpdateJobDetail(info, &JobDetail{
+		detail := JobDetail{
 			Status: trainingStatusScheduleFailed,
 			Error:  err.Error(),
-		})
-		if err != nil {
+		}
+
+		if err = s.repo.UpdateJobDetail(info, &detail); err != nil {
 			return
 		}
+
+		s.notifyWebhook(info, &detail)
+		s.jobs.Notify(info.Project.Owner)
 	}
 
 	return
@@ -0,0 +1,291 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/domain/training"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+type TrainingScheduleIndex = domain.TrainingScheduleIndex
+
+type TrainingScheduleService interface {
+	Create(*TrainingScheduleCreateCmd) (TrainingScheduleDTO, string, error)
+	List(user domain.Account, projectId string) ([]TrainingScheduleDTO, error)
+	Get(*TrainingScheduleIndex) (TrainingScheduleDTO, string, error)
+	Delete(*TrainingScheduleIndex) (string, error)
+	Pause(*TrainingScheduleIndex) (string, error)
+	Resume(*TrainingScheduleIndex) (string, error)
+
+	// TriggerDueSchedules creates the trainings of all the schedules which are due.
+	TriggerDueSchedules() error
+}
+
+func NewTrainingScheduleService(
+	ts TrainingService,
+	train training.Training,
+	trainRepo repository.Training,
+	repo repository.TrainingSchedule,
+	maxScheduleNum int,
+) TrainingScheduleService {
+	return trainingScheduleService{
+		ts:        ts,
+		train:     train,
+		trainRepo: trainRepo,
+		repo:      repo,
+
+		maxScheduleNum: maxScheduleNum,
+	}
+}
+
+type trainingScheduleService struct {
+	ts        TrainingService
+	train     training.Training
+	trainRepo repository.Training
+	repo      repository.TrainingSchedule
+
+	maxScheduleNum int
+}
+
+func (s trainingScheduleService) Create(cmd *TrainingScheduleCreateCmd) (
+	dto TrainingScheduleDTO, code string, err error,
+) {
+	v, err := s.repo.List(cmd.User, cmd.ProjectId)
+	if err != nil {
+		return
+	}
+
+	if len(v) >= s.maxScheduleNum {
+		code = ErrorTrainScheduleExccedMaxNum
+		err = ErrorExccedMaxTrainingSchedule{
+			errors.New("exceed max training schedule num"),
+		}
+
+		return
+	}
+
+	config, err := s.trainRepo.GetTrainingConfig(&TrainingIndex{
+		Project: domain.ResourceIndex{
+			Owner: cmd.User,
+			Id:    cmd.ProjectId,
+		},
+		TrainingId: cmd.TrainingId,
+	})
+	if err != nil {
+		if repository.IsErrorResourceNotExists(err) {
+			code = ErrorTrainNotFound
+		}
+
+		return
+	}
+
+	now := utils.Now()
+
+	schedule := domain.TrainingSchedule{
+		Owner:          cmd.User,
+		ProjectId:      cmd.ProjectId,
+		Name:           cmd.Name,
+		Spec:           cmd.Spec,
+		NextRunAt:      cmd.Spec.Next(now),
+		CreatedAt:      now,
+		TrainingConfig: config,
+	}
+
+	if schedule.Id, err = s.repo.Save(&schedule); err != nil {
+		return
+	}
+
+	s.toTrainingScheduleDTO(&schedule, &dto)
+
+	return
+}
+
+func (s trainingScheduleService) List(user domain.Account, projectId string) (
+	[]TrainingScheduleDTO, error,
+) {
+	v, err := s.repo.List(user, projectId)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]TrainingScheduleDTO, len(v))
+	for i := range v {
+		s.toTrainingScheduleDTO(&v[i], &r[i])
+	}
+
+	return r, nil
+}
+
+func (s trainingScheduleService) Get(index *TrainingScheduleIndex) (
+	dto TrainingScheduleDTO, code string, err error,
+) {
+	v, code, err := s.get(index)
+	if err == nil {
+		s.toTrainingScheduleDTO(&v, &dto)
+	}
+
+	return
+}
+
+func (s trainingScheduleService) get(index *TrainingScheduleIndex) (
+	v domain.TrainingSchedule, code string, err error,
+) {
+	if v, err = s.repo.Get(index); err != nil {
+		if repository.IsErrorResourceNotExists(err) {
+			code = ErrorTrainScheduleNotFound
+		}
+	}
+
+	return
+}
+
+func (s trainingScheduleService) Delete(index *TrainingScheduleIndex) (string, error) {
+	if _, code, err := s.get(index); err != nil {
+		return code, err
+	}
+
+	return "", s.repo.Delete(index)
+}
+
+func (s trainingScheduleService) Pause(index *TrainingScheduleIndex) (string, error) {
+	v, code, err := s.get(index)
+	if err != nil || v.Paused {
+		return code, err
+	}
+
+	v.Pause()
+
+	return "", s.repo.UpdateState(&v)
+}
+
+func (s trainingScheduleService) Resume(index *TrainingScheduleIndex) (string, error) {
+	v, code, err := s.get(index)
+	if err != nil || !v.Paused {
+		return code, err
+	}
+
+	v.Resume(utils.Now())
+
+	return "", s.repo.UpdateState(&v)
+}
+
+func (s trainingScheduleService) TriggerDueSchedules() error {
+	now := utils.Now()
+
+	v, err := s.repo.FindDue(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range v {
+		if err := s.trigger(&v[i], now); err != nil {
+			logrus.Errorf(
+				"trigger training schedule(%s) failed, err:%s",
+				v[i].Id, err.Error(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s trainingScheduleService) trigger(schedule *domain.TrainingSchedule, now int64) error {
+	if !schedule.IsDue(now) {
+		return nil
+	}
+
+	// claim the tick first, so that it will not be triggered twice
+	// by the other instances.
+	schedule.Advance(now)
+
+	if err := s.repo.UpdateState(schedule); err != nil {
+		if repository.IsErrorConcurrentUpdating(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	index := TrainingScheduleIndex{
+		Project: domain.ResourceIndex{
+			Owner: schedule.Owner,
+			Id:    schedule.ProjectId,
+		},
+		ScheduleId: schedule.Id,
+	}
+
+	run := s.run(schedule, now)
+
+	return s.repo.AddRun(&index, &run)
+}
+
+func (s trainingScheduleService) run(schedule *domain.TrainingSchedule, now int64) (
+	run domain.TrainingScheduleRun,
+) {
+	run.TriggeredAt = now
+
+	if s.isLastRunActive(schedule) {
+		run.Status = domain.TrainingScheduleRunStatusSkipped
+		run.Error = "the previous run is still active"
+
+		return
+	}
+
+	config := schedule.TrainingConfig
+
+	name, err := schedule.RunName(now)
+	if err != nil {
+		run.Status = domain.TrainingScheduleRunStatusFailed
+		run.Error = err.Error()
+
+		return
+	}
+
+	config.Name = name
+
+	run.TrainingId, err = s.ts.Create(&TrainingCreateCmd{
+		User:           schedule.Owner,
+		ProjectId:      schedule.ProjectId,
+		TrainingConfig: config,
+	})
+
+	switch {
+	case err == nil:
+		run.Status = domain.TrainingScheduleRunStatusTriggered
+
+	case errors.As(err, &ErrorOnlyOneRunningTraining{}):
+		run.Status = domain.TrainingScheduleRunStatusSkipped
+		run.Error = err.Error()
+
+	default:
+		run.Status = domain.TrainingScheduleRunStatusFailed
+		run.Error = err.Error()
+	}
+
+	return
+}
+
+func (s trainingScheduleService) isLastRunActive(schedule *domain.TrainingSchedule) bool {
+	last := schedule.LastTriggeredRun()
+	if last == nil {
+		return false
+	}
+
+	detail, _, err := s.trainRepo.GetJobDetail(&TrainingIndex{
+		Project: domain.ResourceIndex{
+			Owner: schedule.Owner,
+			Id:    schedule.ProjectId,
+		},
+		TrainingId: last.TrainingId,
+	})
+	if err != nil {
+		// the training may be deleted by user.
+		return !repository.IsErrorResourceNotExists(err)
+	}
+
+	return !isTrainingJobDone(s.train, detail.Status)
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+type TrainingScheduleCreateCmd struct {
+	User       domain.Account
+	ProjectId  string
+	TrainingId string
+
+	Name domain.TrainingName
+	Spec domain.CronSpec
+}
+
+func (cmd *TrainingScheduleCreateCmd) Validate() error {
+	b := cmd.User != nil &&
+		cmd.ProjectId != "" &&
+		cmd.TrainingId != "" &&
+		cmd.Name != nil &&
+		cmd.Spec != nil
+
+	if !b {
+		return errors.New("invalid cmd of creating training schedule")
+	}
+
+	return nil
+}
+
+type TrainingScheduleDTO struct {
+	Id        string                   `json:"id"`
+	Name      string                   `json:"name"`
+	Spec      string                   `json:"spec"`
+	Paused    bool                     `json:"paused"`
+	NextRunAt int64                    `json:"next_run_at"`
+	CreatedAt string                   `json:"created_at"`
+	Config    TrainingConfigDTO        `json:"config"`
+	Runs      []TrainingScheduleRunDTO `json:"runs"`
+}
+
+type TrainingScheduleRunDTO struct {
+	TrainingId  string `json:"training_id"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	TriggeredAt int64  `json:"triggered_at"`
+}
+
+func (s trainingScheduleService) toTrainingScheduleDTO(
+	v *domain.TrainingSchedule, dto *TrainingScheduleDTO,
+) {
+	*dto = TrainingScheduleDTO{
+		Id:        v.Id,
+		Name:      v.Name.TrainingName(),
+		Spec:      v.Spec.CronSpec(),
+		Paused:    v.Paused,
+		NextRunAt: v.NextRunAt,
+		CreatedAt: utils.ToDate(v.CreatedAt),
+	}
+
+	dto.Config.toDTO(&v.TrainingConfig)
+
+	if n := len(v.Runs); n > 0 {
+		dto.Runs = make([]TrainingScheduleRunDTO, n)
+
+		// the latest run is first
+		for i := range v.Runs {
+			r := &v.Runs[n-1-i]
+
+			dto.Runs[i] = TrainingScheduleRunDTO{
+				TrainingId:  r.TrainingId,
+				Status:      r.Status,
+				Error:       r.Error,
+				TriggeredAt: r.TriggeredAt,
+			}
+		}
+	}
+}
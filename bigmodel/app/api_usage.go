@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	commondomain "github.com/opensourceways/xihe-server/common/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+type ApiUsageQueryCmd struct {
+	// User is nil if querying the usages of all the users.
+	User types.Account
+	Days int
+}
+
+type ApiUsageDTO struct {
+	Date        string `json:"date"`
+	User        string `json:"user"`
+	Model       string `json:"model"`
+	Requests    int    `json:"requests"`
+	InputChars  int    `json:"input_chars"`
+	OutputChars int    `json:"output_chars"`
+	Images      int    `json:"images"`
+}
+
+type ApiUsageTotalDTO struct {
+	Model       string `json:"model"`
+	Users       int    `json:"users"`
+	Requests    int    `json:"requests"`
+	InputChars  int    `json:"input_chars"`
+	OutputChars int    `json:"output_chars"`
+	Images      int    `json:"images"`
+}
+
+type ApiUsageStatsDTO struct {
+	Totals []ApiUsageTotalDTO `json:"totals"`
+	Items  []ApiUsageDTO      `json:"items"`
+}
+
+type ApiUsageService interface {
+	GetUsage(*ApiUsageQueryCmd) (ApiUsageStatsDTO, string, error)
+}
+
+func NewApiUsageService(repo repository.ApiUsage, cfg *QuotaConfig) ApiUsageService {
+	return apiUsageService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+type apiUsageService struct {
+	repo repository.ApiUsage
+	cfg  *QuotaConfig
+}
+
+func (s apiUsageService) GetUsage(cmd *ApiUsageQueryCmd) (
+	dto ApiUsageStatsDTO, code string, err error,
+) {
+	if n := s.cfg.MaxUsageDays; cmd.Days <= 0 || cmd.Days > n {
+		code = ErrorBigModelInvalidUsageDays
+		err = fmt.Errorf("days should be between 1 and %d", n)
+
+		return
+	}
+
+	v, err := s.repo.FindAll(&repository.ApiUsageListOption{
+		User:  cmd.User,
+		Since: commondomain.UsageDate(utils.Now() - int64(cmd.Days-1)*86400),
+	})
+	if err != nil {
+		return
+	}
+
+	totals := map[string]*ApiUsageTotalDTO{}
+	users := map[string]map[string]struct{}{}
+
+	dto.Items = make([]ApiUsageDTO, len(v))
+	for i := range v {
+		item := &v[i]
+		model := item.Model.ModelName()
+
+		dto.Items[i] = ApiUsageDTO{
+			Date:        item.Date,
+			User:        item.User.Account(),
+			Model:       model,
+			Requests:    item.Requests,
+			InputChars:  item.InputChars,
+			OutputChars: item.OutputChars,
+			Images:      item.Images,
+		}
+
+		t, ok := totals[model]
+		if !ok {
+			t = &ApiUsageTotalDTO{Model: model}
+			totals[model] = t
+			users[model] = map[string]struct{}{}
+		}
+
+		t.Requests += item.Requests
+		t.InputChars += item.InputChars
+		t.OutputChars += item.OutputChars
+		t.Images += item.Images
+		users[model][item.User.Account()] = struct{}{}
+	}
+
+	dto.Totals = make([]ApiUsageTotalDTO, 0, len(totals))
+	for model, t := range totals {
+		t.Users = len(users[model])
+		dto.Totals = append(dto.Totals, *t)
+	}
+
+	sort.Slice(dto.Totals, func(i, j int) bool {
+		return dto.Totals[i].Model < dto.Totals[j].Model
+	})
+
+	return
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"io"
 	"net/url"
-	"sort"
 	"strings"
 	"time"
 
@@ -79,6 +78,9 @@ type BigModelService interface {
 
 	// skywork 13b
 	SkyWork(*SkyWorkCmd) (string, error)
+
+	// endpoints
+	ListEndpointPools() []EndpointPoolDTO
 }
 
 func NewBigModelService(
@@ -92,6 +94,8 @@ func NewBigModelService(
 	apiService repository.ApiService,
 	apiInfo repository.ApiInfo,
 	userService userapp.RegService,
+	quota ApiQuota,
+	incident ModerationRecorder,
 ) BigModelService {
 	return bigModelService{
 		fm:              fm,
@@ -106,6 +110,8 @@ func NewBigModelService(
 		apiService:      apiService,
 		apiInfo:         apiInfo,
 		userService:     userService,
+		quota:           quota,
+		incident:        incident,
 	}
 }
 
@@ -121,6 +127,8 @@ type bigModelService struct {
 	apiService    repository.ApiService
 	apiInfo       repository.ApiInfo
 	userService   userapp.RegService
+	quota         ApiQuota
+	incident      ModerationRecorder
 
 	bigmodelService service.BigModelService
 
@@ -183,6 +191,11 @@ func (s bigModelService) WuKongHF(cmd *WuKongHFCmd) (
 func (s bigModelService) WukongApi(
 	user types.Account, model domain.ModelName, cmd *WuKongApiCmd,
 ) (links map[string]string, code string, err error) {
+	now := utils.Now()
+	if code, err = s.quota.Reserve(user, model, now); err != nil {
+		return
+	}
+
 	_ = s.sender.SendBigModelStarted(&domain.BigModelStartedEvent{
 		Account:      user,
 		BigModelType: domain.BigmodelWuKong,
@@ -191,8 +204,19 @@ func (s bigModelService) WukongApi(
 	links, err = s.fm.GenPicturesByWuKong(user, &cmd.WuKongPictureMeta, string(domain.BigmodelWuKongUser))
 	if err != nil {
 		code = s.setCode(err)
+		s.quota.Release(user, model, now)
+
+		return
 	}
 
+	s.quota.Record(&domain.ApiUsage{
+		User:       user,
+		Model:      model,
+		Requests:   1,
+		InputChars: utils.StrLen(cmd.Desc.WuKongPictureDesc()),
+		Images:     len(links),
+	})
+
 	a, _ := s.apiService.GetApiByUserModel(user, model)
 	err = s.apiService.AddApiCallCount(user, model, a.Version)
 
@@ -586,28 +610,33 @@ func (s bigModelService) CancelPublic(user types.Account, pid string) (err error
 }
 
 func (s bigModelService) GetPublicsGlobal(cmd *WuKongListPublicGlobalCmd) (r WuKongPublicGlobalDTO, err error) {
-	var v []domain.WuKongPicture
-	if cmd.Level != nil && cmd.Level.IsOfficial() {
-		v, err = s.wukongPicture.GetOfficialPublicsGlobal()
-	} else {
-		v, err = s.wukongPicture.GetPublicsGlobal()
-	}
-	if err != nil {
-		return
+	opt := repository.WuKongPublicListOption{
+		Keyword:      cmd.Keyword,
+		Tags:         cmd.Tags,
+		SortByDigg:   cmd.SortBy == WuKongSortByDigg,
+		PageNum:      cmd.PageNum,
+		CountPerPage: cmd.CountPerPage,
 	}
-	s.sortWuKongPicture(v, cmd.Level)
 
-	var b, e int
-	if b = cmd.CountPerPage * (cmd.PageNum - 1); b >= len(v) {
-		return r, nil
+	if cmd.Level != nil {
+		if cmd.Level.IsOfficial() {
+			opt.Level = cmd.Level
+		}
+
+		// the hot pictures are the ones sorted by digg count.
+		if cmd.SortBy == "" {
+			opt.SortByDigg = cmd.Level.IsHot()
+		}
 	}
-	if e = b + cmd.CountPerPage; e > len(v) {
-		e = len(v)
+
+	v, total, err := s.wukongPicture.ListVisiblePublicsGlobal(&opt)
+	if err != nil {
+		return
 	}
 
-	d := make([]WuKongPublicDTO, len(v[b:e]))
-	for i := range v[b:e] {
-		item := &v[b:e][i]
+	d := make([]WuKongPublicDTO, len(v))
+	for i := range v {
+		item := &v[i]
 		link := s.fm.GenWuKongLinkFromOBSPath(item.OBSPath.OBSPath())
 		avatarId, _ := s.user.GetUserAvatarId(item.Owner)
 
@@ -635,29 +664,13 @@ func (s bigModelService) GetPublicsGlobal(cmd *WuKongListPublicGlobalCmd) (r WuK
 	}
 
 	r = WuKongPublicGlobalDTO{
-		Total:    len(v),
+		Total:    total,
 		Pictures: d,
 	}
 
 	return
 }
 
-func (s bigModelService) sortWuKongPicture(pic []domain.WuKongPicture, picLevel domain.WuKongPictureLevel) {
-	if picLevel == nil {
-		return
-	}
-	if picLevel.IsHot() {
-		sort.Slice(pic, func(i, j int) bool {
-			if pic[i].DiggCount == pic[j].DiggCount {
-				ti, _ := utils.ToUnixTime(pic[i].CreatedAt)
-				tj, _ := utils.ToUnixTime(pic[j].CreatedAt)
-				return ti.After(tj)
-			}
-			return pic[i].DiggCount > pic[j].DiggCount
-		})
-	}
-}
-
 func (s bigModelService) ListPublics(user types.Account) (
 	r []WuKongPublicDTO, err error,
 ) {
@@ -688,6 +701,12 @@ func (s bigModelService) DiggPicture(cmd *WuKongAddDiggCmd) (count int, err erro
 		return
 	}
 
+	if p.Hidden {
+		err = errors.New("the picture is hidden")
+
+		return
+	}
+
 	// insert digg user and update diggcount
 	diggs := p.Diggs
 	for _, user := range diggs {
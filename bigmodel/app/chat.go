@@ -0,0 +1,328 @@
+package app
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/message"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// ChatService chats with the models in the sessions of user.
+type ChatService interface {
+	// Chat streams the reply to cmd.CH and returns the id of session.
+	Chat(*ChatCmd) (string, string, error)
+
+	ListSessions(types.Account) ([]ChatSessionSummaryDTO, error)
+	GetSession(*domain.ChatSessionIndex) (ChatSessionDTO, string, error)
+	RenameSession(*ChatSessionRenameCmd) (string, error)
+	DeleteSession(*domain.ChatSessionIndex) error
+
+	// ListModels lists the models which can be chatted with.
+	ListModels() []ChatModelDTO
+}
+
+func NewChatService(
+	fm bigmodel.BigModel,
+	repo repository.ChatSession,
+	sender message.MessageProducer,
+	quota ApiQuota,
+	incident ModerationRecorder,
+	cfg *ChatConfig,
+) ChatService {
+	return chatService{
+		fm:       fm,
+		repo:     repo,
+		sender:   sender,
+		quota:    quota,
+		incident: incident,
+		cfg:      cfg,
+	}
+}
+
+type chatService struct {
+	fm       bigmodel.BigModel
+	repo     repository.ChatSession
+	sender   message.MessageProducer
+	quota    ApiQuota
+	incident ModerationRecorder
+	cfg      *ChatConfig
+}
+
+func (s chatService) Chat(cmd *ChatCmd) (id string, code string, err error) {
+	if len(cmd.Messages) == 0 {
+		err = errors.New("no chat messages")
+
+		return
+	}
+
+	var history []domain.ChatMessage
+
+	if id = cmd.SessionId; id != "" {
+		session, c, e := s.getSession(&domain.ChatSessionIndex{
+			Owner: cmd.User,
+			Id:    id,
+		})
+		if e != nil {
+			code, err = c, e
+
+			return
+		}
+
+		history = session.Messages
+		if n := len(history) - s.cfg.MaxHistoryNum; n > 0 {
+			history = history[n:]
+		}
+	} else {
+		n, e := s.repo.Count(cmd.User)
+		if e != nil {
+			err = e
+
+			return
+		}
+
+		if n >= s.cfg.MaxSessionNum {
+			code = ErrorChatSessionExccedMaxNum
+			err = errors.New("exceed max chat session num")
+
+			return
+		}
+	}
+
+	model, err := domain.NewModelName(cmd.Model.ChatModel())
+	if err != nil {
+		return
+	}
+
+	now := utils.Now()
+	if code, err = s.quota.Reserve(cmd.User, model, now); err != nil {
+		return
+	}
+
+	for i := range cmd.Messages {
+		cmd.Messages[i].CreatedAt = now
+	}
+
+	input := domain.ChatInput{
+		Model:        cmd.Model,
+		Messages:     append(history, cmd.Messages...),
+		Done:         cmd.Done,
+		ChatSampling: cmd.ChatSampling,
+	}
+
+	_ = s.sender.SendBigModelStarted(&domain.BigModelStartedEvent{
+		Account:      cmd.User,
+		BigModelType: domain.BigmodelType(cmd.Model.ChatModel()),
+	})
+
+	ch := make(chan string, cap(cmd.CH))
+	if err = s.fm.Chat(ch, &input); err != nil {
+		code = setChatCode(err)
+		s.quota.Release(cmd.User, model, now)
+
+		return
+	}
+
+	// the reply is streamed even if the session is not saved.
+	if id, err = s.saveMessages(cmd); err != nil {
+		logrus.Errorf("save chat messages of %s failed, err:%s", cmd.User.Account(), err.Error())
+
+		err = nil
+	}
+
+	usage := domain.ApiUsage{
+		User:       cmd.User,
+		Model:      model,
+		Requests:   1,
+		InputChars: countChatChars(input.Messages),
+	}
+
+	go s.relay(ch, cmd, id, &usage)
+
+	return
+}
+
+func (s chatService) saveMessages(cmd *ChatCmd) (string, error) {
+	if cmd.SessionId == "" {
+		session := domain.NewChatSession(cmd.User, cmd.Model, cmd.Messages)
+		session.Messages = cmd.Messages
+
+		return s.repo.Add(&session)
+	}
+
+	index := domain.ChatSessionIndex{
+		Owner: cmd.User,
+		Id:    cmd.SessionId,
+	}
+
+	return cmd.SessionId, s.repo.AppendMessages(
+		&index, cmd.Model, cmd.Messages, s.cfg.MaxMessageNum,
+	)
+}
+
+// relay forwards the reply to cmd.CH and saves it to the session when done.
+func (s chatService) relay(ch chan string, cmd *ChatCmd, id string, usage *domain.ApiUsage) {
+	reply := relayReplyAndRecord(ch, cmd.CH, cmd.Done, s.incident, &domain.ModerationIncident{
+		User:      cmd.User,
+		Model:     cmd.Model.ChatModel(),
+		RequestId: id,
+		Input:     lastChatContent(cmd.Messages),
+	})
+
+	_ = s.sender.SendBigModelFinished(&domain.BigModelFinishedEvent{
+		Account:      cmd.User,
+		BigModelType: domain.BigmodelType(cmd.Model.ChatModel()),
+	})
+
+	usage.OutputChars = utils.StrLen(reply)
+	s.quota.Record(usage)
+
+	if id == "" || reply == "" {
+		return
+	}
+
+	index := domain.ChatSessionIndex{
+		Owner: cmd.User,
+		Id:    id,
+	}
+
+	msg := []domain.ChatMessage{{
+		Role:      domain.ChatRoleAssistant,
+		Content:   reply,
+		Model:     cmd.Model.ChatModel(),
+		CreatedAt: utils.Now(),
+	}}
+
+	if err := s.repo.AppendMessages(&index, cmd.Model, msg, s.cfg.MaxMessageNum); err != nil {
+		logrus.Errorf("save chat reply to session %s failed, err:%s", id, err.Error())
+	}
+}
+
+func (s chatService) ListSessions(user types.Account) ([]ChatSessionSummaryDTO, error) {
+	v, err := s.repo.List(user)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	dtos := make([]ChatSessionSummaryDTO, len(v))
+	for i := range v {
+		dtos[i] = toChatSessionSummaryDTO(&v[i])
+	}
+
+	return dtos, nil
+}
+
+func (s chatService) GetSession(index *domain.ChatSessionIndex) (
+	dto ChatSessionDTO, code string, err error,
+) {
+	v, code, err := s.getSession(index)
+	if err == nil {
+		dto = toChatSessionDTO(&v)
+	}
+
+	return
+}
+
+func (s chatService) getSession(index *domain.ChatSessionIndex) (
+	v domain.ChatSession, code string, err error,
+) {
+	if v, err = s.repo.Get(index); err != nil && repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorChatSessionNotFound
+	}
+
+	return
+}
+
+func (s chatService) RenameSession(cmd *ChatSessionRenameCmd) (code string, err error) {
+	if err = s.repo.UpdateTitle(&cmd.ChatSessionIndex, cmd.Title); err != nil &&
+		repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorChatSessionNotFound
+	}
+
+	return
+}
+
+func (s chatService) DeleteSession(index *domain.ChatSessionIndex) error {
+	return s.repo.Delete(index)
+}
+
+func (s chatService) ListModels() []ChatModelDTO {
+	v := s.fm.Models()
+
+	dtos := make([]ChatModelDTO, len(v))
+	for i := range v {
+		dtos[i] = toChatModelDTO(&v[i])
+	}
+
+	return dtos
+}
+
+func setChatCode(err error) string {
+	if bigmodel.IsErrorSensitiveInfo(err) {
+		return ErrorBigModelSensitiveInfo
+	}
+
+	if bigmodel.IsErrorBusySource(err) {
+		return ErrorBigModelRecourseBusy
+	}
+
+	return ""
+}
+
+// relayReply forwards the reply from the model to the client, and closes
+// the channel of client when done. It also returns whether the reply is
+// rejected by the moderation. Once the client goes away, the rest of reply
+// is drained, so that the model can finish and release the endpoint.
+func relayReply(from, to chan string, gone <-chan struct{}) (string, bool) {
+	defer close(to)
+
+	reply := strings.Builder{}
+	rejected := false
+
+	for msg := range from {
+		switch msg {
+		case "done", moderation.MsgFailed:
+		case moderation.MsgRejected:
+			rejected = true
+		default:
+			reply.WriteString(msg)
+		}
+
+		select {
+		case to <- msg:
+		case <-gone:
+			for range from {
+			}
+
+			return reply.String(), rejected
+		}
+	}
+
+	return reply.String(), rejected
+}
+
+// lastChatContent returns the content of the last message, which is
+// usually the question of user.
+func lastChatContent(msgs []domain.ChatMessage) string {
+	if n := len(msgs); n > 0 {
+		return msgs[n-1].Content
+	}
+
+	return ""
+}
+
+func countChatChars(msgs []domain.ChatMessage) (n int) {
+	for i := range msgs {
+		n += utils.StrLen(msgs[i].Content)
+	}
+
+	return
+}
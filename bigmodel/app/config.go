@@ -0,0 +1,232 @@
+package app
+
+type ChatConfig struct {
+	// MaxSessionNum is the max num of chat sessions of a user.
+	MaxSessionNum int `json:"max_session_num"`
+
+	// MaxMessageNum is the max num of messages kept in a session.
+	MaxMessageNum int `json:"max_message_num"`
+
+	// MaxHistoryNum is the max num of the previous messages in a session
+	// sent to the model as the context.
+	MaxHistoryNum int `json:"max_history_num"`
+}
+
+func (cfg *ChatConfig) SetDefault() {
+	if cfg.MaxSessionNum <= 0 {
+		cfg.MaxSessionNum = 50
+	}
+
+	if cfg.MaxMessageNum <= 0 {
+		cfg.MaxMessageNum = 200
+	}
+
+	if cfg.MaxHistoryNum <= 0 {
+		cfg.MaxHistoryNum = 20
+	}
+}
+
+type QueueConfig struct {
+	// Concurrency is the num of workers of a model in each instance.
+	Concurrency int `json:"concurrency"`
+
+	// MaxWaitingNum is the max num of waiting tasks of a user.
+	MaxWaitingNum int `json:"max_waiting_num"`
+
+	// PollInterval specifies the interval for the idle worker to look for
+	// a new task. The unit is second.
+	PollInterval int `json:"poll_interval"`
+
+	// StaleTime specifies the time after which the running task is taken
+	// over by other workers. The unit is second.
+	StaleTime int `json:"stale_time"`
+
+	// RetryTimeout specifies how long the task waits for a free endpoint
+	// of model before it fails. The unit is second.
+	RetryTimeout int `json:"retry_timeout"`
+
+	// DefaultLatency is the estimated time to run a task before any task
+	// is finished. The unit is second.
+	DefaultLatency int `json:"default_latency"`
+
+	// LatencySamples is the num of latest finished tasks of a model from
+	// which the time to run a task is estimated.
+	LatencySamples int `json:"latency_samples"`
+}
+
+func (cfg *QueueConfig) SetDefault() {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.MaxWaitingNum <= 0 {
+		cfg.MaxWaitingNum = 5
+	}
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 1
+	}
+
+	if cfg.StaleTime <= 0 {
+		cfg.StaleTime = 900
+	}
+
+	if cfg.RetryTimeout <= 0 {
+		cfg.RetryTimeout = 600
+	}
+
+	if cfg.DefaultLatency <= 0 {
+		cfg.DefaultLatency = 30
+	}
+
+	if cfg.LatencySamples <= 0 {
+		cfg.LatencySamples = 20
+	}
+}
+
+type WuKongGalleryConfig struct {
+	// ReportsToHide is the num of users who report the normal picture for
+	// it to be hidden before the reports are reviewed.
+	ReportsToHide int `json:"reports_to_hide"`
+}
+
+func (cfg *WuKongGalleryConfig) SetDefault() {
+	if cfg.ReportsToHide <= 0 {
+		cfg.ReportsToHide = 3
+	}
+}
+
+type WuKongBatchConfig struct {
+	// Concurrency is the num of workers running the batch jobs in each instance.
+	Concurrency int `json:"concurrency"`
+
+	// MaxUndoneNum is the max num of waiting and running jobs of a user.
+	MaxUndoneNum int `json:"max_undone_num"`
+
+	// MaxPromptNum is the max num of prompts of a job.
+	MaxPromptNum int `json:"max_prompt_num"`
+
+	// MaxImageNum is the max num of pictures generated for each prompt.
+	MaxImageNum int `json:"max_image_num"`
+
+	// PollInterval specifies the interval for the idle worker to look for
+	// a new job. The unit is second.
+	PollInterval int `json:"poll_interval"`
+
+	// StaleTime specifies the time after which the job making no progress
+	// is taken over by other workers. The unit is second.
+	StaleTime int `json:"stale_time"`
+
+	// RetryTimeout specifies how long a prompt waits for a free endpoint
+	// of WuKong, or for the rate limit of user, before it fails. The unit
+	// is second.
+	RetryTimeout int `json:"retry_timeout"`
+}
+
+func (cfg *WuKongBatchConfig) SetDefault() {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.MaxUndoneNum <= 0 {
+		cfg.MaxUndoneNum = 2
+	}
+
+	if cfg.MaxPromptNum <= 0 {
+		cfg.MaxPromptNum = 200
+	}
+
+	if cfg.MaxImageNum <= 0 {
+		cfg.MaxImageNum = 8
+	}
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5
+	}
+
+	if cfg.StaleTime <= 0 {
+		cfg.StaleTime = 900
+	}
+
+	if cfg.RetryTimeout <= 0 {
+		cfg.RetryTimeout = 600
+	}
+}
+
+type PromptConfig struct {
+	// MaxTemplateNum is the max num of prompt templates of a user.
+	MaxTemplateNum int `json:"max_template_num"`
+
+	// MaxVersionNum is the max num of versions kept in a template.
+	MaxVersionNum int `json:"max_version_num"`
+}
+
+func (cfg *PromptConfig) SetDefault() {
+	if cfg.MaxTemplateNum <= 0 {
+		cfg.MaxTemplateNum = 100
+	}
+
+	if cfg.MaxVersionNum <= 0 {
+		cfg.MaxVersionNum = 10
+	}
+}
+
+type QuotaLimit struct {
+	// RateLimit is the max num of requests of a user to a model in a minute.
+	RateLimit int `json:"rate_limit"`
+
+	// DailyLimit and MonthlyLimit are the max num of requests of a user
+	// to a model in a day and a month.
+	DailyLimit   int `json:"daily_limit"`
+	MonthlyLimit int `json:"monthly_limit"`
+}
+
+func (l *QuotaLimit) inherit(v *QuotaLimit) {
+	if l.RateLimit <= 0 {
+		l.RateLimit = v.RateLimit
+	}
+
+	if l.DailyLimit <= 0 {
+		l.DailyLimit = v.DailyLimit
+	}
+
+	if l.MonthlyLimit <= 0 {
+		l.MonthlyLimit = v.MonthlyLimit
+	}
+}
+
+type QuotaConfig struct {
+	Default QuotaLimit `json:"default"`
+
+	// Models is the limit of each model, the item which is not set
+	// inherits the one of Default.
+	Models map[string]QuotaLimit `json:"models"`
+
+	// MaxUsageDays is the max days of usage can be queried.
+	MaxUsageDays int `json:"max_usage_days"`
+}
+
+func (cfg *QuotaConfig) SetDefault() {
+	cfg.Default.inherit(&QuotaLimit{
+		RateLimit:    20,
+		DailyLimit:   500,
+		MonthlyLimit: 10000,
+	})
+
+	for k, v := range cfg.Models {
+		v.inherit(&cfg.Default)
+		cfg.Models[k] = v
+	}
+
+	if cfg.MaxUsageDays <= 0 {
+		cfg.MaxUsageDays = 90
+	}
+}
+
+func (cfg *QuotaConfig) limit(model string) *QuotaLimit {
+	if v, ok := cfg.Models[model]; ok {
+		return &v
+	}
+
+	return &cfg.Default
+}
@@ -79,9 +79,21 @@ type WuKongPictureListOption struct {
 	PageNum      int
 }
 
+const (
+	WuKongSortByTime = "time"
+	WuKongSortByDigg = "digg"
+)
+
 type WuKongListPublicGlobalCmd struct {
 	User  types.Account
 	Level domain.WuKongPictureLevel
+
+	// Keyword searches the desc and style of pictures, and the pictures
+	// should have all the Tags.
+	Keyword string
+	Tags    []string
+	SortBy  string
+
 	WuKongPictureListOption
 }
 
@@ -94,6 +106,10 @@ func (cmd *WuKongListPublicGlobalCmd) Validate() error {
 		return errors.New("count_per_page less than 1")
 	}
 
+	if cmd.SortBy != "" && cmd.SortBy != WuKongSortByTime && cmd.SortBy != WuKongSortByDigg {
+		return errors.New("invalid sort_by")
+	}
+
 	return nil
 }
 
@@ -198,11 +214,13 @@ type WuKongLikeDTO struct { // like
 }
 
 type WuKongPublicDTO struct { // public
-	Avatar    string `json:"avatar"`
-	IsLike    bool   `json:"is_like"`
-	LikeID    string `json:"like_id"`
-	IsDigg    bool   `json:"is_digg"`
-	DiggCount int    `json:"digg_count"`
+	Avatar    string   `json:"avatar"`
+	IsLike    bool     `json:"is_like"`
+	LikeID    string   `json:"like_id"`
+	IsDigg    bool     `json:"is_digg"`
+	DiggCount int      `json:"digg_count"`
+	Tags      []string `json:"tags"`
+	Hidden    bool     `json:"hidden"`
 
 	WuKongPictureBaseDTO
 }
@@ -217,6 +235,8 @@ func (dto *WuKongPublicDTO) toWuKongPublicDTO(
 		LikeID:    likeId,
 		IsDigg:    isDigg,
 		DiggCount: p.DiggCount,
+		Tags:      p.Tags,
+		Hidden:    p.Hidden,
 
 		WuKongPictureBaseDTO: WuKongPictureBaseDTO{
 			Id:        p.Id,
@@ -352,6 +372,7 @@ type BaiChuanDTO struct {
 type GLM2Cmd struct {
 	CH                chan string
 	User              types.Account
+	RequestId         string
 	History           []domain.History
 	Sampling          bool
 	Text              domain.GLM2Text
@@ -377,6 +398,7 @@ type GLM2DTO struct {
 type LLAMA2Cmd struct {
 	CH                chan string
 	User              types.Account
+	RequestId         string
 	History           []domain.History
 	Sampling          bool
 	Text              domain.LLAMA2Text
@@ -402,6 +424,7 @@ type LLAMA2DTO struct {
 type SkyWorkCmd struct {
 	CH                chan string
 	User              types.Account
+	RequestId         string
 	History           []domain.History
 	Sampling          bool
 	Text              domain.SkyWorkText
@@ -427,6 +450,7 @@ type SkyWorkDTO struct {
 type IFlytekSparkCmd struct {
 	CH                chan string
 	User              types.Account
+	RequestId         string
 	Sampling          bool
 	Text              domain.IFlytekSparkText
 	TopK              domain.TopK
@@ -444,3 +468,477 @@ type IFlytekSparkDTO struct {
 	Reply        string `json:"reply"`
 	StreamStatus string `json:"stream_status"`
 }
+
+// chat
+type ChatCmd struct {
+	CH chan string
+
+	// Done is closed when the client goes away, then the reply
+	// is not sent to CH any more.
+	Done <-chan struct{}
+
+	User      types.Account
+	SessionId string
+	Model     domain.ChatModel
+
+	// Messages are the new messages of this round, and the last one
+	// should be of user.
+	Messages []domain.ChatMessage
+
+	domain.ChatSampling
+}
+
+func (cmd *ChatCmd) SetDefault() {
+	cmd.ChatSampling = defaultChatSampling(cmd.Model)
+}
+
+// defaultChatSampling returns the default sampling params which are the
+// same as the ones used by the model alone.
+func defaultChatSampling(m domain.ChatModel) (v domain.ChatSampling) {
+	topK, topP, temperature, penalty := 5, 0.85, 0.3, 1.05
+
+	switch domain.BigmodelType(m.ChatModel()) {
+	case domain.BigmodelLLAMA2:
+		topK, topP, temperature, penalty = 3, 1, 1, 1
+
+	case domain.BigmodelSkyWork, domain.BigmodelIFlytekSpark:
+		topK, topP, temperature, penalty = 1, 1, 1, 1
+	}
+
+	v.TopK, _ = domain.NewTopK(topK)
+	v.TopP, _ = domain.NewTopP(topP)
+	v.Temperature, _ = domain.NewTemperature(temperature)
+	v.RepetitionPenalty, _ = domain.NewRepetitionPenalty(penalty)
+
+	return
+}
+
+type ChatSamplingDTO struct {
+	TopK              int     `json:"top_k"`
+	TopP              float64 `json:"top_p"`
+	Temperature       float64 `json:"temperature"`
+	RepetitionPenalty float64 `json:"repetition_penalty"`
+}
+
+type ChatModelDTO struct {
+	Name     string          `json:"name"`
+	Title    string          `json:"title"`
+	Desc     string          `json:"desc,omitempty"`
+	Icon     string          `json:"icon,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+	Defaults ChatSamplingDTO `json:"defaults"`
+}
+
+func toChatModelDTO(m *bigmodel.ModelInfo) ChatModelDTO {
+	dto := ChatModelDTO{
+		Name:  m.Name,
+		Title: m.Title,
+		Desc:  m.Desc,
+		Icon:  m.Icon,
+		Tags:  m.Tags,
+		Defaults: ChatSamplingDTO{
+			TopK:              m.TopK,
+			TopP:              m.TopP,
+			Temperature:       m.Temperature,
+			RepetitionPenalty: m.RepetitionPenalty,
+		},
+	}
+
+	if m.Builtin {
+		if model, err := domain.NewChatModel(m.Name); err == nil {
+			v := defaultChatSampling(model)
+
+			dto.Defaults = ChatSamplingDTO{
+				TopK:              v.TopK.TopK(),
+				TopP:              v.TopP.TopP(),
+				Temperature:       v.Temperature.Temperature(),
+				RepetitionPenalty: v.RepetitionPenalty.RepetitionPenalty(),
+			}
+		}
+	}
+
+	return dto
+}
+
+type ChatSessionRenameCmd struct {
+	domain.ChatSessionIndex
+
+	Title domain.ChatSessionTitle
+}
+
+type ChatMessageDTO struct {
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	Model     string `json:"model,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type ChatSessionSummaryDTO struct {
+	Id        string `json:"id"`
+	Title     string `json:"title"`
+	Model     string `json:"model"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type ChatSessionDTO struct {
+	ChatSessionSummaryDTO
+
+	Messages []ChatMessageDTO `json:"messages"`
+}
+
+func toChatSessionSummaryDTO(s *domain.ChatSession) ChatSessionSummaryDTO {
+	return ChatSessionSummaryDTO{
+		Id:        s.Id,
+		Title:     s.Title.ChatSessionTitle(),
+		Model:     s.Model.ChatModel(),
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func toChatSessionDTO(s *domain.ChatSession) ChatSessionDTO {
+	msgs := make([]ChatMessageDTO, len(s.Messages))
+	for i := range s.Messages {
+		m := &s.Messages[i]
+
+		msgs[i] = ChatMessageDTO{
+			Role:      m.Role,
+			Content:   m.Content,
+			Model:     m.Model,
+			CreatedAt: m.CreatedAt,
+		}
+	}
+
+	return ChatSessionDTO{
+		ChatSessionSummaryDTO: toChatSessionSummaryDTO(s),
+		Messages:              msgs,
+	}
+}
+
+// openai
+type OpenAIModelDTO struct {
+	Id      string
+	Created int64
+
+	// Token is the encrypted api token of the model.
+	Token string
+}
+
+type OpenAIChatCmd struct {
+	CH chan string
+
+	// Done is closed when the client goes away, then the reply
+	// is not sent to CH any more.
+	Done <-chan struct{}
+
+	User  types.Account
+	Model domain.ChatModel
+
+	// RequestId is the id of the response, by which the moderation
+	// incident of the request can be found.
+	RequestId string
+
+	Messages []domain.ChatMessage
+
+	domain.ChatSampling
+}
+
+// SetDefault sets the default sampling params of model, and the ones
+// specified by the request are kept.
+func (cmd *OpenAIChatCmd) SetDefault(temperature domain.Temperature, topP domain.TopP) {
+	cmd.ChatSampling = defaultChatSampling(cmd.Model)
+
+	if temperature != nil {
+		cmd.Temperature = temperature
+		cmd.Sampling = true
+	}
+
+	if topP != nil {
+		cmd.TopP = topP
+		cmd.Sampling = true
+	}
+}
+
+type OpenAIImageCmd struct {
+	User types.Account
+
+	domain.WuKongPictureMeta
+}
+
+// queue
+type QueueTaskCmd struct {
+	User  types.Account
+	Model domain.ModelName
+
+	// Input is the input of chat model.
+	Input domain.ChatInput
+
+	// Picture is the input of WuKong.
+	Picture domain.WuKongPictureMeta
+}
+
+type QueueTaskDTO struct {
+	Id     string `json:"id"`
+	Model  string `json:"model"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// Rank is the num of tasks waiting before it.
+	Rank int `json:"rank"`
+
+	// EstimatedWait is the estimated seconds to get the result.
+	EstimatedWait int `json:"estimated_wait"`
+
+	CreatedAt  int64 `json:"created_at"`
+	StartedAt  int64 `json:"started_at,omitempty"`
+	FinishedAt int64 `json:"finished_at,omitempty"`
+}
+
+type QueueTaskResultDTO struct {
+	Id         string            `json:"id"`
+	Model      string            `json:"model"`
+	Reply      string            `json:"reply,omitempty"`
+	Pictures   map[string]string `json:"pictures,omitempty"`
+	FinishedAt int64             `json:"finished_at"`
+}
+
+// wukong gallery
+type WuKongUpdateTagsCmd struct {
+	User types.Account
+	Id   string
+	Tags []string
+}
+
+type WuKongTagDTO struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type WuKongAddCommentCmd struct {
+	Picture domain.WuKongPictureIndex
+	User    types.Account
+	Content domain.WuKongCommentContent
+}
+
+type WuKongCommentDTO struct {
+	Id        string `json:"id"`
+	User      string `json:"user"`
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+func toWuKongCommentDTO(c *domain.WuKongComment) WuKongCommentDTO {
+	return WuKongCommentDTO{
+		Id:        c.Id,
+		User:      c.User.Account(),
+		Content:   c.Content.WuKongCommentContent(),
+		CreatedAt: c.CreatedAt,
+	}
+}
+
+type WuKongReportCmd struct {
+	Picture domain.WuKongPictureIndex
+	User    types.Account
+	Reason  string
+}
+
+type WuKongReviewReportCmd struct {
+	Id       string
+	Reviewer types.Account
+
+	// Confirm means the picture is abusive, otherwise the report is dismissed.
+	Confirm bool
+}
+
+type WuKongReportDTO struct {
+	Id           string `json:"id"`
+	PictureId    string `json:"picture_id"`
+	PictureOwner string `json:"picture_owner"`
+	Reporter     string `json:"reporter"`
+	Reason       string `json:"reason"`
+	Status       string `json:"status"`
+	Reviewer     string `json:"reviewer,omitempty"`
+	CreatedAt    int64  `json:"created_at"`
+	ReviewedAt   int64  `json:"reviewed_at,omitempty"`
+}
+
+func toWuKongReportDTO(r *domain.WuKongReport) WuKongReportDTO {
+	return WuKongReportDTO{
+		Id:           r.Id,
+		PictureId:    r.Picture.Id,
+		PictureOwner: r.Picture.Owner.Account(),
+		Reporter:     r.Reporter.Account(),
+		Reason:       r.Reason,
+		Status:       r.Status,
+		Reviewer:     r.Reviewer,
+		CreatedAt:    r.CreatedAt,
+		ReviewedAt:   r.ReviewedAt,
+	}
+}
+
+// prompt
+type PromptCreateCmd struct {
+	Owner   types.Account
+	Name    domain.PromptName
+	Kind    domain.PromptKind
+	Content domain.PromptContent
+	Public  bool
+}
+
+// PromptUpdateCmd updates the fields which are not nil. A new version
+// is added if the content is changed.
+type PromptUpdateCmd struct {
+	Owner   types.Account
+	Id      string
+	Name    domain.PromptName
+	Content domain.PromptContent
+	Public  *bool
+}
+
+type PromptRenderCmd struct {
+	User types.Account
+	Id   string
+	Kind string
+
+	// Version is the version of template, 0 means the latest one.
+	Version   int
+	Variables map[string]string
+}
+
+type PromptVersionDTO struct {
+	Version   int      `json:"version"`
+	Content   string   `json:"content"`
+	Variables []string `json:"variables"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+type PromptTemplateDTO struct {
+	Id        string           `json:"id"`
+	Owner     string           `json:"owner"`
+	Name      string           `json:"name"`
+	Kind      string           `json:"kind"`
+	Public    bool             `json:"public"`
+	Latest    PromptVersionDTO `json:"latest"`
+	Likes     int              `json:"likes"`
+	CreatedAt int64            `json:"created_at"`
+	UpdatedAt int64            `json:"updated_at"`
+}
+
+type PromptTemplateDetailDTO struct {
+	PromptTemplateDTO
+
+	Versions []PromptVersionDTO `json:"versions"`
+}
+
+func toPromptVersionDTO(v *domain.PromptVersion) PromptVersionDTO {
+	return PromptVersionDTO{
+		Version:   v.Version,
+		Content:   v.Content.PromptContent(),
+		Variables: v.Content.Variables(),
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func toPromptTemplateDTO(t *domain.PromptTemplate) PromptTemplateDTO {
+	dto := PromptTemplateDTO{
+		Id:        t.Id,
+		Owner:     t.Owner.Account(),
+		Name:      t.Name.PromptName(),
+		Kind:      t.Kind.PromptKind(),
+		Public:    t.Public,
+		Likes:     t.Likes,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+
+	if v := t.Latest(); v != nil {
+		dto.Latest = toPromptVersionDTO(v)
+	}
+
+	return dto
+}
+
+func toPromptTemplateDetailDTO(t *domain.PromptTemplate) PromptTemplateDetailDTO {
+	versions := make([]PromptVersionDTO, len(t.Versions))
+	for i := range t.Versions {
+		versions[i] = toPromptVersionDTO(&t.Versions[i])
+	}
+
+	return PromptTemplateDetailDTO{
+		PromptTemplateDTO: toPromptTemplateDTO(t),
+		Versions:          versions,
+	}
+}
+
+// wukong batch
+type WuKongBatchCmd struct {
+	User     types.Account
+	Name     string
+	ImageNum int
+	Prompts  []domain.WuKongPictureMeta
+}
+
+type WuKongBatchJobDTO struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	ImageNum   int    `json:"image_num"`
+	Total      int    `json:"total"`
+	Done       int    `json:"done"`
+	Failed     int    `json:"failed"`
+	CreatedAt  int64  `json:"created_at"`
+	StartedAt  int64  `json:"started_at,omitempty"`
+	FinishedAt int64  `json:"finished_at,omitempty"`
+}
+
+type WuKongBatchItemDTO struct {
+	Desc     string `json:"desc"`
+	Style    string `json:"style,omitempty"`
+	Status   string `json:"status"`
+	Pictures int    `json:"pictures"`
+	Error    string `json:"error,omitempty"`
+}
+
+type WuKongBatchJobDetailDTO struct {
+	WuKongBatchJobDTO
+
+	Items []WuKongBatchItemDTO `json:"items"`
+}
+
+func toWuKongBatchJobDTO(job *domain.WuKongBatchJob) WuKongBatchJobDTO {
+	done, failed := job.Progress()
+
+	return WuKongBatchJobDTO{
+		Id:         job.Id,
+		Name:       job.Name,
+		Status:     job.Status,
+		ImageNum:   job.ImageNum,
+		Total:      len(job.Items),
+		Done:       done,
+		Failed:     failed,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func toWuKongBatchJobDetailDTO(job *domain.WuKongBatchJob) WuKongBatchJobDetailDTO {
+	items := make([]WuKongBatchItemDTO, len(job.Items))
+	for i := range job.Items {
+		item := &job.Items[i]
+
+		items[i] = WuKongBatchItemDTO{
+			Desc:     item.Desc.WuKongPictureDesc(),
+			Style:    item.Style,
+			Status:   item.Status,
+			Pictures: len(item.Pictures),
+			Error:    item.Error,
+		}
+	}
+
+	return WuKongBatchJobDetailDTO{
+		WuKongBatchJobDTO: toWuKongBatchJobDTO(job),
+		Items:             items,
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import "github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+
+type EndpointStateDTO struct {
+	URL            string `json:"url"`
+	Weight         int    `json:"weight"`
+	MaxConcurrency int    `json:"max_concurrency"`
+	Inflight       int    `json:"inflight"`
+	Healthy        bool   `json:"healthy"`
+	Ejected        bool   `json:"ejected"`
+	EjectedUntil   int64  `json:"ejected_until,omitempty"`
+	ProbedAt       int64  `json:"probed_at,omitempty"`
+	Requests       int64  `json:"requests"`
+	Errors         int64  `json:"errors"`
+}
+
+type EndpointPoolDTO struct {
+	Name      string             `json:"name"`
+	Idle      int                `json:"idle"`
+	Endpoints []EndpointStateDTO `json:"endpoints"`
+}
+
+func toEndpointPoolDTO(v *bigmodel.EndpointPoolState) EndpointPoolDTO {
+	dto := EndpointPoolDTO{
+		Name:      v.Name,
+		Idle:      v.Idle,
+		Endpoints: make([]EndpointStateDTO, len(v.Endpoints)),
+	}
+
+	for i := range v.Endpoints {
+		e := &v.Endpoints[i]
+
+		dto.Endpoints[i] = EndpointStateDTO{
+			URL:            e.URL,
+			Weight:         e.Weight,
+			MaxConcurrency: e.MaxConcurrency,
+			Inflight:       e.Inflight,
+			Healthy:        e.Healthy,
+			Ejected:        e.Ejected,
+			EjectedUntil:   e.EjectedUntil,
+			ProbedAt:       e.ProbedAt,
+			Requests:       e.Requests,
+			Errors:         e.Errors,
+		}
+	}
+
+	return dto
+}
+
+func (s bigModelService) ListEndpointPools() []EndpointPoolDTO {
+	v := s.fm.EndpointPools()
+
+	dtos := make([]EndpointPoolDTO, len(v))
+	for i := range v {
+		dtos[i] = toEndpointPoolDTO(&v[i])
+	}
+
+	return dtos
+}
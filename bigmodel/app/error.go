@@ -11,6 +11,30 @@ const (
 	ErrorBigModelSensitiveInfo     = "bigmodel_sensitive_info"
 	ErrorBigModelRecourseBusy      = "bigmodel_resource_busy"
 	ErrorBigModelConcurrentRequest = "bigmodel_concurrent_request"
+	ErrorBigModelRateLimited       = "bigmodel_rate_limited"
+	ErrorBigModelQuotaExceeded     = "bigmodel_quota_exceeded"
+	ErrorBigModelInvalidUsageDays  = "bigmodel_invalid_usage_days"
+
+	ErrorChatSessionNotFound     = "chat_session_not_found"
+	ErrorChatSessionExccedMaxNum = "chat_session_excced_max_num"
+
+	ErrorQueueTaskNotFound       = "queue_task_not_found"
+	ErrorQueueTaskExccedMaxNum   = "queue_task_excced_max_num"
+	ErrorQueueTaskNotCancellable = "queue_task_not_cancellable"
+	ErrorQueueTaskNotFinished    = "queue_task_not_finished"
+
+	ErrorWuKongBatchNotFound       = "wukong_batch_not_found"
+	ErrorWuKongBatchExccedMaxNum   = "wukong_batch_excced_max_num"
+	ErrorWuKongBatchNotCancellable = "wukong_batch_not_cancellable"
+	ErrorWuKongBatchNotFinished    = "wukong_batch_not_finished"
+
+	ErrorPromptNotFound         = "prompt_not_found"
+	ErrorPromptExccedMaxNum     = "prompt_excced_max_num"
+	ErrorPromptDuplicateName    = "prompt_duplicate_name"
+	ErrorPromptConcurrentUpdate = "prompt_concurrent_updating"
+	ErrorPromptVersionNotFound  = "prompt_version_not_found"
+	ErrorPromptKindMismatch     = "prompt_kind_mismatch"
+	ErrorPromptRenderFailed     = "prompt_render_failed"
 
 	ErrorWuKongNoPicture        = "bigmodel_no_wukong_picture"
 	ErrorWuKongInvalidId        = "wukong_invalid_id"
@@ -20,4 +44,9 @@ const (
 	ErrorWuKongInvalidLink      = "wukong_invalid_link"
 	ErrorWuKongDuplicateLike    = "wukong_duplicate_like"
 	ErrorWuKongExccedMaxLikeNum = "wukong_excced_max_like_num"
+	ErrorWuKongPictureHidden    = "wukong_picture_hidden"
+	ErrorWuKongCommentNotFound  = "wukong_comment_not_found"
+	ErrorWuKongDuplicateReport  = "wukong_duplicate_report"
+	ErrorWuKongReportNotFound   = "wukong_report_not_found"
+	ErrorWuKongReportReviewed   = "wukong_report_reviewed"
 )
@@ -18,12 +18,20 @@ func (s bigModelService) IFlytekSpark(cmd *IFlytekSparkCmd) (code string, err er
 		RepetitionPenalty: cmd.RepetitionPenalty,
 	}
 
-	if err = s.fm.IFlytekSpark(cmd.CH, input); err != nil {
+	ch := make(chan string, cap(cmd.CH))
+	if err = s.fm.IFlytekSpark(ch, input); err != nil {
 		code = s.setCode(err)
 
 		return
 	}
 
+	go relayReplyAndRecord(ch, cmd.CH, nil, s.incident, &domain.ModerationIncident{
+		User:      cmd.User,
+		Model:     string(domain.BigmodelIFlytekSpark),
+		RequestId: cmd.RequestId,
+		Input:     cmd.Text.IFlytekSparkText(),
+	})
+
 	_ = s.sender.SendBigModelFinished(&domain.BigModelFinishedEvent{
 		Account:      cmd.User,
 		BigModelType: domain.BigmodelIFlytekSpark,
@@ -20,12 +20,20 @@ func (s bigModelService) LLAMA2(cmd *LLAMA2Cmd) (code string, err error) {
 		RepetitionPenalty: cmd.RepetitionPenalty,
 	}
 
-	if err = s.fm.LLAMA2(cmd.CH, input); err != nil {
+	ch := make(chan string, cap(cmd.CH))
+	if err = s.fm.LLAMA2(ch, input); err != nil {
 		code = s.setCode(err)
 		
 		return
 	}
 
+	go relayReplyAndRecord(ch, cmd.CH, nil, s.incident, &domain.ModerationIncident{
+		User:      cmd.User,
+		Model:     string(domain.BigmodelLLAMA2),
+		RequestId: cmd.RequestId,
+		Input:     cmd.Text.LLAMA2Text(),
+	})
+
 	_ = s.sender.SendBigModelFinished(&domain.BigModelFinishedEvent{
 		Account:      cmd.User,
 		BigModelType: domain.BigmodelLLAMA2,
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// ModerationRecorder records the incidents that the output of model is
+// rejected by the moderation, so that they can be reviewed later.
+type ModerationRecorder interface {
+	Record(*domain.ModerationIncident)
+}
+
+func NewModerationRecorder(repo repository.ModerationIncident) ModerationRecorder {
+	return moderationRecorder{repo}
+}
+
+type moderationRecorder struct {
+	repo repository.ModerationIncident
+}
+
+func (r moderationRecorder) Record(v *domain.ModerationIncident) {
+	if v.CreatedAt == 0 {
+		v.CreatedAt = utils.Now()
+	}
+
+	if err := r.repo.Add(v); err != nil {
+		logrus.Errorf(
+			"record moderation incident of %s to %s failed, err:%s",
+			v.User.Account(), v.Model, err.Error(),
+		)
+	}
+}
+
+// relayReplyAndRecord forwards the reply like relayReply, and records the
+// incident if the reply is rejected.
+func relayReplyAndRecord(
+	from, to chan string, gone <-chan struct{},
+	r ModerationRecorder, incident *domain.ModerationIncident,
+) string {
+	reply, rejected := relayReply(from, to, gone)
+	if rejected {
+		r.Record(incident)
+	}
+
+	return reply
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/message"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// OpenAIService serves the big models by the api of OpenAI protocol for
+// the users who have applied the api of the models.
+type OpenAIService interface {
+	// ListModels returns the enabled models applied by user.
+	ListModels(types.Account) ([]OpenAIModelDTO, error)
+
+	// ChatCompletion streams the reply to cmd.CH.
+	ChatCompletion(*OpenAIChatCmd) (string, error)
+	ImageGeneration(*OpenAIImageCmd) ([]string, string, error)
+}
+
+func NewOpenAIService(
+	fm bigmodel.BigModel,
+	apiService repository.ApiService,
+	sender message.MessageProducer,
+	quota ApiQuota,
+	incident ModerationRecorder,
+) OpenAIService {
+	return openAIService{
+		fm:         fm,
+		apiService: apiService,
+		sender:     sender,
+		quota:      quota,
+		incident:   incident,
+	}
+}
+
+type openAIService struct {
+	fm         bigmodel.BigModel
+	apiService repository.ApiService
+	sender     message.MessageProducer
+	quota      ApiQuota
+	incident   ModerationRecorder
+}
+
+func (s openAIService) ListModels(user types.Account) ([]OpenAIModelDTO, error) {
+	v, err := s.apiService.GetApiByUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]OpenAIModelDTO, 0, len(v))
+	for i := range v {
+		item := &v[i]
+		if !item.Enabled {
+			continue
+		}
+
+		created := int64(0)
+		if t, err := utils.ToUnixTime(item.ApplyAt); err == nil {
+			created = t.Unix()
+		}
+
+		r = append(r, OpenAIModelDTO{
+			Id:      item.ModelName.ModelName(),
+			Created: created,
+			Token:   item.Token,
+		})
+	}
+
+	return r, nil
+}
+
+func (s openAIService) ChatCompletion(cmd *OpenAIChatCmd) (code string, err error) {
+	model, err := domain.NewModelName(cmd.Model.ChatModel())
+	if err != nil {
+		return
+	}
+
+	now := utils.Now()
+	if code, err = s.quota.Reserve(cmd.User, model, now); err != nil {
+		return
+	}
+
+	_ = s.sender.SendBigModelStarted(&domain.BigModelStartedEvent{
+		Account:      cmd.User,
+		BigModelType: domain.BigmodelType(cmd.Model.ChatModel()),
+	})
+
+	input := domain.ChatInput{
+		Model:        cmd.Model,
+		Messages:     cmd.Messages,
+		Done:         cmd.Done,
+		ChatSampling: cmd.ChatSampling,
+	}
+
+	ch := make(chan string, cap(cmd.CH))
+	if err = s.fm.Chat(ch, &input); err != nil {
+		code = setChatCode(err)
+		s.quota.Release(cmd.User, model, now)
+
+		return
+	}
+
+	s.addCallCount(cmd.User, model)
+
+	go func() {
+		usage := domain.ApiUsage{
+			User:       cmd.User,
+			Model:      model,
+			Requests:   1,
+			InputChars: countChatChars(cmd.Messages),
+		}
+
+		reply := relayReplyAndRecord(ch, cmd.CH, cmd.Done, s.incident, &domain.ModerationIncident{
+			User:      cmd.User,
+			Model:     cmd.Model.ChatModel(),
+			RequestId: cmd.RequestId,
+			Input:     lastChatContent(cmd.Messages),
+		})
+
+		usage.OutputChars = utils.StrLen(reply)
+
+		s.quota.Record(&usage)
+	}()
+
+	return
+}
+
+func (s openAIService) ImageGeneration(cmd *OpenAIImageCmd) (urls []string, code string, err error) {
+	model, err := domain.NewModelName(string(domain.BigmodelWuKong))
+	if err != nil {
+		return
+	}
+
+	now := utils.Now()
+	if code, err = s.quota.Reserve(cmd.User, model, now); err != nil {
+		return
+	}
+
+	_ = s.sender.SendBigModelStarted(&domain.BigModelStartedEvent{
+		Account:      cmd.User,
+		BigModelType: domain.BigmodelWuKong,
+	})
+
+	links, err := s.fm.GenPicturesByWuKong(
+		cmd.User, &cmd.WuKongPictureMeta, string(domain.BigmodelWuKongUser),
+	)
+	if err != nil {
+		code = setChatCode(err)
+		s.quota.Release(cmd.User, model, now)
+
+		return
+	}
+
+	names := make([]string, 0, len(links))
+	for k := range links {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	urls = make([]string, len(names))
+	for i, k := range names {
+		urls[i] = links[k]
+	}
+
+	s.quota.Record(&domain.ApiUsage{
+		User:       cmd.User,
+		Model:      model,
+		Requests:   1,
+		InputChars: utils.StrLen(cmd.Desc.WuKongPictureDesc()),
+		Images:     len(urls),
+	})
+
+	s.addCallCount(cmd.User, model)
+
+	return
+}
+
+func (s openAIService) addCallCount(user types.Account, model domain.ModelName) {
+	if a, err := s.apiService.GetApiByUserModel(user, model); err == nil {
+		_ = s.apiService.AddApiCallCount(user, model, a.Version)
+	}
+}
@@ -0,0 +1,315 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	commondomain "github.com/opensourceways/xihe-server/common/domain"
+	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// PromptService manages the prompt templates of users and renders them
+// before calling WuKong or the chat models.
+type PromptService interface {
+	Create(*PromptCreateCmd) (PromptTemplateDTO, string, error)
+	Update(*PromptUpdateCmd) (PromptTemplateDTO, string, error)
+	Get(user types.Account, id string) (PromptTemplateDetailDTO, string, error)
+	List(types.Account) ([]PromptTemplateDTO, error)
+	Delete(owner types.Account, id string) (string, error)
+
+	ListPublic(kind string) ([]PromptTemplateDTO, error)
+	Like(user types.Account, id string) (string, error)
+	Unlike(user types.Account, id string) (string, error)
+
+	Render(*PromptRenderCmd) (string, string, error)
+}
+
+func NewPromptService(
+	fm bigmodel.BigModel,
+	repo repository.PromptTemplate,
+	counter commonrepo.UsageCounter,
+	cfg *PromptConfig,
+) PromptService {
+	return promptService{
+		fm:      fm,
+		cfg:     *cfg,
+		repo:    repo,
+		counter: counter,
+	}
+}
+
+type promptService struct {
+	fm      bigmodel.BigModel
+	cfg     PromptConfig
+	repo    repository.PromptTemplate
+	counter commonrepo.UsageCounter
+}
+
+// slotLimit is the num of templates the owner holds in the usage counter,
+// by which the concurrent creatings can't exceed the max num.
+func (s promptService) slotLimit(owner types.Account) []commondomain.UsageLimit {
+	return []commondomain.UsageLimit{{
+		Key:    "bigmodel/prompt/" + owner.Account(),
+		Window: commondomain.TotalWindow(),
+		Max:    s.cfg.MaxTemplateNum,
+	}}
+}
+
+func (s promptService) releaseSlot(owner types.Account) {
+	if err := s.counter.Release(s.slotLimit(owner)); err != nil {
+		logrus.Errorf(
+			"release the prompt template slot of %s failed, err:%s",
+			owner.Account(), err.Error(),
+		)
+	}
+}
+
+func (s promptService) checkText(texts ...string) (code string, err error) {
+	for _, v := range texts {
+		if err = s.fm.CheckText(v); err != nil {
+			if bigmodel.IsErrorSensitiveInfo(err) {
+				code = ErrorBigModelSensitiveInfo
+			}
+
+			return
+		}
+	}
+
+	return
+}
+
+// get returns the template which is visible to the user.
+func (s promptService) get(user types.Account, id string) (
+	t domain.PromptTemplate, code string, err error,
+) {
+	if t, err = s.repo.Get(id); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = ErrorPromptNotFound
+		}
+
+		return
+	}
+
+	if !t.IsVisibleTo(user) {
+		code = ErrorPromptNotFound
+		err = errors.New("no such prompt template")
+	}
+
+	return
+}
+
+func (s promptService) Create(cmd *PromptCreateCmd) (
+	dto PromptTemplateDTO, code string, err error,
+) {
+	code, err = s.checkText(cmd.Name.PromptName(), cmd.Content.PromptContent())
+	if err != nil {
+		return
+	}
+
+	i, err := s.counter.Admit(s.slotLimit(cmd.Owner))
+	if err != nil {
+		return
+	}
+
+	if i >= 0 {
+		code = ErrorPromptExccedMaxNum
+		err = errors.New("exceed max num of prompt templates")
+
+		return
+	}
+
+	now := utils.Now()
+
+	t := domain.PromptTemplate{
+		Owner:     cmd.Owner,
+		Name:      cmd.Name,
+		Kind:      cmd.Kind,
+		Public:    cmd.Public,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	t.AddVersion(cmd.Content, now, s.cfg.MaxVersionNum)
+
+	if t.Id, err = s.repo.Add(&t); err != nil {
+		s.releaseSlot(cmd.Owner)
+
+		if repoerr.IsErrorDuplicateCreating(err) {
+			code = ErrorPromptDuplicateName
+		}
+
+		return
+	}
+
+	dto = toPromptTemplateDTO(&t)
+
+	return
+}
+
+func (s promptService) Update(cmd *PromptUpdateCmd) (
+	dto PromptTemplateDTO, code string, err error,
+) {
+	t, code, err := s.get(cmd.Owner, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	if t.Owner.Account() != cmd.Owner.Account() {
+		code = ErrorPromptNotFound
+		err = errors.New("no such prompt template")
+
+		return
+	}
+
+	now := utils.Now()
+
+	if cmd.Name != nil {
+		if code, err = s.checkText(cmd.Name.PromptName()); err != nil {
+			return
+		}
+
+		t.Name = cmd.Name
+	}
+
+	if cmd.Content != nil {
+		if code, err = s.checkText(cmd.Content.PromptContent()); err != nil {
+			return
+		}
+
+		t.AddVersion(cmd.Content, now, s.cfg.MaxVersionNum)
+	}
+
+	if cmd.Public != nil {
+		t.Public = *cmd.Public
+	}
+
+	t.UpdatedAt = now
+
+	if err = s.repo.Save(&t); err != nil {
+		if repoerr.IsErrorConcurrentUpdating(err) {
+			code = ErrorPromptConcurrentUpdate
+		} else if repoerr.IsErrorDuplicateCreating(err) {
+			code = ErrorPromptDuplicateName
+		}
+
+		return
+	}
+
+	dto = toPromptTemplateDTO(&t)
+
+	return
+}
+
+func (s promptService) Get(user types.Account, id string) (
+	dto PromptTemplateDetailDTO, code string, err error,
+) {
+	t, code, err := s.get(user, id)
+	if err == nil {
+		dto = toPromptTemplateDetailDTO(&t)
+	}
+
+	return
+}
+
+func (s promptService) List(owner types.Account) ([]PromptTemplateDTO, error) {
+	v, err := s.repo.List(owner)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	return toPromptTemplateDTOs(v), nil
+}
+
+func (s promptService) Delete(owner types.Account, id string) (code string, err error) {
+	if err = s.repo.Delete(owner, id); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = ErrorPromptNotFound
+		}
+
+		return
+	}
+
+	s.releaseSlot(owner)
+
+	return
+}
+
+func (s promptService) ListPublic(kind string) ([]PromptTemplateDTO, error) {
+	v, err := s.repo.ListPublic(kind)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	return toPromptTemplateDTOs(v), nil
+}
+
+func (s promptService) Like(user types.Account, id string) (string, error) {
+	return s.like(user, id, s.repo.Like)
+}
+
+func (s promptService) Unlike(user types.Account, id string) (string, error) {
+	return s.like(user, id, s.repo.Unlike)
+}
+
+// only the public template can be liked.
+func (s promptService) like(
+	user types.Account, id string, f func(types.Account, string) error,
+) (code string, err error) {
+	t, code, err := s.get(nil, id)
+	if err != nil {
+		return
+	}
+
+	if !t.Public {
+		return ErrorPromptNotFound, errors.New("no such prompt template")
+	}
+
+	if err = f(user, id); err != nil && repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorPromptNotFound
+	}
+
+	return
+}
+
+func (s promptService) Render(cmd *PromptRenderCmd) (r string, code string, err error) {
+	t, code, err := s.get(cmd.User, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	if t.Kind.PromptKind() != cmd.Kind {
+		code = ErrorPromptKindMismatch
+		err = errors.New("the prompt template can't be used by this model")
+
+		return
+	}
+
+	v := t.GetVersion(cmd.Version)
+	if v == nil {
+		code = ErrorPromptVersionNotFound
+		err = errors.New("no such version of prompt template")
+
+		return
+	}
+
+	if r, err = v.Render(cmd.Variables); err != nil {
+		code = ErrorPromptRenderFailed
+	}
+
+	return
+}
+
+func toPromptTemplateDTOs(v []domain.PromptTemplate) []PromptTemplateDTO {
+	r := make([]PromptTemplateDTO, len(v))
+	for i := range v {
+		r[i] = toPromptTemplateDTO(&v[i])
+	}
+
+	return r
+}
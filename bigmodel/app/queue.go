@@ -0,0 +1,463 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/message"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const queueReplyBufferSize = 1000
+
+// QueueService queues the invocations of models, so that they wait for
+// the free endpoints instead of failing when the models are busy.
+type QueueService interface {
+	Submit(*QueueTaskCmd) (QueueTaskDTO, string, error)
+	Get(*domain.QueueTaskIndex) (QueueTaskDTO, string, error)
+	GetResult(*domain.QueueTaskIndex) (QueueTaskResultDTO, string, error)
+	Cancel(*domain.QueueTaskIndex) (string, error)
+
+	// Run runs the workers of all the models until the ctx is done, and
+	// returns after all of them exit.
+	Run(ctx context.Context)
+}
+
+func NewQueueService(
+	fm bigmodel.BigModel,
+	repo repository.QueueTask,
+	sender message.MessageProducer,
+	quota ApiQuota,
+	incident ModerationRecorder,
+	cfg *QueueConfig,
+) QueueService {
+	return &queueService{
+		fm:       fm,
+		repo:     repo,
+		sender:   sender,
+		quota:    quota,
+		incident: incident,
+		cfg:      cfg,
+	}
+}
+
+type queueService struct {
+	fm       bigmodel.BigModel
+	repo     repository.QueueTask
+	sender   message.MessageProducer
+	quota    ApiQuota
+	incident ModerationRecorder
+	cfg      *QueueConfig
+}
+
+func (s *queueService) Submit(cmd *QueueTaskCmd) (dto QueueTaskDTO, code string, err error) {
+	n, err := s.repo.CountWaiting(cmd.User)
+	if err != nil {
+		return
+	}
+
+	if n >= s.cfg.MaxWaitingNum {
+		code = ErrorQueueTaskExccedMaxNum
+		err = errors.New("exceed max waiting task num")
+
+		return
+	}
+
+	// the quota is reserved when the task is created, and it is released
+	// if the task is cancelled or failed.
+	now := utils.Now()
+	if code, err = s.quota.Reserve(cmd.User, cmd.Model, now); err != nil {
+		return
+	}
+
+	task := domain.QueueTask{
+		Owner:     cmd.User,
+		Model:     cmd.Model,
+		Input:     cmd.Input,
+		Picture:   cmd.Picture,
+		Status:    domain.QueueTaskStatusWaiting,
+		CreatedAt: now,
+	}
+
+	if task.Id, err = s.repo.Add(&task); err != nil {
+		s.quota.Release(cmd.User, cmd.Model, now)
+
+		return
+	}
+
+	dto, err = s.toQueueTaskDTO(&task)
+
+	return
+}
+
+func (s *queueService) Get(index *domain.QueueTaskIndex) (dto QueueTaskDTO, code string, err error) {
+	task, code, err := s.get(index)
+	if err != nil {
+		return
+	}
+
+	dto, err = s.toQueueTaskDTO(&task)
+
+	return
+}
+
+func (s *queueService) GetResult(index *domain.QueueTaskIndex) (
+	dto QueueTaskResultDTO, code string, err error,
+) {
+	task, code, err := s.get(index)
+	if err != nil {
+		return
+	}
+
+	if task.Status != domain.QueueTaskStatusFinished {
+		code = ErrorQueueTaskNotFinished
+		err = errors.New("the task is not finished")
+
+		return
+	}
+
+	dto = QueueTaskResultDTO{
+		Id:         task.Id,
+		Model:      task.Model.ModelName(),
+		Reply:      task.Reply,
+		Pictures:   task.Pictures,
+		FinishedAt: task.FinishedAt,
+	}
+
+	return
+}
+
+func (s *queueService) Cancel(index *domain.QueueTaskIndex) (code string, err error) {
+	task, code, err := s.get(index)
+	if err != nil {
+		return
+	}
+
+	if err = s.repo.Cancel(index); err == nil {
+		s.quota.Release(task.Owner, task.Model, task.CreatedAt)
+
+		return
+	}
+
+	if repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorQueueTaskNotFound
+	} else if repoerr.IsErrorConcurrentUpdating(err) {
+		// it is not waiting, or it doesn't exist.
+		if _, code, err = s.get(index); err == nil {
+			code = ErrorQueueTaskNotCancellable
+			err = errors.New("only the waiting task can be cancelled")
+		}
+	}
+
+	return
+}
+
+func (s *queueService) get(index *domain.QueueTaskIndex) (
+	task domain.QueueTask, code string, err error,
+) {
+	if task, err = s.repo.Get(index); err != nil && repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorQueueTaskNotFound
+	}
+
+	return
+}
+
+func (s *queueService) toQueueTaskDTO(task *domain.QueueTask) (dto QueueTaskDTO, err error) {
+	dto = QueueTaskDTO{
+		Id:         task.Id,
+		Model:      task.Model.ModelName(),
+		Status:     task.Status,
+		Error:      task.Error,
+		CreatedAt:  task.CreatedAt,
+		StartedAt:  task.StartedAt,
+		FinishedAt: task.FinishedAt,
+	}
+
+	if task.IsDone() {
+		return
+	}
+
+	latency, err := s.latency(task.Model)
+	if err != nil {
+		return
+	}
+
+	switch task.Status {
+	case domain.QueueTaskStatusWaiting:
+		if dto.Rank, err = s.repo.Rank(task); err != nil {
+			return
+		}
+
+		// the tasks before it are run in parallel by the workers of all the
+		// instances. All the workers are busy while any task is waiting, so
+		// the num of running tasks is the num of workers.
+		workers, err1 := s.repo.CountRunning(task.Model, s.staleTime())
+		if err1 != nil {
+			err = err1
+
+			return
+		}
+
+		if workers < s.cfg.Concurrency {
+			workers = s.cfg.Concurrency
+		}
+
+		rounds := dto.Rank/workers + 1
+		dto.EstimatedWait = int(float64(rounds) * latency)
+
+	case domain.QueueTaskStatusRunning:
+		if left := latency - float64(utils.Now()-task.StartedAt); left > 0 {
+			dto.EstimatedWait = int(left)
+		}
+	}
+
+	return
+}
+
+// latency returns the seconds to run a task of model, which is estimated
+// by the latest tasks finished by the workers of all the instances.
+func (s *queueService) latency(model domain.ModelName) (float64, error) {
+	v, err := s.repo.Latency(model, s.cfg.LatencySamples)
+	if err != nil || v <= 0 {
+		return float64(s.cfg.DefaultLatency), err
+	}
+
+	return v, nil
+}
+
+func (s *queueService) staleTime() int64 {
+	return utils.Now() - int64(s.cfg.StaleTime)
+}
+
+func (s *queueService) Run(ctx context.Context) {
+	models := s.models()
+
+	wg := sync.WaitGroup{}
+
+	for i := range models {
+		for j := 0; j < s.cfg.Concurrency; j++ {
+			wg.Add(1)
+
+			go func(model domain.ModelName) {
+				defer wg.Done()
+
+				s.work(ctx, model)
+			}(models[i])
+		}
+	}
+
+	wg.Wait()
+}
+
+// models returns the chat models and WuKong, all of which are queued.
+func (s *queueService) models() []domain.ModelName {
+	infos := s.fm.Models()
+
+	names := make([]string, 0, len(infos)+1)
+	for i := range infos {
+		names = append(names, infos[i].Name)
+	}
+
+	names = append(names, string(domain.BigmodelWuKong))
+
+	models := make([]domain.ModelName, 0, len(names))
+
+	for _, name := range names {
+		model, err := domain.NewModelName(name)
+		if err != nil {
+			logrus.Errorf("invalid model %s of queue, err:%s", name, err.Error())
+
+			continue
+		}
+
+		models = append(models, model)
+	}
+
+	return models
+}
+
+func (s *queueService) work(ctx context.Context, model domain.ModelName) {
+	interval := time.Duration(s.cfg.PollInterval) * time.Second
+
+	for {
+		task, err := s.repo.Claim(model, s.staleTime())
+		if err == nil {
+			s.run(ctx, &task)
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			continue
+		}
+
+		if !repoerr.IsErrorResourceNotExists(err) {
+			logrus.Errorf("claim queue task of %s failed, err:%s", model.ModelName(), err.Error())
+		}
+
+		if !sleepUntilDone(ctx, interval) {
+			return
+		}
+	}
+}
+
+func (s *queueService) run(ctx context.Context, task *domain.QueueTask) {
+	bigmodelType := domain.BigmodelType(task.Model.ModelName())
+
+	_ = s.sender.SendBigModelStarted(&domain.BigModelStartedEvent{
+		Account:      task.Owner,
+		BigModelType: bigmodelType,
+	})
+
+	err := s.invokeUntilFree(ctx, task)
+
+	_ = s.sender.SendBigModelFinished(&domain.BigModelFinishedEvent{
+		Account:      task.Owner,
+		BigModelType: bigmodelType,
+	})
+
+	if ctx.Err() != nil {
+		// the instance is shutting down, and the task is left running, so
+		// that it will be claimed again after it is stale.
+		logrus.Infof("queue task %s is interrupted", task.Id)
+
+		return
+	}
+
+	task.FinishedAt = utils.Now()
+
+	if err != nil {
+		task.Status = domain.QueueTaskStatusFailed
+
+		if task.Error = setChatCode(err); task.Error == "" {
+			task.Error = ErrorCodeSystem
+		}
+
+		logrus.Errorf("run queue task %s failed, err:%s", task.Id, err.Error())
+	} else {
+		task.Status = domain.QueueTaskStatusFinished
+	}
+
+	if err := s.repo.Finish(task); err != nil {
+		// the task may have been claimed by another worker which will
+		// finish it and settle the quota.
+		logrus.Errorf("finish queue task %s failed, err:%s", task.Id, err.Error())
+
+		return
+	}
+
+	if task.Status == domain.QueueTaskStatusFailed {
+		s.quota.Release(task.Owner, task.Model, task.CreatedAt)
+
+		return
+	}
+
+	usage := domain.ApiUsage{
+		User:     task.Owner,
+		Model:    task.Model,
+		Requests: 1,
+	}
+
+	if task.Model.IsWuKong() {
+		usage.InputChars = utils.StrLen(task.Picture.Desc.WuKongPictureDesc())
+		usage.Images = len(task.Pictures)
+	} else {
+		usage.InputChars = countChatChars(task.Input.Messages)
+		usage.OutputChars = utils.StrLen(task.Reply)
+	}
+
+	s.quota.Record(&usage)
+}
+
+// invokeUntilFree calls the model again and again while it is busy.
+func (s *queueService) invokeUntilFree(ctx context.Context, task *domain.QueueTask) error {
+	deadline := time.Now().Add(time.Duration(s.cfg.RetryTimeout) * time.Second)
+	interval := time.Duration(s.cfg.PollInterval) * time.Second
+
+	for {
+		err := s.invoke(ctx, task)
+		if err == nil || !bigmodel.IsErrorBusySource(err) || time.Now().After(deadline) {
+			return err
+		}
+
+		if !sleepUntilDone(ctx, interval) {
+			return err
+		}
+	}
+}
+
+// invoke runs the task and saves the reply or the pictures to it.
+func (s *queueService) invoke(ctx context.Context, task *domain.QueueTask) (err error) {
+	if task.Model.IsWuKong() {
+		task.Pictures, err = s.fm.GenPicturesByWuKong(
+			task.Owner, &task.Picture, string(domain.BigmodelWuKongUser),
+		)
+
+		return
+	}
+
+	ch := make(chan string, queueReplyBufferSize)
+
+	input := task.Input
+	input.Done = ctx.Done()
+
+	if err = s.fm.Chat(ch, &input); err != nil {
+		return
+	}
+
+	reply := strings.Builder{}
+	rejected := false
+	failed := false
+
+	for msg := range ch {
+		switch msg {
+		case "done":
+		case moderation.MsgRejected:
+			rejected = true
+		case moderation.MsgFailed:
+			failed = true
+		default:
+			reply.WriteString(msg)
+		}
+	}
+
+	if failed {
+		return errors.New("check the reply failed")
+	}
+
+	if rejected {
+		s.incident.Record(&domain.ModerationIncident{
+			User:      task.Owner,
+			Model:     task.Model.ModelName(),
+			RequestId: task.Id,
+			Input:     lastChatContent(task.Input.Messages),
+		})
+
+		return bigmodel.NewErrorSensitiveInfo(errors.New("the reply is rejected"))
+	}
+
+	task.Reply = reply.String()
+
+	return
+}
+
+// sleepUntilDone waits for the interval, and returns false if the ctx is
+// done before it.
+func sleepUntilDone(ctx context.Context, interval time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(interval):
+		return true
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	commondomain "github.com/opensourceways/xihe-server/common/domain"
+	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// ApiQuota limits the calls of users to the big models and accounts
+// the usages of them.
+type ApiQuota interface {
+	// Reserve takes a request from the rate limit and the quotas at the
+	// unix time before calling the model, so that the concurrent requests
+	// can't exceed them.
+	Reserve(user types.Account, model domain.ModelName, at int64) (string, error)
+
+	// Release gives back the request reserved at the unix time if the
+	// model is not called successfully.
+	Release(user types.Account, model domain.ModelName, at int64)
+
+	// Record accounts the usage after the model is called successfully.
+	Record(*domain.ApiUsage)
+}
+
+func NewApiQuota(
+	repo repository.ApiUsage, counter commonrepo.UsageCounter, cfg *QuotaConfig,
+) ApiQuota {
+	return apiQuota{
+		repo:    repo,
+		counter: counter,
+		cfg:     cfg,
+	}
+}
+
+type apiQuota struct {
+	repo    repository.ApiUsage
+	counter commonrepo.UsageCounter
+	cfg     *QuotaConfig
+}
+
+func (q apiQuota) Reserve(user types.Account, model domain.ModelName, at int64) (string, error) {
+	limit := q.cfg.limit(model.ModelName())
+
+	i, err := q.counter.Admit(q.limits(user, model, at))
+	if err != nil || i < 0 {
+		return "", err
+	}
+
+	switch i {
+	case 0:
+		return ErrorBigModelRateLimited, fmt.Errorf(
+			"exceed the rate limit of %d requests per minute", limit.RateLimit,
+		)
+
+	case 1:
+		return ErrorBigModelQuotaExceeded, fmt.Errorf(
+			"exceed the daily quota of %d requests", limit.DailyLimit,
+		)
+
+	default:
+		return ErrorBigModelQuotaExceeded, fmt.Errorf(
+			"exceed the monthly quota of %d requests", limit.MonthlyLimit,
+		)
+	}
+}
+
+func (q apiQuota) Release(user types.Account, model domain.ModelName, at int64) {
+	if err := q.counter.Release(q.limits(user, model, at)); err != nil {
+		logrus.Errorf(
+			"release api quota of %s to %s failed, err:%s",
+			user.Account(), model.ModelName(), err.Error(),
+		)
+	}
+}
+
+// limits returns the rate limit, the daily and the monthly quota in order.
+func (q apiQuota) limits(user types.Account, model domain.ModelName, at int64) []commondomain.UsageLimit {
+	limit := q.cfg.limit(model.ModelName())
+	key := "bigmodel/" + user.Account() + "/" + model.ModelName()
+
+	return []commondomain.UsageLimit{
+		{Key: key, Window: commondomain.MinuteWindow(at), Max: limit.RateLimit},
+		{Key: key, Window: commondomain.DayWindow(at), Max: limit.DailyLimit},
+		{Key: key, Window: commondomain.MonthWindow(at), Max: limit.MonthlyLimit},
+	}
+}
+
+func (q apiQuota) Record(u *domain.ApiUsage) {
+	if u.Date == "" {
+		u.Date = commondomain.UsageDate(utils.Now())
+	}
+
+	if err := q.repo.Add(u); err != nil {
+		logrus.Errorf(
+			"record api usage of %s to %s failed, err:%s",
+			u.User.Account(), u.Model.ModelName(), err.Error(),
+		)
+	}
+}
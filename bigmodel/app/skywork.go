@@ -20,12 +20,20 @@ func (s bigModelService) SkyWork(cmd *SkyWorkCmd) (code string, err error) {
 		RepetitionPenalty: cmd.RepetitionPenalty,
 	}
 
-	if err = s.fm.SkyWork(cmd.CH, input); err != nil {
+	ch := make(chan string, cap(cmd.CH))
+	if err = s.fm.SkyWork(ch, input); err != nil {
 		code = s.setCode(err)
 
 		return
 	}
 
+	go relayReplyAndRecord(ch, cmd.CH, nil, s.incident, &domain.ModerationIncident{
+		User:      cmd.User,
+		Model:     string(domain.BigmodelSkyWork),
+		RequestId: cmd.RequestId,
+		Input:     cmd.Text.SkyWorkText(),
+	})
+
 	_ = s.sender.SendBigModelFinished(&domain.BigModelFinishedEvent{
 		Account:      cmd.User,
 		BigModelType: domain.BigmodelSkyWork,
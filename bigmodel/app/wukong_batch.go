@@ -0,0 +1,525 @@
+package app
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	wukongBatchManifestFile = "manifest.json"
+	wukongBatchImagesDir    = "images"
+)
+
+// WuKongBatchService generates the pictures for a list of prompts in the
+// background. The pictures are saved on OBS and can be downloaded as a
+// zip archive with a manifest when the job is done. Each prompt takes a
+// request from the api quota of user when it runs, and it fails if the
+// quota is exceeded.
+type WuKongBatchService interface {
+	Submit(*WuKongBatchCmd) (WuKongBatchJobDTO, string, error)
+	Get(*domain.WuKongBatchIndex) (WuKongBatchJobDetailDTO, string, error)
+	List(types.Account) ([]WuKongBatchJobDTO, error)
+	Cancel(*domain.WuKongBatchIndex) (string, error)
+	Archive(*domain.WuKongBatchIndex) (WuKongBatchArchive, string, error)
+
+	// Run runs the workers until ctx is done, it should be called once.
+	Run(ctx context.Context)
+}
+
+func NewWuKongBatchService(
+	fm bigmodel.BigModel,
+	repo repository.WuKongBatchJob,
+	quota ApiQuota,
+	cfg *WuKongBatchConfig,
+) WuKongBatchService {
+	return &wukongBatchService{
+		fm:    fm,
+		repo:  repo,
+		quota: quota,
+		cfg:   cfg,
+	}
+}
+
+type wukongBatchService struct {
+	fm    bigmodel.BigModel
+	repo  repository.WuKongBatchJob
+	quota ApiQuota
+	cfg   *WuKongBatchConfig
+}
+
+func (s *wukongBatchService) Submit(cmd *WuKongBatchCmd) (
+	dto WuKongBatchJobDTO, code string, err error,
+) {
+	if len(cmd.Prompts) > s.cfg.MaxPromptNum {
+		err = fmt.Errorf("the num of prompts should be less than %d", s.cfg.MaxPromptNum)
+
+		return
+	}
+
+	if cmd.ImageNum > s.cfg.MaxImageNum {
+		err = fmt.Errorf("the num of pictures should be less than %d", s.cfg.MaxImageNum)
+
+		return
+	}
+
+	n, err := s.repo.CountUndone(cmd.User)
+	if err != nil {
+		return
+	}
+
+	if n >= s.cfg.MaxUndoneNum {
+		code = ErrorWuKongBatchExccedMaxNum
+		err = errors.New("exceed max undone batch job num")
+
+		return
+	}
+
+	job := domain.WuKongBatchJob{
+		Owner:     cmd.User,
+		Name:      cmd.Name,
+		Status:    domain.WuKongBatchStatusWaiting,
+		ImageNum:  cmd.ImageNum,
+		Items:     make([]domain.WuKongBatchItem, len(cmd.Prompts)),
+		CreatedAt: utils.Now(),
+	}
+
+	for i := range cmd.Prompts {
+		job.Items[i] = domain.WuKongBatchItem{
+			WuKongPictureMeta: cmd.Prompts[i],
+			Status:            domain.WuKongBatchItemStatusWaiting,
+		}
+	}
+
+	if job.Id, err = s.repo.Add(&job); err != nil {
+		return
+	}
+
+	dto = toWuKongBatchJobDTO(&job)
+
+	return
+}
+
+func (s *wukongBatchService) Get(index *domain.WuKongBatchIndex) (
+	dto WuKongBatchJobDetailDTO, code string, err error,
+) {
+	job, code, err := s.get(index)
+	if err == nil {
+		dto = toWuKongBatchJobDetailDTO(&job)
+	}
+
+	return
+}
+
+func (s *wukongBatchService) List(user types.Account) ([]WuKongBatchJobDTO, error) {
+	v, err := s.repo.List(user)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]WuKongBatchJobDTO, len(v))
+	for i := range v {
+		r[i] = toWuKongBatchJobDTO(&v[i])
+	}
+
+	return r, nil
+}
+
+func (s *wukongBatchService) Cancel(index *domain.WuKongBatchIndex) (code string, err error) {
+	if err = s.repo.Cancel(index); err == nil {
+		return
+	}
+
+	if repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorWuKongBatchNotFound
+	} else if repoerr.IsErrorConcurrentUpdating(err) {
+		// it is done, or it doesn't exist.
+		if _, code, err = s.get(index); err == nil {
+			code = ErrorWuKongBatchNotCancellable
+			err = errors.New("the job is done")
+		}
+	}
+
+	return
+}
+
+// Archive returns the archive of the job which is done, including the
+// pictures generated before the job is cancelled.
+func (s *wukongBatchService) Archive(index *domain.WuKongBatchIndex) (
+	r WuKongBatchArchive, code string, err error,
+) {
+	job, code, err := s.get(index)
+	if err != nil {
+		return
+	}
+
+	if !job.IsDone() {
+		code = ErrorWuKongBatchNotFinished
+		err = errors.New("the job is not done")
+
+		return
+	}
+
+	r = WuKongBatchArchive{fm: s.fm, job: job}
+
+	return
+}
+
+func (s *wukongBatchService) get(index *domain.WuKongBatchIndex) (
+	job domain.WuKongBatchJob, code string, err error,
+) {
+	if job, err = s.repo.Get(index); err != nil && repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorWuKongBatchNotFound
+	}
+
+	return
+}
+
+func (s *wukongBatchService) Run(ctx context.Context) {
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < s.cfg.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (s *wukongBatchService) work(ctx context.Context) {
+	interval := time.Duration(s.cfg.PollInterval) * time.Second
+
+	for {
+		job, err := s.repo.Claim(utils.Now() - int64(s.cfg.StaleTime))
+		if err == nil {
+			s.run(ctx, &job)
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			continue
+		}
+
+		if !repoerr.IsErrorResourceNotExists(err) {
+			logrus.Errorf("claim wukong batch job failed, err:%s", err.Error())
+		}
+
+		if !sleepUntilDone(ctx, interval) {
+			return
+		}
+	}
+}
+
+// run runs the items of job one by one. If ctx is done, the job is left
+// running and will be resumed from the unsaved item after it is stale.
+func (s *wukongBatchService) run(ctx context.Context, job *domain.WuKongBatchJob) {
+	model, err := domain.NewModelName(string(domain.BigmodelWuKong))
+	if err != nil {
+		logrus.Errorf("run wukong batch job %s failed, err:%s", job.Id, err.Error())
+
+		return
+	}
+
+	for i := job.NextItem(); i >= 0; i = job.NextItem() {
+		if !s.runItem(ctx, job, i, model) {
+			return
+		}
+
+		if err := s.repo.SaveItem(job, i); err != nil {
+			// the job is cancelled, or it is claimed by another worker.
+			if !repoerr.IsErrorConcurrentUpdating(err) {
+				logrus.Errorf(
+					"save item %d of wukong batch job %s failed, err:%s",
+					i, job.Id, err.Error(),
+				)
+			}
+
+			return
+		}
+	}
+
+	job.Status = domain.WuKongBatchStatusFinished
+	job.FinishedAt = utils.Now()
+
+	if err := s.repo.Finish(job); err != nil && !repoerr.IsErrorConcurrentUpdating(err) {
+		logrus.Errorf("finish wukong batch job %s failed, err:%s", job.Id, err.Error())
+	}
+}
+
+// runItem takes a request from the quota of owner, then calls WuKong until
+// the num of pictures is enough and copies them to the directory of job.
+// The item fails if the quota is exceeded. It returns false if ctx is done
+// before the item is run out.
+func (s *wukongBatchService) runItem(
+	ctx context.Context, job *domain.WuKongBatchJob, index int, model domain.ModelName,
+) bool {
+	item := &job.Items[index]
+	item.Pictures = nil
+
+	at, code, err := s.reserveUntilFree(ctx, job.Owner, model)
+	if ctx.Err() != nil {
+		if err == nil {
+			s.quota.Release(job.Owner, model, at)
+		}
+
+		return false
+	}
+
+	if err != nil {
+		s.failItem(job, index, code, err)
+
+		return true
+	}
+
+	for len(item.Pictures) < job.ImageNum {
+		esType := domain.BigmodelWuKong
+		if job.ImageNum-len(item.Pictures) >= 4 {
+			esType = domain.BigmodelWuKong4Img
+		}
+
+		v, err := s.genUntilFree(ctx, job.Owner, &item.WuKongPictureMeta, string(esType))
+		if ctx.Err() != nil {
+			s.quota.Release(job.Owner, model, at)
+
+			return false
+		}
+
+		if err == nil && len(v) == 0 {
+			err = errors.New("no picture generated")
+		}
+
+		if err == nil {
+			err = s.savePictures(job, index, v)
+		}
+
+		if err != nil {
+			s.quota.Release(job.Owner, model, at)
+			s.failItem(job, index, setChatCode(err), err)
+
+			return true
+		}
+	}
+
+	item.Status = domain.WuKongBatchItemStatusFinished
+
+	s.quota.Record(&domain.ApiUsage{
+		User:       job.Owner,
+		Model:      model,
+		Requests:   1,
+		InputChars: utils.StrLen(item.Desc.WuKongPictureDesc()),
+		Images:     len(item.Pictures),
+	})
+
+	return true
+}
+
+func (s *wukongBatchService) failItem(job *domain.WuKongBatchJob, index int, code string, err error) {
+	item := &job.Items[index]
+
+	item.Status = domain.WuKongBatchItemStatusFailed
+	if item.Error = code; item.Error == "" {
+		item.Error = ErrorCodeSystem
+	}
+
+	logrus.Errorf(
+		"run item %d of wukong batch job %s failed, err:%s",
+		index, job.Id, err.Error(),
+	)
+}
+
+// reserveUntilFree takes a request from the quota of user again and again
+// while the rate limit is reached. It returns the time of reservation.
+func (s *wukongBatchService) reserveUntilFree(
+	ctx context.Context, user types.Account, model domain.ModelName,
+) (int64, string, error) {
+	deadline := time.Now().Add(time.Duration(s.cfg.RetryTimeout) * time.Second)
+	interval := time.Duration(s.cfg.PollInterval) * time.Second
+
+	for {
+		at := utils.Now()
+
+		code, err := s.quota.Reserve(user, model, at)
+		if err == nil || code != ErrorBigModelRateLimited || time.Now().After(deadline) {
+			return at, code, err
+		}
+
+		if !sleepUntilDone(ctx, interval) {
+			return at, code, err
+		}
+	}
+}
+
+// genUntilFree calls WuKong again and again while it is busy.
+func (s *wukongBatchService) genUntilFree(
+	ctx context.Context, user types.Account, meta *domain.WuKongPictureMeta, esType string,
+) (map[string]string, error) {
+	deadline := time.Now().Add(time.Duration(s.cfg.RetryTimeout) * time.Second)
+	interval := time.Duration(s.cfg.PollInterval) * time.Second
+
+	for {
+		v, err := s.fm.GenPicturesByWuKong(user, meta, esType)
+		if err == nil || !bigmodel.IsErrorBusySource(err) || time.Now().After(deadline) {
+			return v, err
+		}
+
+		if !sleepUntilDone(ctx, interval) {
+			return v, err
+		}
+	}
+}
+
+func (s *wukongBatchService) savePictures(
+	job *domain.WuKongBatchJob, index int, links map[string]string,
+) error {
+	item := &job.Items[index]
+
+	v := make([]string, 0, len(links))
+	for p := range links {
+		v = append(v, p)
+	}
+	sort.Strings(v)
+
+	for _, src := range v {
+		if len(item.Pictures) >= job.ImageNum {
+			break
+		}
+
+		name := fmt.Sprintf("%04d-%d%s", index+1, len(item.Pictures)+1, path.Ext(src))
+		dst := s.fm.GenWuKongBatchPath(job.Owner, job.Id, name)
+
+		if err := s.fm.MoveWuKongPictureToDir(dst, src); err != nil {
+			return err
+		}
+
+		item.Pictures = append(item.Pictures, dst)
+	}
+
+	return nil
+}
+
+// WuKongBatchArchive is the zip of the pictures of job and the manifest
+// which describes the prompt of each picture.
+type WuKongBatchArchive struct {
+	fm  bigmodel.BigModel
+	job domain.WuKongBatchJob
+}
+
+func (a *WuKongBatchArchive) FileName() string {
+	return fmt.Sprintf("wukong-batch-%s.zip", a.job.Id)
+}
+
+// Write writes the archive. The picture which can't be read is skipped
+// and recorded in the manifest.
+func (a *WuKongBatchArchive) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	m := wukongBatchManifest{
+		Id:         a.job.Id,
+		Name:       a.job.Name,
+		Status:     a.job.Status,
+		ImageNum:   a.job.ImageNum,
+		CreatedAt:  a.job.CreatedAt,
+		FinishedAt: a.job.FinishedAt,
+		Items:      make([]wukongBatchManifestItem, len(a.job.Items)),
+	}
+
+	for i := range a.job.Items {
+		item := &a.job.Items[i]
+
+		mi := &m.Items[i]
+		mi.Index = i + 1
+		mi.Desc = item.Desc.WuKongPictureDesc()
+		mi.Style = item.Style
+		mi.Status = item.Status
+		mi.Error = item.Error
+		mi.Files = []string{}
+
+		for _, p := range item.Pictures {
+			name := path.Join(wukongBatchImagesDir, path.Base(p))
+
+			if err := a.writeFile(zw, name, p); err != nil {
+				logrus.Errorf("archive picture %s failed, err:%s", p, err.Error())
+
+				mi.Missing = append(mi.Missing, name)
+
+				continue
+			}
+
+			mi.Files = append(mi.Files, name)
+		}
+	}
+
+	f, err := zw.Create(wukongBatchManifestFile)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(&m); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (a *WuKongBatchArchive) writeFile(zw *zip.Writer, name, p string) error {
+	r, err := a.fm.ReadWuKongPicture(p)
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+
+	return err
+}
+
+type wukongBatchManifest struct {
+	Id         string                    `json:"id"`
+	Name       string                    `json:"name"`
+	Status     string                    `json:"status"`
+	ImageNum   int                       `json:"image_num"`
+	CreatedAt  int64                     `json:"created_at"`
+	FinishedAt int64                     `json:"finished_at"`
+	Items      []wukongBatchManifestItem `json:"items"`
+}
+
+type wukongBatchManifestItem struct {
+	Index   int      `json:"index"`
+	Desc    string   `json:"desc"`
+	Style   string   `json:"style,omitempty"`
+	Status  string   `json:"status"`
+	Error   string   `json:"error,omitempty"`
+	Files   []string `json:"files"`
+	Missing []string `json:"missing,omitempty"`
+}
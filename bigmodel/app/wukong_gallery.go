@@ -0,0 +1,278 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// WuKongGalleryService manages the tags, comments and reports of the
+// public pictures of WuKong.
+type WuKongGalleryService interface {
+	UpdateTags(*WuKongUpdateTagsCmd) (string, error)
+	ListTags() ([]WuKongTagDTO, error)
+
+	AddComment(*WuKongAddCommentCmd) (WuKongCommentDTO, string, error)
+	ListComments(*domain.WuKongPictureIndex) ([]WuKongCommentDTO, string, error)
+	DeleteComment(user types.Account, id string) (string, error)
+
+	// Report queues the report for review, and hides the normal picture
+	// once it is reported by enough users.
+	Report(*WuKongReportCmd) (string, string, error)
+	ListReports(status string) ([]WuKongReportDTO, error)
+	ReviewReport(*WuKongReviewReportCmd) (string, error)
+}
+
+func NewWuKongGalleryService(
+	fm bigmodel.BigModel,
+	picture repository.WuKongPicture,
+	comment repository.WuKongComment,
+	report repository.WuKongReport,
+	cfg *WuKongGalleryConfig,
+) WuKongGalleryService {
+	return wukongGalleryService{
+		fm:      fm,
+		picture: picture,
+		comment: comment,
+		report:  report,
+		cfg:     cfg,
+	}
+}
+
+type wukongGalleryService struct {
+	fm      bigmodel.BigModel
+	picture repository.WuKongPicture
+	comment repository.WuKongComment
+	report  repository.WuKongReport
+	cfg     *WuKongGalleryConfig
+}
+
+func (s wukongGalleryService) getPublic(index *domain.WuKongPictureIndex) (
+	p domain.WuKongPicture, code string, err error,
+) {
+	if p, err = s.picture.GetPublicByUserName(index.Owner, index.Id); err != nil {
+		code = ErrorWuKongInvalidId
+	}
+
+	return
+}
+
+// getVisiblePublic returns the picture which is not hidden.
+func (s wukongGalleryService) getVisiblePublic(index *domain.WuKongPictureIndex) (
+	p domain.WuKongPicture, code string, err error,
+) {
+	if p, code, err = s.getPublic(index); err != nil {
+		return
+	}
+
+	if p.Hidden {
+		code = ErrorWuKongPictureHidden
+		err = errors.New("the picture is hidden")
+	}
+
+	return
+}
+
+func (s wukongGalleryService) UpdateTags(cmd *WuKongUpdateTagsCmd) (code string, err error) {
+	index := domain.WuKongPictureIndex{
+		Owner: cmd.User,
+		Id:    cmd.Id,
+	}
+
+	p, code, err := s.getPublic(&index)
+	if err != nil {
+		return
+	}
+
+	p.Tags = cmd.Tags
+
+	err = s.picture.UpdatePublicPicture(p.Owner, p.Id, p.Version, &p)
+
+	return
+}
+
+func (s wukongGalleryService) ListTags() ([]WuKongTagDTO, error) {
+	v, err := s.picture.CountVisibleTags()
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]WuKongTagDTO, len(v))
+	for i := range v {
+		r[i] = WuKongTagDTO{Tag: v[i].Tag, Count: v[i].Count}
+	}
+
+	return r, nil
+}
+
+func (s wukongGalleryService) AddComment(cmd *WuKongAddCommentCmd) (
+	dto WuKongCommentDTO, code string, err error,
+) {
+	if _, code, err = s.getVisiblePublic(&cmd.Picture); err != nil {
+		return
+	}
+
+	if err = s.fm.CheckText(cmd.Content.WuKongCommentContent()); err != nil {
+		if bigmodel.IsErrorSensitiveInfo(err) {
+			code = ErrorBigModelSensitiveInfo
+		}
+
+		return
+	}
+
+	c := domain.WuKongComment{
+		Picture:   cmd.Picture,
+		User:      cmd.User,
+		Content:   cmd.Content,
+		CreatedAt: utils.Now(),
+	}
+
+	if c.Id, err = s.comment.Add(&c); err != nil {
+		return
+	}
+
+	dto = toWuKongCommentDTO(&c)
+
+	return
+}
+
+func (s wukongGalleryService) ListComments(index *domain.WuKongPictureIndex) (
+	r []WuKongCommentDTO, code string, err error,
+) {
+	if _, code, err = s.getVisiblePublic(index); err != nil {
+		return
+	}
+
+	v, err := s.comment.List(index.Id)
+	if err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]WuKongCommentDTO, len(v))
+	for i := range v {
+		r[i] = toWuKongCommentDTO(&v[i])
+	}
+
+	return
+}
+
+func (s wukongGalleryService) DeleteComment(user types.Account, id string) (code string, err error) {
+	if err = s.comment.Delete(user, id); err != nil && repoerr.IsErrorResourceNotExists(err) {
+		code = ErrorWuKongCommentNotFound
+	}
+
+	return
+}
+
+func (s wukongGalleryService) Report(cmd *WuKongReportCmd) (id string, code string, err error) {
+	p, code, err := s.getVisiblePublic(&cmd.Picture)
+	if err != nil {
+		return
+	}
+
+	r := domain.WuKongReport{
+		Picture:   cmd.Picture,
+		Reporter:  cmd.User,
+		Reason:    cmd.Reason,
+		Status:    domain.WuKongReportStatusPending,
+		CreatedAt: utils.Now(),
+	}
+
+	if id, err = s.report.Add(&r); err != nil {
+		if repoerr.IsErrorDuplicateCreating(err) {
+			code = ErrorWuKongDuplicateReport
+		}
+
+		return
+	}
+
+	// each user has one pending report of the picture at most, so the
+	// pending reports tell how many users have reported it.
+	n, err := s.report.CountPending(p.Id)
+	if err != nil {
+		return
+	}
+
+	if p.Report(n, s.cfg.ReportsToHide); p.Hidden {
+		err = s.picture.UpdatePublicPicture(p.Owner, p.Id, p.Version, &p)
+	}
+
+	return
+}
+
+func (s wukongGalleryService) ListReports(status string) ([]WuKongReportDTO, error) {
+	v, err := s.report.List(status)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]WuKongReportDTO, len(v))
+	for i := range v {
+		r[i] = toWuKongReportDTO(&v[i])
+	}
+
+	return r, nil
+}
+
+// ReviewReport confirms or dismisses the report. The confirmed picture is
+// hidden and loses the official level. The dismissed one is shown again
+// when there is no other report waiting for review.
+func (s wukongGalleryService) ReviewReport(cmd *WuKongReviewReportCmd) (code string, err error) {
+	r, err := s.report.Get(cmd.Id)
+	if err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = ErrorWuKongReportNotFound
+		}
+
+		return
+	}
+
+	if !r.IsPending() {
+		code = ErrorWuKongReportReviewed
+		err = errors.New("the report has been reviewed")
+
+		return
+	}
+
+	r.Status = domain.WuKongReportStatusDismissed
+	if cmd.Confirm {
+		r.Status = domain.WuKongReportStatusConfirmed
+	}
+
+	r.Reviewer = cmd.Reviewer.Account()
+	r.ReviewedAt = utils.Now()
+
+	if err = s.report.Review(&r); err != nil {
+		if repoerr.IsErrorConcurrentUpdating(err) {
+			code = ErrorWuKongReportReviewed
+		}
+
+		return
+	}
+
+	p, err := s.picture.GetPublicByUserName(r.Picture.Owner, r.Picture.Id)
+	if err != nil {
+		// the picture may have been deleted by owner.
+		return "", nil
+	}
+
+	if cmd.Confirm {
+		p.ConfirmReport()
+	} else {
+		n, err := s.report.CountPending(p.Id)
+		if err != nil || n > 0 {
+			return "", err
+		}
+
+		p.DismissReport()
+	}
+
+	err = s.picture.UpdatePublicPicture(p.Owner, p.Id, p.Version, &p)
+
+	return
+}
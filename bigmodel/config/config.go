@@ -1,6 +1,7 @@
 package config
 
 import (
+	"github.com/opensourceways/xihe-server/bigmodel/app"
 	"github.com/opensourceways/xihe-server/bigmodel/infrastructure/bigmodels"
 	"github.com/opensourceways/xihe-server/bigmodel/infrastructure/messageadapter"
 )
@@ -8,12 +9,24 @@ import (
 type Config struct {
 	bigmodels.Config
 
-	Message messageadapter.Config `json:"message"`
+	Message messageadapter.Config   `json:"message"`
+	Chat    app.ChatConfig          `json:"chat"`
+	Quota   app.QuotaConfig         `json:"quota"`
+	Queue   app.QueueConfig         `json:"queue"`
+	Prompt  app.PromptConfig        `json:"prompt"`
+	Batch   app.WuKongBatchConfig   `json:"wukong_batch"`
+	Gallery app.WuKongGalleryConfig `json:"wukong_gallery"`
 }
 
 func (cfg *Config) ConfigItems() []interface{} {
 	return []interface{}{
 		&cfg.Config,
 		&cfg.Message,
+		&cfg.Chat,
+		&cfg.Quota,
+		&cfg.Queue,
+		&cfg.Prompt,
+		&cfg.Batch,
+		&cfg.Gallery,
 	}
 }
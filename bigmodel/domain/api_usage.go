@@ -0,0 +1,16 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+// ApiUsage is the usage of the api of a model by a user in a day.
+type ApiUsage struct {
+	User        types.Account
+	Model       ModelName
+	Date        string
+	Requests    int
+	InputChars  int
+	OutputChars int
+	Images      int
+}
@@ -32,6 +32,11 @@ type WuKongPicture struct {
 	DiggCount int
 	Version   int
 	CreatedAt string
+	Tags      []string
+
+	// Hidden means the picture is not shown in the public gallery,
+	// because it is reported.
+	Hidden bool
 
 	WuKongPictureMeta
 }
@@ -49,6 +54,28 @@ func (r *WuKongPicture) SetDefaultDiggs() {
 	r.Diggs = []string{}
 }
 
+// Report hides the normal picture until the reports are reviewed, once it
+// is reported by enough users. The official picture is kept shown, because
+// it has been reviewed when it became official.
+func (r *WuKongPicture) Report(reporters, threshold int) {
+	if !r.IsOfficial() && reporters >= threshold {
+		r.Hidden = true
+	}
+}
+
+// ConfirmReport hides the picture and revokes the official level of it.
+func (r *WuKongPicture) ConfirmReport() {
+	r.Hidden = true
+
+	if r.IsOfficial() {
+		r.Level = NewWuKongPictureLevel(wukongPictureLevelNormal)
+	}
+}
+
+func (r *WuKongPicture) DismissReport() {
+	r.Hidden = false
+}
+
 // ai detector
 type AIDetectorInput struct {
 	Lang Lang
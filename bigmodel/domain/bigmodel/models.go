@@ -17,6 +17,41 @@ type CodeGeexResp struct {
 	Finish string `json:"finish"`
 }
 
+// ModelInfo is the model which can be chatted with.
+type ModelInfo struct {
+	Name    string
+	Title   string
+	Desc    string
+	Icon    string
+	Tags    []string
+	Builtin bool
+
+	TopK              int
+	TopP              float64
+	Temperature       float64
+	RepetitionPenalty float64
+}
+
+// EndpointPoolState is the state of endpoints of a model for monitoring.
+type EndpointPoolState struct {
+	Name      string
+	Idle      int
+	Endpoints []EndpointState
+}
+
+type EndpointState struct {
+	URL            string
+	Weight         int
+	MaxConcurrency int
+	Inflight       int
+	Healthy        bool
+	Ejected        bool
+	EjectedUntil   int64
+	ProbedAt       int64
+	Requests       int64
+	Errors         int64
+}
+
 type BigModel interface {
 	// common
 	GetIdleEndpoint(bid string) (c int, err error)
@@ -35,6 +70,10 @@ type BigModel interface {
 	CheckWuKongPicturePublicToLike(types.Account, string) (string, error)
 	CheckWuKongPictureToPublic(types.Account, string) (domain.WuKongPictureMeta, string, error)
 
+	// GenWuKongBatchPath returns the path of the file of batch job on OBS.
+	GenWuKongBatchPath(user types.Account, jobId, name string) string
+	ReadWuKongPicture(string) (io.ReadCloser, error)
+
 	// luojia
 	LuoJiaUploadPicture(f io.Reader, u types.Account) error
 	LuoJia(string) (string, error)
@@ -56,4 +95,13 @@ type BigModel interface {
 
 	// iflytekspark
 	IFlytekSpark(chan string, *domain.IFlytekSparkInput) error
+
+	// chat continues the conversation by the model of input.
+	Chat(chan string, *domain.ChatInput) error
+
+	// Models lists the models declared by config and the built-in ones.
+	Models() []ModelInfo
+
+	// EndpointPools returns the state of endpoints of all the models.
+	EndpointPools() []EndpointPoolState
 }
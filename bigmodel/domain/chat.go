@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+	ChatRoleSystem    = "system"
+
+	chatTitleLength  = 20
+	chatDefaultTitle = "New chat"
+)
+
+// ChatMessage
+type ChatMessage struct {
+	Role    string
+	Content string
+
+	// Model is the model which generated the message of assistant.
+	Model     string
+	CreatedAt int64
+}
+
+func NewChatMessage(role, content string) (ChatMessage, error) {
+	if role != ChatRoleUser && role != ChatRoleAssistant && role != ChatRoleSystem {
+		return ChatMessage{}, errors.New("invalid chat role")
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return ChatMessage{}, errors.New("no chat content")
+	}
+
+	if max := 20000; utils.StrLen(content) > max { // TODO: to config
+		return ChatMessage{}, errors.New("invalid chat content")
+	}
+
+	return ChatMessage{Role: role, Content: content}, nil
+}
+
+func (m *ChatMessage) IsUser() bool {
+	return m.Role == ChatRoleUser
+}
+
+// ChatSession is a conversation of user which can be continued by any model.
+type ChatSession struct {
+	Id        string
+	Owner     types.Account
+	Title     ChatSessionTitle
+	Model     ChatModel
+	Messages  []ChatMessage
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type ChatSessionIndex struct {
+	Owner types.Account
+	Id    string
+}
+
+// NewChatSession creates a session titled by the first message of user.
+func NewChatSession(owner types.Account, model ChatModel, msgs []ChatMessage) ChatSession {
+	title := ""
+	for i := range msgs {
+		if msgs[i].IsUser() {
+			title = strings.TrimSpace(msgs[i].Content)
+
+			break
+		}
+	}
+
+	if s := []rune(title); len(s) > chatTitleLength {
+		title = string(s[:chatTitleLength])
+	}
+
+	if title = utils.XSSFilter(title); title == "" {
+		title = chatDefaultTitle
+	}
+
+	now := utils.Now()
+
+	return ChatSession{
+		Owner:     owner,
+		Title:     chatSessionTitle(title),
+		Model:     model,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ChatSampling
+type ChatSampling struct {
+	Sampling          bool
+	TopK              TopK
+	TopP              TopP
+	Temperature       Temperature
+	RepetitionPenalty RepetitionPenalty
+}
+
+// ChatInput is the conversation to be continued by the model.
+type ChatInput struct {
+	Model    ChatModel
+	Messages []ChatMessage
+
+	// Done is closed when the reply is not waited for any more,
+	// then the model stops replying.
+	Done <-chan struct{}
+
+	ChatSampling
+}
+
+// Prompt converts the messages to the text to be answered and the history
+// of the previous rounds, because the models only accept them. The messages
+// of system are prepended to the text of the first round, and the adjacent
+// messages of same role are merged.
+func (input *ChatInput) Prompt() (text string, history [][2]string, err error) {
+	system := []string{}
+	rounds := [][2]string{}
+	round := [2]string{}
+
+	join := func(s, v string) string {
+		if s == "" {
+			return v
+		}
+
+		return s + "\n" + v
+	}
+
+	for i := range input.Messages {
+		m := &input.Messages[i]
+
+		switch m.Role {
+		case ChatRoleSystem:
+			system = append(system, m.Content)
+
+		case ChatRoleUser:
+			if round[1] != "" {
+				rounds = append(rounds, round)
+				round = [2]string{}
+			}
+
+			round[0] = join(round[0], m.Content)
+
+		case ChatRoleAssistant:
+			round[1] = join(round[1], m.Content)
+		}
+	}
+
+	if round[0] == "" || round[1] != "" {
+		err = errors.New("the last message should be of user")
+
+		return
+	}
+
+	if len(system) > 0 {
+		first := &round
+		if len(rounds) > 0 {
+			first = &rounds[0]
+		}
+
+		first[0] = join(strings.Join(system, "\n"), first[0])
+	}
+
+	return round[0], rounds, nil
+}
+
+// registeredChatModels are the chat models declared by config besides
+// the built-in ones.
+var registeredChatModels = map[string]bool{}
+
+// RegisterChatModels should only be called at initialization.
+func RegisterChatModels(names []string) {
+	for _, v := range names {
+		registeredChatModels[v] = true
+	}
+}
+
+// BuiltinChatModels returns the chat models served by the built-in adapters.
+func BuiltinChatModels() []string {
+	return []string{
+		bigmodelGLM2, bigmodelLLAMA2, bigmodelSkyWork, bigmodelIFlytekSpark, bigmodelBaiChuan,
+	}
+}
+
+// ChatModel
+type ChatModel interface {
+	ChatModel() string
+}
+
+func NewChatModel(v string) (ChatModel, error) {
+	switch BigmodelType(v) {
+	case BigmodelGLM2, BigmodelLLAMA2, BigmodelSkyWork, BigmodelIFlytekSpark, BigmodelBaiChuan:
+		return chatModel(v), nil
+	}
+
+	if registeredChatModels[v] {
+		return chatModel(v), nil
+	}
+
+	return nil, errors.New("unsupported chat model")
+}
+
+type chatModel string
+
+func (m chatModel) ChatModel() string {
+	return string(m)
+}
+
+// ChatSessionTitle
+type ChatSessionTitle interface {
+	ChatSessionTitle() string
+}
+
+func NewChatSessionTitle(v string) (ChatSessionTitle, error) {
+	v = utils.XSSFilter(strings.TrimSpace(v))
+
+	if v == "" {
+		return nil, errors.New("no chat session title")
+	}
+
+	if max := 50; utils.StrLen(v) > max { // TODO: to config
+		return nil, errors.New("invalid chat session title")
+	}
+
+	return chatSessionTitle(v), nil
+}
+
+type chatSessionTitle string
+
+func (t chatSessionTitle) ChatSessionTitle() string {
+	return string(t)
+}
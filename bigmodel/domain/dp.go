@@ -32,6 +32,8 @@ const (
 	langEN = "en"
 
 	modelNameWukong = "wukong"
+
+	wukongPictureLevelNormal = "normal"
 )
 
 var (
@@ -167,6 +169,75 @@ func (r wukongPictureLevel) IsHot() bool {
 	return r.WuKongPictureLevel() == "hot"
 }
 
+// WuKongPictureTags
+const (
+	maxWuKongPictureTagNum = 5
+	maxWuKongPictureTagLen = 16
+)
+
+// NewWuKongPictureTags validates the tags and removes the duplicate ones.
+func NewWuKongPictureTags(v []string) ([]string, error) {
+	r := make([]string, 0, len(v))
+
+	for _, t := range v {
+		if t = strings.TrimSpace(utils.XSSFilter(t)); t == "" {
+			continue
+		}
+
+		if utils.StrLen(t) > maxWuKongPictureTagLen {
+			return nil, fmt.Errorf(
+				"the length of tag should be less than %d", maxWuKongPictureTagLen,
+			)
+		}
+
+		dup := false
+		for _, item := range r {
+			if strings.EqualFold(item, t) {
+				dup = true
+
+				break
+			}
+		}
+
+		if !dup {
+			r = append(r, t)
+		}
+	}
+
+	if len(r) > maxWuKongPictureTagNum {
+		return nil, fmt.Errorf("the number of tags should be less than %d", maxWuKongPictureTagNum)
+	}
+
+	return r, nil
+}
+
+// WuKongCommentContent
+type WuKongCommentContent interface {
+	WuKongCommentContent() string
+}
+
+func NewWuKongCommentContent(v string) (WuKongCommentContent, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return nil, errors.New("no comment")
+	}
+
+	v = utils.XSSFilter(v)
+
+	if max := 200; utils.StrLen(v) > max {
+		return nil, fmt.Errorf(
+			"the length of comment should be less than %d", max,
+		)
+	}
+
+	return wukongCommentContent(v), nil
+}
+
+type wukongCommentContent string
+
+func (r wukongCommentContent) WuKongCommentContent() string {
+	return string(r)
+}
+
 // obspath
 type OBSPath interface {
 	OBSPath() string
@@ -269,16 +340,24 @@ func (r desc) Desc() string {
 // Model Name
 type ModelName interface {
 	ModelName() string
+	IsWuKong() bool
 }
 
 func NewModelName(v string) (ModelName, error) {
 	b := v == modelNameWukong
 	if !b {
-		return nil, errors.New("invalid model name")
+		// the api of chat models is applied by the same name
+		if _, err := NewChatModel(v); err != nil {
+			return nil, errors.New("invalid model name")
+		}
 	}
 	return modelName(v), nil
 }
 
+func (m modelName) IsWuKong() bool {
+	return string(m) == modelNameWukong
+}
+
 type modelName string
 
 func (m modelName) ModelName() string {
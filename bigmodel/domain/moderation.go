@@ -0,0 +1,28 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+// ModerationIncident records that the output of model for the request of
+// user is rejected by the moderation.
+type ModerationIncident struct {
+	User  types.Account
+	Model string
+
+	// RequestId is the id of chat session or queue task, or the id which
+	// is returned to the client if the request is not saved.
+	RequestId string
+	Input     string
+	CreatedAt int64
+}
+
+// ModerationDecision records that the content is rejected by a provider of
+// the moderation, so that the rules can be reviewed.
+type ModerationDecision struct {
+	Provider  string
+	Scene     string
+	Content   string
+	Reason    string
+	CreatedAt int64
+}
@@ -0,0 +1,24 @@
+package moderation
+
+const (
+	// SceneInput is the content submitted by user.
+	SceneInput = "input"
+
+	// SceneOutput is the content generated by model.
+	SceneOutput = "output"
+
+	// MsgRejected ends the stream of reply instead of "done" when the
+	// output of model is rejected.
+	MsgRejected = "rejected"
+
+	// MsgFailed ends the stream of reply instead of "done" when the output
+	// of model can't be checked, and the rest of reply is dropped.
+	MsgFailed = "failed"
+)
+
+// Moderation checks whether the content is allowed. It returns the error
+// of sensitive info if the content is rejected.
+type Moderation interface {
+	CheckText(scene, content string) error
+	CheckImages(urls []string) error
+}
@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	PromptKindWuKong = "wukong"
+	PromptKindChat   = "chat"
+
+	promptNameMaxLen    = 50
+	promptContentMaxLen = 1000
+	promptVariableMax   = 10
+)
+
+// a variable is written as {name} in the content of template.
+var promptVariableRegexp = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]{0,31})\}`)
+
+// PromptKind
+type PromptKind interface {
+	PromptKind() string
+}
+
+func NewPromptKind(v string) (PromptKind, error) {
+	if v != PromptKindWuKong && v != PromptKindChat {
+		return nil, errors.New("unknown prompt kind")
+	}
+
+	return promptKind(v), nil
+}
+
+type promptKind string
+
+func (r promptKind) PromptKind() string {
+	return string(r)
+}
+
+// PromptName
+type PromptName interface {
+	PromptName() string
+}
+
+func NewPromptName(v string) (PromptName, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return nil, errors.New("no prompt name")
+	}
+
+	v = utils.XSSFilter(v)
+
+	if utils.StrLen(v) > promptNameMaxLen {
+		return nil, fmt.Errorf(
+			"the length of prompt name should be less than %d", promptNameMaxLen,
+		)
+	}
+
+	return promptName(v), nil
+}
+
+type promptName string
+
+func (r promptName) PromptName() string {
+	return string(r)
+}
+
+// PromptContent
+type PromptContent interface {
+	PromptContent() string
+
+	// Variables returns the names of variables in the order they first appear.
+	Variables() []string
+}
+
+func NewPromptContent(v string) (PromptContent, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, errors.New("no prompt content")
+	}
+
+	if utils.StrLen(v) > promptContentMaxLen {
+		return nil, fmt.Errorf(
+			"the length of prompt content should be less than %d", promptContentMaxLen,
+		)
+	}
+
+	c := promptContent(v)
+	if len(c.Variables()) > promptVariableMax {
+		return nil, fmt.Errorf(
+			"the number of variables should be less than %d", promptVariableMax,
+		)
+	}
+
+	return c, nil
+}
+
+type promptContent string
+
+func (r promptContent) PromptContent() string {
+	return string(r)
+}
+
+func (r promptContent) Variables() []string {
+	items := promptVariableRegexp.FindAllStringSubmatch(string(r), -1)
+
+	v := make([]string, 0, len(items))
+	exists := map[string]bool{}
+
+	for _, item := range items {
+		if name := item[1]; !exists[name] {
+			exists[name] = true
+			v = append(v, name)
+		}
+	}
+
+	return v
+}
+
+// PromptVersion is a snapshot of the content of template.
+type PromptVersion struct {
+	Version   int
+	Content   PromptContent
+	CreatedAt int64
+}
+
+// PromptTemplate is a prompt with variables which can be rendered
+// before calling WuKong or the chat models.
+type PromptTemplate struct {
+	Id     string
+	Owner  types.Account
+	Name   PromptName
+	Kind   PromptKind
+	Public bool
+
+	// Versions are sorted by version, the last one is the latest.
+	Versions  []PromptVersion
+	Likes     int
+	CreatedAt int64
+	UpdatedAt int64
+
+	// Version is used to update the template concurrently.
+	Version int
+}
+
+func (t *PromptTemplate) Latest() *PromptVersion {
+	if n := len(t.Versions); n > 0 {
+		return &t.Versions[n-1]
+	}
+
+	return nil
+}
+
+// GetVersion returns the latest version if v is 0.
+func (t *PromptTemplate) GetVersion(v int) *PromptVersion {
+	if v == 0 {
+		return t.Latest()
+	}
+
+	for i := range t.Versions {
+		if t.Versions[i].Version == v {
+			return &t.Versions[i]
+		}
+	}
+
+	return nil
+}
+
+// AddVersion appends the content as a new version and keeps the latest keep ones.
+func (t *PromptTemplate) AddVersion(c PromptContent, now int64, keep int) {
+	v := 1
+	if latest := t.Latest(); latest != nil {
+		v = latest.Version + 1
+	}
+
+	t.Versions = append(t.Versions, PromptVersion{
+		Version:   v,
+		Content:   c,
+		CreatedAt: now,
+	})
+
+	if n := len(t.Versions); keep > 0 && n > keep {
+		t.Versions = t.Versions[n-keep:]
+	}
+}
+
+func (t *PromptTemplate) IsVisibleTo(user types.Account) bool {
+	return t.Public || (user != nil && t.Owner.Account() == user.Account())
+}
+
+// Render replaces the variables of the content with the values.
+func (v *PromptVersion) Render(values map[string]string) (string, error) {
+	for _, name := range v.Content.Variables() {
+		if strings.TrimSpace(values[name]) == "" {
+			return "", fmt.Errorf("missing value of variable: %s", name)
+		}
+	}
+
+	return promptVariableRegexp.ReplaceAllStringFunc(
+		v.Content.PromptContent(),
+		func(s string) string {
+			return values[s[1:len(s)-1]]
+		},
+	), nil
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+const (
+	QueueTaskStatusWaiting   = "waiting"
+	QueueTaskStatusRunning   = "running"
+	QueueTaskStatusFinished  = "finished"
+	QueueTaskStatusFailed    = "failed"
+	QueueTaskStatusCancelled = "cancelled"
+)
+
+// QueueTask is the invocation of model which waits in the queue of model
+// until an endpoint is free.
+type QueueTask struct {
+	Id    string
+	Owner types.Account
+	Model ModelName
+
+	// Input is the input of the task of chat model.
+	Input ChatInput
+
+	// Picture is the input of the task of WuKong.
+	Picture WuKongPictureMeta
+
+	// Lease identifies the run of task. It changes every time the task is
+	// claimed, so the worker which lost the task can't finish it.
+	Lease string
+
+	Status string
+	Reply  string
+
+	// Pictures are the links of pictures generated by WuKong.
+	Pictures map[string]string
+
+	// Error is the code of error if the task is failed.
+	Error string
+
+	CreatedAt  int64
+	StartedAt  int64
+	FinishedAt int64
+}
+
+func (t *QueueTask) IsWaiting() bool {
+	return t.Status == QueueTaskStatusWaiting
+}
+
+func (t *QueueTask) IsDone() bool {
+	return t.Status == QueueTaskStatusFinished ||
+		t.Status == QueueTaskStatusFailed ||
+		t.Status == QueueTaskStatusCancelled
+}
+
+type QueueTaskIndex struct {
+	Owner types.Account
+	Id    string
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type ApiUsageListOption struct {
+	// User and Model are optional.
+	User  types.Account
+	Model domain.ModelName
+
+	// Since is the first date of the usages.
+	Since string
+}
+
+type ApiUsage interface {
+	// Add adds the values of usage to the one of the same day.
+	Add(*domain.ApiUsage) error
+	FindAll(*ApiUsageListOption) ([]domain.ApiUsage, error)
+}
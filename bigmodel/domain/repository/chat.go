@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type ChatSession interface {
+	Add(*domain.ChatSession) (string, error)
+	Get(*domain.ChatSessionIndex) (domain.ChatSession, error)
+	Count(types.Account) (int, error)
+
+	// List returns the sessions without messages, the latest updated first.
+	List(types.Account) ([]domain.ChatSession, error)
+
+	// AppendMessages appends the messages and keeps the latest keep ones.
+	AppendMessages(index *domain.ChatSessionIndex, model domain.ChatModel, msgs []domain.ChatMessage, keep int) error
+	UpdateTitle(*domain.ChatSessionIndex, domain.ChatSessionTitle) error
+	Delete(*domain.ChatSessionIndex) error
+}
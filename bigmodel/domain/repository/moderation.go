@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+)
+
+type ModerationIncident interface {
+	Add(*domain.ModerationIncident) error
+}
+
+type ModerationDecision interface {
+	Add(*domain.ModerationDecision) error
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type PromptTemplate interface {
+	// Add returns duplicate error if the owner has the template of same name.
+	Add(*domain.PromptTemplate) (string, error)
+	Get(id string) (domain.PromptTemplate, error)
+
+	// List returns the templates of owner, the latest updated first.
+	List(types.Account) ([]domain.PromptTemplate, error)
+
+	// ListPublic returns the public templates of kind, the most liked first.
+	ListPublic(kind string) ([]domain.PromptTemplate, error)
+
+	// Save updates the name, visibility and versions of template
+	// and returns concurrent updating error if the version is outdated,
+	// or duplicate error if the owner has the other template of same name.
+	Save(*domain.PromptTemplate) error
+	Delete(owner types.Account, id string) error
+
+	// Like and Unlike do nothing if the user has liked or not liked the
+	// template. They return not exists error if it is not a public template.
+	Like(user types.Account, id string) error
+	Unlike(user types.Account, id string) error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type QueueTask interface {
+	Add(*domain.QueueTask) (string, error)
+	Get(*domain.QueueTaskIndex) (domain.QueueTask, error)
+
+	// Rank returns how many tasks of the same model are waiting before it.
+	Rank(*domain.QueueTask) (int, error)
+
+	// CountWaiting returns the number of waiting tasks of user.
+	CountWaiting(types.Account) (int, error)
+
+	// CountRunning returns the number of tasks of model which have been
+	// running since after staleTime.
+	CountRunning(model domain.ModelName, staleTime int64) (int, error)
+
+	// Latency returns the average seconds to run the latest n finished
+	// tasks of model, or 0 if there is no one.
+	Latency(model domain.ModelName, n int) (float64, error)
+
+	// Claim marks the earliest waiting task of model as running with a new
+	// lease and returns it. The task which has been running since before
+	// staleTime is claimed again, in case its worker is gone.
+	Claim(model domain.ModelName, staleTime int64) (domain.QueueTask, error)
+
+	// Cancel cancels the task only if it is waiting.
+	Cancel(*domain.QueueTaskIndex) error
+
+	// Finish saves the result of task only if it is still running with the
+	// same lease.
+	Finish(*domain.QueueTask) error
+}
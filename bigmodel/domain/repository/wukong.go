@@ -21,6 +21,22 @@ type WuKongTaskResp struct {
 	Links     asyncdomain.Links
 }
 
+// WuKongPublicListOption filters the public pictures which are not hidden,
+// and returns the page of them.
+type WuKongPublicListOption struct {
+	// Keyword is searched in the desc and style of pictures, and the
+	// pictures should have all the Tags. Level is optional.
+	Keyword string
+	Tags    []string
+	Level   domain.WuKongPictureLevel
+
+	// SortByDigg sorts the pictures by the digg count, otherwise by time.
+	SortByDigg bool
+
+	PageNum      int
+	CountPerPage int
+}
+
 type WuKongPicture interface {
 	GetVersion(types.Account) (int, error)
 	ListLikesByUserName(types.Account) ([]domain.WuKongPicture, int, error)
@@ -33,5 +49,13 @@ type WuKongPicture interface {
 	GetPublicByUserName(types.Account, string) (domain.WuKongPicture, error)
 	GetPublicsGlobal() ([]domain.WuKongPicture, error)
 	GetOfficialPublicsGlobal() ([]domain.WuKongPicture, error)
+
+	// ListVisiblePublicsGlobal returns the page of public pictures and the
+	// total num of them.
+	ListVisiblePublicsGlobal(*WuKongPublicListOption) ([]domain.WuKongPicture, int, error)
+
+	// CountVisibleTags returns the tags of the public pictures which are not
+	// hidden with the num of pictures, the most used first.
+	CountVisibleTags() ([]domain.WuKongTagCount, error)
 	UpdatePublicPicture(types.Account, string, int, *domain.WuKongPicture) error
 }
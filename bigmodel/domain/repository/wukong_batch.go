@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type WuKongBatchJob interface {
+	Add(*domain.WuKongBatchJob) (string, error)
+	Get(*domain.WuKongBatchIndex) (domain.WuKongBatchJob, error)
+
+	// List returns the jobs of user, the latest created first.
+	List(types.Account) ([]domain.WuKongBatchJob, error)
+
+	// CountUndone returns the number of waiting and running jobs of user.
+	CountUndone(types.Account) (int, error)
+
+	// Claim marks the earliest waiting job as running with a new lease and
+	// returns it. The job which has not made progress since staleTime is
+	// claimed again, in case its worker is gone.
+	Claim(staleTime int64) (domain.WuKongBatchJob, error)
+
+	// SaveItem saves the item of the running job and refreshes the time
+	// of progress. It returns concurrent updating error if the job is not
+	// running with the same lease anymore, such as being cancelled or
+	// claimed by another worker.
+	SaveItem(job *domain.WuKongBatchJob, index int) error
+
+	// Cancel cancels the job only if it is waiting or running.
+	Cancel(*domain.WuKongBatchIndex) error
+
+	// Finish saves the status of job only if it is still running with the
+	// same lease.
+	Finish(*domain.WuKongBatchJob) error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type WuKongComment interface {
+	Add(*domain.WuKongComment) (string, error)
+
+	// List returns the comments of picture, the latest first.
+	List(pictureId string) ([]domain.WuKongComment, error)
+	Delete(user types.Account, id string) error
+}
+
+type WuKongReport interface {
+	// Add fails with the error of duplicate creating if the user has
+	// reported the picture which is not reviewed.
+	Add(*domain.WuKongReport) (string, error)
+	Get(id string) (domain.WuKongReport, error)
+
+	// List returns the reports of status, all the reports if it is empty.
+	List(status string) ([]domain.WuKongReport, error)
+
+	// CountPending returns the number of pending reports of picture.
+	CountPending(pictureId string) (int, error)
+
+	// Review updates the status of the pending report.
+	Review(*domain.WuKongReport) error
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+const (
+	WuKongBatchStatusWaiting   = "waiting"
+	WuKongBatchStatusRunning   = "running"
+	WuKongBatchStatusFinished  = "finished"
+	WuKongBatchStatusCancelled = "cancelled"
+
+	WuKongBatchItemStatusWaiting  = "waiting"
+	WuKongBatchItemStatusFinished = "finished"
+	WuKongBatchItemStatusFailed   = "failed"
+)
+
+// WuKongBatchItem is a prompt of batch job and the pictures generated by it.
+type WuKongBatchItem struct {
+	WuKongPictureMeta
+
+	Status string
+
+	// Pictures are the paths of pictures on OBS.
+	Pictures []string
+
+	// Error is the code of error if the item is failed.
+	Error string
+}
+
+func (item *WuKongBatchItem) IsDone() bool {
+	return item.Status == WuKongBatchItemStatusFinished ||
+		item.Status == WuKongBatchItemStatusFailed
+}
+
+// WuKongBatchJob generates the pictures for each prompt one by one.
+// It is run by the workers and can be resumed by other workers from
+// the first unfinished item if its worker is gone.
+type WuKongBatchJob struct {
+	Id     string
+	Owner  types.Account
+	Name   string
+	Status string
+
+	// ImageNum is the num of pictures generated for each prompt.
+	ImageNum int
+	Items    []WuKongBatchItem
+
+	// Lease identifies the run of job. It changes every time the job is
+	// claimed, so the worker which lost the job can't save it anymore.
+	Lease string
+
+	CreatedAt  int64
+	StartedAt  int64
+	FinishedAt int64
+}
+
+type WuKongBatchIndex struct {
+	Owner types.Account
+	Id    string
+}
+
+func (job *WuKongBatchJob) IsDone() bool {
+	return job.Status == WuKongBatchStatusFinished ||
+		job.Status == WuKongBatchStatusCancelled
+}
+
+// Progress returns the num of items done and the failed ones of them.
+func (job *WuKongBatchJob) Progress() (done, failed int) {
+	for i := range job.Items {
+		item := &job.Items[i]
+
+		if item.IsDone() {
+			done++
+		}
+
+		if item.Status == WuKongBatchItemStatusFailed {
+			failed++
+		}
+	}
+
+	return
+}
+
+// NextItem returns the index of the first item which is not done,
+// or -1 if all the items are done.
+func (job *WuKongBatchJob) NextItem() int {
+	for i := range job.Items {
+		if !job.Items[i].IsDone() {
+			return i
+		}
+	}
+
+	return -1
+}
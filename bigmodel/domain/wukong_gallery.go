@@ -0,0 +1,47 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+const (
+	WuKongReportStatusPending   = "pending"
+	WuKongReportStatusConfirmed = "confirmed"
+	WuKongReportStatusDismissed = "dismissed"
+)
+
+// WuKongPictureIndex identifies the public picture.
+type WuKongPictureIndex struct {
+	Owner types.Account
+	Id    string
+}
+
+type WuKongComment struct {
+	Id        string
+	Picture   WuKongPictureIndex
+	User      types.Account
+	Content   WuKongCommentContent
+	CreatedAt int64
+}
+
+// WuKongTagCount is the num of public pictures which have the tag.
+type WuKongTagCount struct {
+	Tag   string
+	Count int
+}
+
+// WuKongReport is the report of abuse of the public picture.
+type WuKongReport struct {
+	Id         string
+	Picture    WuKongPictureIndex
+	Reporter   types.Account
+	Reason     string
+	Status     string
+	Reviewer   string
+	CreatedAt  int64
+	ReviewedAt int64
+}
+
+func (r *WuKongReport) IsPending() bool {
+	return r.Status == WuKongReportStatusPending
+}
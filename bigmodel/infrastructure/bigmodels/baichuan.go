@@ -7,10 +7,11 @@ import (
 
 	libutils "github.com/opensourceways/community-robot-lib/utils"
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 )
 
 type baichuanInfo struct {
-	endpoints chan string
+	endpoints *endpointPool
 }
 
 type baichuanRequest struct {
@@ -39,18 +40,14 @@ func newBaiChuanInfo(cfg *Config) (info baichuanInfo, err error) {
 	ce := &cfg.Endpoints
 	es, _ := ce.parse(ce.BaiChuan)
 
-	// init endpoints
-	info.endpoints = make(chan string, len(es))
-	for _, e := range es {
-		info.endpoints <- e
-	}
+	info.endpoints = newEndpointPool("baichuan", es, &cfg.EndpointPool)
 
 	return
 }
 
 func (s *service) BaiChuan(input *domain.BaiChuanInput) (code, r string, err error) {
 	// input check
-	if err = s.check.check(input.Text.BaiChuanText()); err != nil {
+	if err = s.check.CheckText(moderation.SceneInput, input.Text.BaiChuanText()); err != nil {
 		code = CodeInputTextAuditError
 
 		return
@@ -76,7 +73,7 @@ func (s *service) BaiChuan(input *domain.BaiChuanInput) (code, r string, err err
 		return
 	}
 
-	if err = s.check.check(resp.getText()); err != nil {
+	if err = s.check.CheckText(moderation.SceneOutput, resp.getText()); err != nil {
 		code = CodeOutputTextAuditError
 
 		return
@@ -109,7 +106,7 @@ func (s *service) genBaiChuan(
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Auth-Token", t)
 
-	if _, err = s.hc.ForwardTo(req, &resp); err != nil {
+	if err = s.forwardTo(req, &resp); err != nil {
 		return
 	}
 
@@ -0,0 +1,173 @@
+package bigmodels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+)
+
+func (s *service) Chat(ch chan string, input *domain.ChatInput) (err error) {
+	if m, ok := s.registry.get(input.Model.ChatModel()); ok {
+		return s.chatByRegistry(ch, m, input)
+	}
+
+	text, rounds, err := input.Prompt()
+	if err != nil {
+		return
+	}
+
+	history := make([]domain.History, len(rounds))
+	for i := range rounds {
+		if history[i], err = domain.NewHistory(rounds[i][0], rounds[i][1]); err != nil {
+			return
+		}
+	}
+
+	sampling := &input.ChatSampling
+
+	switch domain.BigmodelType(input.Model.ChatModel()) {
+	case domain.BigmodelGLM2:
+		v := domain.GLM2Input{
+			Sampling:          sampling.Sampling,
+			History:           history,
+			TopK:              sampling.TopK,
+			TopP:              sampling.TopP,
+			Temperature:       sampling.Temperature,
+			RepetitionPenalty: sampling.RepetitionPenalty,
+		}
+
+		if v.Text, err = domain.NewGLM2Text(text); err != nil {
+			return
+		}
+
+		return s.GLM2(ch, &v)
+
+	case domain.BigmodelLLAMA2:
+		v := domain.LLAMA2Input{
+			Sampling:          sampling.Sampling,
+			History:           history,
+			TopK:              sampling.TopK,
+			TopP:              sampling.TopP,
+			Temperature:       sampling.Temperature,
+			RepetitionPenalty: sampling.RepetitionPenalty,
+		}
+
+		if v.Text, err = domain.NewLLAMA2Text(text); err != nil {
+			return
+		}
+
+		return s.LLAMA2(ch, &v)
+
+	case domain.BigmodelSkyWork:
+		v := domain.SkyWorkInput{
+			Sampling:          sampling.Sampling,
+			History:           history,
+			TopK:              sampling.TopK,
+			TopP:              sampling.TopP,
+			Temperature:       sampling.Temperature,
+			RepetitionPenalty: sampling.RepetitionPenalty,
+		}
+
+		if v.Text, err = domain.NewSkyWorkText(text); err != nil {
+			return
+		}
+
+		return s.SkyWork(ch, &v)
+
+	case domain.BigmodelIFlytekSpark:
+		v := domain.IFlytekSparkInput{
+			Sampling:          sampling.Sampling,
+			History:           history,
+			TopK:              sampling.TopK,
+			TopP:              sampling.TopP,
+			Temperature:       sampling.Temperature,
+			RepetitionPenalty: sampling.RepetitionPenalty,
+		}
+
+		if v.Text, err = domain.NewIFlytekSparkText(text); err != nil {
+			return
+		}
+
+		return s.IFlytekSpark(ch, &v)
+
+	case domain.BigmodelBaiChuan:
+		return s.chatByBaiChuan(ch, text, rounds, sampling)
+	}
+
+	return errors.New("unsupported chat model")
+}
+
+// chatByBaiChuan sends the whole reply at once, because baichuan doesn't
+// stream. The history is folded into the text, because baichuan doesn't
+// accept it either.
+func (s *service) chatByBaiChuan(
+	ch chan string, text string, rounds [][2]string, sampling *domain.ChatSampling,
+) (err error) {
+	v := domain.BaiChuanInput{
+		Sampling:          sampling.Sampling,
+		TopK:              sampling.TopK,
+		TopP:              sampling.TopP,
+		Temperature:       sampling.Temperature,
+		RepetitionPenalty: sampling.RepetitionPenalty,
+	}
+
+	if v.Text, err = baiChuanText(text, rounds); err != nil {
+		return
+	}
+
+	_, reply, err := s.BaiChuan(&v)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		defer close(ch)
+
+		ch <- reply
+		ch <- "done"
+	}()
+
+	return
+}
+
+// baiChuanText folds the rounds of history into the text as a transcript.
+// The earliest rounds are dropped until the text is short enough.
+func baiChuanText(text string, rounds [][2]string) (domain.BaiChuanText, error) {
+	for i := range rounds {
+		b := strings.Builder{}
+
+		for _, r := range rounds[i:] {
+			fmt.Fprintf(&b, "User: %s\nAssistant: %s\n", r[0], r[1])
+		}
+
+		fmt.Fprintf(&b, "User: %s\nAssistant:", text)
+
+		if v, err := domain.NewBaiChuanText(b.String()); err == nil {
+			return v, nil
+		}
+	}
+
+	return domain.NewBaiChuanText(text)
+}
+
+// withDone returns the context which is canceled once done is closed, so
+// that the call whose reply is not waited for any more stops, and the
+// endpoint is released at once. cancel must be called when the call is over.
+func withDone(done <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if done != nil {
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return ctx, cancel
+}
@@ -2,17 +2,28 @@ package bigmodels
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/opensourceways/xihe-server/bigmodel/infrastructure/moderationimpl"
 )
 
 type Config struct {
-	OBS        OBSConfig   `json:"obs"             required:"true"`
-	Cloud      CloudConfig `json:"cloud"           required:"true"`
-	WuKong     WuKong      `json:"wukong"          required:"true"`
-	Endpoints  Endpoints   `json:"endpoints"       required:"true"`
-	Moderation Moderation  `json:"moderation"      required:"true"`
-	CloudGY    CloudConfig `json:"auth_gy"         required:"true"`
+	OBS        OBSConfig             `json:"obs"             required:"true"`
+	Cloud      CloudConfig           `json:"cloud"           required:"true"`
+	WuKong     WuKong                `json:"wukong"          required:"true"`
+	Endpoints  Endpoints             `json:"endpoints"       required:"true"`
+	Moderation moderationimpl.Config `json:"moderation"`
+	CloudGY    CloudConfig           `json:"auth_gy"         required:"true"`
+
+	// Models are the models served by the generic invocation path.
+	Models []ModelConfig `json:"models"`
+
+	EndpointPool EndpointPool `json:"endpoint_pool"`
+
+	OutputModeration OutputModeration `json:"output_moderation"`
 
 	MaxPictureSizeToDescribe int64 `json:"max_picture_size_to_describe"`
 	MaxPictureSizeToVQA      int64 `json:"max_picture_size_to_vqa"`
@@ -20,6 +31,13 @@ type Config struct {
 
 func (cfg *Config) SetDefault() {
 	cfg.WuKong.setDefault()
+	cfg.EndpointPool.setDefault()
+	cfg.Moderation.SetDefault()
+	cfg.OutputModeration.setDefault()
+
+	for i := range cfg.Models {
+		cfg.Models[i].setDefault()
+	}
 
 	if cfg.MaxPictureSizeToDescribe <= 0 {
 		cfg.MaxPictureSizeToDescribe = 2 << 21
@@ -35,6 +53,25 @@ func (cfg *Config) Validate() error {
 		return err
 	}
 
+	if err := cfg.Moderation.Validate(); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for i := range cfg.Models {
+		item := &cfg.Models[i]
+
+		if err := item.validate(); err != nil {
+			return err
+		}
+
+		if names[item.Name] {
+			return fmt.Errorf("duplicate model: %s", item.Name)
+		}
+
+		names[item.Name] = true
+	}
+
 	return cfg.Endpoints.validate()
 }
 
@@ -163,12 +200,115 @@ func (e *Endpoints) parse(s string) ([]string, error) {
 	return v, nil
 }
 
-type Moderation struct {
-	Endpoint    string `json:"endpoint"       required:"true"`
-	AccessKey   string `json:"access_key"     required:"true"`
-	SecretKey   string `json:"secret_key"     required:"true"`
-	IAMEndpoint string `json:"iam_endpoint"   required:"true"`
-	Region      string `json:"region"         required:"true"`
+// EndpointPool is the config of the pools of endpoints of models.
+type EndpointPool struct {
+	// HealthPath is the path to probe the health of endpoint. The endpoint
+	// itself is probed if it is empty.
+	HealthPath string `json:"health_path"`
+
+	// ProbeInterval specifies the interval to probe the endpoints.
+	// The probing is disabled if it is negative. The unit is second.
+	ProbeInterval int `json:"probe_interval"`
+
+	// ProbeTimeout specifies the timeout of probing. The unit is second.
+	ProbeTimeout int `json:"probe_timeout"`
+
+	// FailureThreshold specifies how many consecutive failures the
+	// endpoint is ejected after.
+	FailureThreshold int `json:"failure_threshold"`
+
+	// EjectDuration specifies how long the endpoint is ejected. It is
+	// tried by the calls again after that, and ejected again at once if
+	// the first call fails. The unit is second.
+	EjectDuration int `json:"eject_duration"`
+
+	// MaxLeaseDuration specifies the time after which the endpoint is
+	// reclaimed if it is not returned. The unit is second.
+	MaxLeaseDuration int `json:"max_lease_duration"`
+
+	// MaxConcurrency is the default max concurrent calls of an endpoint.
+	MaxConcurrency int `json:"max_concurrency"`
+
+	// Endpoints are the options of specific endpoints, the key is url.
+	Endpoints map[string]EndpointOption `json:"endpoints"`
+}
+
+type EndpointOption struct {
+	Weight         int    `json:"weight"`
+	MaxConcurrency int    `json:"max_concurrency"`
+	HealthURL      string `json:"health_url"`
+}
+
+func (cfg *EndpointPool) setDefault() {
+	if cfg.ProbeInterval == 0 {
+		cfg.ProbeInterval = 30
+	}
+
+	if cfg.ProbeTimeout <= 0 {
+		cfg.ProbeTimeout = 5
+	}
+
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+
+	if cfg.EjectDuration <= 0 {
+		cfg.EjectDuration = 60
+	}
+
+	if cfg.MaxLeaseDuration <= 0 {
+		cfg.MaxLeaseDuration = 600
+	}
+
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = 1
+	}
+}
+
+func (cfg *EndpointPool) option(endpoint string) EndpointOption {
+	opt := cfg.Endpoints[endpoint]
+
+	if opt.Weight <= 0 {
+		opt.Weight = 1
+	}
+
+	if opt.MaxConcurrency <= 0 {
+		opt.MaxConcurrency = cfg.MaxConcurrency
+	}
+
+	if opt.HealthURL == "" {
+		opt.HealthURL = endpoint
+
+		if u, err := url.Parse(endpoint); err == nil && cfg.HealthPath != "" {
+			u.Path = cfg.HealthPath
+			u.RawQuery = ""
+			opt.HealthURL = u.String()
+		}
+	}
+
+	return opt
+}
+
+// MaintainInterval returns the interval to maintain the pools, which is
+// the one of probing if it is enabled.
+func (cfg *EndpointPool) MaintainInterval() time.Duration {
+	if cfg.ProbeInterval > 0 {
+		return time.Duration(cfg.ProbeInterval) * time.Second
+	}
+
+	return cfg.maxLeaseDuration()
+}
+
+func (cfg *EndpointPool) probeTimeout() time.Duration {
+	return time.Duration(cfg.ProbeTimeout) * time.Second
+}
+
+func (cfg *EndpointPool) ejectDuration() time.Duration {
+	return time.Duration(cfg.EjectDuration) * time.Second
+}
+
+func (cfg *EndpointPool) maxLeaseDuration() time.Duration {
+	return time.Duration(cfg.MaxLeaseDuration) * time.Second
 }
 
 type WuKong struct {
@@ -181,6 +321,9 @@ type WuKong struct {
 	// DownloadExpiry specifies the timeout to download a obs file.
 	// The unit is second.
 	DownloadExpiry int `json:"download_expiry"`
+
+	// BatchDir is the directory to save the pictures of batch jobs.
+	BatchDir string `json:"batch_dir"`
 }
 
 type WuKongSample struct {
@@ -193,6 +336,10 @@ func (cfg *WuKong) setDefault() {
 	if cfg.DownloadExpiry <= 0 {
 		cfg.DownloadExpiry = 3600
 	}
+
+	if cfg.BatchDir == "" {
+		cfg.BatchDir = "wukong-batch"
+	}
 }
 
 func (cfg *WuKong) validate() error {
@@ -214,3 +361,130 @@ func (cfg *WuKong) validate() error {
 type ApiService struct {
 	TokenExpire string
 }
+
+// ModelConfig declares a model which is served by the protocol family.
+type ModelConfig struct {
+	Name       string          `json:"name"        required:"true"`
+	Protocol   string          `json:"protocol"    required:"true"`
+	Endpoints  string          `json:"endpoints"   required:"true"`
+	Auth       ModelAuth       `json:"auth"`
+	Defaults   ModelDefaults   `json:"defaults"`
+	Moderation ModelModeration `json:"moderation"`
+	Display    ModelDisplay    `json:"display"`
+}
+
+func (cfg *ModelConfig) setDefault() {
+	cfg.Defaults.setDefault()
+
+	if cfg.Display.Title == "" {
+		cfg.Display.Title = cfg.Name
+	}
+}
+
+func (cfg *ModelConfig) validate() error {
+	if cfg.Name == "" {
+		return errors.New("missing model name")
+	}
+
+	if cfg.Protocol != protocolXihe && cfg.Protocol != protocolOpenAI {
+		return fmt.Errorf("unsupported protocol of model %s", cfg.Name)
+	}
+
+	if _, err := (&Endpoints{}).parse(cfg.Endpoints); err != nil {
+		return fmt.Errorf("invalid endpoints of model %s", cfg.Name)
+	}
+
+	return cfg.Auth.validate()
+}
+
+type ModelAuth struct {
+	// Type is one of none, cloud and bearer. The token of cloud is
+	// generated by the account of cloud config.
+	Type  string `json:"type"`
+	Token string `json:"token"`
+}
+
+func (cfg *ModelAuth) validate() error {
+	switch cfg.Type {
+	case "", authNone, authCloud:
+		return nil
+
+	case authBearer:
+		if cfg.Token == "" {
+			return errors.New("missing token of bearer auth")
+		}
+
+		return nil
+	}
+
+	return errors.New("unsupported auth type")
+}
+
+type ModelDefaults struct {
+	// Model is the name of model on the remote side, which is needed by
+	// the protocol of openai.
+	Model             string  `json:"model"`
+	TopK              int     `json:"top_k"`
+	TopP              float64 `json:"top_p"`
+	Temperature       float64 `json:"temperature"`
+	RepetitionPenalty float64 `json:"repetition_penalty"`
+}
+
+func (cfg *ModelDefaults) setDefault() {
+	if cfg.TopK <= 0 {
+		cfg.TopK = 5
+	}
+
+	if cfg.TopP <= 0 {
+		cfg.TopP = 0.85
+	}
+
+	if cfg.Temperature <= 0 {
+		cfg.Temperature = 0.3
+	}
+
+	if cfg.RepetitionPenalty <= 0 {
+		cfg.RepetitionPenalty = 1.05
+	}
+}
+
+type ModelModeration struct {
+	SkipInput  bool `json:"skip_input"`
+	SkipOutput bool `json:"skip_output"`
+}
+
+// OutputModeration is the config of checking the streamed reply. The reply
+// is checked by windows, and a window ends at the delimiter of sentence.
+type OutputModeration struct {
+	// MinWindowLen is the min runes of a window, so that it will not check
+	// too many short sentences.
+	MinWindowLen int `json:"min_window_len"`
+
+	// MaxWindowLen is the max runes of a window, the window ends even if
+	// there is no delimiter.
+	MaxWindowLen int `json:"max_window_len"`
+
+	// Delimiters are the chars which end a sentence.
+	Delimiters string `json:"delimiters"`
+}
+
+func (cfg *OutputModeration) setDefault() {
+	if cfg.MinWindowLen <= 0 {
+		cfg.MinWindowLen = 20
+	}
+
+	if cfg.MaxWindowLen <= 0 {
+		cfg.MaxWindowLen = 200
+	}
+
+	if cfg.Delimiters == "" {
+		cfg.Delimiters = "。！？；.!?;\n"
+	}
+}
+
+type ModelDisplay struct {
+	Title string   `json:"title"`
+	Desc  string   `json:"desc"`
+	Icon  string   `json:"icon"`
+	Tags  []string `json:"tags"`
+}
@@ -11,11 +11,10 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 )
 
 const (
-	skipStepGLM = 5
-
 	doneStatusGLM      = "DONE"
 	replaceResponseGLM = "data: "
 )
@@ -38,32 +37,28 @@ type glm2Response struct {
 }
 
 type glm2Info struct {
-	endpoints chan string
+	endpoints *endpointPool
 }
 
 func newGLM2Info(cfg *Config) (info glm2Info, err error) {
 	ce := &cfg.Endpoints
 	es, _ := ce.parse(ce.GLM2)
 
-	// init endpoints
-	info.endpoints = make(chan string, len(es))
-	for _, e := range es {
-		info.endpoints <- e
-	}
+	info.endpoints = newEndpointPool("glm2", es, &cfg.EndpointPool)
 
 	return
 }
 
 func (s *service) GLM2(ch chan string, input *domain.GLM2Input) (err error) {
 	// input audit
-	if err = s.check.check(input.Text.GLM2Text()); err != nil {
+	if err = s.check.CheckText(moderation.SceneInput, input.Text.GLM2Text()); err != nil {
 		logrus.Debugf("content audit not pass: %s", err.Error())
 		return
 	}
 
 	// call bigmodel glm2
-	f := func(ec chan string, e string) (err error) {
-		err = s.genGLM2(ec, ch, e, input)
+	f := func(l *endpointLease) (err error) {
+		err = s.genGLM2(l, ch, input)
 
 		return
 	}
@@ -75,7 +70,7 @@ func (s *service) GLM2(ch chan string, input *domain.GLM2Input) (err error) {
 	return
 }
 
-func (s *service) genGLM2(ec, ch chan string, endpoint string, input *domain.GLM2Input) (
+func (s *service) genGLM2(l *endpointLease, ch chan string, input *domain.GLM2Input) (
 	err error,
 ) {
 	t, err := genToken(&s.wukongInfo.cfg.CloudConfig)
@@ -90,7 +85,7 @@ func (s *service) genGLM2(ec, ch chan string, endpoint string, input *domain.GLM
 	}
 
 	req, err := http.NewRequest(
-		http.MethodPost, endpoint, bytes.NewBuffer(body),
+		http.MethodPost, l.endpoint(), bytes.NewBuffer(body),
 	)
 	if err != nil {
 		return
@@ -101,7 +96,7 @@ func (s *service) genGLM2(ec, ch chan string, endpoint string, input *domain.GLM
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Accept", "*/*")
 
-	resp, err := s.hc.Client.Do(req)
+	resp, err := s.doRequest(req)
 	if err != nil {
 		return
 	}
@@ -114,13 +109,15 @@ func (s *service) genGLM2(ec, ch chan string, endpoint string, input *domain.GLM
 	)
 	go func() {
 		defer close(ch)
-		defer func() { ec <- endpoint }()
+		defer l.release(nil)
 		defer resp.Body.Close()
 
+		out := s.newOutputStream(ch, false)
+
 		for {
 			line, err := reader.ReadString('\n')
 			if count != 1 && err != nil {
-				ch <- "done"
+				out.done()
 				return
 			}
 
@@ -132,22 +129,16 @@ func (s *service) genGLM2(ec, ch chan string, endpoint string, input *domain.GLM
 			}
 
 			if r.StreamStatus == doneStatusGLM {
-				ch <- "done"
+				out.done()
 				return
 			}
 
-			if r.Reply != "" && count > skipStepGLM {
-				count = 0
+			if !out.send(r.Reply) {
+				logrus.Debug("content audit not pass")
 
-				if err = s.check.check(r.Reply); err != nil {
-					logrus.Debugf("content audit not pass: %s", err.Error())
-					ch <- "done"
-
-					return
-				}
+				return
 			}
 
-			ch <- r.Reply
 			count += 1
 		}
 	}()
@@ -12,11 +12,10 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 )
 
 const (
-	skipStepFly = 5
-
 	doneStatusFly = "DONE"
 	lenThreshold  = 500
 )
@@ -39,8 +38,8 @@ type iflyteksparkResponse struct {
 type iflyteksparkInfo struct {
 	auth CloudConfig
 
-	endpoints     chan string
-	endpointsLong chan string
+	endpoints     *endpointPool
+	endpointsLong *endpointPool
 }
 
 func newiflyteksparkInfo(cfg *Config) (info iflyteksparkInfo, err error) {
@@ -56,29 +55,21 @@ func newiflyteksparkInfo(cfg *Config) (info iflyteksparkInfo, err error) {
 
 	info.auth = cfg.CloudGY
 
-	// init endpoints
-	info.endpoints = make(chan string, len(es))
-	for _, e := range es {
-		info.endpoints <- e
-	}
-
-	info.endpointsLong = make(chan string, len(esLong))
-	for _, e := range esLong {
-		info.endpointsLong <- e
-	}
+	info.endpoints = newEndpointPool("iflytekspark", es, &cfg.EndpointPool)
+	info.endpointsLong = newEndpointPool("iflytekspark_long", esLong, &cfg.EndpointPool)
 
 	return
 }
 
 func (s *service) IFlytekSpark(ch chan string, input *domain.IFlytekSparkInput) (err error) {
 	// input audit
-	if err = s.check.check(input.Text.IFlytekSparkText()); err != nil {
+	if err = s.check.CheckText(moderation.SceneInput, input.Text.IFlytekSparkText()); err != nil {
 		return
 	}
 
 	// call bigmodel iflytekspark
-	f := func(ec chan string, e string) (err error) {
-		err = s.geniflytekspark(ec, ch, e, input)
+	f := func(l *endpointLease) (err error) {
+		err = s.geniflytekspark(l, ch, input)
 
 		return
 	}
@@ -93,7 +84,7 @@ func (s *service) IFlytekSpark(ch chan string, input *domain.IFlytekSparkInput)
 	return
 }
 
-func (s *service) geniflytekspark(ec, ch chan string, endpoint string, input *domain.IFlytekSparkInput) (
+func (s *service) geniflytekspark(l *endpointLease, ch chan string, input *domain.IFlytekSparkInput) (
 	err error,
 ) {
 	t, err := genToken(&s.iflyteksparkInfo.auth)
@@ -108,7 +99,7 @@ func (s *service) geniflytekspark(ec, ch chan string, endpoint string, input *do
 	}
 
 	req, err := http.NewRequest(
-		http.MethodPost, endpoint, bytes.NewBuffer(body),
+		http.MethodPost, l.endpoint(), bytes.NewBuffer(body),
 	)
 	if err != nil {
 		return
@@ -119,7 +110,7 @@ func (s *service) geniflytekspark(ec, ch chan string, endpoint string, input *do
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Accept", "*/*")
 
-	resp, err := s.hc.Client.Do(req)
+	resp, err := s.doRequest(req)
 	if err != nil {
 		return
 	}
@@ -133,14 +124,16 @@ func (s *service) geniflytekspark(ec, ch chan string, endpoint string, input *do
 
 	go func() {
 		defer close(ch)
-		defer func() { ec <- endpoint }()
+		defer l.release(nil)
 		defer resp.Body.Close()
 
+		out := s.newOutputStream(ch, false)
+
 		for {
 			line, err := reader.ReadString('\n')
 
 			if count != 1 && err != nil {
-				ch <- "done"
+				out.done()
 
 				return
 			}
@@ -153,23 +146,17 @@ func (s *service) geniflytekspark(ec, ch chan string, endpoint string, input *do
 			}
 
 			if r.StreamStatus == doneStatusFly {
-				ch <- "done"
+				out.done()
 
 				return
 			}
 
-			if r.Reply != "" && count > skipStepFly {
-				count = 0
-
-				if err = s.check.check(r.Reply); err != nil {
-					logrus.Debugf("content audit not pass: %s", err.Error())
-					ch <- "done"
+			if !out.send(r.Reply) {
+				logrus.Debug("content audit not pass")
 
-					return
-				}
+				return
 			}
 
-			ch <- r.Reply
 			count += 1
 		}
 	}()
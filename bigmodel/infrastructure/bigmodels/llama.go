@@ -11,11 +11,10 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 )
 
 const (
-	skipStepLlama = 10
-
 	doneStatusLlama      = "DONE"
 	replaceResponseLlama = "data: "
 )
@@ -38,32 +37,28 @@ type llama2Response struct {
 }
 
 type llama2Info struct {
-	endpoints chan string
+	endpoints *endpointPool
 }
 
 func newLLAMA2Info(cfg *Config) (info llama2Info, err error) {
 	ce := &cfg.Endpoints
 	es, _ := ce.parse(ce.LLAMA2)
 
-	// init endpoints
-	info.endpoints = make(chan string, len(es))
-	for _, e := range es {
-		info.endpoints <- e
-	}
+	info.endpoints = newEndpointPool("llama2", es, &cfg.EndpointPool)
 
 	return
 }
 
 func (s *service) LLAMA2(ch chan string, input *domain.LLAMA2Input) (err error) {
 	// input audit
-	if err = s.check.check(input.Text.LLAMA2Text()); err != nil {
+	if err = s.check.CheckText(moderation.SceneInput, input.Text.LLAMA2Text()); err != nil {
 		logrus.Debugf("content audit not pass: %s", err.Error())
 		return
 	}
 
 	// call bigmodel llama2
-	f := func(ec chan string, e string) (err error) {
-		err = s.genllama2(ec, ch, e, input)
+	f := func(l *endpointLease) (err error) {
+		err = s.genllama2(l, ch, input)
 
 		return
 	}
@@ -75,7 +70,7 @@ func (s *service) LLAMA2(ch chan string, input *domain.LLAMA2Input) (err error)
 	return
 }
 
-func (s *service) genllama2(ec, ch chan string, endpoint string, input *domain.LLAMA2Input) (
+func (s *service) genllama2(l *endpointLease, ch chan string, input *domain.LLAMA2Input) (
 	err error,
 ) {
 	t, err := genToken(&s.wukongInfo.cfg.CloudConfig)
@@ -90,7 +85,7 @@ func (s *service) genllama2(ec, ch chan string, endpoint string, input *domain.L
 	}
 
 	req, err := http.NewRequest(
-		http.MethodPost, endpoint, bytes.NewBuffer(body),
+		http.MethodPost, l.endpoint(), bytes.NewBuffer(body),
 	)
 	if err != nil {
 		return
@@ -101,26 +96,26 @@ func (s *service) genllama2(ec, ch chan string, endpoint string, input *domain.L
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Accept", "*/*")
 
-	resp, err := s.hc.Client.Do(req)
+	resp, err := s.doRequest(req)
 	if err != nil {
 		return
 	}
 
 	reader := bufio.NewReader(resp.Body)
 
-	var (
-		r     llama2Response
-		count int
-	)
+	var r llama2Response
+
 	go func() {
-		defer func() { ec <- endpoint }()
+		defer l.release(nil)
 		defer resp.Body.Close()
 		defer close(ch)
 
+		out := s.newOutputStream(ch, false)
+
 		for {
 			line, err := reader.ReadString('\n')
 			if err != nil {
-				ch <- "done"
+				out.done()
 
 				logrus.Debugf("llama read end or error: %s", err)
 
@@ -135,25 +130,16 @@ func (s *service) genllama2(ec, ch chan string, endpoint string, input *domain.L
 			}
 
 			if r.StreamStatus == doneStatusLlama {
-				ch <- "done"
+				out.done()
 
 				return
 			}
 
-			if r.Reply != "" && count > skipStepLlama {
-				count = 0
+			if !out.send(r.Reply) {
+				logrus.Debug("content audit not pass")
 
-				if err = s.check.check(r.Reply); err != nil {
-					logrus.Debugf("content audit not pass: %s", err.Error())
-
-					ch <- "done"
-
-					return
-				}
+				return
 			}
-
-			ch <- r.Reply
-			count += 1
 		}
 
 	}()
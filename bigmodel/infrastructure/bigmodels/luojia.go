@@ -12,8 +12,8 @@ import (
 
 type luojiaInfo struct {
 	bucket     string
-	endpoints  chan string
-	endpointHF chan string
+	endpoints  *endpointPool
+	endpointHF *endpointPool
 }
 
 func newLuoJiaInfo(cfg *Config) luojiaInfo {
@@ -23,16 +23,8 @@ func newLuoJiaInfo(cfg *Config) luojiaInfo {
 	eshf, _ := ce.parse(ce.LuoJiaHF)
 
 	v := luojiaInfo{
-		endpoints:  make(chan string, len(es)),
-		endpointHF: make(chan string, len(eshf)),
-	}
-
-	for _, e := range es {
-		v.endpoints <- e
-	}
-
-	for _, e := range eshf {
-		v.endpointHF <- e
+		endpoints:  newEndpointPool("luojia", es, &cfg.EndpointPool),
+		endpointHF: newEndpointPool("luojia_hf", eshf, &cfg.EndpointPool),
 	}
 
 	v.bucket = cfg.OBS.LuoJiaBucket
@@ -81,7 +73,7 @@ func (s *service) sendReqToLuojia(endpoint, userName string) (answer string, err
 		Status int    `json:"status"`
 	}
 
-	if _, err = s.hc.ForwardTo(req, &r); err != nil {
+	if err = s.forwardTo(req, &r); err != nil {
 		return
 	}
 
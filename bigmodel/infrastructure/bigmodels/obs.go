@@ -47,6 +47,19 @@ func (s *obsService) genFileDownloadURL(bucket, p string, downloadExpiry int) (s
 	return output.SignedUrl, nil
 }
 
+func (s *obsService) getObject(bucket, path string) (io.ReadCloser, error) {
+	input := &obs.GetObjectInput{}
+	input.Bucket = bucket
+	input.Key = path
+
+	output, err := s.cli.GetObject(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Body, nil
+}
+
 func (s *obsService) copyObject(bucket, dst, src string) error {
 	input := &obs.CopyObjectInput{}
 	input.Bucket = bucket
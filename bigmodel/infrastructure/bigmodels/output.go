@@ -0,0 +1,101 @@
+package bigmodels
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+)
+
+// outputStream buffers the streamed chunks of reply into sentence windows,
+// and sends them to the client only after the window passes the check.
+// The stream is ended with moderation.MsgRejected if any window fails, or
+// with moderation.MsgFailed if it can't be checked.
+type outputStream struct {
+	ch    chan string
+	check moderation.Moderation
+	cfg   *OutputModeration
+
+	// skip sends the chunks at once without checking.
+	skip    bool
+	stopped bool
+
+	chunks []string
+	window strings.Builder
+}
+
+func (s *service) newOutputStream(ch chan string, skip bool) *outputStream {
+	return &outputStream{
+		ch:    ch,
+		check: s.check,
+		cfg:   &s.outputCfg,
+		skip:  skip,
+	}
+}
+
+// send returns false if the stream is stopped.
+func (o *outputStream) send(chunk string) bool {
+	if o.stopped {
+		return false
+	}
+
+	if o.skip {
+		o.ch <- chunk
+
+		return true
+	}
+
+	o.chunks = append(o.chunks, chunk)
+	o.window.WriteString(chunk)
+
+	n := utf8.RuneCountInString(o.window.String())
+	if n >= o.cfg.MaxWindowLen ||
+		(n >= o.cfg.MinWindowLen && strings.ContainsAny(chunk, o.cfg.Delimiters)) {
+		return o.flush()
+	}
+
+	return true
+}
+
+// done checks the rest of reply and ends the stream.
+func (o *outputStream) done() {
+	if o.stopped {
+		return
+	}
+
+	if o.flush() {
+		o.ch <- "done"
+	}
+}
+
+func (o *outputStream) flush() bool {
+	if len(o.chunks) == 0 {
+		return true
+	}
+
+	if err := o.check.CheckText(moderation.SceneOutput, o.window.String()); err != nil {
+		o.stopped = true
+
+		if bigmodel.IsErrorSensitiveInfo(err) {
+			o.ch <- moderation.MsgRejected
+		} else {
+			logrus.Errorf("check output failed, err:%s", err.Error())
+
+			o.ch <- moderation.MsgFailed
+		}
+
+		return false
+	}
+
+	for _, c := range o.chunks {
+		o.ch <- c
+	}
+
+	o.chunks = o.chunks[:0]
+	o.window.Reset()
+
+	return true
+}
@@ -0,0 +1,448 @@
+package bigmodels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+)
+
+var errorNoEndpoint = errors.New("access overload, please try again later")
+
+// endpointFailure is the error which means the endpoint is unavailable,
+// such as the error of transport and the server error. The other errors,
+// such as the content rejected by the model, are not counted as the
+// failures of endpoint.
+type endpointFailure struct {
+	error
+}
+
+func (e endpointFailure) Unwrap() error {
+	return e.error
+}
+
+func isEndpointFailure(err error) bool {
+	return errors.As(err, &endpointFailure{})
+}
+
+// forwardTo sends the request to the endpoint like HttpClient.ForwardTo.
+// The error of transport and the server error are the failures of endpoint.
+func (s *service) forwardTo(req *http.Request, jsonResp interface{}) error {
+	code, err := s.hc.ForwardTo(req, jsonResp)
+	if err == nil {
+		return nil
+	}
+
+	var uerr *url.Error
+	if code >= http.StatusInternalServerError || errors.As(err, &uerr) {
+		return endpointFailure{err}
+	}
+
+	return err
+}
+
+// doRequest sends the request to the endpoint and returns the response
+// to be read by the caller, such as the streamed reply. The error of
+// transport and the server error are the failures of endpoint.
+func (s *service) doRequest(req *http.Request) (*http.Response, error) {
+	resp, err := s.hc.Client.Do(req)
+	if err != nil {
+		// it is canceled by the caller.
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		return nil, endpointFailure{err}
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		resp.Body.Close()
+
+		return nil, endpointFailure{fmt.Errorf("response has status:%s", resp.Status)}
+	}
+
+	return resp, nil
+}
+
+type poolEndpoint struct {
+	url            string
+	healthURL      string
+	weight         int
+	maxConcurrency int
+
+	// current is used by the smooth weighted round-robin.
+	current      int
+	inflight     int
+	healthy      bool
+	failures     int
+	ejectedUntil time.Time
+	probedAt     time.Time
+	requests     int64
+	errors       int64
+}
+
+func (e *poolEndpoint) isAvailable(now time.Time) bool {
+	return e.healthy && !now.Before(e.ejectedUntil) && e.inflight < e.maxConcurrency
+}
+
+// endpointLease is the endpoint acquired from the pool. It must be released
+// once the call to the endpoint is done, whether it succeeds or not.
+type endpointLease struct {
+	pool       *endpointPool
+	e          *poolEndpoint
+	acquiredAt time.Time
+	once       sync.Once
+}
+
+func (l *endpointLease) endpoint() string {
+	return l.e.url
+}
+
+// release returns the endpoint to the pool. It is safe to be called more
+// than once, only the first one works.
+func (l *endpointLease) release(err error) {
+	l.once.Do(func() {
+		l.pool.release(l, err)
+	})
+}
+
+type endpointPool struct {
+	name string
+	cfg  *EndpointPool
+
+	lock      sync.Mutex
+	endpoints []*poolEndpoint
+	leases    map[*endpointLease]struct{}
+
+	// notify is closed and replaced when an endpoint is released,
+	// so that the waiters can try again.
+	notify chan struct{}
+}
+
+func newEndpointPool(name string, urls []string, cfg *EndpointPool) *endpointPool {
+	p := &endpointPool{
+		name:   name,
+		cfg:    cfg,
+		leases: map[*endpointLease]struct{}{},
+		notify: make(chan struct{}),
+	}
+
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u == "" {
+			continue
+		}
+
+		opt := cfg.option(u)
+
+		p.endpoints = append(p.endpoints, &poolEndpoint{
+			url:            u,
+			healthURL:      opt.HealthURL,
+			weight:         opt.Weight,
+			maxConcurrency: opt.MaxConcurrency,
+			healthy:        true,
+		})
+	}
+
+	return p
+}
+
+// tryAcquire picks an available endpoint by the smooth weighted round-robin.
+func (p *endpointPool) tryAcquire() *endpointLease {
+	now := time.Now()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var (
+		best  *poolEndpoint
+		total int
+	)
+
+	for _, e := range p.endpoints {
+		if !e.isAvailable(now) {
+			continue
+		}
+
+		e.current += e.weight
+		total += e.weight
+
+		if best == nil || e.current > best.current {
+			best = e
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	best.current -= total
+	best.inflight++
+	best.requests++
+
+	l := &endpointLease{
+		pool:       p,
+		e:          best,
+		acquiredAt: now,
+	}
+	p.leases[l] = struct{}{}
+
+	return l
+}
+
+// acquire waits for an available endpoint until timeout.
+func (p *endpointPool) acquire(timeout time.Duration) (*endpointLease, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		p.lock.Lock()
+		notify := p.notify
+		p.lock.Unlock()
+
+		if l := p.tryAcquire(); l != nil {
+			return l, nil
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, bigmodel.NewErrorBusySource(errorNoEndpoint)
+		}
+	}
+}
+
+func (p *endpointPool) release(l *endpointLease, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.leases[l]; !ok {
+		return
+	}
+
+	delete(p.leases, l)
+
+	e := l.e
+	e.inflight--
+
+	if err != nil {
+		e.errors++
+	}
+
+	if !isEndpointFailure(err) {
+		e.failures = 0
+	} else {
+		if e.failures++; e.failures >= p.cfg.FailureThreshold {
+			// the endpoint is ejected again at once if the first call
+			// after the ejection fails.
+			e.failures = p.cfg.FailureThreshold - 1
+			e.ejectedUntil = time.Now().Add(p.cfg.ejectDuration())
+
+			logrus.Warnf(
+				"endpoint %s of %s is ejected, err:%s", e.url, p.name, err.Error(),
+			)
+		}
+	}
+
+	p.wakeup()
+}
+
+func (p *endpointPool) wakeup() {
+	close(p.notify)
+	p.notify = make(chan struct{})
+}
+
+// idle returns how many calls can be served at once.
+func (p *endpointPool) idle() int {
+	now := time.Now()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	n := 0
+	for _, e := range p.endpoints {
+		if e.isAvailable(now) {
+			n += e.maxConcurrency - e.inflight
+		}
+	}
+
+	return n
+}
+
+// reclaim releases the leases which are not returned in time, in case
+// the caller forgets to release them.
+func (p *endpointPool) reclaim() {
+	deadline := time.Now().Add(-p.cfg.maxLeaseDuration())
+
+	p.lock.Lock()
+	expired := []*endpointLease{}
+	for l := range p.leases {
+		if l.acquiredAt.Before(deadline) {
+			expired = append(expired, l)
+		}
+	}
+	p.lock.Unlock()
+
+	for _, l := range expired {
+		logrus.Warnf("lease of endpoint %s of %s is expired", l.e.url, p.name)
+
+		l.release(nil)
+	}
+}
+
+// probe checks the health of endpoints. An endpoint is healthy if it
+// responds without server error. The ejected endpoint is not reinstated by
+// the probing, because the calls may keep failing while it is healthy.
+func (p *endpointPool) probe(cli *http.Client) {
+	p.lock.Lock()
+	es := make([]*poolEndpoint, len(p.endpoints))
+	copy(es, p.endpoints)
+	p.lock.Unlock()
+
+	for _, e := range es {
+		ok := probeEndpoint(cli, e.healthURL, p.cfg.probeTimeout())
+
+		p.lock.Lock()
+
+		if !ok && e.healthy {
+			logrus.Warnf("endpoint %s of %s is unhealthy", e.url, p.name)
+		}
+
+		if ok && !e.healthy {
+			logrus.Infof("endpoint %s of %s is healthy", e.url, p.name)
+		}
+
+		e.healthy = ok
+		e.probedAt = time.Now()
+
+		p.wakeup()
+		p.lock.Unlock()
+	}
+}
+
+func probeEndpoint(cli *http.Client, url string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return false
+	}
+
+	resp.Body.Close()
+
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+func (p *endpointPool) state() bigmodel.EndpointPoolState {
+	now := time.Now()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	v := bigmodel.EndpointPoolState{
+		Name:      p.name,
+		Endpoints: make([]bigmodel.EndpointState, len(p.endpoints)),
+	}
+
+	for i, e := range p.endpoints {
+		s := bigmodel.EndpointState{
+			URL:            e.url,
+			Weight:         e.weight,
+			MaxConcurrency: e.maxConcurrency,
+			Inflight:       e.inflight,
+			Healthy:        e.healthy,
+			Ejected:        now.Before(e.ejectedUntil),
+			Requests:       e.requests,
+			Errors:         e.errors,
+		}
+
+		if s.Ejected {
+			s.EjectedUntil = e.ejectedUntil.Unix()
+		}
+
+		if !e.probedAt.IsZero() {
+			s.ProbedAt = e.probedAt.Unix()
+		}
+
+		if e.isAvailable(now) {
+			v.Idle += e.maxConcurrency - e.inflight
+		}
+
+		v.Endpoints[i] = s
+	}
+
+	return v
+}
+
+// MaintainEndpointPools probes the endpoints and reclaims the expired
+// leases of all the pools. It should be called periodically at the
+// interval returned by EndpointPool.MaintainInterval.
+func MaintainEndpointPools() {
+	fm.maintainEndpointPools()
+}
+
+func (s *service) maintainEndpointPools() {
+	for _, p := range s.pools {
+		if s.poolCfg.ProbeInterval > 0 {
+			p.probe(s.probeClient)
+		}
+
+		p.reclaim()
+	}
+}
+
+func (s *service) EndpointPools() []bigmodel.EndpointPoolState {
+	v := make([]bigmodel.EndpointPoolState, len(s.pools))
+	for i, p := range s.pools {
+		v[i] = p.state()
+	}
+
+	return v
+}
+
+// doIfFree calls f with an available endpoint, it fails at once if
+// there is no one.
+func (s *service) doIfFree(p *endpointPool, f func(string) error) error {
+	if p == nil {
+		return bigmodel.NewErrorBusySource(errorNoEndpoint)
+	}
+
+	l := p.tryAcquire()
+	if l == nil {
+		return bigmodel.NewErrorBusySource(errorNoEndpoint)
+	}
+
+	err := f(l.endpoint())
+	l.release(err)
+
+	return err
+}
+
+// doWaitAndEndpointNotReturned waits for an available endpoint and calls f
+// with it. The lease is released by f when it succeeds, because f may keep
+// using the endpoint in background, such as streaming the reply.
+func (s *service) doWaitAndEndpointNotReturned(p *endpointPool, f func(*endpointLease) error) error {
+	l, err := p.acquire(waitTime)
+	if err != nil {
+		return err
+	}
+
+	if err = f(l); err != nil {
+		l.release(err)
+	}
+
+	return err
+}
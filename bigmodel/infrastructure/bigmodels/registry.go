@@ -0,0 +1,348 @@
+package bigmodels
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	libutils "github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+)
+
+const (
+	// protocolXihe is the streaming protocol of the models deployed on
+	// the platform, such as glm2, llama2 and skywork.
+	protocolXihe   = "xihe"
+	protocolOpenAI = "openai"
+
+	authNone   = "none"
+	authCloud  = "cloud"
+	authBearer = "bearer"
+
+	doneOpenAI = "[DONE]"
+)
+
+type registeredModel struct {
+	cfg       *ModelConfig
+	endpoints *endpointPool
+}
+
+type modelRegistry struct {
+	models map[string]*registeredModel
+	order  []string
+}
+
+func newModelRegistry(cfg *Config) (r modelRegistry, err error) {
+	r.models = make(map[string]*registeredModel, len(cfg.Models))
+	r.order = make([]string, 0, len(cfg.Models))
+
+	for i := range cfg.Models {
+		item := &cfg.Models[i]
+
+		es, err := cfg.Endpoints.parse(item.Endpoints)
+		if err != nil {
+			return r, err
+		}
+
+		r.models[item.Name] = &registeredModel{
+			cfg:       item,
+			endpoints: newEndpointPool(item.Name, es, &cfg.EndpointPool),
+		}
+		r.order = append(r.order, item.Name)
+	}
+
+	domain.RegisterChatModels(r.order)
+
+	return
+}
+
+func (r *modelRegistry) get(name string) (*registeredModel, bool) {
+	m, ok := r.models[name]
+
+	return m, ok
+}
+
+func (s *service) Models() []bigmodel.ModelInfo {
+	r := &s.registry
+
+	v := make([]bigmodel.ModelInfo, 0, len(r.order))
+	for _, name := range r.order {
+		cfg := r.models[name].cfg
+
+		v = append(v, bigmodel.ModelInfo{
+			Name:              cfg.Name,
+			Title:             cfg.Display.Title,
+			Desc:              cfg.Display.Desc,
+			Icon:              cfg.Display.Icon,
+			Tags:              cfg.Display.Tags,
+			TopK:              cfg.Defaults.TopK,
+			TopP:              cfg.Defaults.TopP,
+			Temperature:       cfg.Defaults.Temperature,
+			RepetitionPenalty: cfg.Defaults.RepetitionPenalty,
+		})
+	}
+
+	// the built-in models are overridden by the ones declared by config.
+	for _, name := range domain.BuiltinChatModels() {
+		if _, ok := r.get(name); !ok {
+			v = append(v, bigmodel.ModelInfo{
+				Name:    name,
+				Title:   name,
+				Builtin: true,
+			})
+		}
+	}
+
+	return v
+}
+
+// chatByRegistry calls the model declared by config.
+func (s *service) chatByRegistry(
+	ch chan string, m *registeredModel, input *domain.ChatInput,
+) (err error) {
+	text, history, err := input.Prompt()
+	if err != nil {
+		return
+	}
+
+	if !m.cfg.Moderation.SkipInput {
+		if err = s.check.CheckText(moderation.SceneInput, text); err != nil {
+			logrus.Debugf("content audit not pass: %s", err.Error())
+
+			return
+		}
+	}
+
+	f := func(l *endpointLease) error {
+		return s.genByRegistry(l, ch, m, input, text, history)
+	}
+
+	return s.doWaitAndEndpointNotReturned(m.endpoints, f)
+}
+
+func (s *service) genByRegistry(
+	l *endpointLease, ch chan string, m *registeredModel,
+	input *domain.ChatInput, text string, history [][2]string,
+) error {
+	var (
+		body  []byte
+		err   error
+		parse func(string) (string, bool)
+	)
+
+	if m.cfg.Protocol == protocolOpenAI {
+		body, err = libutils.JsonMarshal(toOpenAIReq(m.cfg, input))
+		parse = parseOpenAIChunk
+	} else {
+		body, err = libutils.JsonMarshal(toXiheReq(m.cfg, input, text, history))
+		parse = parseXiheChunk
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withDone(input.Done)
+
+	resp, err := s.postToModel(ctx, l.endpoint(), m.cfg, body)
+	if err != nil {
+		cancel()
+
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+
+		return errors.New("the model is unavailable")
+	}
+
+	go func() {
+		defer close(ch)
+		defer l.release(nil)
+		defer cancel()
+		defer resp.Body.Close()
+
+		s.relayChunks(ch, bufio.NewReader(resp.Body), &m.cfg.Moderation, parse)
+	}()
+
+	return nil
+}
+
+func (s *service) postToModel(
+	ctx context.Context, endpoint string, cfg *ModelConfig, body []byte,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Auth.Type {
+	case authCloud:
+		t, err := s.token()
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Auth-Token", t)
+
+	case authBearer:
+		req.Header.Set("Authorization", "Bearer "+cfg.Auth.Token)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Accept", "*/*")
+
+	return s.doRequest(req)
+}
+
+// relayChunks sends the reply to ch line by line. The output is checked by
+// sentence windows, and it stops if the check fails.
+func (s *service) relayChunks(
+	ch chan string, reader *bufio.Reader, cfg *ModelModeration,
+	parse func(string) (string, bool),
+) {
+	out := s.newOutputStream(ch, cfg.SkipOutput)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			chunk, done := parse(line)
+
+			if chunk != "" && !out.send(chunk) {
+				logrus.Debug("content audit not pass")
+
+				return
+			}
+
+			if done {
+				break
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	out.done()
+}
+
+type xiheRequest struct {
+	Inputs            string      `json:"inputs"`
+	History           [][2]string `json:"history"`
+	Sampling          bool        `json:"sampling"`
+	TopK              int         `json:"top_k"`
+	TopP              float64     `json:"top_p"`
+	Temperature       float64     `json:"temperature"`
+	RepetitionPenalty float64     `json:"repetition_penalty"`
+}
+
+type xiheResponse struct {
+	Reply        string `json:"reply"`
+	StreamStatus string `json:"stream_status"`
+}
+
+func toXiheReq(cfg *ModelConfig, input *domain.ChatInput, text string, history [][2]string) xiheRequest {
+	req := xiheRequest{
+		Inputs:            text,
+		History:           history,
+		Sampling:          true,
+		TopK:              cfg.Defaults.TopK,
+		TopP:              cfg.Defaults.TopP,
+		Temperature:       cfg.Defaults.Temperature,
+		RepetitionPenalty: cfg.Defaults.RepetitionPenalty,
+	}
+
+	if v := &input.ChatSampling; v.Sampling {
+		req.TopK = v.TopK.TopK()
+		req.TopP = v.TopP.TopP()
+		req.Temperature = v.Temperature.Temperature()
+		req.RepetitionPenalty = v.RepetitionPenalty.RepetitionPenalty()
+	}
+
+	return req
+}
+
+func parseXiheChunk(line string) (string, bool) {
+	data := strings.TrimRight(strings.TrimPrefix(line, replaceResponseGLM), "\x00")
+
+	var r xiheResponse
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		return "", false
+	}
+
+	return r.Reply, r.StreamStatus == doneStatusGLM
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model,omitempty"`
+	Messages    []openAIMessage `json:"messages"`
+	Stream      bool            `json:"stream"`
+	TopP        float64         `json:"top_p"`
+	Temperature float64         `json:"temperature"`
+}
+
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+func toOpenAIReq(cfg *ModelConfig, input *domain.ChatInput) openAIRequest {
+	req := openAIRequest{
+		Model:       cfg.Defaults.Model,
+		Messages:    make([]openAIMessage, len(input.Messages)),
+		Stream:      true,
+		TopP:        cfg.Defaults.TopP,
+		Temperature: cfg.Defaults.Temperature,
+	}
+
+	for i := range input.Messages {
+		req.Messages[i] = openAIMessage{
+			Role:    input.Messages[i].Role,
+			Content: input.Messages[i].Content,
+		}
+	}
+
+	if v := &input.ChatSampling; v.Sampling {
+		req.TopP = v.TopP.TopP()
+		req.Temperature = v.Temperature.Temperature()
+	}
+
+	return req
+}
+
+func parseOpenAIChunk(line string) (string, bool) {
+	data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+	if data == doneOpenAI {
+		return "", true
+	}
+
+	var r openAIChunk
+	if err := json.Unmarshal([]byte(data), &r); err != nil || len(r.Choices) == 0 {
+		return "", false
+	}
+
+	c := &r.Choices[0]
+
+	return c.Delta.Content, c.FinishReason != nil
+}
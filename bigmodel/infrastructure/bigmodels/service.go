@@ -11,6 +11,8 @@ import (
 	"github.com/opensourceways/community-robot-lib/utils"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	"github.com/opensourceways/xihe-server/bigmodel/infrastructure/moderationimpl"
 )
 
 const (
@@ -19,19 +21,24 @@ const (
 
 var fm *service
 
-func Init(cfg *Config) error {
+func Init(cfg *Config, decision repository.ModerationDecision) error {
 	obs, err := initOBS(&cfg.OBS.OBSAuthInfo)
 	if err != nil {
 		return err
 	}
 
-	check := initTextCheck(&cfg.Moderation)
+	check, err := moderationimpl.NewModeration(&cfg.Moderation, decision)
+	if err != nil {
+		return err
+	}
 
 	fm = &service{
 		obs:   obs,
 		check: check,
 		cfg:   cfg.Cloud,
 		hc:    utils.NewHttpClient(3),
+
+		outputCfg: cfg.OutputModeration,
 	}
 
 	http.DefaultClient.Transport = &http.Transport{
@@ -65,6 +72,14 @@ func Init(cfg *Config) error {
 		return err
 	}
 
+	if fm.registry, err = newModelRegistry(cfg); err != nil {
+		return err
+	}
+
+	fm.pools = fm.endpointPools()
+	fm.poolCfg = &cfg.EndpointPool
+	fm.probeClient = &http.Client{Transport: http.DefaultClient.Transport}
+
 	return err
 }
 
@@ -75,7 +90,9 @@ func NewBigModelService() bigmodel.BigModel {
 type service struct {
 	cfg   CloudConfig
 	obs   obsService
-	check textCheckService
+	check moderationimpl.Moderation
+
+	outputCfg OutputModeration
 
 	hc utils.HttpClient
 
@@ -88,6 +105,32 @@ type service struct {
 	llama2Info       llama2Info
 	skyWorkInfo      skyWorkInfo
 	iflyteksparkInfo iflyteksparkInfo
+
+	registry modelRegistry
+
+	// pools are all the pools of endpoints which are maintained.
+	pools       []*endpointPool
+	poolCfg     *EndpointPool
+	probeClient *http.Client
+}
+
+func (s *service) endpointPools() []*endpointPool {
+	v := []*endpointPool{
+		s.wukongInfo.endpoints, s.wukongInfo.endpoints4,
+		s.wukongInfo.endpointsHF, s.wukongInfo.endpointsUser,
+		s.luojiaInfo.endpoints, s.luojiaInfo.endpointHF,
+		s.baichuanInfo.endpoints,
+		s.glm2Info.endpoints,
+		s.llama2Info.endpoints,
+		s.skyWorkInfo.endpoints,
+		s.iflyteksparkInfo.endpoints, s.iflyteksparkInfo.endpointsLong,
+	}
+
+	for _, name := range s.registry.order {
+		v = append(v, s.registry.models[name].endpoints)
+	}
+
+	return v
 }
 
 func (s *service) token() (string, error) {
@@ -142,41 +185,12 @@ func genToken(cfg *CloudConfig) (string, error) {
 	return t, nil
 }
 
-func (s *service) doIfFree(
-	ec chan string,
-	f func(string) error,
-) error {
-	select {
-	case e := <-ec:
-		err := f(e)
-		ec <- e
-
-		return err
-
-	default:
-		return bigmodel.NewErrorBusySource(errors.New("access overload, please try again later"))
-	}
-}
-
-func (s *service) doWaitAndEndpointNotReturned(
-	ec chan string,
-	f func(chan string, string) error,
-) error {
-	select {
-	case e := <-ec:
-		err := f(ec, e)
-		return err
-	case <-time.After(waitTime):
-		return bigmodel.NewErrorBusySource(errors.New("access overload, please try again later"))
-	}
-}
-
 func (s *service) GetIdleEndpoint(bid string) (int, error) {
 	switch bid {
 	case "wukong":
-		return len(s.wukongInfo.endpoints), nil
+		return s.wukongInfo.endpoints.idle(), nil
 	case "wukong_4img":
-		return len(s.wukongInfo.endpoints4), nil
+		return s.wukongInfo.endpoints4.idle(), nil
 	default:
 		return 0, errors.New("internal error, cannot found this bigmodel")
 	}
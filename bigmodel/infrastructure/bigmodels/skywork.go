@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 )
 
 type skyWorkRequest struct {
@@ -31,31 +32,27 @@ type skyWorkResponse struct {
 }
 
 type skyWorkInfo struct {
-	endpoints chan string
+	endpoints *endpointPool
 }
 
 func newSkyWorkInfo(cfg *Config) (info skyWorkInfo, err error) {
 	ce := &cfg.Endpoints
 	es, _ := ce.parse(ce.SkyWork)
 
-	// init endpoints
-	info.endpoints = make(chan string, len(es))
-	for _, e := range es {
-		info.endpoints <- e
-	}
+	info.endpoints = newEndpointPool("skywork", es, &cfg.EndpointPool)
 
 	return
 }
 
 func (s *service) SkyWork(ch chan string, input *domain.SkyWorkInput) (err error) {
 	// input audit
-	if err = s.check.check(input.Text.SkyWorkText()); err != nil {
+	if err = s.check.CheckText(moderation.SceneInput, input.Text.SkyWorkText()); err != nil {
 		return
 	}
 
 	// call bigmodel skywork 13b
-	f := func(ec chan string, e string) (err error) {
-		err = s.genSkyWork(ec, ch, e, input)
+	f := func(l *endpointLease) (err error) {
+		err = s.genSkyWork(l, ch, input)
 
 		return
 	}
@@ -67,7 +64,7 @@ func (s *service) SkyWork(ch chan string, input *domain.SkyWorkInput) (err error
 	return
 }
 
-func (s *service) genSkyWork(ec, ch chan string, endpoint string, input *domain.SkyWorkInput) (
+func (s *service) genSkyWork(l *endpointLease, ch chan string, input *domain.SkyWorkInput) (
 	err error,
 ) {
 	t, err := genToken(&s.wukongInfo.cfg.CloudConfig)
@@ -82,7 +79,7 @@ func (s *service) genSkyWork(ec, ch chan string, endpoint string, input *domain.
 	}
 
 	req, err := http.NewRequest(
-		http.MethodPost, endpoint, bytes.NewBuffer(body),
+		http.MethodPost, l.endpoint(), bytes.NewBuffer(body),
 	)
 	if err != nil {
 		return
@@ -93,25 +90,26 @@ func (s *service) genSkyWork(ec, ch chan string, endpoint string, input *domain.
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Accept", "*/*")
 
-	resp, err := s.hc.Client.Do(req)
+	resp, err := s.doRequest(req)
 	if err != nil {
 		return
 	}
 
 	reader := bufio.NewReader(resp.Body)
 
-	var (
-		r     skyWorkResponse
-		count int
-	)
+	var r skyWorkResponse
+
 	go func() {
-		defer func() { ec <- endpoint }()
+		defer close(ch)
+		defer l.release(nil)
 		defer resp.Body.Close()
 
+		out := s.newOutputStream(ch, false)
+
 		for {
 			line, err := reader.ReadString('\n')
 			if err != nil {
-				ch <- "done"
+				out.done()
 
 				logrus.Debugf("skywork read end or error: %s", err)
 
@@ -126,26 +124,16 @@ func (s *service) genSkyWork(ec, ch chan string, endpoint string, input *domain.
 			}
 
 			if r.StreamStatus == "DONE" {
-				ch <- "done"
+				out.done()
 
 				return
 			}
 
-			// response audit, skip 6 response
-			if r.Reply != "" && count > 6 {
-				count = 0
+			if !out.send(r.Reply) {
+				logrus.Debug("content audit not pass")
 
-				if err = s.check.check(r.Reply); err != nil {
-					logrus.Debugf("content audit not pass: %s", err.Error())
-
-					ch <- "done"
-
-					return
-				}
+				return
 			}
-
-			ch <- r.Reply
-			count += 1
 		}
 	}()
 
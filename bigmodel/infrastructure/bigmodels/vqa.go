@@ -11,6 +11,7 @@ import (
 	"github.com/opensourceways/community-robot-lib/utils"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 	types "github.com/opensourceways/xihe-server/domain"
 )
 
@@ -60,7 +61,7 @@ func newVQAInfo(cfg *Config) vqaInfo {
 }
 
 func (s *service) Ask(q domain.Question, f string) (string, error) {
-	if err := s.check.check(q.Question()); err != nil {
+	if err := s.check.CheckText(moderation.SceneInput, q.Question()); err != nil {
 		return "", err
 	}
 
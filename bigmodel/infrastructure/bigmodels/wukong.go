@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	libutils "github.com/opensourceways/community-robot-lib/utils"
 
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
 	types "github.com/opensourceways/xihe-server/domain"
 	"github.com/opensourceways/xihe-server/utils"
 )
@@ -21,10 +23,10 @@ type wukongInfo struct {
 	cli           obsService
 	cfg           WuKong
 	maxBatch      int
-	endpoints     chan string
-	endpoints4    chan string
-	endpointsHF   chan string
-	endpointsUser chan string
+	endpoints     *endpointPool
+	endpoints4    *endpointPool
+	endpointsHF   *endpointPool
+	endpointsUser *endpointPool
 }
 
 func newWuKongInfo(cfg *Config) (wukongInfo, error) {
@@ -47,29 +49,11 @@ func newWuKongInfo(cfg *Config) (wukongInfo, error) {
 	eshf, _ := ce.parse(ce.WuKongHF)
 	esus, _ := ce.parse(ce.WuKongUser)
 
-	// init endpoints
-	info.endpoints = make(chan string, len(es))
-	for _, e := range es {
-		info.endpoints <- e
-	}
-
-	// init endpoints4
-	info.endpoints4 = make(chan string, len(es4))
-	for _, e := range es4 {
-		info.endpoints4 <- e
-	}
-
-	// init endpoints_hf
-	info.endpointsHF = make(chan string, len(eshf))
-	for _, e := range eshf {
-		info.endpointsHF <- e
-	}
-
-	// init endpoint_user
-	info.endpointsUser = make(chan string, len(esus))
-	for _, e := range esus {
-		info.endpointsUser <- e
-	}
+	pool := &cfg.EndpointPool
+	info.endpoints = newEndpointPool("wukong", es, pool)
+	info.endpoints4 = newEndpointPool("wukong_4img", es4, pool)
+	info.endpointsHF = newEndpointPool("wukong_hf", eshf, pool)
+	info.endpointsUser = newEndpointPool("wukong_user", esus, pool)
 
 	return info, nil
 }
@@ -100,7 +84,7 @@ func (s *service) GetWuKongSampleId() string {
 func (s *service) GenPicturesByWuKong(
 	user types.Account, desc *domain.WuKongPictureMeta, estype string,
 ) (map[string]string, error) {
-	if err := s.check.check(desc.Desc.WuKongPictureDesc()); err != nil {
+	if err := s.check.CheckText(moderation.SceneInput, desc.Desc.WuKongPictureDesc()); err != nil {
 		return nil, err
 	}
 
@@ -113,7 +97,7 @@ func (s *service) GenPicturesByWuKong(
 	}
 
 	// select endpoints
-	var es chan string
+	var es *endpointPool
 	switch estype {
 	case string(domain.BigmodelWuKong):
 		es = s.wukongInfo.endpoints
@@ -149,7 +133,7 @@ func (s *service) GenPicturesByWuKong(
 		checkUrls[i] = v
 		i++
 	}
-	if err := s.check.checkImages(checkUrls); err != nil {
+	if err := s.check.CheckImages(checkUrls); err != nil {
 		return nil, err
 	}
 
@@ -185,7 +169,7 @@ func (s *service) genPicturesByWuKong(
 	req.Header.Set("X-Auth-Token", t)
 
 	var r wukongResponse
-	if _, err = s.hc.ForwardTo(req, &r); err != nil {
+	if err = s.forwardTo(req, &r); err != nil {
 		return nil, err
 	}
 
@@ -212,6 +196,16 @@ func (s *service) MoveWuKongPictureToDir(dst, src string) error {
 	return info.cli.copyObject(info.cfg.Bucket, dst, src)
 }
 
+func (s *service) GenWuKongBatchPath(user types.Account, jobId, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", s.wukongInfo.cfg.BatchDir, user.Account(), jobId, name)
+}
+
+func (s *service) ReadWuKongPicture(p string) (io.ReadCloser, error) {
+	info := &s.wukongInfo
+
+	return info.cli.getObject(info.cfg.Bucket, p)
+}
+
 func (s *service) DeleteWuKongPicture(p string) error {
 	info := &s.wukongInfo
 
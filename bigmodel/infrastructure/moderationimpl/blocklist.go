@@ -0,0 +1,70 @@
+package moderationimpl
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// blocklist loads the file and reloads it once it is changed. It is checked
+// by the ticker of server which calls ReloadBlocklists.
+type blocklist struct {
+	cfg  *BlocklistConfig
+	load func([]byte) error
+
+	lock      sync.Mutex
+	updatedAt time.Time
+}
+
+func newBlocklist(cfg *BlocklistConfig, load func([]byte) error) (*blocklist, error) {
+	b := &blocklist{
+		cfg:  cfg,
+		load: load,
+	}
+
+	if _, err := b.reload(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *blocklist) check() {
+	ok, err := b.reload()
+	if err != nil {
+		logrus.Errorf("reload blocklist %s failed, err:%s", b.cfg.File, err.Error())
+	} else if ok {
+		logrus.Infof("blocklist %s is reloaded", b.cfg.File)
+	}
+}
+
+// reload loads the file if it is changed since last time.
+func (b *blocklist) reload() (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	info, err := os.Stat(b.cfg.File)
+	if err != nil {
+		return false, err
+	}
+
+	if !info.ModTime().After(b.updatedAt) {
+		return false, nil
+	}
+
+	v, err := os.ReadFile(b.cfg.File)
+	if err != nil {
+		return false, err
+	}
+
+	// the previous rules are kept if the file is invalid.
+	if err = b.load(v); err != nil {
+		return false, err
+	}
+
+	b.updatedAt = info.ModTime()
+
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package moderationimpl
+
+import (
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/region"
+	moderationv2 "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/moderation/v2"
+	modelv2 "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/moderation/v2/model"
+	regionv2 "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/moderation/v2/region"
+	moderationv3 "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/moderation/v3"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/moderation/v3/model"
+)
+
+const suggestionPass = "pass"
+
+// cloudProvider checks the content by the moderation service of cloud.
+type cloudProvider struct {
+	cli   *moderationv3.ModerationClient
+	cliv2 *moderationv2.ModerationClient
+}
+
+func newCloudProvider(cfg *CloudConfig) provider {
+	auth := basic.NewCredentialsBuilder().
+		WithAk(cfg.AccessKey).
+		WithSk(cfg.SecretKey).
+		WithIamEndpointOverride(cfg.IAMEndpoint).
+		Build()
+
+	cli := moderationv3.NewModerationClient(
+		moderationv3.ModerationClientBuilder().
+			WithRegion(region.NewRegion(cfg.Region, cfg.Endpoint)).
+			WithCredential(auth).
+			Build(),
+	)
+
+	authv2 := basic.NewCredentialsBuilder().
+		WithAk(cfg.AccessKey).
+		WithSk(cfg.SecretKey).
+		Build()
+
+	cliv2 := moderationv2.NewModerationClient(
+		moderationv3.ModerationClientBuilder().
+			WithRegion(regionv2.ValueOf(cfg.Region)).
+			WithCredential(authv2).
+			Build(),
+	)
+
+	return &cloudProvider{cli, cliv2}
+}
+
+func (p *cloudProvider) name() string {
+	return providerCloud
+}
+
+func (p *cloudProvider) checkText(scene, content string) (string, error) {
+	var t string = "comment"
+	request := &model.RunTextModerationRequest{
+		Body: &model.TextDetectionReq{
+			Data: &model.TextDetectionDataReq{
+				Text: content,
+			},
+			EventType: &t,
+		},
+	}
+
+	resp, err := p.cli.RunTextModeration(request)
+	if err != nil {
+		return "", err
+	}
+
+	if v := *resp.Result.Suggestion; v != suggestionPass {
+		return "suggestion: " + v, nil
+	}
+
+	return "", nil
+}
+
+func (p *cloudProvider) checkImages(urls []string) (string, error) {
+	request := &modelv2.RunImageBatchModerationRequest{}
+	var listCategoriesbody = []modelv2.ImageBatchModerationReqCategories{
+		modelv2.GetImageBatchModerationReqCategoriesEnum().ALL,
+	}
+	var listUrlsbody = urls
+	rule := "default"
+	request.Body = &modelv2.ImageBatchModerationReq{
+		ModerationRule: &rule,
+		Categories:     &listCategoriesbody,
+		Urls:           listUrlsbody,
+	}
+	resp, err := p.cliv2.RunImageBatchModeration(request)
+	if err != nil {
+		return "", err
+	}
+
+	results := resp.Result
+	for _, res := range *results {
+		if v := *res.Suggestion; v != suggestionPass {
+			return "suggestion: " + v, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,113 @@
+package moderationimpl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	providerKeyword   = "keyword"
+	providerImageHash = "image_hash"
+	providerCloud     = "cloud"
+)
+
+type Config struct {
+	CloudConfig
+
+	// Providers are the names of providers which check the content in
+	// order. All the providers are used by default.
+	Providers []string `json:"providers"`
+
+	Keyword   BlocklistConfig `json:"keyword"`
+	ImageHash ImageHashConfig `json:"image_hash"`
+}
+
+func (cfg *Config) SetDefault() {
+	if len(cfg.Providers) == 0 {
+		cfg.Providers = []string{providerKeyword, providerImageHash, providerCloud}
+	}
+
+	cfg.Keyword.setDefault()
+	cfg.ImageHash.setDefault()
+}
+
+// ReloadInterval returns the interval to check whether the blocklists are
+// changed, which is the shortest one of them.
+func (cfg *Config) ReloadInterval() time.Duration {
+	v := cfg.Keyword.ReloadInterval
+	if cfg.ImageHash.ReloadInterval < v {
+		v = cfg.ImageHash.ReloadInterval
+	}
+
+	return time.Duration(v) * time.Second
+}
+
+func (cfg *Config) Validate() error {
+	for _, p := range cfg.Providers {
+		switch p {
+		case providerKeyword, providerImageHash:
+
+		case providerCloud:
+			if err := cfg.CloudConfig.validate(); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unknown moderation provider: %s", p)
+		}
+	}
+
+	return nil
+}
+
+// CloudConfig is the config of the moderation service of cloud.
+type CloudConfig struct {
+	Endpoint    string `json:"endpoint"`
+	AccessKey   string `json:"access_key"`
+	SecretKey   string `json:"secret_key"`
+	IAMEndpoint string `json:"iam_endpoint"`
+	Region      string `json:"region"`
+}
+
+func (cfg *CloudConfig) validate() error {
+	if cfg.Endpoint == "" || cfg.AccessKey == "" || cfg.SecretKey == "" ||
+		cfg.IAMEndpoint == "" || cfg.Region == "" {
+		return errors.New("missing config of cloud moderation")
+	}
+
+	return nil
+}
+
+// BlocklistConfig is the config of the local blocklist file.
+type BlocklistConfig struct {
+	// File is the path of blocklist, the provider does nothing if it is empty.
+	File string `json:"file"`
+
+	// ReloadInterval specifies the interval to check whether the file is
+	// changed. The unit is second.
+	ReloadInterval int `json:"reload_interval"`
+}
+
+func (cfg *BlocklistConfig) setDefault() {
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = 30
+	}
+}
+
+// ImageHashConfig is the config of the blocklist of image hashes.
+type ImageHashConfig struct {
+	BlocklistConfig
+
+	// MaxDistance is the max num of different bits between the hash of
+	// image and the one in the blocklist for the image to be rejected.
+	MaxDistance int `json:"max_distance"`
+}
+
+func (cfg *ImageHashConfig) setDefault() {
+	cfg.BlocklistConfig.setDefault()
+
+	if cfg.MaxDistance <= 0 {
+		cfg.MaxDistance = 10
+	}
+}
@@ -0,0 +1,187 @@
+package moderationimpl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	imageDownloadTimeout = 30 * time.Second
+	maxImageSize         = 20 << 20
+
+	// the image is shrunk to dHashWidth x dHashHeight gray pixels, and
+	// each row gives dHashWidth-1 bits, so the hash has 64 bits.
+	dHashWidth  = 9
+	dHashHeight = 8
+)
+
+// imageHashProvider rejects the image whose perceptual hash is close to
+// any one in the blocklist. The hash is the difference hash of 64 bits, so
+// the image which is resized, re-encoded or slightly changed is still
+// matched. Each line of the file is a hash in hex, and the one prefixed
+// with "#" is comment.
+type imageHashProvider struct {
+	hashes      atomic.Value
+	cli         http.Client
+	maxDistance int
+}
+
+func newImageHashProvider(cfg *ImageHashConfig) (provider, *blocklist, error) {
+	if cfg.File == "" {
+		return nil, nil, nil
+	}
+
+	p := &imageHashProvider{
+		cli:         http.Client{Timeout: imageDownloadTimeout},
+		maxDistance: cfg.MaxDistance,
+	}
+
+	b, err := newBlocklist(&cfg.BlocklistConfig, p.load)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p, b, nil
+}
+
+func (p *imageHashProvider) load(data []byte) error {
+	hashes := []uint64{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		h, err := strconv.ParseUint(line, 16, 64)
+		if err != nil || len(line) != 16 {
+			return fmt.Errorf("invalid image hash: %s", line)
+		}
+
+		hashes = append(hashes, h)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	p.hashes.Store(hashes)
+
+	return nil
+}
+
+func (p *imageHashProvider) name() string {
+	return providerImageHash
+}
+
+func (p *imageHashProvider) checkText(scene, content string) (string, error) {
+	return "", nil
+}
+
+func (p *imageHashProvider) checkImages(urls []string) (string, error) {
+	hashes, _ := p.hashes.Load().([]uint64)
+	if len(hashes) == 0 {
+		return "", nil
+	}
+
+	for _, u := range urls {
+		h, err := p.hash(u)
+		if err != nil {
+			return "", err
+		}
+
+		for _, v := range hashes {
+			if d := bits.OnesCount64(h ^ v); d <= p.maxDistance {
+				return fmt.Sprintf("dhash: %016x, distance %d to %016x", h, d, v), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (p *imageHashProvider) hash(url string) (uint64, error) {
+	resp, err := p.cli.Get(url)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download image failed, status:%d", resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return 0, err
+	}
+
+	return dHash(img), nil
+}
+
+// dHash computes the difference hash of image. Each bit tells whether a
+// gray pixel of the shrunk image is brighter than the one on its right.
+func dHash(img image.Image) uint64 {
+	var (
+		gray [dHashHeight][dHashWidth]uint64
+		h    uint64
+	)
+
+	b := img.Bounds()
+
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth; x++ {
+			gray[y][x] = averageGray(img, image.Rect(
+				b.Min.X+x*b.Dx()/dHashWidth,
+				b.Min.Y+y*b.Dy()/dHashHeight,
+				b.Min.X+(x+1)*b.Dx()/dHashWidth,
+				b.Min.Y+(y+1)*b.Dy()/dHashHeight,
+			))
+		}
+	}
+
+	for y := 0; y < dHashHeight; y++ {
+		for x := 0; x < dHashWidth-1; x++ {
+			h <<= 1
+
+			if gray[y][x] > gray[y][x+1] {
+				h |= 1
+			}
+		}
+	}
+
+	return h
+}
+
+// averageGray returns the average gray of the pixels in the rectangle, or
+// the gray of its top left pixel if it is empty.
+func averageGray(img image.Image, r image.Rectangle) uint64 {
+	if r.Empty() {
+		return uint64(color.Gray16Model.Convert(img.At(r.Min.X, r.Min.Y)).(color.Gray16).Y)
+	}
+
+	var sum uint64
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			sum += uint64(color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y)
+		}
+	}
+
+	return sum / uint64(r.Dx()*r.Dy())
+}
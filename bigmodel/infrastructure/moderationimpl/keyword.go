@@ -0,0 +1,101 @@
+package moderationimpl
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+const regexpRulePrefix = "re:"
+
+type keywordRules struct {
+	keywords []string
+	regexps  []*regexp.Regexp
+}
+
+// keywordProvider rejects the text which contains any keyword or matches
+// any regexp of the blocklist. Each line of the file is a rule, the one
+// prefixed with "re:" is a regexp, and the one prefixed with "#" is comment.
+type keywordProvider struct {
+	rules atomic.Value
+}
+
+func newKeywordProvider(cfg *BlocklistConfig) (provider, *blocklist, error) {
+	if cfg.File == "" {
+		return nil, nil, nil
+	}
+
+	p := &keywordProvider{}
+
+	b, err := newBlocklist(cfg, p.load)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p, b, nil
+}
+
+func (p *keywordProvider) load(data []byte) error {
+	rules := keywordRules{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, regexpRulePrefix) {
+			rules.keywords = append(rules.keywords, strings.ToLower(line))
+
+			continue
+		}
+
+		re, err := regexp.Compile(strings.TrimPrefix(line, regexpRulePrefix))
+		if err != nil {
+			return err
+		}
+
+		rules.regexps = append(rules.regexps, re)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	p.rules.Store(&rules)
+
+	return nil
+}
+
+func (p *keywordProvider) name() string {
+	return providerKeyword
+}
+
+func (p *keywordProvider) checkText(scene, content string) (string, error) {
+	rules, _ := p.rules.Load().(*keywordRules)
+	if rules == nil {
+		return "", nil
+	}
+
+	s := strings.ToLower(content)
+	for _, k := range rules.keywords {
+		if strings.Contains(s, k) {
+			return "keyword: " + k, nil
+		}
+	}
+
+	for _, re := range rules.regexps {
+		if re.MatchString(content) {
+			return "regexp: " + re.String(), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (p *keywordProvider) checkImages(urls []string) (string, error) {
+	return "", nil
+}
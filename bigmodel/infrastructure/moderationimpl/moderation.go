@@ -0,0 +1,143 @@
+package moderationimpl
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/bigmodel"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const maxRecordedContentLen = 1000
+
+// provider checks the content, and returns the reason if it is rejected.
+type provider interface {
+	name() string
+	checkText(scene, content string) (string, error)
+	checkImages(urls []string) (string, error)
+}
+
+// Moderation checks the content by the providers, and records the decisions
+// of rejecting for review.
+type Moderation interface {
+	moderation.Moderation
+
+	// ReloadBlocklists reloads the blocklists which are changed, it should
+	// be called periodically.
+	ReloadBlocklists()
+}
+
+func NewModeration(cfg *Config, repo repository.ModerationDecision) (Moderation, error) {
+	c := chain{
+		repo:      repo,
+		providers: make([]provider, 0, len(cfg.Providers)),
+	}
+
+	for _, name := range cfg.Providers {
+		var (
+			p   provider
+			b   *blocklist
+			err error
+		)
+
+		switch name {
+		case providerKeyword:
+			p, b, err = newKeywordProvider(&cfg.Keyword)
+
+		case providerImageHash:
+			p, b, err = newImageHashProvider(&cfg.ImageHash)
+
+		case providerCloud:
+			p = newCloudProvider(&cfg.CloudConfig)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			c.providers = append(c.providers, p)
+		}
+
+		if b != nil {
+			c.blocklists = append(c.blocklists, b)
+		}
+	}
+
+	return c, nil
+}
+
+// chain passes the content only if all the providers pass it.
+type chain struct {
+	repo       repository.ModerationDecision
+	providers  []provider
+	blocklists []*blocklist
+}
+
+func (c chain) CheckText(scene, content string) error {
+	for _, p := range c.providers {
+		reason, err := p.checkText(scene, content)
+		if err != nil {
+			return err
+		}
+
+		if reason != "" {
+			c.record(p.name(), scene, content, reason)
+
+			return bigmodel.NewErrorSensitiveInfo(errors.New("invalid text"))
+		}
+	}
+
+	return nil
+}
+
+func (c chain) CheckImages(urls []string) error {
+	for _, p := range c.providers {
+		reason, err := p.checkImages(urls)
+		if err != nil {
+			return err
+		}
+
+		if reason != "" {
+			c.record(p.name(), moderation.SceneOutput, strings.Join(urls, ","), reason)
+
+			return bigmodel.NewErrorSensitiveInfo(
+				errors.New("the generated image is illegal, please try again"),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c chain) ReloadBlocklists() {
+	for _, b := range c.blocklists {
+		b.check()
+	}
+}
+
+// record saves the decision of rejecting the content for review.
+func (c chain) record(provider, scene, content, reason string) {
+	if v := []rune(content); len(v) > maxRecordedContentLen {
+		content = string(v[:maxRecordedContentLen]) + "..."
+	}
+
+	err := c.repo.Add(&domain.ModerationDecision{
+		Provider:  provider,
+		Scene:     scene,
+		Content:   content,
+		Reason:    reason,
+		CreatedAt: utils.Now(),
+	})
+	if err != nil {
+		logrus.Errorf(
+			"record moderation decision of %s failed, reason:%s, err:%s",
+			provider, reason, err.Error(),
+		)
+	}
+}
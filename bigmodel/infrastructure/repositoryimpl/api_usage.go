@@ -0,0 +1,85 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+)
+
+func NewApiUsageRepo(m mongodbClient) repository.ApiUsage {
+	return apiUsageRepoImpl{m}
+}
+
+type apiUsageRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl apiUsageRepoImpl) Add(u *domain.ApiUsage) error {
+	filter := bson.M{
+		fieldUser:  u.User.Account(),
+		fieldModel: u.Model.ModelName(),
+		fieldDate:  u.Date,
+	}
+
+	update := bson.M{
+		"$inc": bson.M{
+			"requests":     u.Requests,
+			"input_chars":  u.InputChars,
+			"output_chars": u.OutputChars,
+			"images":       u.Images,
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().UpdateOne(
+			ctx, filter, update, options.Update().SetUpsert(true),
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (impl apiUsageRepoImpl) FindAll(opt *repository.ApiUsageListOption) (
+	r []domain.ApiUsage, err error,
+) {
+	filter := bson.M{
+		fieldDate: bson.M{"$gte": opt.Since},
+	}
+
+	if opt.User != nil {
+		filter[fieldUser] = opt.User.Account()
+	}
+
+	if opt.Model != nil {
+		filter[fieldModel] = opt.Model.ModelName()
+	}
+
+	var v []dApiUsage
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, filter,
+			options.Find().SetSort(bson.D{{Key: fieldDate, Value: 1}, {Key: fieldUser, Value: 1}}),
+			&v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.ApiUsage, len(v))
+	for i := range v {
+		if err = v[i].toApiUsage(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,197 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+func NewChatSessionRepo(m mongodbClient) repository.ChatSession {
+	return chatSessionRepoImpl{m}
+}
+
+type chatSessionRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl chatSessionRepoImpl) docFilter(index *domain.ChatSessionIndex) (bson.M, error) {
+	filter, err := impl.cli.ObjectIdFilter(index.Id)
+	if err != nil {
+		return nil, repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldOwner] = index.Owner.Account()
+
+	return filter, nil
+}
+
+func (impl chatSessionRepoImpl) Add(s *domain.ChatSession) (id string, err error) {
+	doc, err := genDoc(toChatSessionDoc(s))
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().InsertOne(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		if v, ok := r.InsertedID.(primitive.ObjectID); ok {
+			id = v.Hex()
+		}
+
+		return nil
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl chatSessionRepoImpl) Get(index *domain.ChatSessionIndex) (
+	s domain.ChatSession, err error,
+) {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return
+	}
+
+	var v dChatSession
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toChatSession(&s)
+
+	return
+}
+
+func (impl chatSessionRepoImpl) Count(owner types.Account) (n int, err error) {
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().CountDocuments(
+			ctx, bson.M{fieldOwner: owner.Account()},
+		)
+		n = int(v)
+
+		return err
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl chatSessionRepoImpl) List(owner types.Account) (r []domain.ChatSession, err error) {
+	var v []dChatSession
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, bson.M{fieldOwner: owner.Account()},
+			options.Find().
+				SetProjection(bson.M{fieldMessages: 0}).
+				SetSort(bson.M{fieldUpdatedAt: -1}),
+			&v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.ChatSession, len(v))
+	for i := range v {
+		if err = v[i].toChatSession(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl chatSessionRepoImpl) AppendMessages(
+	index *domain.ChatSessionIndex, model domain.ChatModel,
+	msgs []domain.ChatMessage, keep int,
+) error {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return err
+	}
+
+	items := make(bson.A, len(msgs))
+	for i := range msgs {
+		if items[i], err = genDoc(toChatMessageDoc(&msgs[i])); err != nil {
+			return err
+		}
+	}
+
+	update := bson.M{
+		mongoCmdPush: bson.M{
+			fieldMessages: bson.M{"$each": items, "$slice": -keep},
+		},
+		mongoCmdSet: bson.M{
+			fieldModel:     model.ChatModel(),
+			fieldUpdatedAt: utils.Now(),
+		},
+	}
+
+	return impl.update(filter, update)
+}
+
+func (impl chatSessionRepoImpl) UpdateTitle(
+	index *domain.ChatSessionIndex, title domain.ChatSessionTitle,
+) error {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return err
+	}
+
+	return impl.update(filter, bson.M{
+		mongoCmdSet: bson.M{fieldTitle: title.ChatSessionTitle()},
+	})
+}
+
+func (impl chatSessionRepoImpl) update(filter, update bson.M) error {
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err == nil && r.MatchedCount == 0 {
+			err = repoerr.NewErrorResourceNotExists(errDocNotExists)
+		}
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (impl chatSessionRepoImpl) Delete(index *domain.ChatSessionIndex) error {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().DeleteOne(ctx, filter)
+
+		return err
+	}
+
+	return withContext(f)
+}
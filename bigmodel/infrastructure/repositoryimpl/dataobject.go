@@ -18,3 +18,413 @@ func (a *dApiInfo) toApiInfo(d *domain.ApiInfo) (err error) {
 	d.Endpoint = a.Endpoint
 	return
 }
+
+func toChatSessionDoc(s *domain.ChatSession) dChatSession {
+	msgs := make([]dChatMessage, len(s.Messages))
+	for i := range s.Messages {
+		msgs[i] = toChatMessageDoc(&s.Messages[i])
+	}
+
+	return dChatSession{
+		Owner:     s.Owner.Account(),
+		Title:     s.Title.ChatSessionTitle(),
+		Model:     s.Model.ChatModel(),
+		Messages:  msgs,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func toChatMessageDoc(m *domain.ChatMessage) dChatMessage {
+	return dChatMessage{
+		Role:      m.Role,
+		Content:   m.Content,
+		Model:     m.Model,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func (d *dChatSession) toChatSession(s *domain.ChatSession) (err error) {
+	s.Id = d.Id.Hex()
+	s.CreatedAt = d.CreatedAt
+	s.UpdatedAt = d.UpdatedAt
+
+	if s.Owner, err = types.NewAccount(d.Owner); err != nil {
+		return
+	}
+
+	if s.Title, err = domain.NewChatSessionTitle(d.Title); err != nil {
+		return
+	}
+
+	if s.Model, err = domain.NewChatModel(d.Model); err != nil {
+		return
+	}
+
+	s.Messages = make([]domain.ChatMessage, len(d.Messages))
+	for i := range d.Messages {
+		m := &d.Messages[i]
+
+		s.Messages[i] = domain.ChatMessage{
+			Role:      m.Role,
+			Content:   m.Content,
+			Model:     m.Model,
+			CreatedAt: m.CreatedAt,
+		}
+	}
+
+	return
+}
+
+func (d *dApiUsage) toApiUsage(u *domain.ApiUsage) (err error) {
+	if u.User, err = types.NewAccount(d.User); err != nil {
+		return
+	}
+
+	if u.Model, err = domain.NewModelName(d.Model); err != nil {
+		return
+	}
+
+	u.Date = d.Date
+	u.Requests = d.Requests
+	u.InputChars = d.InputChars
+	u.OutputChars = d.OutputChars
+	u.Images = d.Images
+
+	return
+}
+
+func toQueueTaskDoc(t *domain.QueueTask) dQueueTask {
+	d := dQueueTask{
+		Owner:      t.Owner.Account(),
+		Model:      t.Model.ModelName(),
+		Lease:      t.Lease,
+		Status:     t.Status,
+		Reply:      t.Reply,
+		Pictures:   t.Pictures,
+		Error:      t.Error,
+		CreatedAt:  t.CreatedAt,
+		StartedAt:  t.StartedAt,
+		FinishedAt: t.FinishedAt,
+	}
+
+	if t.Model.IsWuKong() {
+		d.Style = t.Picture.Style
+		d.Desc = t.Picture.Desc.WuKongPictureDesc()
+
+		return d
+	}
+
+	input := &t.Input
+
+	d.Messages = make([]dChatMessage, len(input.Messages))
+	for i := range input.Messages {
+		d.Messages[i] = toChatMessageDoc(&input.Messages[i])
+	}
+
+	d.Sampling = toChatSamplingDoc(&input.ChatSampling)
+
+	return d
+}
+
+func toChatSamplingDoc(v *domain.ChatSampling) (d dChatSampling) {
+	d.Sampling = v.Sampling
+
+	if v.TopK != nil {
+		d.TopK = v.TopK.TopK()
+	}
+
+	if v.TopP != nil {
+		d.TopP = v.TopP.TopP()
+	}
+
+	if v.Temperature != nil {
+		d.Temperature = v.Temperature.Temperature()
+	}
+
+	if v.RepetitionPenalty != nil {
+		d.RepetitionPenalty = v.RepetitionPenalty.RepetitionPenalty()
+	}
+
+	return
+}
+
+func (d *dChatSampling) toChatSampling(v *domain.ChatSampling) (err error) {
+	v.Sampling = d.Sampling
+
+	if v.TopK, err = domain.NewTopK(d.TopK); err != nil {
+		return
+	}
+
+	if v.TopP, err = domain.NewTopP(d.TopP); err != nil {
+		return
+	}
+
+	if v.Temperature, err = domain.NewTemperature(d.Temperature); err != nil {
+		return
+	}
+
+	v.RepetitionPenalty, err = domain.NewRepetitionPenalty(d.RepetitionPenalty)
+
+	return
+}
+
+func (d *dQueueTask) toQueueTask(t *domain.QueueTask) (err error) {
+	t.Id = d.Id.Hex()
+	t.Lease = d.Lease
+	t.Status = d.Status
+	t.Reply = d.Reply
+	t.Pictures = d.Pictures
+	t.Error = d.Error
+	t.CreatedAt = d.CreatedAt
+	t.StartedAt = d.StartedAt
+	t.FinishedAt = d.FinishedAt
+
+	if t.Owner, err = types.NewAccount(d.Owner); err != nil {
+		return
+	}
+
+	if t.Model, err = domain.NewModelName(d.Model); err != nil {
+		return
+	}
+
+	if t.Model.IsWuKong() {
+		t.Picture.Style = d.Style
+		t.Picture.Desc, err = domain.NewWuKongPictureDesc(d.Desc)
+
+		return
+	}
+
+	if t.Input.Model, err = domain.NewChatModel(d.Model); err != nil {
+		return
+	}
+
+	if err = d.Sampling.toChatSampling(&t.Input.ChatSampling); err != nil {
+		return
+	}
+
+	t.Input.Messages = make([]domain.ChatMessage, len(d.Messages))
+	for i := range d.Messages {
+		item := &d.Messages[i]
+
+		t.Input.Messages[i] = domain.ChatMessage{
+			Role:      item.Role,
+			Content:   item.Content,
+			Model:     item.Model,
+			CreatedAt: item.CreatedAt,
+		}
+	}
+
+	return
+}
+
+func toModerationIncidentDoc(v *domain.ModerationIncident) dModerationIncident {
+	return dModerationIncident{
+		User:      v.User.Account(),
+		Model:     v.Model,
+		RequestId: v.RequestId,
+		Input:     v.Input,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func toModerationDecisionDoc(v *domain.ModerationDecision) dModerationDecision {
+	return dModerationDecision{
+		Provider:  v.Provider,
+		Scene:     v.Scene,
+		Content:   v.Content,
+		Reason:    v.Reason,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func toWuKongCommentDoc(c *domain.WuKongComment) dWuKongComment {
+	return dWuKongComment{
+		PictureId:    c.Picture.Id,
+		PictureOwner: c.Picture.Owner.Account(),
+		User:         c.User.Account(),
+		Content:      c.Content.WuKongCommentContent(),
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+func (d *dWuKongComment) toWuKongComment(c *domain.WuKongComment) (err error) {
+	if c.Picture.Owner, err = types.NewAccount(d.PictureOwner); err != nil {
+		return
+	}
+
+	if c.User, err = types.NewAccount(d.User); err != nil {
+		return
+	}
+
+	if c.Content, err = domain.NewWuKongCommentContent(d.Content); err != nil {
+		return
+	}
+
+	c.Id = d.Id.Hex()
+	c.Picture.Id = d.PictureId
+	c.CreatedAt = d.CreatedAt
+
+	return
+}
+
+func toWuKongReportDoc(r *domain.WuKongReport) dWuKongReport {
+	return dWuKongReport{
+		PictureId:    r.Picture.Id,
+		PictureOwner: r.Picture.Owner.Account(),
+		Reporter:     r.Reporter.Account(),
+		Reason:       r.Reason,
+		Status:       r.Status,
+		Reviewer:     r.Reviewer,
+		CreatedAt:    r.CreatedAt,
+		ReviewedAt:   r.ReviewedAt,
+	}
+}
+
+func (d *dWuKongReport) toWuKongReport(r *domain.WuKongReport) (err error) {
+	if r.Picture.Owner, err = types.NewAccount(d.PictureOwner); err != nil {
+		return
+	}
+
+	if r.Reporter, err = types.NewAccount(d.Reporter); err != nil {
+		return
+	}
+
+	r.Id = d.Id.Hex()
+	r.Picture.Id = d.PictureId
+	r.Reason = d.Reason
+	r.Status = d.Status
+	r.Reviewer = d.Reviewer
+	r.CreatedAt = d.CreatedAt
+	r.ReviewedAt = d.ReviewedAt
+
+	return
+}
+
+func toPromptVersionDocs(v []domain.PromptVersion) []dPromptVersion {
+	r := make([]dPromptVersion, len(v))
+	for i := range v {
+		r[i] = dPromptVersion{
+			Version:   v[i].Version,
+			Content:   v[i].Content.PromptContent(),
+			CreatedAt: v[i].CreatedAt,
+		}
+	}
+
+	return r
+}
+
+func toPromptTemplateDoc(t *domain.PromptTemplate) dPromptTemplate {
+	return dPromptTemplate{
+		Owner:     t.Owner.Account(),
+		Name:      t.Name.PromptName(),
+		Kind:      t.Kind.PromptKind(),
+		Public:    t.Public,
+		Versions:  toPromptVersionDocs(t.Versions),
+		Likes:     t.Likes,
+		LikedBy:   []string{},
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+		Version:   t.Version,
+	}
+}
+
+func (d *dPromptTemplate) toPromptTemplate(t *domain.PromptTemplate) (err error) {
+	if t.Owner, err = types.NewAccount(d.Owner); err != nil {
+		return
+	}
+
+	if t.Name, err = domain.NewPromptName(d.Name); err != nil {
+		return
+	}
+
+	if t.Kind, err = domain.NewPromptKind(d.Kind); err != nil {
+		return
+	}
+
+	t.Versions = make([]domain.PromptVersion, len(d.Versions))
+	for i := range d.Versions {
+		item := &d.Versions[i]
+
+		if t.Versions[i].Content, err = domain.NewPromptContent(item.Content); err != nil {
+			return
+		}
+
+		t.Versions[i].Version = item.Version
+		t.Versions[i].CreatedAt = item.CreatedAt
+	}
+
+	t.Id = d.Id.Hex()
+	t.Public = d.Public
+	t.Likes = d.Likes
+	t.CreatedAt = d.CreatedAt
+	t.UpdatedAt = d.UpdatedAt
+	t.Version = d.Version
+
+	return
+}
+
+func toWuKongBatchItemDoc(item *domain.WuKongBatchItem) dWuKongBatchItem {
+	pictures := item.Pictures
+	if pictures == nil {
+		pictures = []string{}
+	}
+
+	return dWuKongBatchItem{
+		Desc:     item.Desc.WuKongPictureDesc(),
+		Style:    item.Style,
+		Status:   item.Status,
+		Pictures: pictures,
+		Error:    item.Error,
+	}
+}
+
+func toWuKongBatchJobDoc(job *domain.WuKongBatchJob) dWuKongBatchJob {
+	items := make([]dWuKongBatchItem, len(job.Items))
+	for i := range job.Items {
+		items[i] = toWuKongBatchItemDoc(&job.Items[i])
+	}
+
+	return dWuKongBatchJob{
+		Owner:      job.Owner.Account(),
+		Name:       job.Name,
+		Status:     job.Status,
+		ImageNum:   job.ImageNum,
+		Items:      items,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+		UpdatedAt:  job.CreatedAt,
+	}
+}
+
+func (d *dWuKongBatchJob) toWuKongBatchJob(job *domain.WuKongBatchJob) (err error) {
+	if job.Owner, err = types.NewAccount(d.Owner); err != nil {
+		return
+	}
+
+	job.Items = make([]domain.WuKongBatchItem, len(d.Items))
+	for i := range d.Items {
+		item := &d.Items[i]
+
+		if job.Items[i].Desc, err = domain.NewWuKongPictureDesc(item.Desc); err != nil {
+			return
+		}
+
+		job.Items[i].Style = item.Style
+		job.Items[i].Status = item.Status
+		job.Items[i].Pictures = item.Pictures
+		job.Items[i].Error = item.Error
+	}
+
+	job.Id = d.Id.Hex()
+	job.Name = d.Name
+	job.Status = d.Status
+	job.ImageNum = d.ImageNum
+	job.Lease = d.Lease
+	job.CreatedAt = d.CreatedAt
+	job.StartedAt = d.StartedAt
+	job.FinishedAt = d.FinishedAt
+
+	return
+}
@@ -1,5 +1,7 @@
 package repositoryimpl
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 const (
 	fieldId        = "id"
 	fieldOwner     = "owner"
@@ -15,6 +17,29 @@ const (
 	fieldCallCount = "call_count"
 	fieldToken     = "token"
 	fieldUpdateAt  = "update_at"
+	fieldTitle     = "title"
+	fieldModel     = "model"
+	fieldMessages  = "messages"
+	fieldUpdatedAt = "updated_at"
+	fieldDate      = "date"
+	fieldStatus    = "status"
+	fieldStartedAt = "started_at"
+	fieldFinished  = "finished_at"
+	fieldLease     = "lease"
+	fieldPicture   = "picture_id"
+	fieldReporter  = "reporter"
+	fieldCreatedAt = "created_at"
+	fieldName      = "name"
+	fieldKind      = "kind"
+	fieldPublic    = "public"
+	fieldVersions  = "versions"
+	fieldLikedBy   = "liked_by"
+	fieldTags      = "tags"
+	fieldHidden    = "hidden"
+	fieldLevel     = "level"
+	fieldDesc      = "desc"
+	fieldStyle     = "style"
+	fieldDiggCount = "digg_count"
 )
 
 type DCompetitorInfo struct {
@@ -66,6 +91,8 @@ type pictureItem struct {
 	DiggCount int      `bson:"digg_count" json:"digg_count"`
 	Version   int      `bson:"version"    json:"-"`
 	CreatedAt string   `bson:"created_at" json:"created_at"`
+	Tags      []string `bson:"tags"       json:"tags"`
+	Hidden    bool     `bson:"hidden"     json:"hidden"`
 }
 
 type dApiApply struct {
@@ -85,3 +112,137 @@ type dApiInfo struct {
 	Endpoint string `bson:"endpoint"  json:"endpoint"`
 	Doc      string `bson:"doc"       json:"doc"`
 }
+
+type dChatSession struct {
+	Id        primitive.ObjectID `bson:"_id"        json:"-"`
+	Owner     string             `bson:"owner"      json:"owner"`
+	Title     string             `bson:"title"      json:"title"`
+	Model     string             `bson:"model"      json:"model"`
+	Messages  []dChatMessage     `bson:"messages"   json:"messages"`
+	CreatedAt int64              `bson:"created_at" json:"created_at"`
+	UpdatedAt int64              `bson:"updated_at" json:"updated_at"`
+}
+
+type dChatMessage struct {
+	Role      string `bson:"role"       json:"role"`
+	Content   string `bson:"content"    json:"content"`
+	Model     string `bson:"model"      json:"model,omitempty"`
+	CreatedAt int64  `bson:"created_at" json:"created_at"`
+}
+
+type dApiUsage struct {
+	User        string `bson:"user"         json:"user"`
+	Model       string `bson:"model"        json:"model"`
+	Date        string `bson:"date"         json:"date"`
+	Requests    int    `bson:"requests"     json:"requests"`
+	InputChars  int    `bson:"input_chars"  json:"input_chars"`
+	OutputChars int    `bson:"output_chars" json:"output_chars"`
+	Images      int    `bson:"images"       json:"images"`
+}
+
+type dQueueTask struct {
+	Id         primitive.ObjectID `bson:"_id"         json:"-"`
+	Owner      string             `bson:"owner"       json:"owner"`
+	Model      string             `bson:"model"       json:"model"`
+	Messages   []dChatMessage     `bson:"messages"    json:"messages,omitempty"`
+	Sampling   dChatSampling      `bson:"sampling"    json:"sampling"`
+	Style      string             `bson:"style"       json:"style,omitempty"`
+	Desc       string             `bson:"desc"        json:"desc,omitempty"`
+	Lease      string             `bson:"lease"       json:"lease"`
+	Status     string             `bson:"status"      json:"status"`
+	Reply      string             `bson:"reply"       json:"reply"`
+	Pictures   map[string]string  `bson:"pictures"    json:"pictures,omitempty"`
+	Error      string             `bson:"error"       json:"error"`
+	CreatedAt  int64              `bson:"created_at"  json:"created_at"`
+	StartedAt  int64              `bson:"started_at"  json:"started_at"`
+	FinishedAt int64              `bson:"finished_at" json:"finished_at"`
+}
+
+type dChatSampling struct {
+	Sampling          bool    `bson:"sampling"           json:"sampling"`
+	TopK              int     `bson:"top_k"              json:"top_k"`
+	TopP              float64 `bson:"top_p"              json:"top_p"`
+	Temperature       float64 `bson:"temperature"        json:"temperature"`
+	RepetitionPenalty float64 `bson:"repetition_penalty" json:"repetition_penalty"`
+}
+
+type dModerationIncident struct {
+	User      string `bson:"user"       json:"user"`
+	Model     string `bson:"model"      json:"model"`
+	RequestId string `bson:"request_id" json:"request_id,omitempty"`
+	Input     string `bson:"input"      json:"input"`
+	CreatedAt int64  `bson:"created_at" json:"created_at"`
+}
+
+type dModerationDecision struct {
+	Provider  string `bson:"provider"   json:"provider"`
+	Scene     string `bson:"scene"      json:"scene"`
+	Content   string `bson:"content"    json:"content"`
+	Reason    string `bson:"reason"     json:"reason"`
+	CreatedAt int64  `bson:"created_at" json:"created_at"`
+}
+
+type dWuKongComment struct {
+	Id           primitive.ObjectID `bson:"_id"           json:"-"`
+	PictureId    string             `bson:"picture_id"    json:"picture_id"`
+	PictureOwner string             `bson:"picture_owner" json:"picture_owner"`
+	User         string             `bson:"user"          json:"user"`
+	Content      string             `bson:"content"       json:"content"`
+	CreatedAt    int64              `bson:"created_at"    json:"created_at"`
+}
+
+type dWuKongReport struct {
+	Id           primitive.ObjectID `bson:"_id"           json:"-"`
+	PictureId    string             `bson:"picture_id"    json:"picture_id"`
+	PictureOwner string             `bson:"picture_owner" json:"picture_owner"`
+	Reporter     string             `bson:"reporter"      json:"reporter"`
+	Reason       string             `bson:"reason"        json:"reason"`
+	Status       string             `bson:"status"        json:"status"`
+	Reviewer     string             `bson:"reviewer"      json:"reviewer"`
+	CreatedAt    int64              `bson:"created_at"    json:"created_at"`
+	ReviewedAt   int64              `bson:"reviewed_at"   json:"reviewed_at"`
+}
+
+type dPromptTemplate struct {
+	Id        primitive.ObjectID `bson:"_id"        json:"-"`
+	Owner     string             `bson:"owner"      json:"owner"`
+	Name      string             `bson:"name"       json:"name"`
+	Kind      string             `bson:"kind"       json:"kind"`
+	Public    bool               `bson:"public"     json:"public"`
+	Versions  []dPromptVersion   `bson:"versions"   json:"versions"`
+	Likes     int                `bson:"likes"      json:"likes"`
+	LikedBy   []string           `bson:"liked_by"   json:"liked_by"`
+	CreatedAt int64              `bson:"created_at" json:"created_at"`
+	UpdatedAt int64              `bson:"updated_at" json:"updated_at"`
+	Version   int                `bson:"version"    json:"version"`
+}
+
+type dPromptVersion struct {
+	Version   int    `bson:"version"    json:"version"`
+	Content   string `bson:"content"    json:"content"`
+	CreatedAt int64  `bson:"created_at" json:"created_at"`
+}
+
+type dWuKongBatchJob struct {
+	Id         primitive.ObjectID `bson:"_id"         json:"-"`
+	Owner      string             `bson:"owner"       json:"owner"`
+	Name       string             `bson:"name"        json:"name"`
+	Status     string             `bson:"status"      json:"status"`
+	ImageNum   int                `bson:"image_num"   json:"image_num"`
+	Items      []dWuKongBatchItem `bson:"items"       json:"items"`
+	CreatedAt  int64              `bson:"created_at"  json:"created_at"`
+	StartedAt  int64              `bson:"started_at"  json:"started_at"`
+	FinishedAt int64              `bson:"finished_at" json:"finished_at"`
+	Lease      string             `bson:"lease"       json:"lease"`
+
+	// UpdatedAt is the time when the job made progress last time.
+	UpdatedAt int64 `bson:"updated_at" json:"updated_at"`
+}
+
+type dWuKongBatchItem struct {
+	Desc     string   `bson:"desc"     json:"desc"`
+	Style    string   `bson:"style"    json:"style"`
+	Status   string   `bson:"status"   json:"status"`
+	Pictures []string `bson:"pictures" json:"pictures"`
+	Error    string   `bson:"error"    json:"error"`
+}
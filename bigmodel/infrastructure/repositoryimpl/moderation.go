@@ -0,0 +1,54 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+)
+
+func NewModerationIncidentRepo(m mongodbClient) repository.ModerationIncident {
+	return moderationIncidentRepoImpl{m}
+}
+
+type moderationIncidentRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl moderationIncidentRepoImpl) Add(v *domain.ModerationIncident) error {
+	doc, err := genDoc(toModerationIncidentDoc(v))
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().InsertOne(ctx, doc)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func NewModerationDecisionRepo(m mongodbClient) repository.ModerationDecision {
+	return moderationDecisionRepoImpl{m}
+}
+
+type moderationDecisionRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl moderationDecisionRepoImpl) Add(v *domain.ModerationDecision) error {
+	doc, err := genDoc(toModerationDecisionDoc(v))
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().InsertOne(ctx, doc)
+
+		return err
+	}
+
+	return withContext(f)
+}
@@ -0,0 +1,273 @@
+package repositoryimpl
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+)
+
+// CreatePromptTemplateIndexes creates the unique index of the owner and name
+// of templates, so that a template can't be renamed to the name of another
+// one of the same owner. It does nothing if the index exists already.
+func CreatePromptTemplateIndexes(m mongodbClient) error {
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: fieldOwner, Value: 1},
+			{Key: fieldName, Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+
+	return withContext(func(ctx context.Context) error {
+		_, err := m.Collection().Indexes().CreateOne(ctx, index)
+
+		return err
+	})
+}
+
+func NewPromptTemplateRepo(m mongodbClient) repository.PromptTemplate {
+	return promptTemplateRepoImpl{m}
+}
+
+type promptTemplateRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl promptTemplateRepoImpl) Add(t *domain.PromptTemplate) (string, error) {
+	doc, err := genDoc(toPromptTemplateDoc(t))
+	if err != nil {
+		return "", err
+	}
+
+	filter := bson.M{
+		fieldOwner: t.Owner.Account(),
+		fieldName:  t.Name.PromptName(),
+	}
+
+	var id string
+
+	f := func(ctx context.Context) (err error) {
+		id, err = impl.cli.NewDocIfNotExist(ctx, filter, doc)
+
+		return
+	}
+
+	err = withContext(f)
+	if err != nil && (impl.cli.IsDocExists(err) || mongo.IsDuplicateKeyError(err)) {
+		err = repoerr.NewErrorDuplicateCreating(err)
+	}
+
+	return id, err
+}
+
+func (impl promptTemplateRepoImpl) Get(id string) (t domain.PromptTemplate, err error) {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		err = repoerr.NewErrorResourceNotExists(err)
+
+		return
+	}
+
+	var v dPromptTemplate
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, bson.M{fieldLikedBy: 0}, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toPromptTemplate(&t)
+
+	return
+}
+
+func (impl promptTemplateRepoImpl) List(owner types.Account) ([]domain.PromptTemplate, error) {
+	return impl.list(
+		bson.M{fieldOwner: owner.Account()},
+		bson.M{fieldUpdatedAt: -1},
+	)
+}
+
+func (impl promptTemplateRepoImpl) ListPublic(kind string) ([]domain.PromptTemplate, error) {
+	filter := bson.M{fieldPublic: true}
+	if kind != "" {
+		filter[fieldKind] = kind
+	}
+
+	return impl.list(filter, bson.D{{Key: fieldLikes, Value: -1}, {Key: fieldUpdatedAt, Value: -1}})
+}
+
+func (impl promptTemplateRepoImpl) list(filter bson.M, sort interface{}) (
+	r []domain.PromptTemplate, err error,
+) {
+	var v []dPromptTemplate
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, filter,
+			options.Find().
+				SetProjection(bson.M{fieldLikedBy: 0}).
+				SetSort(sort),
+			&v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.PromptTemplate, len(v))
+	for i := range v {
+		if err = v[i].toPromptTemplate(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl promptTemplateRepoImpl) Save(t *domain.PromptTemplate) error {
+	filter, err := impl.cli.ObjectIdFilter(t.Id)
+	if err != nil {
+		return repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldOwner] = t.Owner.Account()
+	filter[fieldVersion] = t.Version
+
+	docs := toPromptVersionDocs(t.Versions)
+
+	versions := make(bson.A, len(docs))
+	for i := range docs {
+		if versions[i], err = genDoc(docs[i]); err != nil {
+			return err
+		}
+	}
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldName:      t.Name.PromptName(),
+			fieldPublic:    t.Public,
+			fieldVersions:  versions,
+			fieldUpdatedAt: t.UpdatedAt,
+		},
+		"$inc": bson.M{fieldVersion: 1},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			// the unique index of owner and name is violated.
+			if mongo.IsDuplicateKeyError(err) {
+				return repoerr.NewErrorDuplicateCreating(err)
+			}
+
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(
+				errors.New("template is updated or deleted"),
+			)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (impl promptTemplateRepoImpl) Delete(owner types.Account, id string) error {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldOwner] = owner.Account()
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().DeleteOne(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		if r.DeletedCount == 0 {
+			return repoerr.NewErrorResourceNotExists(errDocNotExists)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (impl promptTemplateRepoImpl) Like(user types.Account, id string) error {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldPublic] = true
+
+	return impl.updateLikes(filter, bson.M{fieldLikedBy: bson.M{"$ne": user.Account()}}, bson.M{
+		mongoCmdPush: bson.M{fieldLikedBy: user.Account()},
+		"$inc":       bson.M{fieldLikes: 1},
+	})
+}
+
+func (impl promptTemplateRepoImpl) Unlike(user types.Account, id string) error {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldPublic] = true
+
+	return impl.updateLikes(filter, bson.M{fieldLikedBy: user.Account()}, bson.M{
+		"$pull": bson.M{fieldLikedBy: user.Account()},
+		"$inc":  bson.M{fieldLikes: -1},
+	})
+}
+
+// updateLikes updates the public template of filter if it matches cond.
+// If it doesn't match, it checks whether the template exists.
+func (impl promptTemplateRepoImpl) updateLikes(filter, cond, update bson.M) error {
+	f := func(ctx context.Context) error {
+		v := bson.M{}
+		for k, item := range filter {
+			v[k] = item
+		}
+
+		for k, item := range cond {
+			v[k] = item
+		}
+
+		r, err := impl.cli.Collection().UpdateOne(ctx, v, update)
+		if err != nil || r.MatchedCount > 0 {
+			return err
+		}
+
+		n, err := impl.cli.Collection().CountDocuments(ctx, filter)
+		if err == nil && n == 0 {
+			err = repoerr.NewErrorResourceNotExists(errDocNotExists)
+		}
+
+		return err
+	}
+
+	return withContext(f)
+}
@@ -0,0 +1,278 @@
+package repositoryimpl
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+func NewQueueTaskRepo(m mongodbClient) repository.QueueTask {
+	return queueTaskRepoImpl{m}
+}
+
+type queueTaskRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl queueTaskRepoImpl) docFilter(index *domain.QueueTaskIndex) (bson.M, error) {
+	filter, err := impl.cli.ObjectIdFilter(index.Id)
+	if err != nil {
+		return nil, repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldOwner] = index.Owner.Account()
+
+	return filter, nil
+}
+
+func (impl queueTaskRepoImpl) Add(t *domain.QueueTask) (id string, err error) {
+	doc, err := genDoc(toQueueTaskDoc(t))
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().InsertOne(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		if v, ok := r.InsertedID.(primitive.ObjectID); ok {
+			id = v.Hex()
+		}
+
+		return nil
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl queueTaskRepoImpl) Get(index *domain.QueueTaskIndex) (t domain.QueueTask, err error) {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return
+	}
+
+	var v dQueueTask
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toQueueTask(&t)
+
+	return
+}
+
+func (impl queueTaskRepoImpl) Rank(t *domain.QueueTask) (n int, err error) {
+	oid, err := primitive.ObjectIDFromHex(t.Id)
+	if err != nil {
+		return
+	}
+
+	filter := bson.M{
+		"_id":       bson.M{"$lt": oid},
+		fieldModel:  t.Model.ModelName(),
+		fieldStatus: domain.QueueTaskStatusWaiting,
+	}
+
+	return impl.count(filter)
+}
+
+func (impl queueTaskRepoImpl) CountRunning(model domain.ModelName, staleTime int64) (int, error) {
+	return impl.count(bson.M{
+		fieldModel:     model.ModelName(),
+		fieldStatus:    domain.QueueTaskStatusRunning,
+		fieldStartedAt: bson.M{"$gte": staleTime},
+	})
+}
+
+func (impl queueTaskRepoImpl) Latency(model domain.ModelName, n int) (float64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			fieldModel:  model.ModelName(),
+			fieldStatus: domain.QueueTaskStatusFinished,
+		}}},
+		{{Key: "$sort", Value: bson.M{fieldFinished: -1}}},
+		{{Key: "$limit", Value: n}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"latency": bson.M{"$avg": bson.M{
+				"$subtract": bson.A{"$" + fieldFinished, "$" + fieldStartedAt},
+			}},
+		}}},
+	}
+
+	var v []struct {
+		Latency float64 `bson:"latency"`
+	}
+
+	f := func(ctx context.Context) error {
+		cursor, err := impl.cli.Collection().Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		return cursor.All(ctx, &v)
+	}
+
+	if err := withContext(f); err != nil || len(v) == 0 {
+		return 0, err
+	}
+
+	return v[0].Latency, nil
+}
+
+func (impl queueTaskRepoImpl) CountWaiting(user types.Account) (int, error) {
+	return impl.count(bson.M{
+		fieldOwner:  user.Account(),
+		fieldStatus: domain.QueueTaskStatusWaiting,
+	})
+}
+
+func (impl queueTaskRepoImpl) count(filter bson.M) (n int, err error) {
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().CountDocuments(ctx, filter)
+		n = int(v)
+
+		return err
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl queueTaskRepoImpl) Claim(model domain.ModelName, staleTime int64) (
+	t domain.QueueTask, err error,
+) {
+	filter := bson.M{
+		fieldModel: model.ModelName(),
+		"$or": bson.A{
+			bson.M{fieldStatus: domain.QueueTaskStatusWaiting},
+			bson.M{
+				fieldStatus:    domain.QueueTaskStatusRunning,
+				fieldStartedAt: bson.M{"$lt": staleTime},
+			},
+		},
+	}
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:    domain.QueueTaskStatusRunning,
+			fieldStartedAt: utils.Now(),
+			fieldLease:     primitive.NewObjectID().Hex(),
+		},
+	}
+
+	var v dQueueTask
+
+	f := func(ctx context.Context) error {
+		return impl.cli.Collection().FindOneAndUpdate(
+			ctx, filter, update,
+			options.FindOneAndUpdate().
+				SetSort(bson.M{"_id": 1}).
+				SetReturnDocument(options.After),
+		).Decode(&v)
+	}
+
+	if err = withContext(f); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toQueueTask(&t)
+
+	return
+}
+
+func (impl queueTaskRepoImpl) Cancel(index *domain.QueueTaskIndex) error {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return err
+	}
+
+	filter[fieldStatus] = domain.QueueTaskStatusWaiting
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:   domain.QueueTaskStatusCancelled,
+			fieldFinished: utils.Now(),
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(errors.New("task is not waiting"))
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (impl queueTaskRepoImpl) Finish(t *domain.QueueTask) error {
+	filter, err := impl.cli.ObjectIdFilter(t.Id)
+	if err != nil {
+		return err
+	}
+
+	filter[fieldStatus] = domain.QueueTaskStatusRunning
+	filter[fieldLease] = t.Lease
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:   t.Status,
+			"reply":       t.Reply,
+			"pictures":    t.Pictures,
+			"error":       t.Error,
+			fieldFinished: t.FinishedAt,
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(
+				errors.New("the task is claimed by another worker"),
+			)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
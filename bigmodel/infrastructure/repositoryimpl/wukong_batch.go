@@ -0,0 +1,288 @@
+package repositoryimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+func NewWuKongBatchJobRepo(m mongodbClient) repository.WuKongBatchJob {
+	return wukongBatchJobRepoImpl{m}
+}
+
+type wukongBatchJobRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl wukongBatchJobRepoImpl) docFilter(index *domain.WuKongBatchIndex) (bson.M, error) {
+	filter, err := impl.cli.ObjectIdFilter(index.Id)
+	if err != nil {
+		return nil, repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldOwner] = index.Owner.Account()
+
+	return filter, nil
+}
+
+func (impl wukongBatchJobRepoImpl) Add(job *domain.WuKongBatchJob) (id string, err error) {
+	doc, err := genDoc(toWuKongBatchJobDoc(job))
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().InsertOne(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		if v, ok := r.InsertedID.(primitive.ObjectID); ok {
+			id = v.Hex()
+		}
+
+		return nil
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl wukongBatchJobRepoImpl) Get(index *domain.WuKongBatchIndex) (
+	job domain.WuKongBatchJob, err error,
+) {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return
+	}
+
+	var v dWuKongBatchJob
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toWuKongBatchJob(&job)
+
+	return
+}
+
+func (impl wukongBatchJobRepoImpl) List(owner types.Account) (r []domain.WuKongBatchJob, err error) {
+	var v []dWuKongBatchJob
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, bson.M{fieldOwner: owner.Account()},
+			options.Find().SetSort(bson.M{fieldCreatedAt: -1}),
+			&v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.WuKongBatchJob, len(v))
+	for i := range v {
+		if err = v[i].toWuKongBatchJob(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl wukongBatchJobRepoImpl) CountUndone(owner types.Account) (n int, err error) {
+	filter := bson.M{
+		fieldOwner: owner.Account(),
+		fieldStatus: bson.M{"$in": bson.A{
+			domain.WuKongBatchStatusWaiting,
+			domain.WuKongBatchStatusRunning,
+		}},
+	}
+
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().CountDocuments(ctx, filter)
+		n = int(v)
+
+		return err
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl wukongBatchJobRepoImpl) Claim(staleTime int64) (job domain.WuKongBatchJob, err error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{fieldStatus: domain.WuKongBatchStatusWaiting},
+			bson.M{
+				fieldStatus:    domain.WuKongBatchStatusRunning,
+				fieldUpdatedAt: bson.M{"$lt": staleTime},
+			},
+		},
+	}
+
+	now := utils.Now()
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:    domain.WuKongBatchStatusRunning,
+			fieldStartedAt: now,
+			fieldUpdatedAt: now,
+			fieldLease:     primitive.NewObjectID().Hex(),
+		},
+	}
+
+	var v dWuKongBatchJob
+
+	f := func(ctx context.Context) error {
+		return impl.cli.Collection().FindOneAndUpdate(
+			ctx, filter, update,
+			options.FindOneAndUpdate().
+				SetSort(bson.M{"_id": 1}).
+				SetReturnDocument(options.After),
+		).Decode(&v)
+	}
+
+	if err = withContext(f); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toWuKongBatchJob(&job)
+
+	return
+}
+
+func (impl wukongBatchJobRepoImpl) SaveItem(job *domain.WuKongBatchJob, index int) error {
+	filter, err := impl.cli.ObjectIdFilter(job.Id)
+	if err != nil {
+		return err
+	}
+
+	filter[fieldStatus] = domain.WuKongBatchStatusRunning
+	filter[fieldLease] = job.Lease
+
+	item, err := genDoc(toWuKongBatchItemDoc(&job.Items[index]))
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fmt.Sprintf("%s.%d", fieldItems, index): item,
+			fieldUpdatedAt:                          utils.Now(),
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(
+				errors.New("the job is not running, or it is claimed by another worker"),
+			)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (impl wukongBatchJobRepoImpl) Cancel(index *domain.WuKongBatchIndex) error {
+	filter, err := impl.docFilter(index)
+	if err != nil {
+		return err
+	}
+
+	filter[fieldStatus] = bson.M{"$in": bson.A{
+		domain.WuKongBatchStatusWaiting,
+		domain.WuKongBatchStatusRunning,
+	}}
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:   domain.WuKongBatchStatusCancelled,
+			"finished_at": utils.Now(),
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(errors.New("job is done"))
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (impl wukongBatchJobRepoImpl) Finish(job *domain.WuKongBatchJob) error {
+	filter, err := impl.cli.ObjectIdFilter(job.Id)
+	if err != nil {
+		return err
+	}
+
+	// don't overwrite the cancelled job or the one claimed by another worker.
+	filter[fieldStatus] = domain.WuKongBatchStatusRunning
+	filter[fieldLease] = job.Lease
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:   job.Status,
+			fieldFinished: job.FinishedAt,
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(
+				errors.New("the job is not running, or it is claimed by another worker"),
+			)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
@@ -0,0 +1,412 @@
+package repositoryimpl
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+)
+
+// comment
+func NewWuKongCommentRepo(m mongodbClient) repository.WuKongComment {
+	return wukongCommentRepoImpl{m}
+}
+
+type wukongCommentRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl wukongCommentRepoImpl) Add(c *domain.WuKongComment) (id string, err error) {
+	doc, err := genDoc(toWuKongCommentDoc(c))
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().InsertOne(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		if v, ok := r.InsertedID.(primitive.ObjectID); ok {
+			id = v.Hex()
+		}
+
+		return nil
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl wukongCommentRepoImpl) List(pictureId string) (r []domain.WuKongComment, err error) {
+	var v []dWuKongComment
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, bson.M{fieldPicture: pictureId},
+			options.Find().SetSort(bson.M{fieldCreatedAt: -1}),
+			&v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.WuKongComment, len(v))
+	for i := range v {
+		if err = v[i].toWuKongComment(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl wukongCommentRepoImpl) Delete(user types.Account, id string) error {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldUser] = user.Account()
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().DeleteOne(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		if r.DeletedCount == 0 {
+			return repoerr.NewErrorResourceNotExists(errDocNotExists)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+// report
+func NewWuKongReportRepo(m mongodbClient) repository.WuKongReport {
+	return wukongReportRepoImpl{m}
+}
+
+type wukongReportRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl wukongReportRepoImpl) Add(r *domain.WuKongReport) (string, error) {
+	doc, err := genDoc(toWuKongReportDoc(r))
+	if err != nil {
+		return "", err
+	}
+
+	filter := bson.M{
+		fieldPicture:  r.Picture.Id,
+		fieldReporter: r.Reporter.Account(),
+		fieldStatus:   domain.WuKongReportStatusPending,
+	}
+
+	var id string
+
+	f := func(ctx context.Context) (err error) {
+		id, err = impl.cli.NewDocIfNotExist(ctx, filter, doc)
+
+		return
+	}
+
+	if err = withContext(f); err != nil && impl.cli.IsDocExists(err) {
+		err = repoerr.NewErrorDuplicateCreating(err)
+	}
+
+	return id, err
+}
+
+func (impl wukongReportRepoImpl) Get(id string) (r domain.WuKongReport, err error) {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		err = repoerr.NewErrorResourceNotExists(err)
+
+		return
+	}
+
+	var v dWuKongReport
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toWuKongReport(&r)
+
+	return
+}
+
+func (impl wukongReportRepoImpl) List(status string) (r []domain.WuKongReport, err error) {
+	filter := bson.M{}
+	if status != "" {
+		filter[fieldStatus] = status
+	}
+
+	var v []dWuKongReport
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, filter, options.Find().SetSort(bson.M{fieldCreatedAt: -1}), &v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.WuKongReport, len(v))
+	for i := range v {
+		if err = v[i].toWuKongReport(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl wukongReportRepoImpl) CountPending(pictureId string) (n int, err error) {
+	filter := bson.M{
+		fieldPicture: pictureId,
+		fieldStatus:  domain.WuKongReportStatusPending,
+	}
+
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().CountDocuments(ctx, filter)
+		n = int(v)
+
+		return err
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl wukongReportRepoImpl) Review(r *domain.WuKongReport) error {
+	filter, err := impl.cli.ObjectIdFilter(r.Id)
+	if err != nil {
+		return repoerr.NewErrorResourceNotExists(err)
+	}
+
+	filter[fieldStatus] = domain.WuKongReportStatusPending
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus:   r.Status,
+			"reviewer":    r.Reviewer,
+			"reviewed_at": r.ReviewedAt,
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if v.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(errors.New("report is reviewed"))
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
+// public pictures
+const wukongPublicTextIndex = "publics_text"
+
+// CreateWuKongPictureIndexes creates the text index of the desc and style of
+// public pictures by which they are searched. It does nothing if the index
+// exists already.
+func CreateWuKongPictureIndexes(m mongodbClient) error {
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: fieldPublics + "." + fieldDesc, Value: "text"},
+			{Key: fieldPublics + "." + fieldStyle, Value: "text"},
+		},
+		// the words are not stemmed, because the desc is mostly chinese.
+		Options: options.Index().
+			SetName(wukongPublicTextIndex).
+			SetDefaultLanguage("none"),
+	}
+
+	return withContext(func(ctx context.Context) error {
+		_, err := m.Collection().Indexes().CreateOne(ctx, index)
+
+		return err
+	})
+}
+
+func (impl *wukongPictureRepoImpl) ListVisiblePublicsGlobal(opt *repository.WuKongPublicListOption) (
+	r []domain.WuKongPicture, total int, err error,
+) {
+	pipeline := mongo.Pipeline{}
+
+	if opt.Keyword != "" {
+		// the text index finds the users who have the matched pictures, and
+		// then the pictures are matched one by one after they are unwound.
+		pipeline = append(pipeline, bson.D{{
+			Key: "$match", Value: bson.M{"$text": bson.M{"$search": opt.Keyword}},
+		}})
+	}
+
+	sortBy := bson.D{{Key: fieldCreatedAt, Value: -1}}
+	if opt.SortByDigg {
+		sortBy = append(bson.D{{Key: fieldDiggCount, Value: -1}}, sortBy...)
+	}
+
+	pipeline = append(
+		pipeline,
+		bson.D{{Key: "$unwind", Value: "$" + fieldPublics}},
+		bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$" + fieldPublics}}},
+		bson.D{{Key: "$match", Value: visiblePublicFilter(opt)}},
+		bson.D{{Key: "$sort", Value: sortBy}},
+		bson.D{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "n"}},
+			"items": bson.A{
+				bson.M{"$skip": opt.CountPerPage * (opt.PageNum - 1)},
+				bson.M{"$limit": opt.CountPerPage},
+			},
+		}}},
+	)
+
+	var v []struct {
+		Total []struct {
+			N int `bson:"n"`
+		} `bson:"total"`
+		Items []pictureItem `bson:"items"`
+	}
+
+	f := func(ctx context.Context) error {
+		cursor, err := impl.cli.Collection().Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		return cursor.All(ctx, &v)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 || len(v[0].Total) == 0 {
+		return
+	}
+
+	total = v[0].Total[0].N
+
+	items := v[0].Items
+	r = make([]domain.WuKongPicture, len(items))
+
+	for i := range items {
+		if err = items[i].toWuKongPicture(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// visiblePublicFilter matches the unwound public pictures which are not
+// hidden and meet the option.
+func visiblePublicFilter(opt *repository.WuKongPublicListOption) bson.M {
+	filter := bson.M{fieldHidden: bson.M{"$ne": true}}
+
+	if opt.Level != nil {
+		filter[fieldLevel] = opt.Level.Int()
+	}
+
+	if len(opt.Tags) > 0 {
+		tags := make(bson.A, len(opt.Tags))
+		for i, t := range opt.Tags {
+			tags[i] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(t) + "$", Options: "i"}
+		}
+
+		filter[fieldTags] = bson.M{"$all": tags}
+	}
+
+	// the picture matches if its desc or style contains any word of keyword
+	// like the text search does.
+	if words := strings.Fields(opt.Keyword); len(words) > 0 {
+		for i := range words {
+			words[i] = regexp.QuoteMeta(words[i])
+		}
+
+		re := primitive.Regex{Pattern: strings.Join(words, "|"), Options: "i"}
+
+		filter["$or"] = bson.A{
+			bson.M{fieldDesc: re},
+			bson.M{fieldStyle: re},
+		}
+	}
+
+	return filter
+}
+
+func (impl *wukongPictureRepoImpl) CountVisibleTags() (r []domain.WuKongTagCount, err error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$" + fieldPublics}},
+		{{Key: "$match", Value: bson.M{
+			fieldPublics + "." + fieldHidden: bson.M{"$ne": true},
+		}}},
+		{{Key: "$unwind", Value: "$" + fieldPublics + "." + fieldTags}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$" + fieldPublics + "." + fieldTags,
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "count", Value: -1},
+			{Key: "_id", Value: 1},
+		}}},
+	}
+
+	var v []struct {
+		Tag   string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+
+	f := func(ctx context.Context) error {
+		cursor, err := impl.cli.Collection().Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		return cursor.All(ctx, &v)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.WuKongTagCount, len(v))
+	for i := range v {
+		r[i] = domain.WuKongTagCount{Tag: v[i].Tag, Count: v[i].Count}
+	}
+
+	return
+}
@@ -409,6 +409,8 @@ func (r *pictureItem) toWuKongPicture(d *domain.WuKongPicture) (err error) {
 	d.DiggCount = r.DiggCount
 	d.Version = r.Version
 	d.CreatedAt = r.CreatedAt
+	d.Tags = r.Tags
+	d.Hidden = r.Hidden
 
 	return
 }
@@ -440,6 +442,8 @@ func toPictureItemDoc(d *domain.WuKongPicture) (bson.M, error) {
 		DiggCount: d.DiggCount,
 		Version:   d.Version,
 		CreatedAt: d.CreatedAt,
+		Tags:      d.Tags,
+		Hidden:    d.Hidden,
 	}
 
 	if d.Owner != nil {
@@ -29,6 +29,7 @@ var _ CloudService = (*cloudService)(nil)
 func NewCloudService(
 	cloudRepo repository.Cloud,
 	podRepo repository.Pod,
+	reservationRepo repository.Reservation,
 	producer message.CloudMessageProducer,
 	whitelistRepo userrepo.WhiteList,
 ) *cloudService {
@@ -36,7 +37,7 @@ func NewCloudService(
 		cloudRepo:        cloudRepo,
 		podRepo:          podRepo,
 		producer:         producer,
-		cloudService:     service.NewCloudService(podRepo, producer),
+		cloudService:     service.NewCloudService(podRepo, reservationRepo, producer),
 		whitelistService: userapp.NewWhiteListService(whitelistRepo),
 	}
 }
@@ -101,16 +102,8 @@ func (s *cloudService) SubscribeCloud(cmd *SubscribeCloudCmd) (code string, err
 	}
 
 	// whitelist
-	if cloudConf.IsNPU() {
-		useNPU, useMultiNPU, err := s.whitelistService.CheckCloudWhitelist(cmd.User)
-		if err != nil {
-			return "", err
-		}
-
-		if (cmd.CardsNum.CloudSpecCardsNum() > 1 && !useMultiNPU) ||
-			(cmd.CardsNum.CloudSpecCardsNum() == 1 && !useNPU) {
-			return errorWhitelistNotAllowed, errors.New("not in cloud whitelist")
-		}
+	if code, err = s.checkWhitelist(&cloudConf, cmd.User, cmd.CardsNum); err != nil {
+		return
 	}
 
 	// check
@@ -130,32 +123,55 @@ func (s *cloudService) SubscribeCloud(cmd *SubscribeCloudCmd) (code string, err
 	c.CloudConf = cloudConf
 
 	// check
-	if err = s.cloudService.ToCloud(c); err != nil {
+	if code, err = s.checkIdle(c, cmd.CardsNum); err != nil {
 		return
 	}
 
-	deduction := cmd.CardsNum.CloudSpecCardsNum()
+	// subscribe
+	_, err = s.cloudService.SubscribeCloud(&c.CloudConf, cmd.User, cmd.ImageAlias, cmd.CardsNum, 0)
+
+	return
+}
+
+// checkIdle checks whether the cloud has enough idle cards for the pod.
+func (s *cloudService) checkIdle(c *domain.Cloud, cardsNum domain.CloudSpecCardsNum) (string, error) {
+	if err := s.cloudService.ToCloud(c); err != nil {
+		return "", err
+	}
+
+	deduction := cardsNum.CloudSpecCardsNum()
 
 	singleCardBusy := deduction == 1 && !c.HasSingleCardIdle()
 	if singleCardBusy {
-		code = errorResourceBusy
-		err = errors.New("no idle resource remain")
-
-		return
+		return errorResourceBusy, errors.New("no idle resource remain")
 	}
 
 	multiCardsBusy := deduction > 1 && !c.HasMultiCardsIdle(deduction)
 	if multiCardsBusy {
-		code = errorResourceBusy
-		err = errors.New("no idle cards remain")
+		return errorResourceBusy, errors.New("no idle cards remain")
+	}
 
-		return
+	return "", nil
+}
+
+func (s *cloudService) checkWhitelist(
+	c *domain.CloudConf, user types.Account, cardsNum domain.CloudSpecCardsNum,
+) (string, error) {
+	if !c.IsNPU() {
+		return "", nil
 	}
 
-	// subscribe
-	err = s.cloudService.SubscribeCloud(&c.CloudConf, cmd.User, cmd.ImageAlias, cmd.CardsNum)
+	useNPU, useMultiNPU, err := s.whitelistService.CheckCloudWhitelist(user)
+	if err != nil {
+		return "", err
+	}
 
-	return
+	if (cardsNum.CloudSpecCardsNum() > 1 && !useMultiNPU) ||
+		(cardsNum.CloudSpecCardsNum() == 1 && !useNPU) {
+		return errorWhitelistNotAllowed, errors.New("not in cloud whitelist")
+	}
+
+	return "", nil
 }
 
 func (s *cloudService) ReleaseCloud(cmd *ReleaseCloudCmd) error {
@@ -172,8 +188,12 @@ func (s *cloudService) ReleaseCloud(cmd *ReleaseCloudCmd) error {
 		return ErrCloudReleased
 	}
 
+	return s.releasePod(&podInfo)
+}
+
+func (s *cloudService) releasePod(podInfo *domain.PodInfo) error {
 	podInfo.StatusSetTerminating()
-	if err := s.podRepo.UpdatePod(&podInfo); err != nil {
+	if err := s.podRepo.UpdatePod(podInfo); err != nil {
 		return err
 	}
 
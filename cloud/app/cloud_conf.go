@@ -0,0 +1,217 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/cloud/domain"
+	"github.com/opensourceways/xihe-server/cloud/domain/repository"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+)
+
+// CloudConfService manages the cloud configs for admin. The configs are
+// read from repo on each request, so the changes of them, such as the
+// capacity, take effect without restarting the server.
+type CloudConfService interface {
+	List() ([]CloudConfDetailDTO, error)
+	Create(*CloudConfCreateCmd) (CloudConfDetailDTO, string, error)
+	Update(*CloudConfUpdateCmd) (CloudConfDetailDTO, string, error)
+	UpdateImages(*CloudImagesUpdateCmd) (CloudConfDetailDTO, string, error)
+	UpdateSpecs(*CloudSpecsUpdateCmd) (CloudConfDetailDTO, string, error)
+}
+
+func NewCloudConfService(
+	cloudRepo repository.Cloud,
+	podRepo repository.Pod,
+) CloudConfService {
+	return &cloudConfService{
+		cloudRepo: cloudRepo,
+		podRepo:   podRepo,
+	}
+}
+
+type cloudConfService struct {
+	cloudRepo repository.Cloud
+	podRepo   repository.Pod
+}
+
+func (s *cloudConfService) List() ([]CloudConfDetailDTO, error) {
+	confs, err := s.cloudRepo.ListCloudConf()
+	if err != nil || len(confs) == 0 {
+		return nil, err
+	}
+
+	dtos := make([]CloudConfDetailDTO, len(confs))
+	for i := range confs {
+		dtos[i].toCloudConfDetailDTO(&confs[i])
+	}
+
+	return dtos, nil
+}
+
+func (s *cloudConfService) Create(cmd *CloudConfCreateCmd) (
+	dto CloudConfDetailDTO, code string, err error,
+) {
+	if err = cmd.Conf.Validate(); err != nil {
+		code = errorCloudConfInvalid
+
+		return
+	}
+
+	if err = s.cloudRepo.AddCloudConf(&cmd.Conf); err != nil {
+		if repoerr.IsErrorDuplicateCreating(err) {
+			code = errorCloudConfExists
+		}
+
+		return
+	}
+
+	logrus.Infof(
+		"cloud conf %s is created by %s, single limited: %d, multi limited: %d",
+		cmd.Conf.Id, cmd.Admin.Account(),
+		cmd.Conf.SingleLimited.CloudLimited(), cmd.Conf.MultiLimited.CloudLimited(),
+	)
+
+	dto.toCloudConfDetailDTO(&cmd.Conf)
+
+	return
+}
+
+func (s *cloudConfService) Update(cmd *CloudConfUpdateCmd) (
+	dto CloudConfDetailDTO, code string, err error,
+) {
+	conf, code, err := s.getVersion(cmd.Id, cmd.Version)
+	if err != nil {
+		return
+	}
+
+	old := conf
+	if !cmd.toCloudConf(&conf) {
+		dto.toCloudConfDetailDTO(&conf)
+
+		return
+	}
+
+	if code, err = s.checkAndSave(&conf); err != nil {
+		return
+	}
+
+	logrus.Infof(
+		"cloud conf %s is updated by %s, single limited: %d -> %d, multi limited: %d -> %d, credit: %d -> %d",
+		conf.Id, cmd.Admin.Account(),
+		old.SingleLimited.CloudLimited(), conf.SingleLimited.CloudLimited(),
+		old.MultiLimited.CloudLimited(), conf.MultiLimited.CloudLimited(),
+		old.Credit.Credit(), conf.Credit.Credit(),
+	)
+
+	dto.toCloudConfDetailDTO(&conf)
+
+	return
+}
+
+func (s *cloudConfService) UpdateImages(cmd *CloudImagesUpdateCmd) (
+	dto CloudConfDetailDTO, code string, err error,
+) {
+	conf, code, err := s.getVersion(cmd.Id, cmd.Version)
+	if err != nil {
+		return
+	}
+
+	conf.Images = cmd.Images
+
+	if code, err = s.checkAndSave(&conf); err != nil {
+		return
+	}
+
+	logrus.Infof(
+		"images of cloud conf %s are updated by %s, images: %v",
+		conf.Id, cmd.Admin.Account(), cmd.imageAliases(),
+	)
+
+	dto.toCloudConfDetailDTO(&conf)
+
+	return
+}
+
+func (s *cloudConfService) UpdateSpecs(cmd *CloudSpecsUpdateCmd) (
+	dto CloudConfDetailDTO, code string, err error,
+) {
+	conf, code, err := s.getVersion(cmd.Id, cmd.Version)
+	if err != nil {
+		return
+	}
+
+	conf.Specs = cmd.Specs
+
+	if code, err = s.checkAndSave(&conf); err != nil {
+		return
+	}
+
+	logrus.Infof(
+		"specs of cloud conf %s are updated by %s, cards num: %v",
+		conf.Id, cmd.Admin.Account(), cmd.cardsNums(),
+	)
+
+	dto.toCloudConfDetailDTO(&conf)
+
+	return
+}
+
+func (s *cloudConfService) get(cid string) (conf domain.CloudConf, code string, err error) {
+	if conf, err = s.cloudRepo.GetCloudConf(cid); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = errorCloudConfNotFound
+		}
+	}
+
+	return
+}
+
+// getVersion gets the config only if it is still the version which the
+// admin read, and the version is kept in it to be checked again on saving.
+func (s *cloudConfService) getVersion(cid string, version int) (
+	conf domain.CloudConf, code string, err error,
+) {
+	if conf, code, err = s.get(cid); err != nil {
+		return
+	}
+
+	if conf.Version != version {
+		code, err = errorCloudConfConcurrent, errCloudConfChanged
+	}
+
+	return
+}
+
+// checkAndSave saves the config if it is valid and the images, specs and
+// limits of it still support the pods which are holding.
+func (s *cloudConfService) checkAndSave(conf *domain.CloudConf) (string, error) {
+	if err := conf.Validate(); err != nil {
+		return errorCloudConfInvalid, err
+	}
+
+	pods, err := s.podRepo.GetRunningPod(conf.Id)
+	if err != nil {
+		return "", err
+	}
+
+	if err := conf.CheckPodsSupported(pods.PodInfos); err != nil {
+		return errorCloudConfInUse, err
+	}
+
+	return s.save(conf)
+}
+
+func (s *cloudConfService) save(conf *domain.CloudConf) (string, error) {
+	err := s.cloudRepo.UpdateCloudConf(conf)
+	if err == nil {
+		conf.Version++
+
+		return "", nil
+	}
+
+	if repoerr.IsErrorConcurrentUpdating(err) {
+		return errorCloudConfConcurrent, errCloudConfChanged
+	}
+
+	return "", err
+}
@@ -0,0 +1,49 @@
+package app
+
+import "time"
+
+type ReservationConfig struct {
+	// SlotDuration is the length of time slot which can be reserved, it
+	// should be the same as the survival time of pod. The unit is second.
+	SlotDuration int64 `json:"slot_duration"`
+
+	// GraceWindow specifies how long the started pod of reservation waits
+	// for the user to check in before it is released. The unit is second.
+	GraceWindow int64 `json:"grace_window"`
+
+	// MaxDaysAhead is the max num of days a slot can be reserved ahead.
+	MaxDaysAhead int `json:"max_days_ahead"`
+
+	// MaxBookedNum is the max num of booked reservations of a user.
+	MaxBookedNum int `json:"max_booked_num"`
+
+	// PollInterval specifies the interval to start and release the pods
+	// of reservations. The unit is second.
+	PollInterval int `json:"poll_interval"`
+}
+
+func (cfg *ReservationConfig) SetDefault() {
+	if cfg.SlotDuration <= 0 {
+		cfg.SlotDuration = 2 * 60 * 60
+	}
+
+	if cfg.GraceWindow <= 0 {
+		cfg.GraceWindow = 15 * 60
+	}
+
+	if cfg.MaxDaysAhead <= 0 {
+		cfg.MaxDaysAhead = 7
+	}
+
+	if cfg.MaxBookedNum <= 0 {
+		cfg.MaxBookedNum = 2
+	}
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30
+	}
+}
+
+func (cfg *ReservationConfig) ScheduleInterval() time.Duration {
+	return time.Duration(cfg.PollInterval) * time.Second
+}
@@ -197,3 +197,170 @@ func (c *ReleaseCloudCmd) Validate() error {
 type GetReleasedPodCmd struct {
 	PodId string
 }
+
+type CloudConfCreateCmd struct {
+	Admin types.Account
+	Conf  domain.CloudConf
+}
+
+// CloudConfUpdateCmd updates the basic info and the capacity of cloud,
+// the nil fields are not changed. Version is the one of the config which
+// the admin read, the update fails if the config has been changed since.
+type CloudConfUpdateCmd struct {
+	Admin         types.Account
+	Id            string
+	Version       int
+	Name          domain.CloudName
+	Feature       domain.CloudFeature
+	Processor     domain.CloudProcessor
+	SingleLimited domain.CloudLimited
+	MultiLimited  domain.CloudLimited
+	Credit        domain.Credit
+}
+
+func (cmd *CloudConfUpdateCmd) toCloudConf(c *domain.CloudConf) (changed bool) {
+	if cmd.Name != nil {
+		c.Name = cmd.Name
+		changed = true
+	}
+
+	if cmd.Feature != nil {
+		c.Feature = cmd.Feature
+		changed = true
+	}
+
+	if cmd.Processor != nil {
+		c.Processor = cmd.Processor
+		changed = true
+	}
+
+	if cmd.SingleLimited != nil {
+		c.SingleLimited = cmd.SingleLimited
+		changed = true
+	}
+
+	if cmd.MultiLimited != nil {
+		c.MultiLimited = cmd.MultiLimited
+		changed = true
+	}
+
+	if cmd.Credit != nil {
+		c.Credit = cmd.Credit
+		changed = true
+	}
+
+	return
+}
+
+type CloudImagesUpdateCmd struct {
+	Admin   types.Account
+	Id      string
+	Version int
+	Images  []domain.CloudImage
+}
+
+func (cmd *CloudImagesUpdateCmd) imageAliases() []string {
+	v := make([]string, len(cmd.Images))
+	for i := range cmd.Images {
+		v[i] = cmd.Images[i].Alias.CloudImageAlias()
+	}
+
+	return v
+}
+
+type CloudSpecsUpdateCmd struct {
+	Admin   types.Account
+	Id      string
+	Version int
+	Specs   []domain.CloudSpec
+}
+
+func (cmd *CloudSpecsUpdateCmd) cardsNums() []int {
+	v := make([]int, len(cmd.Specs))
+	for i := range cmd.Specs {
+		v[i] = cmd.Specs[i].CardsNum.CloudSpecCardsNum()
+	}
+
+	return v
+}
+
+type CloudImageDTO struct {
+	Alias string `json:"alias"`
+	Image string `json:"image"`
+}
+
+// CloudConfDetailDTO is the cloud config with the images and
+// capacity which are only visible to admin.
+type CloudConfDetailDTO struct {
+	CloudConfDTO
+
+	Images        []CloudImageDTO `json:"images"`
+	SingleLimited int             `json:"single_limited"`
+	MultiLimited  int             `json:"multi_limited"`
+	Version       int             `json:"version"`
+}
+
+func (r *CloudConfDetailDTO) toCloudConfDetailDTO(c *domain.CloudConf) {
+	r.CloudConfDTO.toCloudConfDTO(c)
+
+	r.SingleLimited = c.SingleLimited.CloudLimited()
+	r.MultiLimited = c.MultiLimited.CloudLimited()
+	r.Version = c.Version
+
+	r.Images = make([]CloudImageDTO, len(c.Images))
+	for i := range c.Images {
+		r.Images[i] = CloudImageDTO{
+			Alias: c.Images[i].Alias.CloudImageAlias(),
+			Image: c.Images[i].Image.Image(),
+		}
+	}
+}
+
+type ReserveCmd struct {
+	User       types.Account
+	CloudId    string
+	ImageAlias domain.CloudImageAlias
+	CardsNum   domain.CloudSpecCardsNum
+	StartAt    int64
+}
+
+type ReservationSlotsCmd struct {
+	CloudId  string
+	CardsNum domain.CloudSpecCardsNum
+}
+
+type ReservationDTO struct {
+	Id           string `json:"id"`
+	CloudId      string `json:"cloud_id"`
+	Image        string `json:"image"`
+	CardsNum     int    `json:"cards_num"`
+	StartAt      int64  `json:"start_at"`
+	EndAt        int64  `json:"end_at"`
+	CheckInUntil int64  `json:"check_in_until"`
+	Status       string `json:"status"`
+	PodId        string `json:"pod_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+func (r *ReservationDTO) toReservationDTO(v *domain.Reservation, grace int64) {
+	*r = ReservationDTO{
+		Id:           v.Id,
+		CloudId:      v.CloudId,
+		Image:        v.ImageAlias.CloudImageAlias(),
+		CardsNum:     v.CardsNum.CloudSpecCardsNum(),
+		StartAt:      v.StartAt,
+		EndAt:        v.EndAt,
+		CheckInUntil: v.StartAt + grace,
+		Status:       v.Status,
+		PodId:        v.PodId,
+		Error:        v.Error,
+		CreatedAt:    v.CreatedAt,
+	}
+}
+
+type ReservationSlotDTO struct {
+	StartAt int64 `json:"start_at"`
+	EndAt   int64 `json:"end_at"`
+	Remain  int   `json:"remain"`
+}
@@ -6,10 +6,22 @@ const (
 	errorResourceBusy        = "cloud_resource_busy"
 	errorNotAllowed          = "cloud_not_allowed"
 	errorWhitelistNotAllowed = "not_allowed"
+	errorCloudConfNotFound   = "cloud_conf_not_found"
+	errorCloudConfExists     = "cloud_conf_exists"
+	errorCloudConfInvalid    = "cloud_conf_invalid"
+	errorCloudConfInUse      = "cloud_conf_in_use"
+	errorCloudConfConcurrent = "cloud_conf_concurrent_updating"
+	errorReservationNotFound = "cloud_reservation_not_found"
+	errorReservationInvalid  = "cloud_reservation_invalid"
+	errorReservationConflict = "cloud_reservation_conflict"
+	errorReservationLimited  = "cloud_reservation_limited"
+	errorReservationStatus   = "cloud_reservation_status_changed"
 )
 
 var (
 	ErrCloudReleased   = errors.New("cloud was released")
 	ErrCloudNotAllowed = errors.New("not allowed")
 	ErrPodNotFound     = errors.New("not found")
+
+	errCloudConfChanged = errors.New("cloud conf has been changed, try again")
 )
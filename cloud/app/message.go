@@ -1,6 +1,8 @@
 package app
 
 import (
+	"fmt"
+
 	"github.com/opensourceways/xihe-server/cloud/domain"
 	"github.com/opensourceways/xihe-server/cloud/domain/cloud"
 	"github.com/opensourceways/xihe-server/cloud/domain/message"
@@ -41,14 +43,21 @@ type cloudMessageService struct {
 func (c *cloudMessageService) CreatePodInstance(p *domain.PodInfo) error {
 	// create pod instance by SDK
 	logrus.Infof("send create pod info: %#v", p)
-	survivalTime := p.Expiry.PodExpiry()
+	now := utils.Now()
+
+	survivalTime := c.survivalTimeForPodAscend
 	if p.IsCpu() {
 		survivalTime = c.survivalTimeForPodCPU
-	} else if p.IsAscend() {
-		survivalTime = c.survivalTimeForPodAscend
 	}
 
-	expire, err := domain.NewPodExpiry(utils.Now() + survivalTime)
+	// the pod of reservation expires at the end of its slot.
+	if p.Expiry != nil {
+		if survivalTime = p.Expiry.PodExpiry() - now; survivalTime <= 0 {
+			return fmt.Errorf("pod %s has expired", p.Id)
+		}
+	}
+
+	expire, err := domain.NewPodExpiry(now + survivalTime)
 	if err != nil {
 		return err
 	}
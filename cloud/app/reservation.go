@@ -0,0 +1,487 @@
+package app
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/cloud/domain"
+	"github.com/opensourceways/xihe-server/cloud/domain/repository"
+	commondomain "github.com/opensourceways/xihe-server/common/domain"
+	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+type ReservationService interface {
+	Reserve(*ReserveCmd) (ReservationDTO, string, error)
+	List(types.Account) ([]ReservationDTO, error)
+	ListSlots(*ReservationSlotsCmd) ([]ReservationSlotDTO, string, error)
+	Cancel(*domain.ReservationIndex) (string, error)
+	CheckIn(*domain.ReservationIndex) (ReservationDTO, string, error)
+
+	// Schedule starts the pods of reservations at the slots and releases
+	// them if the users don't check in. It should be called periodically.
+	Schedule()
+}
+
+// NewReservationService creates the service whose reservations take the
+// cards of slots from the counter, so that the concurrent reservations
+// can't exceed the limits of cloud.
+func NewReservationService(
+	cs *cloudService,
+	repo repository.Reservation,
+	counter commonrepo.UsageCounter,
+	cfg *ReservationConfig,
+) ReservationService {
+	return &reservationService{
+		cs:      cs,
+		repo:    repo,
+		counter: counter,
+		cfg:     *cfg,
+	}
+}
+
+type reservationService struct {
+	cs      *cloudService
+	repo    repository.Reservation
+	counter commonrepo.UsageCounter
+	cfg     ReservationConfig
+}
+
+func (s *reservationService) Reserve(cmd *ReserveCmd) (
+	dto ReservationDTO, code string, err error,
+) {
+	conf, err := s.cs.cloudRepo.GetCloudConf(cmd.CloudId)
+	if err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = errorCloudConfNotFound
+		}
+
+		return
+	}
+
+	if code, err = s.checkReservation(&conf, cmd); err != nil {
+		return
+	}
+
+	if code, err = s.cs.checkWhitelist(&conf, cmd.User, cmd.CardsNum); err != nil {
+		return
+	}
+
+	r := domain.Reservation{
+		Owner:      cmd.User,
+		CloudId:    cmd.CloudId,
+		ImageAlias: cmd.ImageAlias,
+		CardsNum:   cmd.CardsNum,
+		StartAt:    cmd.StartAt,
+		EndAt:      cmd.StartAt + s.cfg.SlotDuration,
+		Status:     domain.ReservationStatusBooked,
+		CreatedAt:  utils.Now(),
+	}
+
+	if code, err = s.checkOwnReservations(&r); err != nil {
+		return
+	}
+
+	if code, err = s.checkOwnPod(&r); err != nil {
+		return
+	}
+
+	if code, err = s.holdSlot(&conf, &r); err != nil {
+		return
+	}
+
+	if r.Id, err = s.repo.Add(&r); err != nil {
+		s.releaseSlot(&r)
+
+		return
+	}
+
+	dto.toReservationDTO(&r, s.cfg.GraceWindow)
+
+	return
+}
+
+func (s *reservationService) checkReservation(conf *domain.CloudConf, cmd *ReserveCmd) (
+	string, error,
+) {
+	if _, err := conf.GetImage(cmd.ImageAlias.CloudImageAlias()); err != nil {
+		return errorReservationInvalid, err
+	}
+
+	if _, err := conf.GetSpecDesc(cmd.CardsNum.CloudSpecCardsNum()); err != nil {
+		return errorReservationInvalid, err
+	}
+
+	if cmd.StartAt%s.cfg.SlotDuration != 0 {
+		return errorReservationInvalid, errors.New("invalid start time of slot")
+	}
+
+	now := utils.Now()
+	if cmd.StartAt <= now || cmd.StartAt > now+int64(s.cfg.MaxDaysAhead)*24*3600 {
+		return errorReservationInvalid, errors.New("the slot can't be reserved")
+	}
+
+	return "", nil
+}
+
+func (s *reservationService) checkOwnReservations(r *domain.Reservation) (string, error) {
+	v, err := s.repo.List(r.Owner)
+	if err != nil {
+		return "", err
+	}
+
+	n := 0
+	for i := range v {
+		item := &v[i]
+
+		if !item.IsBooked() {
+			continue
+		}
+
+		if n++; n >= s.cfg.MaxBookedNum {
+			return errorReservationLimited, errors.New("too many reservations")
+		}
+
+		if item.CloudId == r.CloudId && item.IsOverlapped(r.StartAt, r.EndAt) {
+			return errorReservationConflict, errors.New("the slot has been reserved")
+		}
+	}
+
+	return "", nil
+}
+
+// checkOwnPod checks whether the pod of user is still running in the slot.
+// The slot is in the future, so the pod overlaps with it only if it runs
+// until the start of slot. The pod which will expire before the slot doesn't
+// matter, and it is checked again when the pod of reservation starts.
+func (s *reservationService) checkOwnPod(r *domain.Reservation) (string, error) {
+	p, err := s.cs.podRepo.GetUserCloudIdLastPod(r.Owner, r.CloudId)
+	if err != nil {
+		if commonrepo.IsErrorResourceNotExists(err) {
+			err = nil
+		}
+
+		return "", err
+	}
+
+	if p.MayRunAt(r.StartAt) {
+		return errorReservationConflict, errors.New("the pod is running in the slot")
+	}
+
+	return "", nil
+}
+
+// holdSlot takes the cards of reservation from the counter of its slot.
+// The counter is limited by the cards which are not held by the pods
+// running into the slot, so the reservations booked concurrently can't
+// exceed the limit of cloud.
+func (s *reservationService) holdSlot(conf *domain.CloudConf, r *domain.Reservation) (
+	string, error,
+) {
+	pods, err := s.cs.podRepo.GetRunningPod(conf.Id)
+	if err != nil {
+		return "", err
+	}
+
+	n := r.CardsNum.CloudSpecCardsNum()
+
+	// check it first, because the counter doesn't limit if free is 0.
+	free := conf.SlotRemain(r.StartAt, r.EndAt, n, pods.PodInfos, nil)
+	if free < n {
+		return errorResourceBusy, errors.New("no idle cards remain in the slot")
+	}
+
+	i, err := s.counter.Admit(s.slotLimits(r, free))
+	if err != nil || i < 0 {
+		return "", err
+	}
+
+	return errorResourceBusy, errors.New("no idle cards remain in the slot")
+}
+
+// releaseSlot gives back the cards of reservation which is not booked.
+func (s *reservationService) releaseSlot(r *domain.Reservation) {
+	if err := s.counter.Release(s.slotLimits(r, 0)); err != nil {
+		logrus.Errorf("release slot of cloud reservation %s failed, err:%s", r.Id, err.Error())
+	}
+}
+
+// slotLimits returns the limit of the cards of the same kind as the
+// reservation in its slot. The slots are aligned, so the reservations
+// overlapped are in the same slot.
+func (s *reservationService) slotLimits(r *domain.Reservation, max int) []commondomain.UsageLimit {
+	kind := "single"
+	if r.IsMulti() {
+		kind = "multi"
+	}
+
+	return []commondomain.UsageLimit{{
+		Key: "cloud/reservation/" + r.CloudId + "/" + kind,
+		Window: commondomain.UsageWindow{
+			Name:   "s" + strconv.FormatInt(r.StartAt, 10),
+			Expiry: r.EndAt,
+		},
+		Max: max,
+		Num: r.CardsNum.CloudSpecCardsNum(),
+	}}
+}
+
+func (s *reservationService) List(user types.Account) ([]ReservationDTO, error) {
+	v, err := s.repo.List(user)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	dtos := make([]ReservationDTO, len(v))
+	for i := range v {
+		dtos[i].toReservationDTO(&v[i], s.cfg.GraceWindow)
+	}
+
+	return dtos, nil
+}
+
+func (s *reservationService) ListSlots(cmd *ReservationSlotsCmd) (
+	dtos []ReservationSlotDTO, code string, err error,
+) {
+	conf, err := s.cs.cloudRepo.GetCloudConf(cmd.CloudId)
+	if err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = errorCloudConfNotFound
+		}
+
+		return
+	}
+
+	cardsNum := cmd.CardsNum.CloudSpecCardsNum()
+	if _, err = conf.GetSpecDesc(cardsNum); err != nil {
+		code = errorReservationInvalid
+
+		return
+	}
+
+	pods, err := s.cs.podRepo.GetRunningPod(conf.Id)
+	if err != nil {
+		return
+	}
+
+	now := utils.Now()
+
+	rs, err := s.repo.ListBooked(conf.Id, now)
+	if err != nil {
+		return
+	}
+
+	d := s.cfg.SlotDuration
+	end := now + int64(s.cfg.MaxDaysAhead)*24*3600
+
+	for start := (now/d + 1) * d; start <= end; start += d {
+		dtos = append(dtos, ReservationSlotDTO{
+			StartAt: start,
+			EndAt:   start + d,
+			Remain:  conf.SlotRemain(start, start+d, cardsNum, pods.PodInfos, rs),
+		})
+	}
+
+	return
+}
+
+func (s *reservationService) Cancel(index *domain.ReservationIndex) (string, error) {
+	r, code, err := s.get(index)
+	if err != nil {
+		return code, err
+	}
+
+	if !r.IsBooked() {
+		return errorReservationStatus, errors.New("the reservation can't be cancelled")
+	}
+
+	r.Status = domain.ReservationStatusCancelled
+
+	if code, err := s.updateStatus(&r, domain.ReservationStatusBooked); err != nil {
+		return code, err
+	}
+
+	s.releaseSlot(&r)
+
+	return "", nil
+}
+
+func (s *reservationService) CheckIn(index *domain.ReservationIndex) (
+	dto ReservationDTO, code string, err error,
+) {
+	r, code, err := s.get(index)
+	if err != nil {
+		return
+	}
+
+	if r.Status != domain.ReservationStatusStarted || r.PodId == "" {
+		code, err = errorReservationStatus, errors.New("the pod of reservation is not started")
+
+		return
+	}
+
+	r.Status = domain.ReservationStatusAttended
+
+	if code, err = s.updateStatus(&r, domain.ReservationStatusStarted); err != nil {
+		return
+	}
+
+	dto.toReservationDTO(&r, s.cfg.GraceWindow)
+
+	return
+}
+
+func (s *reservationService) get(index *domain.ReservationIndex) (
+	r domain.Reservation, code string, err error,
+) {
+	if r, err = s.repo.Get(index); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = errorReservationNotFound
+		}
+	}
+
+	return
+}
+
+func (s *reservationService) updateStatus(r *domain.Reservation, old string) (string, error) {
+	err := s.repo.UpdateStatus(r, old)
+	if err != nil && repoerr.IsErrorConcurrentUpdating(err) {
+		return errorReservationStatus, err
+	}
+
+	return "", err
+}
+
+func (s *reservationService) Schedule() {
+	s.startDue()
+
+	s.releaseAbsent()
+}
+
+// startDue starts the pods of reservations whose slots are coming.
+func (s *reservationService) startDue() {
+	for {
+		r, err := s.repo.Claim(utils.Now())
+		if err != nil {
+			if !repoerr.IsErrorResourceNotExists(err) {
+				logrus.Errorf("claim cloud reservation failed, err:%s", err.Error())
+			}
+
+			return
+		}
+
+		if err := s.startPod(&r); err != nil {
+			r.Status = domain.ReservationStatusFailed
+			r.Error = err.Error()
+		}
+
+		if err := s.repo.UpdateStatus(&r, domain.ReservationStatusStarted); err != nil {
+			logrus.Errorf("update cloud reservation %s failed, err:%s", r.Id, err.Error())
+		}
+	}
+}
+
+func (s *reservationService) startPod(r *domain.Reservation) error {
+	// the slot was missed, such as the server was down.
+	if utils.Now() >= r.StartAt+s.cfg.GraceWindow {
+		r.Status = domain.ReservationStatusReleased
+
+		return nil
+	}
+
+	conf, err := s.cs.cloudRepo.GetCloudConf(r.CloudId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.cs.checkWhitelist(&conf, r.Owner, r.CardsNum); err != nil {
+		return err
+	}
+
+	_, ok, err := s.cs.cloudService.CheckUserCanSubscribe(r.Owner, r.CloudId)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("starting or running pod exist")
+	}
+
+	// the pods may not be released in time, such as the one subscribed
+	// before the reservation held the cards.
+	if _, err := s.cs.checkIdle(&domain.Cloud{CloudConf: conf}, r.CardsNum); err != nil {
+		return err
+	}
+
+	// the pod expires at the end of slot no matter when it is started.
+	r.PodId, err = s.cs.cloudService.SubscribeCloud(
+		&conf, r.Owner, r.ImageAlias, r.CardsNum, r.EndAt,
+	)
+	if err == nil {
+		logrus.Infof("start pod %s for cloud reservation %s", r.PodId, r.Id)
+	}
+
+	return err
+}
+
+// releaseAbsent releases the pods of reservations whose users don't check
+// in within the grace window.
+func (s *reservationService) releaseAbsent() {
+	v, err := s.repo.ListStarted(utils.Now() - s.cfg.GraceWindow)
+	if err != nil {
+		logrus.Errorf("list started cloud reservations failed, err:%s", err.Error())
+
+		return
+	}
+
+	for i := range v {
+		if err := s.release(&v[i]); err != nil {
+			logrus.Errorf("release cloud reservation %s failed, err:%s", v[i].Id, err.Error())
+		}
+	}
+}
+
+func (s *reservationService) release(r *domain.Reservation) error {
+	if r.PodId == "" {
+		r.Status = domain.ReservationStatusReleased
+
+		return s.repo.UpdateStatus(r, domain.ReservationStatusStarted)
+	}
+
+	p, err := s.cs.podRepo.GetPodInfo(r.PodId)
+	if err != nil {
+		return err
+	}
+
+	// wait until the pod is created.
+	if p.Status.IsStarting() || p.Status.IsCreating() {
+		return nil
+	}
+
+	r.Status = domain.ReservationStatusReleased
+	if p.Status.IsFailed() {
+		r.Status = domain.ReservationStatusFailed
+		r.Error = p.Error.PodError()
+	}
+
+	// the user may check in at the same time.
+	if err := s.repo.UpdateStatus(r, domain.ReservationStatusStarted); err != nil {
+		if repoerr.IsErrorConcurrentUpdating(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !p.CanRelease() {
+		return nil
+	}
+
+	logrus.Infof("release pod %s of cloud reservation %s", p.Id, r.Id)
+
+	return s.cs.releasePod(&p)
+}
@@ -22,6 +22,7 @@ type CloudConf struct {
 	SingleLimited CloudLimited
 	MultiLimited  CloudLimited
 	Credit        Credit
+	Version       int
 }
 
 type CloudImage struct {
@@ -82,3 +83,83 @@ func (c *CloudConf) GetImageAlias(image string) (CloudImageAlias, error) {
 
 	return nil, fmt.Errorf("%s doesn't exist", image)
 }
+
+// Validate checks the specs and images of cloud config which are
+// changed by admin.
+func (c *CloudConf) Validate() error {
+	if len(c.Specs) == 0 {
+		return errors.New("missing specs")
+	}
+
+	if len(c.Images) == 0 {
+		return errors.New("missing images")
+	}
+
+	nums := map[int]struct{}{}
+	for i := range c.Specs {
+		n := c.Specs[i].CardsNum.CloudSpecCardsNum()
+		if _, ok := nums[n]; ok {
+			return fmt.Errorf("duplicate spec of %d cards", n)
+		}
+
+		nums[n] = struct{}{}
+	}
+
+	aliases := map[string]struct{}{}
+	for i := range c.Images {
+		alias := c.Images[i].Alias.CloudImageAlias()
+		if _, ok := aliases[alias]; ok {
+			return fmt.Errorf("duplicate image alias: %s", alias)
+		}
+
+		aliases[alias] = struct{}{}
+	}
+
+	return nil
+}
+
+// CheckPodsSupported checks whether the images and specs used by the pods
+// which are holding are still in the cloud config, otherwise the pods
+// can't be shown. It also checks whether the limits are still enough for
+// the cards held by them.
+func (c *CloudConf) CheckPodsSupported(pods []PodInfo) error {
+	var single, multi int
+
+	for i := range pods {
+		p := &pods[i]
+
+		if p.Expiry == nil || p.Status == nil || !p.IsHoldingAndNotExpired() {
+			continue
+		}
+
+		if _, err := c.GetImageAlias(p.Image); err != nil {
+			return fmt.Errorf("image %s is used by running pod", p.Image)
+		}
+
+		if p.CardsNum == nil {
+			continue
+		}
+
+		n := p.CardsNum.CloudSpecCardsNum()
+
+		if _, err := c.GetSpecDesc(n); err != nil {
+			return fmt.Errorf("spec of %d cards is used by running pod", n)
+		}
+
+		if n > 1 {
+			multi += n
+		} else {
+			single += n
+		}
+	}
+
+	if v := c.SingleLimited.CloudLimited(); v < single {
+		return fmt.Errorf("single limited %d is less than %d cards held by running pods", v, single)
+	}
+
+	if v := c.MultiLimited.CloudLimited(); v < multi {
+		return fmt.Errorf("multi limited %d is less than %d cards held by running pods", v, multi)
+	}
+
+	return nil
+}
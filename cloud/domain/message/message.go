@@ -12,6 +12,7 @@ type MsgCloudConf struct {
 	CloudName     string `json:"cloud_name"`
 	CloudImage    string `json:"cloud_image"`
 	CloudCardsNum int    `json:"cloud_cards_num"`
+	Expiry        int64  `json:"expiry,omitempty"`
 }
 
 type MsgPod struct {
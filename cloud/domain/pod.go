@@ -40,6 +40,11 @@ func (p *PodInfo) IsFailedOrTerminated() bool {
 	return p.Status.IsFailed() || p.IsTerminated()
 }
 
+// MayRunAt checks whether the pod may still be running at the unix time.
+func (p *PodInfo) MayRunAt(t int64) bool {
+	return !p.IsFailedOrTerminated() && t < p.Expiry.PodExpiry()
+}
+
 func (p *PodInfo) IsHoldingAndNotExpired() bool {
 	if p.IsExpired() {
 		return false
@@ -85,9 +90,12 @@ func (p *PodInfo) SetStatus() {
 	}
 }
 
-func (p *PodInfo) SetDefaultExpiry() (err error) {
-	if p.Expiry, err = NewPodExpiry(utils.Now() + 2*60*60); err != nil { // TODO config
-		return
+// SetExpiry sets the expiry of pod if it is positive, such as the end of
+// slot for the pod of reservation. Otherwise, the expiry is set by the
+// survival time of the cloud when the pod instance is created.
+func (p *PodInfo) SetExpiry(expiry int64) (err error) {
+	if expiry > 0 {
+		p.Expiry, err = NewPodExpiry(expiry)
 	}
 
 	return
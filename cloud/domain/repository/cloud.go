@@ -5,4 +5,6 @@ import "github.com/opensourceways/xihe-server/cloud/domain"
 type Cloud interface {
 	ListCloudConf() ([]domain.CloudConf, error)
 	GetCloudConf(cid string) (domain.CloudConf, error)
+	AddCloudConf(*domain.CloudConf) error
+	UpdateCloudConf(*domain.CloudConf) error
 }
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/cloud/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type Reservation interface {
+	Add(*domain.Reservation) (string, error)
+	Get(*domain.ReservationIndex) (domain.Reservation, error)
+	List(owner types.Account) ([]domain.Reservation, error)
+
+	// ListBooked lists the booked reservations of the cloud which end
+	// after the time.
+	ListBooked(cid string, endAfter int64) ([]domain.Reservation, error)
+
+	// ListStarted lists the started reservations which start before the time.
+	ListStarted(startBefore int64) ([]domain.Reservation, error)
+
+	// Claim changes the status of the first booked reservation which
+	// starts before the time to started and returns it.
+	Claim(startBefore int64) (domain.Reservation, error)
+
+	// UpdateStatus changes the status of reservation if its status is
+	// still the old one.
+	UpdateStatus(r *domain.Reservation, old string) error
+}
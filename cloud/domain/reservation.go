@@ -0,0 +1,97 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+const (
+	ReservationStatusBooked    = "booked"
+	ReservationStatusStarted   = "started"
+	ReservationStatusAttended  = "attended"
+	ReservationStatusReleased  = "released"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusFailed    = "failed"
+)
+
+// Reservation books the cards of cloud for the time slot [StartAt, EndAt).
+// The pod is started automatically at StartAt, and it will be released if
+// the user doesn't check in within the grace window.
+type Reservation struct {
+	Id         string
+	Owner      types.Account
+	CloudId    string
+	ImageAlias CloudImageAlias
+	CardsNum   CloudSpecCardsNum
+	StartAt    int64
+	EndAt      int64
+	Status     string
+	PodId      string
+	Error      string
+	CreatedAt  int64
+}
+
+type ReservationIndex struct {
+	Owner types.Account
+	Id    string
+}
+
+func (r *Reservation) IsBooked() bool {
+	return r.Status == ReservationStatusBooked
+}
+
+func (r *Reservation) IsMulti() bool {
+	return r.CardsNum.CloudSpecCardsNum() > 1
+}
+
+// IsHolding checks whether the reservation holds the cards at the time.
+// The booked reservation holds the cards since one slot before it starts,
+// so that the pods subscribed at that time will not run into the slot.
+// It doesn't hold any more once started, because the pod holds instead.
+func (r *Reservation) IsHolding(now int64) bool {
+	return r.IsBooked() && now < r.EndAt && now >= r.StartAt-(r.EndAt-r.StartAt)
+}
+
+func (r *Reservation) IsOverlapped(start, end int64) bool {
+	return r.StartAt < end && start < r.EndAt
+}
+
+// SlotRemain returns the num of cards of the same kind as cardsNum which are
+// still free in the slot [start, end). The pods which are still running at
+// the start of slot and the reservations overlapped with the slot are deducted.
+func (c *CloudConf) SlotRemain(
+	start, end int64, cardsNum int, pods []PodInfo, rs []Reservation,
+) int {
+	multi := cardsNum > 1
+
+	used := 0
+	for i := range pods {
+		p := &pods[i]
+
+		if p.IsExpired() || p.Expiry.PodExpiry() <= start {
+			continue
+		}
+
+		if n := p.CardsNum.CloudSpecCardsNum(); (n > 1) == multi {
+			used += n
+		}
+	}
+
+	for i := range rs {
+		r := &rs[i]
+
+		if r.IsBooked() && r.IsMulti() == multi && r.IsOverlapped(start, end) {
+			used += r.CardsNum.CloudSpecCardsNum()
+		}
+	}
+
+	limit := c.SingleLimited.CloudLimited()
+	if multi {
+		limit = c.MultiLimited.CloudLimited()
+	}
+
+	if remain := limit - used; remain > 0 {
+		return remain
+	}
+
+	return 0
+}
@@ -7,25 +7,29 @@ import (
 	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
 	types "github.com/opensourceways/xihe-server/domain"
 	userdomain "github.com/opensourceways/xihe-server/user/domain"
+	"github.com/opensourceways/xihe-server/utils"
 )
 
 type CloudService struct {
-	podRepo repository.Pod
-	sender  message.CloudMessageProducer
+	podRepo         repository.Pod
+	reservationRepo repository.Reservation
+	sender          message.CloudMessageProducer
 }
 
 func NewCloudService(
 	pod repository.Pod,
+	reservation repository.Reservation,
 	sender message.CloudMessageProducer,
 ) CloudService {
 	return CloudService{
 		pod,
+		reservation,
 		sender,
 	}
 }
 
 func (r *CloudService) calculateRemain(
-	c *domain.Cloud, p *repository.PodInfoList,
+	c *domain.Cloud, p *repository.PodInfoList, rs []domain.Reservation,
 ) (err error) {
 	// calculate running and not expiry pod
 	var singleCount, multiCount int
@@ -40,6 +44,20 @@ func (r *CloudService) calculateRemain(
 		}
 	}
 
+	// the cards held by the reservations
+	now := utils.Now()
+	for i := range rs {
+		if !rs[i].IsHolding(now) {
+			continue
+		}
+
+		if count := rs[i].CardsNum.CloudSpecCardsNum(); count == 1 {
+			singleCount += count
+		} else {
+			multiCount += count
+		}
+	}
+
 	var remain int
 	remain = c.SingleLimited.CloudLimited() - singleCount
 	if remain < 0 {
@@ -66,12 +84,20 @@ func (r *CloudService) ToCloud(c *domain.Cloud) (err error) {
 		return
 	}
 
-	return r.calculateRemain(c, &plist)
+	rs, err := r.reservationRepo.ListBooked(c.CloudConf.Id, utils.Now())
+	if err != nil {
+		return
+	}
+
+	return r.calculateRemain(c, &plist, rs)
 }
 
+// SubscribeCloud starts a pod which expires at the unix time if it is
+// positive, otherwise after the survival time of the cloud.
 func (r *CloudService) SubscribeCloud(
 	c *domain.CloudConf, u types.Account, imageAlias domain.CloudImageAlias, cardsNum domain.CloudSpecCardsNum,
-) (err error) {
+	expiry int64,
+) (pid string, err error) {
 	image, err := c.GetImage(imageAlias.CloudImageAlias())
 	if err != nil {
 		return
@@ -79,11 +105,14 @@ func (r *CloudService) SubscribeCloud(
 
 	// save into repo
 	p := new(domain.PodInfo)
-	if err := p.SetStartingPodInfo(c.Id, u, image, cardsNum); err != nil {
-		return err
+	if err = p.SetStartingPodInfo(c.Id, u, image, cardsNum); err != nil {
+		return
+	}
+
+	if err = p.SetExpiry(expiry); err != nil {
+		return
 	}
 
-	var pid string
 	if pid, err = r.podRepo.AddStartingPod(p); err != nil {
 		return
 	}
@@ -91,8 +120,11 @@ func (r *CloudService) SubscribeCloud(
 	// send msg to call pod instance api
 	msg := new(message.MsgCloudConf)
 	msg.ToMsgCloudConf(c, u, pid, image, cardsNum)
+	msg.Expiry = expiry
 
-	return r.sender.SubscribeCloud(msg)
+	err = r.sender.SubscribeCloud(msg)
+
+	return
 }
 
 func (r *CloudService) CheckUserCanSubscribe(user types.Account, cid string) (
@@ -2,6 +2,7 @@ package repositoryimpl
 
 import (
 	"context"
+	"errors"
 
 	"go.mongodb.org/mongo-driver/bson"
 
@@ -61,6 +62,66 @@ func (impl *cloudRepoImpl) GetCloudConf(cid string) (conf domain.CloudConf, err
 	return
 }
 
+func (impl *cloudRepoImpl) AddCloudConf(conf *domain.CloudConf) error {
+	doc, err := genDoc(toCloudConfDoc(conf))
+	if err != nil {
+		return err
+	}
+
+	doc[fieldVersion] = 0
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.NewDocIfNotExist(ctx, impl.docIdFilter(conf.Id), doc)
+
+		return err
+	}
+
+	if err = withContext(f); err != nil && impl.cli.IsDocExists(err) {
+		err = repoerr.NewErrorDuplicateCreating(err)
+	}
+
+	return err
+}
+
+func (impl *cloudRepoImpl) UpdateCloudConf(conf *domain.CloudConf) error {
+	doc, err := genDoc(toCloudConfDoc(conf))
+	if err != nil {
+		return err
+	}
+
+	delete(doc, fieldId)
+
+	filter := impl.docIdFilter(conf.Id)
+	if conf.Version == 0 {
+		// the configs created by hand have no version.
+		filter[fieldVersion] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter[fieldVersion] = conf.Version
+	}
+
+	update := bson.M{
+		mongoCmdSet: doc,
+		mongoCmdInc: bson.M{fieldVersion: 1},
+	}
+
+	f := func(ctx context.Context) error {
+		r, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if r.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(
+				errors.New("cloud config has been changed"),
+			)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
+
 func (impl *cloudRepoImpl) docIdFilter(id string) bson.M {
 	return bson.M{
 		fieldId: id,
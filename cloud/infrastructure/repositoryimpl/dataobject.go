@@ -11,10 +11,46 @@ const (
 	fieldCloudId = "cloud_id"
 	fieldStatus  = "status"
 	fieldOwner   = "owner"
+	fieldVersion = "version"
+	fieldStartAt = "start_at"
+	fieldEndAt   = "end_at"
+	fieldPodId   = "pod_id"
+	fieldError   = "error"
 )
 
+func toCloudConfDoc(c *domain.CloudConf) DCloudConf {
+	doc := DCloudConf{
+		Id:            c.Id,
+		Name:          c.Name.CloudName(),
+		Feature:       c.Feature.CloudFeature(),
+		Processor:     c.Processor.CloudProcessor(),
+		SingleLimited: c.SingleLimited.CloudLimited(),
+		MultiLimited:  c.MultiLimited.CloudLimited(),
+		Credit:        c.Credit.Credit(),
+	}
+
+	doc.Specs = make([]SpecDO, len(c.Specs))
+	for i := range c.Specs {
+		doc.Specs[i] = SpecDO{
+			Desc:     c.Specs[i].Desc.CloudSpecDesc(),
+			CardsNum: c.Specs[i].CardsNum.CloudSpecCardsNum(),
+		}
+	}
+
+	doc.Images = make([]ImageDO, len(c.Images))
+	for i := range c.Images {
+		doc.Images[i] = ImageDO{
+			Alias: c.Images[i].Alias.CloudImageAlias(),
+			Image: c.Images[i].Image.Image(),
+		}
+	}
+
+	return doc
+}
+
 func (doc *DCloudConf) toCloudConf(c *domain.CloudConf) (err error) {
 	c.Id = doc.Id
+	c.Version = doc.Version
 
 	if c.Name, err = domain.NewCloudName(doc.Name); err != nil {
 		return
@@ -146,3 +182,43 @@ func (table *TPod) toTPod(p *domain.PodInfo) {
 		table.CardsNum = p.CardsNum.CloudSpecCardsNum()
 	}
 }
+
+func toReservationDoc(r *domain.Reservation) DReservation {
+	return DReservation{
+		Owner:     r.Owner.Account(),
+		CloudId:   r.CloudId,
+		Image:     r.ImageAlias.CloudImageAlias(),
+		CardsNum:  r.CardsNum.CloudSpecCardsNum(),
+		StartAt:   r.StartAt,
+		EndAt:     r.EndAt,
+		Status:    r.Status,
+		PodId:     r.PodId,
+		Error:     r.Error,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func (doc *DReservation) toReservation(r *domain.Reservation) (err error) {
+	*r = domain.Reservation{
+		Id:        doc.Id.Hex(),
+		CloudId:   doc.CloudId,
+		StartAt:   doc.StartAt,
+		EndAt:     doc.EndAt,
+		Status:    doc.Status,
+		PodId:     doc.PodId,
+		Error:     doc.Error,
+		CreatedAt: doc.CreatedAt,
+	}
+
+	if r.Owner, err = otypes.NewAccount(doc.Owner); err != nil {
+		return
+	}
+
+	if r.ImageAlias, err = domain.NewCloudImageAlias(doc.Image); err != nil {
+		return
+	}
+
+	r.CardsNum, err = domain.NewCloudSpecCardsNum(doc.CardsNum)
+
+	return
+}
@@ -1,5 +1,7 @@
 package repositoryimpl
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 type DCloudConf struct {
 	Id            string    `bson:"id"                json:"id"`
 	Name          string    `bson:"name"              json:"name"`
@@ -10,6 +12,7 @@ type DCloudConf struct {
 	SingleLimited int       `bson:"single_limited"    json:"single_limited"`
 	MultiLimited  int       `bson:"multi_limited"     json:"multi_limited"`
 	Credit        int64     `bson:"credit"            json:"credit"`
+	Version       int       `bson:"version"           json:"-"`
 }
 
 type ImageDO struct {
@@ -21,3 +24,17 @@ type SpecDO struct {
 	Desc     string `bson:"desc" json:"desc"`
 	CardsNum int    `bson:"cards_num" json:"cards_num"`
 }
+
+type DReservation struct {
+	Id        primitive.ObjectID `bson:"_id"         json:"-"`
+	Owner     string             `bson:"owner"       json:"owner"`
+	CloudId   string             `bson:"cloud_id"    json:"cloud_id"`
+	Image     string             `bson:"image"       json:"image"`
+	CardsNum  int                `bson:"cards_num"   json:"cards_num"`
+	StartAt   int64              `bson:"start_at"    json:"start_at"`
+	EndAt     int64              `bson:"end_at"      json:"end_at"`
+	Status    string             `bson:"status"      json:"status"`
+	PodId     string             `bson:"pod_id"      json:"pod_id"`
+	Error     string             `bson:"error"       json:"error"`
+	CreatedAt int64              `bson:"created_at"  json:"created_at"`
+}
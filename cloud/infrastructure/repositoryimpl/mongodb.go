@@ -2,19 +2,31 @@ package repositoryimpl
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const (
+	mongoCmdSet = "$set"
+	mongoCmdInc = "$inc"
+)
+
 type mongodbClient interface {
+	Collection() *mongo.Collection
+
 	IsDocNotExists(error) bool
 	IsDocExists(error) bool
+	ObjectIdFilter(s string) (bson.M, error)
 
 	GetDoc(ctx context.Context, filterOfDoc, project bson.M, result interface{}) error
 
 	GetDocs(ctx context.Context, filterOfDoc bson.M, opts *options.FindOptions, result interface{}) error
+
+	NewDocIfNotExist(ctx context.Context, filterOfDoc, docInfo bson.M) (string, error)
 }
 
 func withContext(f func(context.Context) error) error {
@@ -26,3 +38,14 @@ func withContext(f func(context.Context) error) error {
 
 	return f(ctx)
 }
+
+func genDoc(doc interface{}) (m bson.M, err error) {
+	v, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(v, &m)
+
+	return
+}
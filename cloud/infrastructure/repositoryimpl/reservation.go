@@ -0,0 +1,200 @@
+package repositoryimpl
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/cloud/domain"
+	"github.com/opensourceways/xihe-server/cloud/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+)
+
+func NewReservationRepo(m mongodbClient) repository.Reservation {
+	return &reservationRepoImpl{m}
+}
+
+type reservationRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl *reservationRepoImpl) Add(r *domain.Reservation) (id string, err error) {
+	doc, err := genDoc(toReservationDoc(r))
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().InsertOne(ctx, doc)
+		if err != nil {
+			return err
+		}
+
+		if oid, ok := v.InsertedID.(primitive.ObjectID); ok {
+			id = oid.Hex()
+		}
+
+		return nil
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl *reservationRepoImpl) Get(index *domain.ReservationIndex) (
+	r domain.Reservation, err error,
+) {
+	filter, err := impl.cli.ObjectIdFilter(index.Id)
+	if err != nil {
+		err = repoerr.NewErrorResourceNotExists(err)
+
+		return
+	}
+
+	filter[fieldOwner] = index.Owner.Account()
+
+	var v DReservation
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toReservation(&r)
+
+	return
+}
+
+func (impl *reservationRepoImpl) List(owner types.Account) ([]domain.Reservation, error) {
+	return impl.list(
+		bson.M{fieldOwner: owner.Account()},
+		options.Find().SetSort(bson.M{fieldStartAt: -1}),
+	)
+}
+
+func (impl *reservationRepoImpl) ListBooked(cid string, endAfter int64) (
+	[]domain.Reservation, error,
+) {
+	return impl.list(
+		bson.M{
+			fieldCloudId: cid,
+			fieldStatus:  domain.ReservationStatusBooked,
+			fieldEndAt:   bson.M{"$gt": endAfter},
+		},
+		nil,
+	)
+}
+
+func (impl *reservationRepoImpl) ListStarted(startBefore int64) ([]domain.Reservation, error) {
+	return impl.list(
+		bson.M{
+			fieldStatus:  domain.ReservationStatusStarted,
+			fieldStartAt: bson.M{"$lte": startBefore},
+		},
+		nil,
+	)
+}
+
+func (impl *reservationRepoImpl) list(filter bson.M, opts *options.FindOptions) (
+	r []domain.Reservation, err error,
+) {
+	var v []DReservation
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(ctx, filter, opts, &v)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.Reservation, len(v))
+	for i := range v {
+		if err = v[i].toReservation(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl *reservationRepoImpl) Claim(startBefore int64) (r domain.Reservation, err error) {
+	filter := bson.M{
+		fieldStatus:  domain.ReservationStatusBooked,
+		fieldStartAt: bson.M{"$lte": startBefore},
+	}
+
+	update := bson.M{
+		mongoCmdSet: bson.M{fieldStatus: domain.ReservationStatusStarted},
+	}
+
+	var v DReservation
+
+	f := func(ctx context.Context) error {
+		return impl.cli.Collection().FindOneAndUpdate(
+			ctx, filter, update,
+			options.FindOneAndUpdate().
+				SetSort(bson.M{fieldStartAt: 1}).
+				SetReturnDocument(options.After),
+		).Decode(&v)
+	}
+
+	if err = withContext(f); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toReservation(&r)
+
+	return
+}
+
+func (impl *reservationRepoImpl) UpdateStatus(r *domain.Reservation, old string) error {
+	filter, err := impl.cli.ObjectIdFilter(r.Id)
+	if err != nil {
+		return err
+	}
+
+	filter[fieldStatus] = old
+
+	update := bson.M{
+		mongoCmdSet: bson.M{
+			fieldStatus: r.Status,
+			fieldPodId:  r.PodId,
+			fieldError:  r.Error,
+		},
+	}
+
+	f := func(ctx context.Context) error {
+		v, err := impl.cli.Collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if v.MatchedCount == 0 {
+			return repoerr.NewErrorConcurrentUpdating(
+				errors.New("the status of reservation has been changed"),
+			)
+		}
+
+		return nil
+	}
+
+	return withContext(f)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "github.com/opensourceways/xihe-server/common/domain"
+
+// UsageCounter counts the requests in the windows for limiting. The counts
+// are stored in the shared store, so the limits hold no matter how many
+// instances of server are running.
+type UsageCounter interface {
+	// Admit counts a request in all the limits only if none of them has
+	// been reached, so the rejected requests are never counted. It returns
+	// the index of the limit reached, or -1 if the request is admitted.
+	Admit([]domain.UsageLimit) (int, error)
+
+	// Release uncounts the request admitted in all the limits.
+	Release([]domain.UsageLimit) error
+
+	// Sum returns the num of requests of the key in the windows which
+	// expire after the unix time.
+	Sum(key string, after int64) (int, error)
+
+	// Clean removes the counts of the windows which expired before the unix time.
+	Clean(before int64) error
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const usageDateLayout = "2006-01-02"
+
+// UsageDate returns the date in UTC of the unix time t. The daily usages
+// of all the services are accounted by it.
+func UsageDate(t int64) string {
+	return time.Unix(t, 0).UTC().Format(usageDateLayout)
+}
+
+// UsageMonthStart returns the first date of the month of date.
+func UsageMonthStart(date string) string {
+	return date[:len("2006-01")] + "-01"
+}
+
+// UsageWindow is the period in which the requests are counted.
+type UsageWindow struct {
+	Name   string
+	Expiry int64
+}
+
+// MinuteWindow returns the minute the unix time t is in.
+func MinuteWindow(t int64) UsageWindow {
+	m := t / 60
+
+	return UsageWindow{
+		Name:   "m" + time.Unix(m*60, 0).UTC().Format("200601021504"),
+		Expiry: (m + 1) * 60,
+	}
+}
+
+// DayWindow returns the day in UTC the unix time t is in.
+func DayWindow(t int64) UsageWindow {
+	d := t / 86400
+
+	return UsageWindow{
+		Name:   "d" + UsageDate(t),
+		Expiry: (d + 1) * 86400,
+	}
+}
+
+// MonthWindow returns the month in UTC the unix time t is in.
+func MonthWindow(t int64) UsageWindow {
+	v := time.Unix(t, 0).UTC()
+
+	return UsageWindow{
+		Name:   "M" + v.Format("200601"),
+		Expiry: time.Date(v.Year(), v.Month()+1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+}
+
+// TotalWindow never expires, in which the resources held by a user are
+// counted until they are released.
+func TotalWindow() UsageWindow {
+	return UsageWindow{
+		Name:   "total",
+		Expiry: math.MaxInt64,
+	}
+}
+
+// UsageLimit is the max num of requests of the key in the window.
+// The requests are only counted if Max is not positive.
+type UsageLimit struct {
+	Key    string
+	Window UsageWindow
+	Max    int
+
+	// Num is the num counted for each request, such as the cards of a
+	// reservation. It is 1 if not positive.
+	Num int
+}
+
+// Count returns the num counted for each request.
+func (l *UsageLimit) Count() int {
+	if l.Num > 0 {
+		return l.Num
+	}
+
+	return 1
+}
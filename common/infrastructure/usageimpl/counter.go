@@ -0,0 +1,206 @@
+package usageimpl
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/common/domain"
+	"github.com/opensourceways/xihe-server/common/domain/repository"
+)
+
+const (
+	fieldId     = "_id"
+	fieldKey    = "key"
+	fieldCount  = "count"
+	fieldWindow = "window"
+	fieldExpiry = "expiry"
+
+	mongoCmdInc         = "$inc"
+	mongoCmdLt          = "$lt"
+	mongoCmdLte         = "$lte"
+	mongoCmdGt          = "$gt"
+	mongoCmdSetOnInsert = "$setOnInsert"
+)
+
+type mongodbClient interface {
+	Collection() *mongo.Collection
+}
+
+type dCounter struct {
+	Key    string `bson:"key"     json:"key"`
+	Window string `bson:"window"  json:"window"`
+	Count  int    `bson:"count"   json:"count"`
+	Expiry int64  `bson:"expiry"  json:"expiry"`
+}
+
+// NewUsageCounter stores the count of each key and window in a document
+// whose id is made of them, so that the concurrent requests in a new
+// window can't create two documents.
+func NewUsageCounter(m mongodbClient) repository.UsageCounter {
+	return usageCounter{m}
+}
+
+type usageCounter struct {
+	cli mongodbClient
+}
+
+func (impl usageCounter) Admit(limits []domain.UsageLimit) (index int, err error) {
+	index = -1
+
+	f := func(ctx context.Context) error {
+		for i := range limits {
+			b, err := impl.inc(ctx, &limits[i])
+			if err != nil {
+				_ = impl.dec(ctx, limits[:i])
+
+				return err
+			}
+
+			if !b {
+				index = i
+
+				// release the ones counted before.
+				return impl.dec(ctx, limits[:i])
+			}
+		}
+
+		return nil
+	}
+
+	err = withContext(f)
+
+	return
+}
+
+func (impl usageCounter) Release(limits []domain.UsageLimit) error {
+	return withContext(func(ctx context.Context) error {
+		return impl.dec(ctx, limits)
+	})
+}
+
+func (impl usageCounter) Sum(key string, after int64) (int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			fieldKey:    key,
+			fieldExpiry: bson.M{mongoCmdGt: after},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			fieldId:    nil,
+			fieldCount: bson.M{"$sum": "$" + fieldCount},
+		}}},
+	}
+
+	var v []dCounter
+
+	f := func(ctx context.Context) error {
+		cursor, err := impl.cli.Collection().Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		return cursor.All(ctx, &v)
+	}
+
+	if err := withContext(f); err != nil || len(v) == 0 {
+		return 0, err
+	}
+
+	return v[0].Count, nil
+}
+
+func (impl usageCounter) Clean(before int64) error {
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().DeleteMany(
+			ctx, bson.M{fieldExpiry: bson.M{mongoCmdLt: before}},
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+// inc counts the request if the limit is not reached. The document of the
+// window is created with the request counted if it doesn't exist.
+func (impl usageCounter) inc(ctx context.Context, l *domain.UsageLimit) (bool, error) {
+	id := docId(l)
+	n := l.Count()
+
+	filter := bson.M{fieldId: id}
+	if l.Max > 0 {
+		if n > l.Max {
+			return false, nil
+		}
+
+		filter[fieldCount] = bson.M{mongoCmdLte: l.Max - n}
+	}
+
+	r, err := impl.cli.Collection().UpdateOne(
+		ctx, filter, bson.M{mongoCmdInc: bson.M{fieldCount: n}},
+	)
+	if err != nil || r.MatchedCount > 0 {
+		return err == nil, err
+	}
+
+	r, err = impl.cli.Collection().UpdateOne(
+		ctx,
+		bson.M{fieldId: id},
+		bson.M{mongoCmdSetOnInsert: bson.M{
+			fieldKey:    l.Key,
+			fieldWindow: l.Window.Name,
+			fieldCount:  n,
+			fieldExpiry: l.Window.Expiry,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err == nil && r.UpsertedCount > 0 {
+		return true, nil
+	}
+
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// the document exists, it may be created by another request just now.
+	r, err = impl.cli.Collection().UpdateOne(
+		ctx, filter, bson.M{mongoCmdInc: bson.M{fieldCount: n}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return r.MatchedCount > 0, nil
+}
+
+func (impl usageCounter) dec(ctx context.Context, limits []domain.UsageLimit) error {
+	for i := range limits {
+		_, err := impl.cli.Collection().UpdateOne(
+			ctx,
+			bson.M{fieldId: docId(&limits[i])},
+			bson.M{mongoCmdInc: bson.M{fieldCount: -limits[i].Count()}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func docId(l *domain.UsageLimit) string {
+	return l.Key + "|" + l.Window.Name
+}
+
+func withContext(f func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		10*time.Second, // TODO use config
+	)
+	defer cancel()
+
+	return f(ctx)
+}
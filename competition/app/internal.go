@@ -3,8 +3,13 @@ package app
 import (
 	"errors"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/opensourceways/xihe-server/competition/domain"
 	"github.com/opensourceways/xihe-server/competition/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	webhookapp "github.com/opensourceways/xihe-server/webhook/app"
+	webhookdomain "github.com/opensourceways/xihe-server/webhook/domain"
 )
 
 type CompetitionSubmissionUpdateCmd = domain.SubmissionUpdatingInfo
@@ -14,14 +19,19 @@ type CompetitionInternalService interface {
 	UpdateSubmission(*CompetitionSubmissionUpdateCmd) error
 }
 
-func NewCompetitionInternalService(repo repository.Work) CompetitionInternalService {
+func NewCompetitionInternalService(
+	repo repository.Work,
+	webhook webhookapp.WebhookEventService,
+) CompetitionInternalService {
 	return competitionInternalService{
-		repo: repo,
+		repo:    repo,
+		webhook: webhook,
 	}
 }
 
 type competitionInternalService struct {
-	repo repository.Work
+	repo    repository.Work
+	webhook webhookapp.WebhookEventService
 }
 
 func (s competitionInternalService) UpdateSubmission(cmd *CompetitionSubmissionUpdateCmd) error {
@@ -30,7 +40,7 @@ func (s competitionInternalService) UpdateSubmission(cmd *CompetitionSubmissionU
 		return err
 	}
 
-	submission := w.UpdateSubmission(cmd)
+	submission, changed := w.UpdateSubmission(cmd)
 	if submission == nil {
 		return errors.New("no corresponding submission")
 	}
@@ -40,5 +50,38 @@ func (s competitionInternalService) UpdateSubmission(cmd *CompetitionSubmissionU
 		Submission: *submission,
 	}
 
-	return s.repo.SaveSubmission(&w, &v)
+	if err := s.repo.SaveSubmission(&w, &v); err != nil {
+		return err
+	}
+
+	if changed {
+		s.notifyWebhook(&w, submission)
+	}
+
+	return nil
+}
+
+// notifyWebhook sends the event to the webhooks of the owner of the related project.
+func (s competitionInternalService) notifyWebhook(w *domain.Work, submission *domain.Submission) {
+	if w.RepoOwner == "" {
+		return
+	}
+
+	owner, err := types.NewAccount(w.RepoOwner)
+	if err != nil {
+		return
+	}
+
+	err = s.webhook.Notify(&webhookapp.WebhookEventCmd{
+		Type:       webhookdomain.WebhookEventCompetitionSubmission,
+		Owner:      owner,
+		ResourceId: submission.Id,
+		Status:     submission.Status,
+	})
+	if err != nil {
+		logrus.Errorf(
+			"notify webhook of competition submission(%s) failed, err:%s",
+			submission.Id, err.Error(),
+		)
+	}
 }
@@ -160,6 +160,7 @@ func (s *competitionService) AddRelatedProject(cmd *CompetitionAddRelatedProject
 	}
 
 	w.Repo = cmd.repo()
+	w.RepoOwner = cmd.User.Account()
 	err = s.workRepo.SaveRepo(&w, version)
 
 	return
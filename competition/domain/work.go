@@ -25,6 +25,7 @@ type Work struct {
 	PlayerName string
 
 	Repo        string
+	RepoOwner   string
 	Final       []Submission
 	Preliminary []Submission
 }
@@ -98,16 +99,20 @@ func (w *Work) NewSubmissionMessage(s *PhaseSubmission) WorkSubmittedEvent {
 	}
 }
 
-func (w *Work) UpdateSubmission(info *SubmissionUpdatingInfo) *Submission {
+// UpdateSubmission updates the submission and returns it with whether its
+// status is changed.
+func (w *Work) UpdateSubmission(info *SubmissionUpdatingInfo) (*Submission, bool) {
 	submissions := w.Submissions(info.Phase)
 	for i := range submissions {
 		if item := &submissions[i]; item.Id == info.Id {
+			changed := item.Status != info.Status
+
 			item.Status = info.Status
 			item.Score = info.Score
 
-			return &submissions[i]
+			return item, changed
 		}
 	}
 
-	return nil
+	return nil, false
 }
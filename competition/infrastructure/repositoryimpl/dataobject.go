@@ -92,6 +92,7 @@ func (doc *dWork) toWork(w *domain.Work) {
 	w.PlayerName = doc.PlayerName
 	w.PlayerId = doc.PlayerId
 	w.Repo = doc.Repo
+	w.RepoOwner = doc.RepoOwner
 
 	if r := doc.toSubmissions(doc.Preliminary); len(r) != 0 {
 		w.Preliminary = r
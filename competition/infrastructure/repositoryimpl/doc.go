@@ -7,6 +7,7 @@ const (
 	fieldCid         = "cid"
 	fieldPid         = "pid"
 	fieldRepo        = "repo"
+	fieldRepoOwner   = "repo_owner"
 	fieldVersion     = "version"
 	fieldFinal       = "final"
 	fieldPreliminary = "preliminary"
@@ -47,6 +48,7 @@ type dWork struct {
 	PlayerId      string        `bson:"pid"            json:"pid"`
 	PlayerName    string        `bson:"pname"          json:"pname"`
 	Repo          string        `bson:"repo"           json:"repo"`
+	RepoOwner     string        `bson:"repo_owner"     json:"repo_owner"`
 	Final         []dSubmission `bson:"final"          json:"final"`
 	Preliminary   []dSubmission `bson:"preliminary"    json:"preliminary"`
 	Version       int           `bson:"version"        json:"-"`
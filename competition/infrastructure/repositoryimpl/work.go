@@ -59,7 +59,7 @@ func (impl workRepoImpl) SaveRepo(w *domain.Work, version int) error {
 	f := func(ctx context.Context) error {
 		return impl.cli.UpdateDoc(
 			ctx, impl.docFilter(&w.WorkIndex),
-			bson.M{fieldRepo: w.Repo}, mongoCmdSet, version,
+			bson.M{fieldRepo: w.Repo, fieldRepoOwner: w.RepoOwner}, mongoCmdSet, version,
 		)
 	}
 
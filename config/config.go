@@ -8,6 +8,7 @@ import (
 	aiccconfig "github.com/opensourceways/xihe-server/aiccfinetune/config"
 	"github.com/opensourceways/xihe-server/app"
 	bigmodel "github.com/opensourceways/xihe-server/bigmodel/config"
+	cloudapp "github.com/opensourceways/xihe-server/cloud/app"
 	cloudmsg "github.com/opensourceways/xihe-server/cloud/infrastructure/messageadapter"
 	cloudrepoimpl "github.com/opensourceways/xihe-server/cloud/infrastructure/repositoryimpl"
 	common "github.com/opensourceways/xihe-server/common/config"
@@ -17,15 +18,17 @@ import (
 	"github.com/opensourceways/xihe-server/competition"
 	"github.com/opensourceways/xihe-server/controller"
 	"github.com/opensourceways/xihe-server/course"
+	deploymentconfig "github.com/opensourceways/xihe-server/deployment/config"
 	"github.com/opensourceways/xihe-server/domain"
 	"github.com/opensourceways/xihe-server/infrastructure/authingimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/challengeimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/finetuneimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/gitlab"
-	"github.com/opensourceways/xihe-server/infrastructure/inferenceimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/messages"
+	jobconfig "github.com/opensourceways/xihe-server/job/config"
 	pointsdomain "github.com/opensourceways/xihe-server/points/domain"
 	"github.com/opensourceways/xihe-server/utils"
+	webhookconfig "github.com/opensourceways/xihe-server/webhook/config"
 )
 
 func LoadConfig(path string, cfg *Config) error {
@@ -63,12 +66,16 @@ type Config struct {
 	Course       course.Config                   `json:"course"       required:"true"`
 	Resource     messages.ResourceConfig         `json:"resource"     required:"true"`
 	Download     messages.DownloadProducerConfig `json:"download"     required:"true"`
-	Inference    inferenceimpl.Config            `json:"inference"    required:"true"`
+	Inference    inferenceConfig                 `json:"inference"    required:"true"`
 	Cloud        cloudmsg.Config                 `json:"cloud"        required:"true"`
+	CloudReserve cloudapp.ReservationConfig      `json:"cloud_reservation"`
 	User         userConfig                      `json:"user"`
 	Like         messages.LikeConfig             `json:"like"`
 	Agreement    agreement.Config                `json:"agreement"`
 	AICCFinetune aiccconfig.Config               `json:"aicc_finetune"`
+	Webhook      webhookconfig.Config            `json:"webhook"`
+	Job          jobconfig.Config                `json:"job"`
+	Deployment   deploymentconfig.Config         `json:"deployment"`
 }
 
 func (cfg *Config) GetRedisConfig() redislib.Config {
@@ -103,6 +110,7 @@ func (cfg *Config) ConfigItems() []interface{} {
 		&cfg.SignIn,
 		&cfg.Points,
 		&cfg.Cloud,
+		&cfg.CloudReserve,
 		&cfg.Download,
 		&cfg.Course,
 		&cfg.Resource,
@@ -110,6 +118,9 @@ func (cfg *Config) ConfigItems() []interface{} {
 		&cfg.Like,
 		&cfg.AICCFinetune,
 		&cfg.Agreement,
+		&cfg.Webhook,
+		&cfg.Job,
+		&cfg.Deployment,
 	}
 }
 
@@ -170,6 +181,7 @@ type MongodbCollections struct {
 	Training          string `json:"training"               required:"true"`
 	Finetune          string `json:"finetune"               required:"true"`
 	Inference         string `json:"inference"              required:"true"`
+	InferenceUsage    string `json:"inference_usage"        required:"true"`
 	AIQuestion        string `json:"aiquestion"             required:"true"`
 	Competition       string `json:"competition"            required:"true"`
 	QuestionPool      string `json:"question_pool"          required:"true"`
@@ -190,6 +202,22 @@ type MongodbCollections struct {
 	PromotionTask     string `json:"promotion_task"         required:"true"`
 	AICCFinetune      string `json:"aicc_finetune"          required:"true"`
 	UserWhiteList     string `json:"user_whitelist"         required:"true"`
+	TrainingSchedule  string `json:"training_schedule"      required:"true"`
+	Webhook           string `json:"webhook"                required:"true"`
+	WebhookDelivery   string `json:"webhook_delivery"       required:"true"`
+	Deployment        string `json:"deployment"             required:"true"`
+	DeploymentUsage   string `json:"deployment_usage"       required:"true"`
+	UsageCounter      string `json:"usage_counter"          required:"true"`
+	ChatSession       string `json:"chat_session"           required:"true"`
+	ApiUsage          string `json:"api_usage"              required:"true"`
+	QueueTask         string `json:"queue_task"             required:"true"`
+	Moderation        string `json:"moderation_incident"    required:"true"`
+	ModerationAudit   string `json:"moderation_decision"    required:"true"`
+	WuKongComment     string `json:"wukong_comment"         required:"true"`
+	WuKongReport      string `json:"wukong_report"          required:"true"`
+	PromptTemplate    string `json:"prompt_template"        required:"true"`
+	WuKongBatch       string `json:"wukong_batch"           required:"true"`
+	CloudReservation  string `json:"cloud_reservation"      required:"true"`
 }
 
 func (cfg *Config) InitDomainConfig() {
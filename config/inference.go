@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/opensourceways/xihe-server/infrastructure/inferenceimpl"
+	"github.com/opensourceways/xihe-server/infrastructure/localinferenceimpl"
+)
+
+type inferenceConfig struct {
+	inferenceimpl.Config
+
+	// Local runs the inference on the server host instead of the cluster.
+	Local localinferenceimpl.Config `json:"local"`
+}
+
+func (cfg *inferenceConfig) ConfigItems() []interface{} {
+	return []interface{}{
+		&cfg.Config,
+		&cfg.Local,
+	}
+}
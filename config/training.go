@@ -1,6 +1,7 @@
 package config
 
 import (
+	"github.com/opensourceways/xihe-server/infrastructure/localtrainingimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/messages"
 	"github.com/opensourceways/xihe-server/infrastructure/trainingimpl"
 )
@@ -8,12 +9,43 @@ import (
 type trainingConfig struct {
 	trainingimpl.Config
 
-	Message messages.TrainingConfig `json:"message" required:"true"`
+	Message  messages.TrainingConfig `json:"message"  required:"true"`
+	Schedule trainingScheduleConfig  `json:"schedule"`
+
+	// Local is the local training center which is used for development.
+	Local localtrainingimpl.Config `json:"local"`
 }
 
 func (cfg *trainingConfig) ConfigItems() []interface{} {
 	return []interface{}{
 		&cfg.Config,
 		&cfg.Message,
+		&cfg.Schedule,
+		&cfg.Local,
+	}
+}
+
+type trainingScheduleConfig struct {
+	// Interval is the seconds between two checks of the due schedules
+	Interval int `json:"interval"`
+
+	// RunsKeepNum is the max num of runs kept for each schedule
+	RunsKeepNum int `json:"runs_keep_num"`
+
+	// MaxScheduleNum is the max num of schedules of a project
+	MaxScheduleNum int `json:"max_schedule_num"`
+}
+
+func (cfg *trainingScheduleConfig) SetDefault() {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 60
+	}
+
+	if cfg.RunsKeepNum <= 0 {
+		cfg.RunsKeepNum = 20
+	}
+
+	if cfg.MaxScheduleNum <= 0 {
+		cfg.MaxScheduleNum = 5
 	}
 }
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	userapp "github.com/opensourceways/xihe-server/user/app"
+	userdomain "github.com/opensourceways/xihe-server/user/domain"
+)
+
+// checkAdmin checks whether the user is in the admin whitelist, and sends
+// the response if not.
+func (ctl baseController) checkAdmin(
+	ctx *gin.Context, whitelist userapp.WhiteListService, user types.Account,
+) bool {
+	t, err := userdomain.NewWhiteListType(userdomain.WhitelistTypeAdmin)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return false
+	}
+
+	v, err := whitelist.CheckWhiteList(&userapp.UserWhiteListCmd{
+		Account: user,
+		Type:    t,
+	})
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return false
+	}
+
+	if !v.Allowed {
+		ctx.JSON(http.StatusForbidden, newResponseCodeMsg(
+			errorNotAllowed, "not allowed",
+		))
+
+		return false
+	}
+
+	return true
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"time"
 
@@ -30,14 +31,23 @@ func AddRouterForAICCFinetuneController(
 	rg.POST("/v1/aiccfinetune/:model", checkUserEmailMiddleware(&ctl.baseController), ctl.Create)
 	rg.GET("/v1/aiccfinetune/:model", ctl.List)
 	rg.GET("/v1/aiccfinetune/:model/ws", ctl.ListByWS)
+	rg.GET("/v1/aiccfinetune/:model/leaderboard", ctl.Leaderboard)
 	rg.GET(
 		"/v1/aiccfinetune/:model/:id/result/:type", checkUserEmailMiddleware(&ctl.baseController),
 		ctl.GetResultDownloadURL,
 	)
 	rg.PUT("/v1/aiccfinetune/:model/:id", ctl.Terminate)
 	rg.GET("/v1/aiccfinetune/:model/:id", ctl.Get)
+	rg.GET("/v1/aiccfinetune/:model/:id/log", ctl.GetLog)
+	rg.GET("/v1/aiccfinetune/:model/:id/log/sse", ctl.StreamLog)
+	rg.GET("/v1/aiccfinetune/:model/:id/log/ws", ctl.WatchLog)
+	rg.GET("/v1/aiccfinetune/:model/:id/log/archive", ctl.DownloadLogArchive)
 	rg.DELETE("/v1/aiccfinetune/:model/:id", ctl.Delete)
 	rg.POST("/v1/aiccfinetune/:model/:task/data", ctl.UploadData)
+	rg.POST("/v1/aiccfinetune/:model/:task/data/preview", ctl.PreviewData)
+	// the wildcard of task must be named as id, because it is at the same
+	// position as the id of finetune in the other GET routes.
+	rg.GET("/v1/aiccfinetune/:model/:id/data", ctl.GetDataUpload)
 }
 
 type AICCFinetuneController struct {
@@ -106,9 +116,9 @@ func (ctl *AICCFinetuneController) Create(ctx *gin.Context) {
 		return
 	}
 
-	v, err := ctl.as.Create(cmd)
+	v, code, err := ctl.as.Create(cmd)
 	if err != nil {
-		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		ctl.sendCodeMessage(ctx, code, err)
 
 		return
 	}
@@ -116,6 +126,52 @@ func (ctl *AICCFinetuneController) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, newResponseData(aiccFinetuneCreateResp{v}))
 }
 
+// @Summary		Leaderboard
+// @Description	compare the finetunes of user by the metrics of their latest evaluations
+// @Tags			AICC Finetune
+// @Param			model	path	string	true	"model name"
+// @Param			metric	query	string	false	"metric to sort by, the first one by default"
+// @Param			order	query	string	false	"asc or desc, desc by default"
+// @Accept			json
+// @Success		200	{object}			app.LeaderboardDTO
+// @Failure		400	bad_request_param	some	parameter	of	query	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/aiccfinetune/{model}/leaderboard [get]
+func (ctl *AICCFinetuneController) Leaderboard(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := aiccLeaderboardRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd := app.LeaderboardCmd{User: pl.DomainAccount()}
+
+	var err error
+	if cmd.Model, err = domain.NewModelName(ctx.Param("model")); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if err = req.toCmd(&cmd); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if v, err := ctl.as.Leaderboard(&cmd); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
 // @Summary		Delete
 // @Description	delete AICC Finetune
 // @Tags			AICC Finetune
@@ -501,6 +557,118 @@ func (ctl *AICCFinetuneController) GetResultDownloadURL(ctx *gin.Context) {
 	}
 }
 
+// @Summary		GetLog
+// @Description	read the log of aicc finetune from the offset
+// @Tags			AICC Finetune
+// @Param			model	path	string	true	"model name"
+// @Param			id		path	string	true	"finetune id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		appout.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/aiccfinetune/{model}/{id}/log [get]
+func (ctl *AICCFinetuneController) GetLog(ctx *gin.Context) {
+	info, ok := ctl.getAICCFinetuneInfo(ctx)
+	if !ok {
+		return
+	}
+
+	ctl.getJobLog(ctx, ctl.logReader(&info))
+}
+
+// @Summary		StreamLog
+// @Description	stream the log of aicc finetune by server-sent events
+// @Tags			AICC Finetune
+// @Param			model	path	string	true	"model name"
+// @Param			id		path	string	true	"finetune id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines of each event"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		appout.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/aiccfinetune/{model}/{id}/log/sse [get]
+func (ctl *AICCFinetuneController) StreamLog(ctx *gin.Context) {
+	info, ok := ctl.getAICCFinetuneInfo(ctx)
+	if !ok {
+		return
+	}
+
+	ctl.streamJobLogBySSE(ctx, ctl.logReader(&info), appout.ErrorAICCFinetuneNotFound)
+}
+
+// @Summary		WatchLog
+// @Description	stream the log of aicc finetune by websocket
+// @Tags			AICC Finetune
+// @Param			model	path	string	true	"model name"
+// @Param			id		path	string	true	"finetune id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines of each message"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		appout.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/aiccfinetune/{model}/{id}/log/ws [get]
+func (ctl *AICCFinetuneController) WatchLog(ctx *gin.Context) {
+	pl, csrftoken, _, ok := ctl.checkTokenForWebsocket(ctx, false)
+	if !ok {
+		return
+	}
+
+	model, err := domain.NewModelName(ctx.Param("model"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
+			errorBadRequestParam, err,
+		))
+
+		return
+	}
+
+	info := domain.AICCFinetuneIndex{
+		User:       pl.DomainAccount(),
+		FinetuneId: ctx.Param("id"),
+		Model:      model,
+	}
+
+	ctl.streamJobLogByWS(
+		ctx, csrftoken, ctl.logReader(&info), appout.ErrorAICCFinetuneNotFound,
+	)
+}
+
+// @Summary		DownloadLogArchive
+// @Description	download the full log of finished aicc finetune compressed by gzip
+// @Tags			AICC Finetune
+// @Param			model	path	string	true	"model name"
+// @Param			id		path	string	true	"finetune id"
+// @Accept			json
+// @Success		200
+// @Failure		500	system_error	system	error
+// @Router			/v1/aiccfinetune/{model}/{id}/log/archive [get]
+func (ctl *AICCFinetuneController) DownloadLogArchive(ctx *gin.Context) {
+	info, ok := ctl.getAICCFinetuneInfo(ctx)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.as.GetFullLog(&info)
+
+	ctl.sendJobLogArchive(ctx, info.FinetuneId, v, code, err)
+}
+
+func (ctl *AICCFinetuneController) logReader(info *domain.AICCFinetuneIndex) jobLogReader {
+	return func(q *appout.JobLogQuery) (appout.JobLogDTO, string, error) {
+		return ctl.as.GetLog(info, q)
+	}
+}
+
 func (ctl *AICCFinetuneController) getAICCFinetuneInfo(ctx *gin.Context) (domain.AICCFinetuneIndex, bool) {
 	pl, _, ok := ctl.checkUserApiToken(ctx, false)
 	if !ok {
@@ -520,13 +688,15 @@ func (ctl *AICCFinetuneController) getAICCFinetuneInfo(ctx *gin.Context) (domain
 }
 
 // @Summary		UploadData
-// @Description	Upload Data
+// @Description	Upload Data, the data of finetune and evaluate will be validated before uploading
 // @Tags			AICC Finetune
 // @Param			model	path		string	true	"model name"
+// @Param			task	path		string	true	"task name"
 // @Param			file	formData	file	true	"result file"
 // @Accept			json
-// @Success		201	{object}		app.UploadDataDTO
-// @Failure		500	system_error	system	error
+// @Success		201	{object}			app.UploadDataDTO
+// @Failure		400	bad_request_param	the	data	is	invalid
+// @Failure		500	system_error		system	error
 // @Router			/v1/aiccfinetune/{model}/{task}/data [post]
 func (ctl *AICCFinetuneController) UploadData(ctx *gin.Context) {
 	pl, _, ok := ctl.checkUserApiToken(ctx, false)
@@ -536,20 +706,115 @@ func (ctl *AICCFinetuneController) UploadData(ctx *gin.Context) {
 
 	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "upload data")
 
+	cmd, file, ok := ctl.parseUploadDataCmd(ctx, pl.DomainAccount())
+	if !ok {
+		return
+	}
+
+	defer file.Close()
+
+	v, code, err := ctl.as.UploadData(&cmd)
+	if err == nil {
+		ctl.sendRespOfPost(ctx, v)
+
+		return
+	}
+
+	if code == "" {
+		code = errorBadRequestParam
+	}
+
+	// return the report so that user can fix the data.
+	ctl.sendBadRequest(ctx, responseData{
+		Code: code,
+		Msg:  err.Error(),
+		Data: v,
+	})
+}
+
+// @Summary		PreviewData
+// @Description	validate the data without uploading it and return the sampled records
+// @Tags			AICC Finetune
+// @Param			model	path		string	true	"model name"
+// @Param			task	path		string	true	"task name"
+// @Param			file	formData	file	true	"data file"
+// @Accept			json
+// @Success		200	{object}			app.DataReportDTO
+// @Failure		400	bad_request_param	some	parameter	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/aiccfinetune/{model}/{task}/data/preview [post]
+func (ctl *AICCFinetuneController) PreviewData(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	cmd, file, ok := ctl.parseUploadDataCmd(ctx, pl.DomainAccount())
+	if !ok {
+		return
+	}
+
+	defer file.Close()
+
+	if v, code, err := ctl.as.PreviewData(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		GetDataUpload
+// @Description	get the validation report of the latest data uploaded for the task
+// @Tags			AICC Finetune
+// @Param			model	path	string	true	"model name"
+// @Param			task	path	string	true	"task name"
+// @Accept			json
+// @Success		200	{object}			app.DataUploadDTO
+// @Failure		400	bad_request_param	some	parameter	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/aiccfinetune/{model}/{task}/data [get]
+func (ctl *AICCFinetuneController) GetDataUpload(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
 	model, err := domain.NewModelName(ctx.Param("model"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
-			errorBadRequestParam, err,
-		))
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	task, err := domain.NewFinetuneTask(ctx.Param("id"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if v, code, err := ctl.as.GetDataUpload(pl.DomainAccount(), model, task); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// parseUploadDataCmd parses the data file. The caller must close the file
+// if it returns true.
+func (ctl *AICCFinetuneController) parseUploadDataCmd(ctx *gin.Context, user types.Account) (
+	cmd app.UploadDataCmd, file multipart.File, ok bool,
+) {
+	model, err := domain.NewModelName(ctx.Param("model"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
 
 		return
 	}
 
 	task, err := domain.NewFinetuneTask(ctx.Param("task"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
-			errorBadRequestParam, err,
-		))
+		ctl.sendBadRequestParam(ctx, err)
 
 		return
 	}
@@ -564,48 +829,31 @@ func (ctl *AICCFinetuneController) UploadData(ctx *gin.Context) {
 	}
 
 	if f.Size > apiConfig.MaxFinetuneSubmmitFileSize {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
-			errorBadRequestParam, "too big file",
-		))
+		ctl.sendBadRequestParamWithMsg(ctx, "too big file")
 
 		return
 	}
 
-	p, err := f.Open()
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
-			errorBadRequestParam, "can't get file",
-		))
+	if err = domain.NewFileName(f.Filename, model.ModelName(), task.FinetuneTask()); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
 
 		return
 	}
 
-	defer p.Close()
-
-	err = domain.NewFileName(f.Filename, model.ModelName(), task.FinetuneTask())
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
-			errorBadRequestParam, err,
-		))
+	if file, err = f.Open(); err != nil {
+		ctl.sendBadRequestParamWithMsg(ctx, "can't get file")
 
 		return
 	}
 
-	cmd := &app.UploadDataCmd{
+	cmd = app.UploadDataCmd{
 		FileName: f.Filename,
-		Data:     p,
-		User:     pl.DomainAccount(),
+		Data:     file,
+		Size:     f.Size,
+		User:     user,
 		Model:    model,
 		Task:     task,
 	}
 
-	if v, err := ctl.as.UploadData(cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeError(
-			errorBadRequestParam, err,
-		))
-
-		return
-	} else {
-		ctl.sendRespOfPost(ctx, v)
-	}
+	return cmd, file, true
 }
@@ -25,6 +25,9 @@ type aiccFinetuneCreateRequest struct {
 	Desc string `json:"desc"`
 	Task string `json:"task"`
 
+	// Source is the id of finetune to be evaluated when the task is evaluate.
+	Source string `json:"source"`
+
 	Hyperparameters []AICCKeyValue `json:"hyperparameter"`
 	Env             []AICCKeyValue `json:"env"`
 }
@@ -46,6 +49,8 @@ func (req *aiccFinetuneCreateRequest) toCmd(cmd *app.AICCFinetuneCreateCmd, mode
 		return
 	}
 
+	cmd.Source = req.Source
+
 	if cmd.Env, err = req.toKeyValue(req.Env); err != nil {
 		return
 	}
@@ -93,3 +98,22 @@ func (kv *AICCKeyValue) toKeyValue() (r domain.KeyValue, err error) {
 
 	return
 }
+
+type aiccLeaderboardRequest struct {
+	Metric string `form:"metric"`
+	Order  string `form:"order"`
+}
+
+func (req *aiccLeaderboardRequest) toCmd(cmd *app.LeaderboardCmd) error {
+	switch req.Order {
+	case "", "desc":
+	case "asc":
+		cmd.Asc = true
+	default:
+		return errors.New("order should be asc or desc")
+	}
+
+	cmd.Metric = req.Metric
+
+	return nil
+}
@@ -26,6 +26,7 @@ const (
 	Token              = "token"
 	encodeUsername     = "encode-username"
 	headerSecWebsocket = "Sec-Websocket-Protocol"
+	headerRequestId    = "X-Request-Id"
 	PayLoad            = "PAYLOAD"
 
 	roleIndividuals = "individuals"
@@ -33,6 +34,9 @@ const (
 	fileApp         = "app.py"
 
 	visitorPrefix = "visitor"
+
+	// bigmodelApiTokenExpiry is the seconds an api token of bigmodel is valid.
+	bigmodelApiTokenExpiry = 5184000
 )
 
 type baseController struct {
@@ -703,24 +707,10 @@ func (ctl baseController) newRepo() repositories.Access {
 }
 
 func (ctl baseController) checkBigmodelApiToken(ctx *gin.Context) (user string, ok bool) {
-	v := ctx.GetHeader(Token)
-	deToken, err := ctl.decryptData(v)
+	user, err := ctl.parseBigmodelApiToken(ctx.GetHeader(Token))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
-			errorBadRequestParam, "invalid token",
-		))
-		prepareOperateLog(ctx, "anonymous", OPERATE_TYPE_SYSTEM, "check bigmodel api token")
-		return
-	}
-	defer utils.ClearByteArrayMemory(deToken)
-
-	strs := strings.Split(string(deToken), "+")
-	user = strs[0]
-
-	time, err := strconv.ParseInt(strs[1], 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
-			errorBadRequestParam, "invalid token",
+			errorBadRequestParam, err.Error(),
 		))
 
 		if user == "" {
@@ -732,19 +722,41 @@ func (ctl baseController) checkBigmodelApiToken(ctx *gin.Context) (user string,
 		return
 	}
 
-	if utils.Now()-time > 5184000 {
-		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
-			errorBadRequestParam, "token expire",
-		))
+	return user, true
+}
 
-		if user == "" {
-			prepareOperateLog(ctx, "anonymous", OPERATE_TYPE_SYSTEM, "check bigmodel api token")
-		} else {
-			prepareOperateLog(ctx, user, OPERATE_TYPE_SYSTEM, "check bigmodel api token")
-		}
+// parseBigmodelApiToken returns the user of token. The user is also
+// returned if the token is expired.
+func (ctl baseController) parseBigmodelApiToken(token string) (user string, err error) {
+	deToken, err := ctl.decryptData(token)
+	if err != nil {
+		return "", errors.New("invalid token")
+	}
+	defer utils.ClearByteArrayMemory(deToken)
 
-		return
+	strs := strings.Split(string(deToken), "+")
+	if len(strs) != 2 {
+		return "", errors.New("invalid token")
 	}
 
-	return user, true
+	user = strs[0]
+
+	time, err := strconv.ParseInt(strs[1], 10, 64)
+	if err != nil {
+		return user, errors.New("invalid token")
+	}
+
+	if utils.Now()-time > bigmodelApiTokenExpiry {
+		return user, errors.New("token expire")
+	}
+
+	return user, nil
+}
+
+// isBigmodelApiTokenOf checks whether the token is the one applied for
+// the model, which is saved encrypted.
+func (ctl baseController) isBigmodelApiTokenOf(token, encrypted string) bool {
+	v, err := ctl.decryptDataForToken(encrypted)
+
+	return err == nil && string(v) == token
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -27,10 +28,12 @@ func AddRouterForBigModelController(
 	rg *gin.RouterGroup,
 	s app.BigModelService,
 	us userapp.RegService,
+	prompt app.PromptService,
 ) {
 	ctl := BigModelController{
-		s:  s,
-		us: us,
+		s:      s,
+		us:     us,
+		prompt: prompt,
 	}
 
 	// luojia
@@ -75,8 +78,9 @@ func AddRouterForBigModelController(
 type BigModelController struct {
 	baseController
 
-	s  app.BigModelService
-	us userapp.RegService
+	s      app.BigModelService
+	us     userapp.RegService
+	prompt app.PromptService
 }
 
 // @Title			LuoJia
@@ -222,6 +226,14 @@ func (ctl *BigModelController) WuKong(ctx *gin.Context) {
 		return
 	}
 
+	if req.Template != nil {
+		if req.Desc, ok = ctl.renderPrompt(
+			ctx, ctl.prompt, req.Template, pl.DomainAccount(), domain.PromptKindWuKong,
+		); !ok {
+			return
+		}
+	}
+
 	cmd, err := req.toCmd()
 	if err != nil {
 		ctl.sendBadRequestParam(ctx, err)
@@ -259,6 +271,14 @@ func (ctl *BigModelController) WuKongAsync(ctx *gin.Context) {
 		return
 	}
 
+	if req.Template != nil {
+		if req.Desc, ok = ctl.renderPrompt(
+			ctx, ctl.prompt, req.Template, pl.DomainAccount(), domain.PromptKindWuKong,
+		); !ok {
+			return
+		}
+	}
+
 	cmd, err := req.toCmd()
 	if err != nil {
 		ctl.sendBadRequestParam(ctx, err)
@@ -645,6 +665,9 @@ func (ctl *BigModelController) ListPublic(ctx *gin.Context) {
 // @Title			GetPublicGlobal
 // @Description	list all wukong pictures publiced
 // @Tags			BigModel
+// @Param			keyword	query	string	false	"search the words in the desc and style of picture"
+// @Param			tags	query	string	false	"tags separated by comma"
+// @Param			sort_by	query	string	false	"time or digg"
 // @Accept			json
 // @Success		200	{object}		app.WuKongPublicDTO
 // @Failure		500	system_error	system	error
@@ -678,6 +701,15 @@ func (ctl *BigModelController) GetPublicsGlobal(ctx *gin.Context) {
 			cmd.Level = domain.NewWuKongPictureLevel(v)
 		}
 
+		cmd.Keyword = ctl.getQueryParameter(ctx, "keyword")
+		cmd.SortBy = ctl.getQueryParameter(ctx, "sort_by")
+
+		if v := ctl.getQueryParameter(ctx, "tags"); v != "" {
+			if cmd.Tags, err = domain.NewWuKongPictureTags(strings.Split(v, ",")); err != nil {
+				return
+			}
+		}
+
 		cmd.User = pl.DomainAccount()
 
 		return
@@ -849,6 +881,8 @@ func (ctl *BigModelController) GLM2(ctx *gin.Context) {
 		return
 	}
 
+	cmd.RequestId = setRequestId(ctx)
+
 	code, err := ctl.s.GLM2(&cmd)
 	if err != nil {
 		ctx.Stream(func(w io.Writer) bool {
@@ -872,11 +906,7 @@ func (ctl *BigModelController) GLM2(ctx *gin.Context) {
 
 	ctx.Stream(func(w io.Writer) bool {
 		if msg, ok := <-ch; ok {
-			if msg == "done" {
-				ctx.SSEvent("status", "done")
-			} else {
-				ctx.SSEvent("message", msg)
-			}
+			sendReplyEvent(ctx, msg)
 
 			return true
 		}
@@ -917,6 +947,8 @@ func (ctl *BigModelController) LLAMA2(ctx *gin.Context) {
 		return
 	}
 
+	cmd.RequestId = setRequestId(ctx)
+
 	code, err := ctl.s.LLAMA2(&cmd)
 	if err != nil {
 		ctx.Stream(func(w io.Writer) bool {
@@ -940,11 +972,7 @@ func (ctl *BigModelController) LLAMA2(ctx *gin.Context) {
 
 	ctx.Stream(func(w io.Writer) bool {
 		if msg, ok := <-ch; ok {
-			if msg == "done" {
-				ctx.SSEvent("status", "done")
-			} else {
-				ctx.SSEvent("message", msg)
-			}
+			sendReplyEvent(ctx, msg)
 
 			return true
 		}
@@ -985,6 +1013,8 @@ func (ctl *BigModelController) SkyWork(ctx *gin.Context) {
 		return
 	}
 
+	cmd.RequestId = setRequestId(ctx)
+
 	code, err := ctl.s.SkyWork(&cmd)
 	if err != nil {
 		ctx.Stream(func(w io.Writer) bool {
@@ -1008,14 +1038,11 @@ func (ctl *BigModelController) SkyWork(ctx *gin.Context) {
 
 	ctx.Stream(func(w io.Writer) bool {
 		if msg, ok := <-ch; ok {
-			if msg == "done" {
-				ctx.SSEvent("status", "done")
-				close(ch)
-			} else {
-				ctx.SSEvent("message", msg)
-			}
+			sendReplyEvent(ctx, msg)
+
 			return true
 		}
+
 		return false
 	})
 }
@@ -1052,6 +1079,8 @@ func (ctl *BigModelController) IFlytekSpark(ctx *gin.Context) {
 		return
 	}
 
+	cmd.RequestId = setRequestId(ctx)
+
 	code, err := ctl.s.IFlytekSpark(&cmd)
 	if err != nil {
 		ctx.Stream(func(w io.Writer) bool {
@@ -1075,11 +1104,7 @@ func (ctl *BigModelController) IFlytekSpark(ctx *gin.Context) {
 
 	ctx.Stream(func(w io.Writer) bool {
 		if msg, ok := <-ch; ok {
-			if msg == "done" {
-				ctx.SSEvent("status", "done")
-			} else {
-				ctx.SSEvent("message", msg)
-			}
+			sendReplyEvent(ctx, msg)
 
 			return true
 		}
@@ -1190,13 +1215,7 @@ func (ctl *BigModelController) WukongAPI(ctx *gin.Context) {
 		return
 	}
 
-	deToken, err := ctl.decryptDataForToken(r)
-	if err != nil {
-		ctl.sendRespWithInternalError(ctx, newResponseError(err))
-
-		return
-	}
-	if ctx.GetHeader(Token) != string(deToken) {
+	if !ctl.isBigmodelApiTokenOf(ctx.GetHeader(Token), r) {
 		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
 			errorBadRequestParam, "invalid token",
 		))
@@ -1217,7 +1236,11 @@ func (ctl *BigModelController) WukongAPI(ctx *gin.Context) {
 	}
 
 	if v, code, err := ctl.s.WukongApi(ac, model, &cmd); err != nil {
-		ctl.sendCodeMessage(ctx, code, err)
+		if code == app.ErrorBigModelRateLimited || code == app.ErrorBigModelQuotaExceeded {
+			ctx.JSON(http.StatusTooManyRequests, newResponseCodeError(code, err))
+		} else {
+			ctl.sendCodeMessage(ctx, code, err)
+		}
 	} else {
 		ctl.sendRespOfPost(ctx, wukongPicturesGenerateResp{v})
 	}
@@ -1338,3 +1361,12 @@ func (ctl *BigModelController) RefreshApiToken(ctx *gin.Context) {
 		ctl.sendRespOfGet(ctx, newApiTokenResp{newToken, date})
 	}
 }
+
+// setRequestId gives the request of model an id and returns it by the
+// header, so that the moderation incident of the request can be found.
+func setRequestId(ctx *gin.Context) string {
+	id := newRequestId("req-")
+	ctx.Header(headerRequestId, id)
+
+	return id
+}
@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+func AddRouterForBigModelChatController(
+	rg *gin.RouterGroup,
+	s app.ChatService,
+	prompt app.PromptService,
+) {
+	ctl := BigModelChatController{
+		s:      s,
+		prompt: prompt,
+	}
+
+	rg.GET("/v1/bigmodel/models", ctl.ListModels)
+	rg.POST("/v1/bigmodel/chat", ctl.Chat)
+	rg.GET("/v1/bigmodel/chat/sessions", ctl.ListSessions)
+	rg.GET("/v1/bigmodel/chat/sessions/:id", ctl.GetSession)
+	rg.PUT("/v1/bigmodel/chat/sessions/:id", ctl.RenameSession)
+	rg.DELETE("/v1/bigmodel/chat/sessions/:id", ctl.DeleteSession)
+}
+
+type BigModelChatController struct {
+	baseController
+
+	s      app.ChatService
+	prompt app.PromptService
+}
+
+// @Summary		ListModels
+// @Description	list the models which can be chatted with
+// @Tags			BigModel
+// @Accept			json
+// @Success		200	{object}		[]app.ChatModelDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/models [get]
+func (ctl *BigModelChatController) ListModels(ctx *gin.Context) {
+	if _, _, ok := ctl.checkUserApiToken(ctx, true); !ok {
+		return
+	}
+
+	ctl.sendRespOfGet(ctx, ctl.s.ListModels())
+}
+
+// @Summary		Chat
+// @Description	chat with the model. A new session is created with the messages if session_id is empty,
+// @Description	otherwise the messages are appended to the session which can be continued by any model.
+// @Description	The reply is streamed by SSE, the first event is the id of session.
+// @Tags			BigModel
+// @Param			body	body	chatRequest	true	"body of chat"
+// @Accept			json
+// @Success		202	{object}			string
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		429	bigmodel_resource_busy	too	many	requests
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/chat [post]
+func (ctl *BigModelChatController) Chat(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "chat with bigmodel")
+
+	req := chatRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	if req.Template != nil {
+		v, ok := ctl.renderPrompt(
+			ctx, ctl.prompt, req.Template, pl.DomainAccount(), domain.PromptKindChat,
+		)
+		if !ok {
+			return
+		}
+
+		req.Messages = append(req.Messages, chatMessageRequest{
+			Role:    domain.ChatRoleUser,
+			Content: v,
+		})
+	}
+
+	ch := make(chan string, chBufferSize)
+	cmd, err := req.toCmd(ch, pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd.Done = ctx.Request.Context().Done()
+
+	// the errors are responded before the stream starts.
+	id, code, err := ctl.s.Chat(&cmd)
+	if err != nil {
+		switch code {
+		case app.ErrorBigModelRecourseBusy, app.ErrorBigModelRateLimited,
+			app.ErrorBigModelQuotaExceeded:
+			ctx.JSON(http.StatusTooManyRequests, newResponseCodeError(code, err))
+
+		default:
+			ctl.sendCodeMessage(ctx, code, err)
+		}
+
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream; charset=utf-8")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+
+	ctx.SSEvent("session", id)
+
+	ctx.Stream(func(w io.Writer) bool {
+		if msg, ok := <-ch; ok {
+			sendReplyEvent(ctx, msg)
+
+			return true
+		}
+
+		return false
+	})
+}
+
+// @Summary		ListSessions
+// @Description	list the chat sessions, the latest updated first
+// @Tags			BigModel
+// @Accept			json
+// @Success		200	{object}		app.ChatSessionSummaryDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/chat/sessions [get]
+func (ctl *BigModelChatController) ListSessions(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, err := ctl.s.ListSessions(pl.DomainAccount()); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		GetSession
+// @Description	get the chat session with its messages
+// @Tags			BigModel
+// @Param			id	path	string	true	"session id"
+// @Accept			json
+// @Success		200	{object}		app.ChatSessionDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/chat/sessions/{id} [get]
+func (ctl *BigModelChatController) GetSession(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	index := domain.ChatSessionIndex{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+	}
+
+	if v, code, err := ctl.s.GetSession(&index); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		RenameSession
+// @Description	rename the chat session
+// @Tags			BigModel
+// @Param			id		path	string						true	"session id"
+// @Param			body	body	chatSessionRenameRequest	true	"body of renaming session"
+// @Accept			json
+// @Success		202
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/chat/sessions/{id} [put]
+func (ctl *BigModelChatController) RenameSession(ctx *gin.Context) {
+	req := chatSessionRenameRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	title, err := domain.NewChatSessionTitle(req.Title)
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd := app.ChatSessionRenameCmd{
+		ChatSessionIndex: domain.ChatSessionIndex{
+			Owner: pl.DomainAccount(),
+			Id:    ctx.Param("id"),
+		},
+		Title: title,
+	}
+
+	if code, err := ctl.s.RenameSession(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newResponseData("success"))
+}
+
+// @Summary		DeleteSession
+// @Description	delete the chat session
+// @Tags			BigModel
+// @Param			id	path	string	true	"session id"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/chat/sessions/{id} [delete]
+func (ctl *BigModelChatController) DeleteSession(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "delete chat session")
+
+	index := domain.ChatSessionIndex{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+	}
+
+	if err := ctl.s.DeleteSession(&index); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "delete chat session",
+		fmt.Sprintf("sessionid: %s", index.Id), "success")
+
+	ctl.sendRespOfDelete(ctx)
+}
+
+// sendReplyEvent sends the message of streamed reply by SSE. The stream
+// ends with the error event if the reply is rejected by the moderation or
+// can't be checked.
+func sendReplyEvent(ctx *gin.Context, msg string) {
+	switch msg {
+	case "done":
+		ctx.SSEvent("status", "done")
+
+	case moderation.MsgRejected:
+		ctx.SSEvent("error", newResponseCodeMsg(
+			app.ErrorBigModelSensitiveInfo, "the reply is stopped for sensitive content",
+		))
+
+	case moderation.MsgFailed:
+		ctx.SSEvent("error", newResponseCodeMsg(
+			errorSystemError, "the reply is stopped for failing to check it",
+		))
+
+	default:
+		ctx.SSEvent("message", msg)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	userapp "github.com/opensourceways/xihe-server/user/app"
+)
+
+func AddRouterForBigModelEndpointController(
+	rg *gin.RouterGroup,
+	s app.BigModelService,
+	whitelist userapp.WhiteListService,
+) {
+	ctl := BigModelEndpointController{
+		s:         s,
+		whitelist: whitelist,
+	}
+
+	rg.GET("/v1/bigmodel/endpoints", ctl.List)
+}
+
+type BigModelEndpointController struct {
+	baseController
+
+	s         app.BigModelService
+	whitelist userapp.WhiteListService
+}
+
+// @Summary		List
+// @Description	list the state of endpoint pools of models, only for admin
+// @Tags			BigModel
+// @Accept			json
+// @Success		200	{object}		[]app.EndpointPoolDTO
+// @Failure		403	not_allowed		not		allowed
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/endpoints [get]
+func (ctl *BigModelEndpointController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	ctl.sendRespOfGet(ctx, ctl.s.ListEndpointPools())
+}
@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+func AddRouterForBigModelPromptController(
+	rg *gin.RouterGroup,
+	s app.PromptService,
+) {
+	ctl := BigModelPromptController{
+		s: s,
+	}
+
+	rg.POST("/v1/bigmodel/prompts", ctl.Create)
+	rg.GET("/v1/bigmodel/prompts", ctl.List)
+	rg.GET("/v1/bigmodel/prompts/public", ctl.ListPublic)
+	rg.GET("/v1/bigmodel/prompts/:id", ctl.Get)
+	rg.PUT("/v1/bigmodel/prompts/:id", ctl.Update)
+	rg.DELETE("/v1/bigmodel/prompts/:id", ctl.Delete)
+	rg.POST("/v1/bigmodel/prompts/:id/like", ctl.Like)
+	rg.DELETE("/v1/bigmodel/prompts/:id/like", ctl.Unlike)
+	rg.POST("/v1/bigmodel/prompts/:id/preview/:kind", ctl.Preview)
+}
+
+type BigModelPromptController struct {
+	baseController
+
+	s app.PromptService
+}
+
+// @Summary		Create
+// @Description	create a prompt template, the variables are written as {name} in the content
+// @Tags			BigModel
+// @Param			body	body	promptCreateRequest	true	"body of prompt template"
+// @Accept			json
+// @Success		201	{object}			app.PromptTemplateDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/prompts [post]
+func (ctl *BigModelPromptController) Create(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := promptCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "create prompt template")
+
+	if v, code, err := ctl.s.Create(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, v)
+	}
+}
+
+// @Summary		List
+// @Description	list the prompt templates of user
+// @Tags			BigModel
+// @Accept			json
+// @Success		200	{object}		[]app.PromptTemplateDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/prompts [get]
+func (ctl *BigModelPromptController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, err := ctl.s.List(pl.DomainAccount()); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		ListPublic
+// @Description	list the public prompt templates, the most liked first
+// @Tags			BigModel
+// @Param			kind	query	string	false	"wukong or chat"
+// @Accept			json
+// @Success		200	{object}		[]app.PromptTemplateDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/prompts/public [get]
+func (ctl *BigModelPromptController) ListPublic(ctx *gin.Context) {
+	if _, _, ok := ctl.checkUserApiToken(ctx, true); !ok {
+		return
+	}
+
+	kind := ctl.getQueryParameter(ctx, "kind")
+	if kind != "" {
+		if _, err := domain.NewPromptKind(kind); err != nil {
+			ctl.sendBadRequestParam(ctx, err)
+
+			return
+		}
+	}
+
+	if v, err := ctl.s.ListPublic(kind); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Get
+// @Description	get the prompt template with all the versions
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of prompt template"
+// @Accept			json
+// @Success		200	{object}		app.PromptTemplateDetailDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/prompts/{id} [get]
+func (ctl *BigModelPromptController) Get(ctx *gin.Context) {
+	pl, visitor, ok := ctl.checkUserApiToken(ctx, true)
+	if !ok {
+		return
+	}
+
+	var user types.Account
+	if !visitor {
+		user = pl.DomainAccount()
+	}
+
+	if v, code, err := ctl.s.Get(user, ctx.Param("id")); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Update
+// @Description	update the prompt template, a new version is added if the content is changed
+// @Tags			BigModel
+// @Param			id		path	string				true	"id of prompt template"
+// @Param			body	body	promptUpdateRequest	true	"body of prompt template"
+// @Accept			json
+// @Success		202	{object}			app.PromptTemplateDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/prompts/{id} [put]
+func (ctl *BigModelPromptController) Update(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := promptUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount(), ctx.Param("id"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "update prompt template")
+
+	if v, code, err := ctl.s.Update(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPut(ctx, v)
+	}
+}
+
+// @Summary		Delete
+// @Description	delete the prompt template
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of prompt template"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/prompts/{id} [delete]
+func (ctl *BigModelPromptController) Delete(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "delete prompt template")
+
+	if code, err := ctl.s.Delete(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfDelete(ctx)
+	}
+}
+
+// @Summary		Like
+// @Description	like the public prompt template
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of prompt template"
+// @Accept			json
+// @Success		201
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/prompts/{id}/like [post]
+func (ctl *BigModelPromptController) Like(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if code, err := ctl.s.Like(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, "success")
+	}
+}
+
+// @Summary		Unlike
+// @Description	cancel the like of the public prompt template
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of prompt template"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/prompts/{id}/like [delete]
+func (ctl *BigModelPromptController) Unlike(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if code, err := ctl.s.Unlike(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfDelete(ctx)
+	}
+}
+
+// @Summary		Preview
+// @Description	render the prompt template without calling the model
+// @Tags			BigModel
+// @Param			id		path	string				true	"id of prompt template"
+// @Param			kind	path	string				true	"wukong or chat"
+// @Param			body	body	promptRenderRequest	true	"version and variables, the id in body is ignored"
+// @Accept			json
+// @Success		201	{object}			promptPreviewResp
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/prompts/{id}/preview/{kind} [post]
+func (ctl *BigModelPromptController) Preview(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := promptRenderRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	req.Id = ctx.Param("id")
+
+	if v, ok := ctl.renderPrompt(ctx, ctl.s, &req, pl.DomainAccount(), ctx.Param("kind")); ok {
+		ctl.sendRespOfPost(ctx, promptPreviewResp{v})
+	}
+}
+
+// renderPrompt renders the prompt template specified in the request of
+// models, and sends the response if failed.
+func (ctl baseController) renderPrompt(
+	ctx *gin.Context, s app.PromptService,
+	req *promptRenderRequest, user types.Account, kind string,
+) (string, bool) {
+	cmd, err := req.toCmd(user, kind)
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return "", false
+	}
+
+	v, code, err := s.Render(&cmd)
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return "", false
+	}
+
+	return v, true
+}
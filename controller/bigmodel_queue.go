@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+)
+
+func AddRouterForBigModelQueueController(
+	rg *gin.RouterGroup,
+	s app.QueueService,
+) {
+	ctl := BigModelQueueController{
+		s: s,
+	}
+
+	rg.POST("/v1/bigmodel/queue", ctl.Submit)
+	rg.GET("/v1/bigmodel/queue/:id", ctl.Get)
+	rg.GET("/v1/bigmodel/queue/:id/result", ctl.GetResult)
+	rg.DELETE("/v1/bigmodel/queue/:id", ctl.Cancel)
+}
+
+type BigModelQueueController struct {
+	baseController
+
+	s app.QueueService
+}
+
+// @Summary		Submit
+// @Description	submit the invocation of chat model or wukong to the queue, it is run once the model is free
+// @Tags			BigModel
+// @Param			body	body	queueTaskRequest	true	"body of task"
+// @Accept			json
+// @Success		201	{object}			app.QueueTaskDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/queue [post]
+func (ctl *BigModelQueueController) Submit(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "submit bigmodel task to queue")
+
+	req := queueTaskRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	v, code, err := ctl.s.Submit(&cmd)
+	if err != nil {
+		if code == app.ErrorBigModelRateLimited || code == app.ErrorBigModelQuotaExceeded {
+			ctx.JSON(http.StatusTooManyRequests, newResponseCodeError(code, err))
+		} else {
+			ctl.sendCodeMessage(ctx, code, err)
+		}
+
+		return
+	}
+
+	ctl.sendRespOfPost(ctx, v)
+}
+
+// @Summary		Get
+// @Description	get the status of task, including the rank and the estimated seconds to wait
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of task"
+// @Accept			json
+// @Success		200	{object}			app.QueueTaskDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/queue/{id} [get]
+func (ctl *BigModelQueueController) Get(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.s.Get(ctl.index(ctx, pl))
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		GetResult
+// @Description	get the result of finished task
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of task"
+// @Accept			json
+// @Success		200	{object}			app.QueueTaskResultDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/queue/{id}/result [get]
+func (ctl *BigModelQueueController) GetResult(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.s.GetResult(ctl.index(ctx, pl))
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Cancel
+// @Description	cancel the task which is waiting
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of task"
+// @Accept			json
+// @Success		204
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/queue/{id} [delete]
+func (ctl *BigModelQueueController) Cancel(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "cancel bigmodel task in queue")
+
+	if code, err := ctl.s.Cancel(ctl.index(ctx, pl)); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfDelete(ctx)
+	}
+}
+
+func (ctl *BigModelQueueController) index(ctx *gin.Context, pl *oldUserTokenPayload) *domain.QueueTaskIndex {
+	return &domain.QueueTaskIndex{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+	}
+}
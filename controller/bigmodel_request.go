@@ -1,11 +1,21 @@
 package controller
 
 import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
 	"github.com/opensourceways/xihe-server/bigmodel/app"
 	"github.com/opensourceways/xihe-server/bigmodel/domain"
 	types "github.com/opensourceways/xihe-server/domain"
 	userapp "github.com/opensourceways/xihe-server/user/app"
 	userd "github.com/opensourceways/xihe-server/user/domain"
+	"github.com/opensourceways/xihe-server/utils"
 )
 
 type pictureUploadResp struct {
@@ -20,6 +30,9 @@ type wukongRequest struct {
 	Desc        string `json:"desc"`
 	Style       string `json:"style"`
 	ImgQuantity int    `json:"img_quantity"`
+
+	// Template is rendered as the desc if it is set.
+	Template *promptRenderRequest `json:"template"`
 }
 
 func (req *wukongRequest) toCmd() (cmd app.WuKongCmd, err error) {
@@ -477,3 +490,406 @@ func (req *iflyteksparkRequest) toCmd(ch chan string, user types.Account) (cmd a
 
 	return
 }
+
+// chat
+type chatMessageRequest struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model             string               `json:"model"`
+	SessionId         string               `json:"session_id"`
+	Messages          []chatMessageRequest `json:"messages"`
+	Sampling          bool                 `json:"sampling"`
+	TopK              int                  `json:"top_k"`
+	TopP              float64              `json:"top_p"`
+	Temperature       float64              `json:"temperature"`
+	RepetitionPenalty float64              `json:"repetition_penalty"`
+
+	// Template is rendered and appended as the last message of user if it is set.
+	Template *promptRenderRequest `json:"template"`
+}
+
+func (req *chatRequest) toCmd(ch chan string, user types.Account) (cmd app.ChatCmd, err error) {
+	if cmd.Model, err = domain.NewChatModel(req.Model); err != nil {
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		err = errors.New("no chat messages")
+
+		return
+	}
+
+	cmd.Messages = make([]domain.ChatMessage, len(req.Messages))
+	for i := range req.Messages {
+		item := &req.Messages[i]
+
+		if cmd.Messages[i], err = domain.NewChatMessage(item.Role, item.Content); err != nil {
+			return
+		}
+	}
+
+	if req.Sampling {
+		if cmd.TopK, err = domain.NewTopK(req.TopK); err != nil {
+			return
+		}
+
+		if cmd.TopP, err = domain.NewTopP(req.TopP); err != nil {
+			return
+		}
+
+		if cmd.Temperature, err = domain.NewTemperature(req.Temperature); err != nil {
+			return
+		}
+
+		if cmd.RepetitionPenalty, err = domain.NewRepetitionPenalty(req.RepetitionPenalty); err != nil {
+			return
+		}
+	} else {
+		cmd.SetDefault()
+	}
+
+	cmd.CH = ch
+	cmd.Sampling = req.Sampling
+	cmd.User = user
+	cmd.SessionId = req.SessionId
+
+	return
+}
+
+type chatSessionRenameRequest struct {
+	Title string `json:"title"`
+}
+
+// queue
+type queueTaskRequest struct {
+	Model string `json:"model"`
+
+	// chat model
+	Messages          []chatMessageRequest `json:"messages"`
+	Sampling          bool                 `json:"sampling"`
+	TopK              int                  `json:"top_k"`
+	TopP              float64              `json:"top_p"`
+	Temperature       float64              `json:"temperature"`
+	RepetitionPenalty float64              `json:"repetition_penalty"`
+
+	// wukong
+	Desc  string `json:"desc"`
+	Style string `json:"style"`
+}
+
+func (req *queueTaskRequest) toCmd(user types.Account) (cmd app.QueueTaskCmd, err error) {
+	if cmd.Model, err = domain.NewModelName(req.Model); err != nil {
+		return
+	}
+
+	cmd.User = user
+
+	if cmd.Model.IsWuKong() {
+		cmd.Picture.Style = req.Style
+		cmd.Picture.Desc, err = domain.NewWuKongPictureDesc(req.Desc)
+
+		return
+	}
+
+	v := chatRequest{
+		Model:             req.Model,
+		Messages:          req.Messages,
+		Sampling:          req.Sampling,
+		TopK:              req.TopK,
+		TopP:              req.TopP,
+		Temperature:       req.Temperature,
+		RepetitionPenalty: req.RepetitionPenalty,
+	}
+
+	c, err := v.toCmd(nil, user)
+	if err != nil {
+		return
+	}
+
+	if !c.Messages[len(c.Messages)-1].IsUser() {
+		err = errors.New("the last message should be of user")
+
+		return
+	}
+
+	cmd.Input = domain.ChatInput{
+		Model:        c.Model,
+		Messages:     c.Messages,
+		ChatSampling: c.ChatSampling,
+	}
+
+	return
+}
+
+type wukongTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (req *wukongTagsRequest) toCmd(user types.Account, id string) (
+	cmd app.WuKongUpdateTagsCmd, err error,
+) {
+	if cmd.Tags, err = domain.NewWuKongPictureTags(req.Tags); err != nil {
+		return
+	}
+
+	cmd.User = user
+	cmd.Id = id
+
+	return
+}
+
+type wukongCommentRequest struct {
+	Content string `json:"content"`
+}
+
+func (req *wukongCommentRequest) toCmd(index *domain.WuKongPictureIndex, user types.Account) (
+	cmd app.WuKongAddCommentCmd, err error,
+) {
+	if cmd.Content, err = domain.NewWuKongCommentContent(req.Content); err != nil {
+		return
+	}
+
+	cmd.Picture = *index
+	cmd.User = user
+
+	return
+}
+
+type wukongReportRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (req *wukongReportRequest) toCmd(index *domain.WuKongPictureIndex, user types.Account) (
+	cmd app.WuKongReportCmd, err error,
+) {
+	if req.Reason == "" || utils.StrLen(req.Reason) > wukongReportReasonMaxLen {
+		err = errors.New("invalid reason")
+
+		return
+	}
+
+	cmd.Picture = *index
+	cmd.User = user
+	cmd.Reason = utils.XSSFilter(req.Reason)
+
+	return
+}
+
+type wukongReportResp struct {
+	Id string `json:"id"`
+}
+
+type wukongReviewRequest struct {
+	// Action is confirm or dismiss
+	Action string `json:"action"`
+}
+
+func (req *wukongReviewRequest) toCmd(id string, reviewer types.Account) (
+	cmd app.WuKongReviewReportCmd, err error,
+) {
+	switch req.Action {
+	case wukongReviewConfirm:
+		cmd.Confirm = true
+	case wukongReviewDismiss:
+	default:
+		err = errors.New("unknown action")
+
+		return
+	}
+
+	cmd.Id = id
+	cmd.Reviewer = reviewer
+
+	return
+}
+
+type promptCreateRequest struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Content string `json:"content"`
+	Public  bool   `json:"public"`
+}
+
+func (req *promptCreateRequest) toCmd(owner types.Account) (cmd app.PromptCreateCmd, err error) {
+	if cmd.Name, err = domain.NewPromptName(req.Name); err != nil {
+		return
+	}
+
+	if cmd.Kind, err = domain.NewPromptKind(req.Kind); err != nil {
+		return
+	}
+
+	if cmd.Content, err = domain.NewPromptContent(req.Content); err != nil {
+		return
+	}
+
+	cmd.Owner = owner
+	cmd.Public = req.Public
+
+	return
+}
+
+type promptUpdateRequest struct {
+	Name    *string `json:"name"`
+	Content *string `json:"content"`
+	Public  *bool   `json:"public"`
+}
+
+func (req *promptUpdateRequest) toCmd(owner types.Account, id string) (
+	cmd app.PromptUpdateCmd, err error,
+) {
+	if req.Name == nil && req.Content == nil && req.Public == nil {
+		err = errors.New("nothing to update")
+
+		return
+	}
+
+	if req.Name != nil {
+		if cmd.Name, err = domain.NewPromptName(*req.Name); err != nil {
+			return
+		}
+	}
+
+	if req.Content != nil {
+		if cmd.Content, err = domain.NewPromptContent(*req.Content); err != nil {
+			return
+		}
+	}
+
+	cmd.Owner = owner
+	cmd.Id = id
+	cmd.Public = req.Public
+
+	return
+}
+
+// promptRenderRequest specifies the prompt template which will be
+// rendered with the variables.
+type promptRenderRequest struct {
+	Id        string            `json:"id"`
+	Version   int               `json:"version"`
+	Variables map[string]string `json:"variables"`
+}
+
+func (req *promptRenderRequest) toCmd(user types.Account, kind string) (
+	cmd app.PromptRenderCmd, err error,
+) {
+	if req.Id == "" {
+		err = errors.New("missing id of prompt template")
+
+		return
+	}
+
+	if req.Version < 0 {
+		err = errors.New("invalid version of prompt template")
+
+		return
+	}
+
+	cmd.User = user
+	cmd.Id = req.Id
+	cmd.Kind = kind
+	cmd.Version = req.Version
+	cmd.Variables = req.Variables
+
+	return
+}
+
+type promptPreviewResp struct {
+	Prompt string `json:"prompt"`
+}
+
+const wukongBatchFileMaxSize = 1 << 20
+
+// wukongBatchPrompt is a line of the JSONL file of batch job.
+type wukongBatchPrompt struct {
+	Desc  string `json:"desc"`
+	Style string `json:"style"`
+}
+
+func (p *wukongBatchPrompt) toMeta() (meta domain.WuKongPictureMeta, err error) {
+	cmd := app.WuKongCmd{}
+	cmd.Style = strings.TrimSpace(p.Style)
+
+	if cmd.Desc, err = domain.NewWuKongPictureDesc(strings.TrimSpace(p.Desc)); err != nil {
+		return
+	}
+
+	if err = cmd.Validate(); err == nil {
+		meta = cmd.WuKongPictureMeta
+	}
+
+	return
+}
+
+// parseWuKongBatchPrompts parses the prompts from the CSV file whose columns
+// are desc and style, or the JSONL file whose lines are wukongBatchPrompt.
+func parseWuKongBatchPrompts(r io.Reader, filename string) ([]domain.WuKongPictureMeta, error) {
+	var prompts []wukongBatchPrompt
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "desc") {
+				continue // header
+			}
+
+			p := wukongBatchPrompt{Desc: record[0]}
+			if len(record) > 1 {
+				p.Style = record[1]
+			}
+
+			prompts = append(prompts, p)
+		}
+
+	case ".jsonl":
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			p := wukongBatchPrompt{}
+			if err := json.Unmarshal([]byte(line), &p); err != nil {
+				return nil, err
+			}
+
+			prompts = append(prompts, p)
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errors.New("only csv or jsonl file is supported")
+	}
+
+	if len(prompts) == 0 {
+		return nil, errors.New("no prompts")
+	}
+
+	v := make([]domain.WuKongPictureMeta, len(prompts))
+	for i := range prompts {
+		meta, err := prompts[i].toMeta()
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt %d: %s", i+1, err.Error())
+		}
+
+		v[i] = meta
+	}
+
+	return v, nil
+}
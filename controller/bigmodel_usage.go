@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	userapp "github.com/opensourceways/xihe-server/user/app"
+)
+
+const defaultApiUsageDays = 7
+
+func AddRouterForBigModelUsageController(
+	rg *gin.RouterGroup,
+	s app.ApiUsageService,
+	whitelist userapp.WhiteListService,
+) {
+	ctl := BigModelUsageController{
+		s:         s,
+		whitelist: whitelist,
+	}
+
+	rg.GET("/v1/bigmodel/api/usage", ctl.GetUsage)
+	rg.GET("/v1/bigmodel/api/usage/report", ctl.GetReport)
+}
+
+type BigModelUsageController struct {
+	baseController
+
+	s         app.ApiUsageService
+	whitelist userapp.WhiteListService
+}
+
+// @Summary		GetUsage
+// @Description	get the usages of big model apis called by user
+// @Tags			BigModel
+// @Param			days	query	int	false	"the recent days, default is 7"
+// @Accept			json
+// @Success		200	{object}			app.ApiUsageStatsDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/api/usage [get]
+func (ctl *BigModelUsageController) GetUsage(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	cmd := app.ApiUsageQueryCmd{User: pl.DomainAccount()}
+	if cmd.Days, ok = ctl.getDays(ctx); !ok {
+		return
+	}
+
+	ctl.getUsage(ctx, &cmd)
+}
+
+// @Summary		GetReport
+// @Description	get the usages of big model apis called by all users, only for admin
+// @Tags			BigModel
+// @Param			days	query	int	false	"the recent days, default is 7"
+// @Accept			json
+// @Success		200	{object}			app.ApiUsageStatsDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		403	not_allowed			not		allowed
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/api/usage/report [get]
+func (ctl *BigModelUsageController) GetReport(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "get usage report of bigmodel api")
+
+	cmd := app.ApiUsageQueryCmd{}
+	if cmd.Days, ok = ctl.getDays(ctx); !ok {
+		return
+	}
+
+	ctl.getUsage(ctx, &cmd)
+}
+
+func (ctl *BigModelUsageController) getDays(ctx *gin.Context) (int, bool) {
+	v := ctl.getQueryParameter(ctx, "days")
+	if v == "" {
+		return defaultApiUsageDays, true
+	}
+
+	days, err := strconv.Atoi(v)
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return 0, false
+	}
+
+	return days, true
+}
+
+func (ctl *BigModelUsageController) getUsage(ctx *gin.Context, cmd *app.ApiUsageQueryCmd) {
+	if v, code, err := ctl.s.GetUsage(cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+func AddRouterForBigModelWuKongBatchController(
+	rg *gin.RouterGroup,
+	s app.WuKongBatchService,
+) {
+	ctl := BigModelWuKongBatchController{
+		s: s,
+	}
+
+	rg.POST("/v1/bigmodel/wukong/batch", ctl.Submit)
+	rg.GET("/v1/bigmodel/wukong/batch", ctl.List)
+	rg.GET("/v1/bigmodel/wukong/batch/:id", ctl.Get)
+	rg.GET("/v1/bigmodel/wukong/batch/:id/archive", ctl.Download)
+	rg.DELETE("/v1/bigmodel/wukong/batch/:id", ctl.Cancel)
+}
+
+type BigModelWuKongBatchController struct {
+	baseController
+
+	s app.WuKongBatchService
+}
+
+// @Summary		Submit
+// @Description	submit a batch job to generate pictures for each prompt of the file.
+// @Description	The columns of csv file are desc and style, each line of jsonl file is {"desc": "", "style": ""}.
+// @Tags			BigModel
+// @Param			file		formData	file	true	"csv or jsonl file of prompts"
+// @Param			name		formData	string	false	"name of job"
+// @Param			image_num	formData	int		true	"num of pictures for each prompt"
+// @Accept			multipart/form-data
+// @Success		201	{object}			app.WuKongBatchJobDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/wukong/batch [post]
+func (ctl *BigModelWuKongBatchController) Submit(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	cmd := app.WuKongBatchCmd{
+		User: pl.DomainAccount(),
+		Name: utils.XSSFilter(strings.TrimSpace(ctx.PostForm("name"))),
+	}
+
+	if max := 50; utils.StrLen(cmd.Name) > max {
+		ctl.sendBadRequestParamWithMsg(ctx, fmt.Sprintf("the length of name should be less than %d", max))
+
+		return
+	}
+
+	n, err := strconv.Atoi(ctx.PostForm("image_num"))
+	if err != nil || n <= 0 {
+		ctl.sendBadRequestParamWithMsg(ctx, "invalid image_num")
+
+		return
+	}
+	cmd.ImageNum = n
+
+	f, err := ctx.FormFile("file")
+	if err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	if f.Size > wukongBatchFileMaxSize {
+		ctl.sendBadRequestParam(ctx, errors.New("the file is too big"))
+
+		return
+	}
+
+	p, err := f.Open()
+	if err != nil {
+		ctl.sendBadRequestParamWithMsg(ctx, "can't read the file")
+
+		return
+	}
+
+	defer p.Close()
+
+	if cmd.Prompts, err = parseWuKongBatchPrompts(p, f.Filename); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "submit wukong batch job")
+
+	if v, code, err := ctl.s.Submit(&cmd); err != nil {
+		switch code {
+		case app.ErrorBigModelRateLimited, app.ErrorBigModelQuotaExceeded:
+			ctx.JSON(http.StatusTooManyRequests, newResponseCodeError(code, err))
+
+		default:
+			ctl.sendCodeMessage(ctx, code, err)
+		}
+	} else {
+		ctl.sendRespOfPost(ctx, v)
+	}
+}
+
+// @Summary		List
+// @Description	list the batch jobs of user
+// @Tags			BigModel
+// @Accept			json
+// @Success		200	{object}		[]app.WuKongBatchJobDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/batch [get]
+func (ctl *BigModelWuKongBatchController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, err := ctl.s.List(pl.DomainAccount()); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Get
+// @Description	get the progress of batch job
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of job"
+// @Accept			json
+// @Success		200	{object}		app.WuKongBatchJobDetailDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/batch/{id} [get]
+func (ctl *BigModelWuKongBatchController) Get(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, code, err := ctl.s.Get(ctl.index(ctx, pl)); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Download
+// @Description	download the zip of pictures and manifest of the batch job which is done
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of job"
+// @Accept			json
+// @Produce		application/zip
+// @Success		200
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/batch/{id}/archive [get]
+func (ctl *BigModelWuKongBatchController) Download(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.s.Archive(ctl.index(ctx, pl))
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "download wukong batch job")
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", v.FileName()))
+	ctx.Status(http.StatusOK)
+
+	if err := v.Write(ctx.Writer); err != nil {
+		logrus.Errorf("send wukong batch archive failed, err=%s", err.Error())
+	}
+}
+
+// @Summary		Cancel
+// @Description	cancel the batch job which is not done
+// @Tags			BigModel
+// @Param			id	path	string	true	"id of job"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/batch/{id} [delete]
+func (ctl *BigModelWuKongBatchController) Cancel(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "cancel wukong batch job")
+
+	if code, err := ctl.s.Cancel(ctl.index(ctx, pl)); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfDelete(ctx)
+	}
+}
+
+func (ctl *BigModelWuKongBatchController) index(
+	ctx *gin.Context, pl *oldUserTokenPayload,
+) *domain.WuKongBatchIndex {
+	return &domain.WuKongBatchIndex{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+	}
+}
@@ -0,0 +1,331 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+	userapp "github.com/opensourceways/xihe-server/user/app"
+)
+
+const (
+	wukongReviewConfirm      = "confirm"
+	wukongReviewDismiss      = "dismiss"
+	wukongReportReasonMaxLen = 200
+)
+
+func AddRouterForBigModelWuKongGalleryController(
+	rg *gin.RouterGroup,
+	s app.WuKongGalleryService,
+	whitelist userapp.WhiteListService,
+) {
+	ctl := BigModelWuKongGalleryController{
+		s:         s,
+		whitelist: whitelist,
+	}
+
+	rg.PUT("/v1/bigmodel/wukong/public/:id/tags", ctl.UpdateTags)
+	rg.GET("/v1/bigmodel/wukong/tags", ctl.ListTags)
+	rg.GET("/v1/bigmodel/wukong/publics/:owner/:id/comments", ctl.ListComments)
+	rg.POST("/v1/bigmodel/wukong/publics/:owner/:id/comments", ctl.AddComment)
+	rg.DELETE("/v1/bigmodel/wukong/comments/:id", ctl.DeleteComment)
+	rg.POST("/v1/bigmodel/wukong/publics/:owner/:id/report", ctl.Report)
+	rg.GET("/v1/bigmodel/wukong/reports", ctl.ListReports)
+	rg.PUT("/v1/bigmodel/wukong/reports/:id", ctl.ReviewReport)
+}
+
+type BigModelWuKongGalleryController struct {
+	baseController
+
+	s         app.WuKongGalleryService
+	whitelist userapp.WhiteListService
+}
+
+func (ctl *BigModelWuKongGalleryController) pictureIndex(ctx *gin.Context) (
+	index domain.WuKongPictureIndex, ok bool,
+) {
+	owner, err := types.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	index.Owner = owner
+	index.Id = ctx.Param("id")
+	ok = true
+
+	return
+}
+
+// @Summary		UpdateTags
+// @Description	update the tags of the public picture of user
+// @Tags			BigModel
+// @Param			id		path	string				true	"picture id"
+// @Param			body	body	wukongTagsRequest	true	"body of tags"
+// @Accept			json
+// @Success		202
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/wukong/public/{id}/tags [put]
+func (ctl *BigModelWuKongGalleryController) UpdateTags(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := wukongTagsRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount(), ctx.Param("id"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "update tags of wukong picture")
+
+	if code, err := ctl.s.UpdateTags(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPut(ctx, "success")
+	}
+}
+
+// @Summary		ListTags
+// @Description	list the tags of public pictures with the count of pictures
+// @Tags			BigModel
+// @Accept			json
+// @Success		200	{object}		app.WuKongTagDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/tags [get]
+func (ctl *BigModelWuKongGalleryController) ListTags(ctx *gin.Context) {
+	if _, _, ok := ctl.checkUserApiToken(ctx, true); !ok {
+		return
+	}
+
+	if v, err := ctl.s.ListTags(); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		ListComments
+// @Description	list the comments of public picture
+// @Tags			BigModel
+// @Param			owner	path	string	true	"owner of picture"
+// @Param			id		path	string	true	"picture id"
+// @Accept			json
+// @Success		200	{object}		app.WuKongCommentDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/publics/{owner}/{id}/comments [get]
+func (ctl *BigModelWuKongGalleryController) ListComments(ctx *gin.Context) {
+	if _, _, ok := ctl.checkUserApiToken(ctx, true); !ok {
+		return
+	}
+
+	index, ok := ctl.pictureIndex(ctx)
+	if !ok {
+		return
+	}
+
+	if v, code, err := ctl.s.ListComments(&index); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		AddComment
+// @Description	add a comment to public picture
+// @Tags			BigModel
+// @Param			owner	path	string					true	"owner of picture"
+// @Param			id		path	string					true	"picture id"
+// @Param			body	body	wukongCommentRequest	true	"body of comment"
+// @Accept			json
+// @Success		201	{object}			app.WuKongCommentDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/wukong/publics/{owner}/{id}/comments [post]
+func (ctl *BigModelWuKongGalleryController) AddComment(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	index, ok := ctl.pictureIndex(ctx)
+	if !ok {
+		return
+	}
+
+	req := wukongCommentRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(&index, pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "add comment to wukong picture")
+
+	if v, code, err := ctl.s.AddComment(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, v)
+	}
+}
+
+// @Summary		DeleteComment
+// @Description	delete the comment of user
+// @Tags			BigModel
+// @Param			id	path	string	true	"comment id"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/comments/{id} [delete]
+func (ctl *BigModelWuKongGalleryController) DeleteComment(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "delete comment of wukong picture")
+
+	if code, err := ctl.s.DeleteComment(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfDelete(ctx)
+	}
+}
+
+// @Summary		Report
+// @Description	report an abusive public picture for review, the picture is hidden until reviewed once enough users report it
+// @Tags			BigModel
+// @Param			owner	path	string				true	"owner of picture"
+// @Param			id		path	string				true	"picture id"
+// @Param			body	body	wukongReportRequest	true	"body of report"
+// @Accept			json
+// @Success		201	{object}			wukongReportResp
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/wukong/publics/{owner}/{id}/report [post]
+func (ctl *BigModelWuKongGalleryController) Report(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	index, ok := ctl.pictureIndex(ctx)
+	if !ok {
+		return
+	}
+
+	req := wukongReportRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(&index, pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "report wukong picture")
+
+	if id, code, err := ctl.s.Report(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, wukongReportResp{id})
+	}
+}
+
+// @Summary		ListReports
+// @Description	list the reports of public pictures, only for admin
+// @Tags			BigModel
+// @Param			status	query	string	false	"pending, confirmed or dismissed"
+// @Accept			json
+// @Success		200	{object}		app.WuKongReportDTO
+// @Failure		403	not_allowed		not	allowed
+// @Failure		500	system_error	system	error
+// @Router			/v1/bigmodel/wukong/reports [get]
+func (ctl *BigModelWuKongGalleryController) ListReports(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	if v, err := ctl.s.ListReports(ctl.getQueryParameter(ctx, "status")); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		ReviewReport
+// @Description	confirm or dismiss the report, only for admin
+// @Tags			BigModel
+// @Param			id		path	string				true	"report id"
+// @Param			body	body	wukongReviewRequest	true	"body of review"
+// @Accept			json
+// @Success		202
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		403	not_allowed			not		allowed
+// @Failure		500	system_error		system	error
+// @Router			/v1/bigmodel/wukong/reports/{id} [put]
+func (ctl *BigModelWuKongGalleryController) ReviewReport(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	req := wukongReviewRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(ctx.Param("id"), pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "review report of wukong picture")
+
+	if code, err := ctl.s.ReviewReport(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPut(ctx, "success")
+	}
+}
@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/cloud/app"
+	userapp "github.com/opensourceways/xihe-server/user/app"
+)
+
+func AddRouterForCloudConfController(
+	rg *gin.RouterGroup,
+	s app.CloudConfService,
+	whitelist userapp.WhiteListService,
+) {
+	ctl := CloudConfController{
+		s:         s,
+		whitelist: whitelist,
+	}
+
+	rg.GET("/v1/cloud/admin/conf", ctl.List)
+	rg.POST("/v1/cloud/admin/conf", ctl.Create)
+	rg.PUT("/v1/cloud/admin/conf/:cid", ctl.Update)
+	rg.PUT("/v1/cloud/admin/conf/:cid/images", ctl.UpdateImages)
+	rg.PUT("/v1/cloud/admin/conf/:cid/specs", ctl.UpdateSpecs)
+}
+
+type CloudConfController struct {
+	baseController
+
+	s         app.CloudConfService
+	whitelist userapp.WhiteListService
+}
+
+// @Summary		List
+// @Description	list the cloud configs with images and capacity, only for admin
+// @Tags			Cloud
+// @Accept			json
+// @Success		200	{object}		[]app.CloudConfDetailDTO
+// @Failure		403	not_allowed		not		allowed
+// @Failure		500	system_error	system	error
+// @Router			/v1/cloud/admin/conf [get]
+func (ctl *CloudConfController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	if v, err := ctl.s.List(); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Create
+// @Description	create a cloud config, only for admin
+// @Tags			Cloud
+// @Param			body	body	cloudConfCreateRequest	true	"body of cloud config"
+// @Accept			json
+// @Success		201	{object}			app.CloudConfDetailDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		403	not_allowed			not		allowed
+// @Failure		500	system_error		system	error
+// @Router			/v1/cloud/admin/conf [post]
+func (ctl *CloudConfController) Create(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	req := cloudConfCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(
+		ctx, pl.Account, OPERATE_TYPE_USER,
+		fmt.Sprintf("create cloud config %s", req.Id),
+	)
+
+	if v, code, err := ctl.s.Create(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, v)
+	}
+}
+
+// @Summary		Update
+// @Description	update the basic info and capacity of cloud config, only for admin.
+// @Description	The fields which are not set will not be changed. The version is the one
+// @Description	of the config got before, and the update fails if it has been changed.
+// @Tags			Cloud
+// @Param			cid		path	string					true	"id of cloud"
+// @Param			body	body	cloudConfUpdateRequest	true	"body of cloud config"
+// @Accept			json
+// @Success		202	{object}			app.CloudConfDetailDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		403	not_allowed			not		allowed
+// @Failure		500	system_error		system	error
+// @Router			/v1/cloud/admin/conf/{cid} [put]
+func (ctl *CloudConfController) Update(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	req := cloudConfUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount(), ctx.Param("cid"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(
+		ctx, pl.Account, OPERATE_TYPE_USER,
+		fmt.Sprintf("update cloud config %s", cmd.Id),
+	)
+
+	if v, code, err := ctl.s.Update(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPut(ctx, v)
+	}
+}
+
+// @Summary		UpdateImages
+// @Description	replace the images of cloud config, only for admin.
+// @Description	The images used by the holding pods can't be removed.
+// @Tags			Cloud
+// @Param			cid		path	string						true	"id of cloud"
+// @Param			body	body	cloudImagesUpdateRequest	true	"body of images"
+// @Accept			json
+// @Success		202	{object}			app.CloudConfDetailDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		403	not_allowed			not		allowed
+// @Failure		500	system_error		system	error
+// @Router			/v1/cloud/admin/conf/{cid}/images [put]
+func (ctl *CloudConfController) UpdateImages(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	req := cloudImagesUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	images, err := toCloudImages(req.Images)
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd := app.CloudImagesUpdateCmd{
+		Admin:   pl.DomainAccount(),
+		Id:      ctx.Param("cid"),
+		Version: *req.Version,
+		Images:  images,
+	}
+
+	prepareOperateLog(
+		ctx, pl.Account, OPERATE_TYPE_USER,
+		fmt.Sprintf("update images of cloud config %s", cmd.Id),
+	)
+
+	if v, code, err := ctl.s.UpdateImages(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPut(ctx, v)
+	}
+}
+
+// @Summary		UpdateSpecs
+// @Description	replace the specs of cloud config, only for admin.
+// @Description	The specs used by the holding pods can't be removed.
+// @Tags			Cloud
+// @Param			cid		path	string					true	"id of cloud"
+// @Param			body	body	cloudSpecsUpdateRequest	true	"body of specs"
+// @Accept			json
+// @Success		202	{object}			app.CloudConfDetailDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		403	not_allowed			not		allowed
+// @Failure		500	system_error		system	error
+// @Router			/v1/cloud/admin/conf/{cid}/specs [put]
+func (ctl *CloudConfController) UpdateSpecs(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if !ctl.checkAdmin(ctx, ctl.whitelist, pl.DomainAccount()) {
+		return
+	}
+
+	req := cloudSpecsUpdateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	specs, err := toCloudSpecs(req.Specs)
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd := app.CloudSpecsUpdateCmd{
+		Admin:   pl.DomainAccount(),
+		Id:      ctx.Param("cid"),
+		Version: *req.Version,
+		Specs:   specs,
+	}
+
+	prepareOperateLog(
+		ctx, pl.Account, OPERATE_TYPE_USER,
+		fmt.Sprintf("update specs of cloud config %s", cmd.Id),
+	)
+
+	if v, code, err := ctl.s.UpdateSpecs(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPut(ctx, v)
+	}
+}
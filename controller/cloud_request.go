@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
 	cloudapp "github.com/opensourceways/xihe-server/cloud/app"
 	cloudtypes "github.com/opensourceways/xihe-server/cloud/domain"
 	"github.com/opensourceways/xihe-server/domain"
@@ -23,3 +28,203 @@ func (req *cloudSubscribeRequest) toCmd(user domain.Account) cloudapp.SubscribeC
 
 	return cmd
 }
+
+var cloudIdRegexp = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+type cloudImageRequest struct {
+	Alias string `json:"alias"`
+	Image string `json:"image"`
+}
+
+func toCloudImages(req []cloudImageRequest) ([]cloudtypes.CloudImage, error) {
+	v := make([]cloudtypes.CloudImage, len(req))
+
+	for i := range req {
+		alias, err := cloudtypes.NewCloudImageAlias(strings.TrimSpace(req[i].Alias))
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias of image: %s", err.Error())
+		}
+
+		image, err := cloudtypes.NewICloudImage(strings.TrimSpace(req[i].Image))
+		if err != nil {
+			return nil, fmt.Errorf("invalid image: %s", err.Error())
+		}
+
+		v[i] = cloudtypes.CloudImage{Alias: alias, Image: image}
+	}
+
+	return v, nil
+}
+
+type cloudSpecRequest struct {
+	Desc     string `json:"desc"`
+	CardsNum int    `json:"cards_num"`
+}
+
+func toCloudSpecs(req []cloudSpecRequest) ([]cloudtypes.CloudSpec, error) {
+	v := make([]cloudtypes.CloudSpec, len(req))
+
+	for i := range req {
+		desc, err := cloudtypes.NewCloudSpecDesc(strings.TrimSpace(req[i].Desc))
+		if err != nil {
+			return nil, fmt.Errorf("invalid desc of spec: %s", err.Error())
+		}
+
+		num, err := cloudtypes.NewCloudSpecCardsNum(req[i].CardsNum)
+		if err != nil {
+			return nil, err
+		}
+
+		v[i] = cloudtypes.CloudSpec{Desc: desc, CardsNum: num}
+	}
+
+	return v, nil
+}
+
+type cloudConfCreateRequest struct {
+	Id            string              `json:"id"`
+	Name          string              `json:"name"`
+	Feature       string              `json:"feature"`
+	Processor     string              `json:"processor"`
+	SingleLimited int                 `json:"single_limited"`
+	MultiLimited  int                 `json:"multi_limited"`
+	Credit        int64               `json:"credit"`
+	Images        []cloudImageRequest `json:"images"`
+	Specs         []cloudSpecRequest  `json:"specs"`
+}
+
+func (req *cloudConfCreateRequest) toCmd(admin domain.Account) (
+	cmd cloudapp.CloudConfCreateCmd, err error,
+) {
+	if !cloudIdRegexp.MatchString(req.Id) {
+		err = errors.New("invalid id, only lowercase letters, digits and _ are allowed")
+
+		return
+	}
+
+	cmd.Admin = admin
+
+	c := &cmd.Conf
+	c.Id = req.Id
+
+	if c.Name, err = cloudtypes.NewCloudName(strings.TrimSpace(req.Name)); err != nil {
+		return
+	}
+
+	if c.Feature, err = cloudtypes.NewCloudFeature(strings.TrimSpace(req.Feature)); err != nil {
+		return
+	}
+
+	if c.Processor, err = cloudtypes.NewCloudProcessor(strings.TrimSpace(req.Processor)); err != nil {
+		return
+	}
+
+	if c.SingleLimited, err = cloudtypes.NewCloudLimited(req.SingleLimited); err != nil {
+		return
+	}
+
+	if c.MultiLimited, err = cloudtypes.NewCloudLimited(req.MultiLimited); err != nil {
+		return
+	}
+
+	if c.Credit, err = cloudtypes.NewCredit(req.Credit); err != nil {
+		return
+	}
+
+	if c.Images, err = toCloudImages(req.Images); err != nil {
+		return
+	}
+
+	c.Specs, err = toCloudSpecs(req.Specs)
+
+	return
+}
+
+// cloudConfUpdateRequest is the change of cloud config, version is the one
+// of the config got before.
+type cloudConfUpdateRequest struct {
+	Version       *int    `json:"version" binding:"required"`
+	Name          *string `json:"name"`
+	Feature       *string `json:"feature"`
+	Processor     *string `json:"processor"`
+	SingleLimited *int    `json:"single_limited"`
+	MultiLimited  *int    `json:"multi_limited"`
+	Credit        *int64  `json:"credit"`
+}
+
+func (req *cloudConfUpdateRequest) toCmd(admin domain.Account, cid string) (
+	cmd cloudapp.CloudConfUpdateCmd, err error,
+) {
+	cmd.Admin = admin
+	cmd.Id = cid
+	cmd.Version = *req.Version
+
+	if req.Name != nil {
+		if cmd.Name, err = cloudtypes.NewCloudName(strings.TrimSpace(*req.Name)); err != nil {
+			return
+		}
+	}
+
+	if req.Feature != nil {
+		if cmd.Feature, err = cloudtypes.NewCloudFeature(strings.TrimSpace(*req.Feature)); err != nil {
+			return
+		}
+	}
+
+	if req.Processor != nil {
+		if cmd.Processor, err = cloudtypes.NewCloudProcessor(strings.TrimSpace(*req.Processor)); err != nil {
+			return
+		}
+	}
+
+	if req.SingleLimited != nil {
+		if cmd.SingleLimited, err = cloudtypes.NewCloudLimited(*req.SingleLimited); err != nil {
+			return
+		}
+	}
+
+	if req.MultiLimited != nil {
+		if cmd.MultiLimited, err = cloudtypes.NewCloudLimited(*req.MultiLimited); err != nil {
+			return
+		}
+	}
+
+	if req.Credit != nil {
+		cmd.Credit, err = cloudtypes.NewCredit(*req.Credit)
+	}
+
+	return
+}
+
+type cloudImagesUpdateRequest struct {
+	Version *int                `json:"version" binding:"required"`
+	Images  []cloudImageRequest `json:"images"`
+}
+
+type cloudSpecsUpdateRequest struct {
+	Version *int               `json:"version" binding:"required"`
+	Specs   []cloudSpecRequest `json:"specs"`
+}
+
+type cloudReserveRequest struct {
+	CloudId  string `json:"cloud_id" binding:"required"`
+	Image    string `json:"image" binding:"required"`
+	CardsNum int    `json:"cards_num" binding:"required,min=1"`
+	StartAt  int64  `json:"start_at" binding:"required"`
+}
+
+func (req *cloudReserveRequest) toCmd(user domain.Account) (
+	cmd cloudapp.ReserveCmd, err error,
+) {
+	cmd.User = user
+	cmd.CloudId = req.CloudId
+	cmd.StartAt = req.StartAt
+
+	if cmd.ImageAlias, err = cloudtypes.NewCloudImageAlias(req.Image); err != nil {
+		return
+	}
+
+	cmd.CardsNum, err = cloudtypes.NewCloudSpecCardsNum(req.CardsNum)
+
+	return
+}
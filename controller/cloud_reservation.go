@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/cloud/app"
+	cloudtypes "github.com/opensourceways/xihe-server/cloud/domain"
+)
+
+func AddRouterForCloudReservationController(
+	rg *gin.RouterGroup,
+	s app.ReservationService,
+) {
+	ctl := CloudReservationController{
+		s: s,
+	}
+
+	rg.POST("/v1/cloud/reservation", checkUserEmailMiddleware(&ctl.baseController), ctl.Reserve)
+	rg.GET("/v1/cloud/reservation", ctl.List)
+	rg.GET("/v1/cloud/reservation/slots", ctl.ListSlots)
+	rg.DELETE("/v1/cloud/reservation/:id", ctl.Cancel)
+	rg.POST("/v1/cloud/reservation/:id/checkin", ctl.CheckIn)
+}
+
+type CloudReservationController struct {
+	baseController
+
+	s app.ReservationService
+}
+
+// @Summary		Reserve
+// @Description	reserve the cards of cloud for a time slot, the pod will be started at the slot
+// @Tags			Cloud
+// @Param			body	body	cloudReserveRequest	true	"body of reservation"
+// @Accept			json
+// @Success		201	{object}			app.ReservationDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		400	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/cloud/reservation [post]
+func (ctl *CloudReservationController) Reserve(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := cloudReserveRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "reserve cloud")
+
+	if v, code, err := ctl.s.Reserve(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, v)
+	}
+}
+
+// @Summary		List
+// @Description	list the cloud reservations of user
+// @Tags			Cloud
+// @Accept			json
+// @Success		200	{object}		[]app.ReservationDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/cloud/reservation [get]
+func (ctl *CloudReservationController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, err := ctl.s.List(pl.DomainAccount()); err != nil {
+		ctl.sendCodeMessage(ctx, "", err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		ListSlots
+// @Description	list the time slots which can be reserved and the num of idle cards of them
+// @Tags			Cloud
+// @Param			cloud_id	query	string	true	"cloud config id"
+// @Param			cards_num	query	int		true	"num of cards"
+// @Accept			json
+// @Success		200	{object}			[]app.ReservationSlotDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/cloud/reservation/slots [get]
+func (ctl *CloudReservationController) ListSlots(ctx *gin.Context) {
+	if _, _, ok := ctl.checkUserApiToken(ctx, false); !ok {
+		return
+	}
+
+	cmd := app.ReservationSlotsCmd{
+		CloudId: ctl.getQueryParameter(ctx, "cloud_id"),
+	}
+
+	if cmd.CloudId == "" {
+		ctl.sendBadRequestParamWithMsg(ctx, "missing cloud_id")
+
+		return
+	}
+
+	n, err := strconv.Atoi(ctl.getQueryParameter(ctx, "cards_num"))
+	if err != nil {
+		ctl.sendBadRequestParamWithMsg(ctx, "invalid cards_num")
+
+		return
+	}
+
+	if cmd.CardsNum, err = cloudtypes.NewCloudSpecCardsNum(n); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if v, code, err := ctl.s.ListSlots(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Cancel
+// @Description	cancel the cloud reservation which is not started
+// @Tags			Cloud
+// @Param			id	path	string	true	"id of reservation"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/cloud/reservation/{id} [delete]
+func (ctl *CloudReservationController) Cancel(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "cancel cloud reservation")
+
+	index := cloudtypes.ReservationIndex{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+	}
+
+	if code, err := ctl.s.Cancel(&index); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfDelete(ctx)
+	}
+}
+
+// @Summary		CheckIn
+// @Description	check in the cloud reservation whose pod is started,
+// @Description	otherwise the pod will be released after the grace window.
+// @Tags			Cloud
+// @Param			id	path	string	true	"id of reservation"
+// @Accept			json
+// @Success		201	{object}		app.ReservationDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/cloud/reservation/{id}/checkin [post]
+func (ctl *CloudReservationController) CheckIn(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	index := cloudtypes.ReservationIndex{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+	}
+
+	if v, code, err := ctl.s.CheckIn(&index); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfPost(ctx, v)
+	}
+}
@@ -0,0 +1,460 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/deployment/app"
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	headerAPIKey        = "X-API-Key"
+	headerAuthorization = "Authorization"
+	bearerPrefix        = "Bearer "
+)
+
+var deploymentGatewayStatus = map[string]int{
+	app.ErrorDeploymentNotFound:    http.StatusNotFound,
+	app.ErrorDeploymentInvalidKey:  http.StatusUnauthorized,
+	app.ErrorDeploymentRateLimited: http.StatusTooManyRequests,
+	app.ErrorDeploymentUnavailable: http.StatusServiceUnavailable,
+}
+
+func AddRouterForDeploymentController(
+	rg *gin.RouterGroup,
+	s app.DeploymentService,
+	gateway app.GatewayService,
+	project repository.Project,
+) {
+	ctl := DeploymentController{
+		s:       s,
+		gateway: gateway,
+		project: project,
+	}
+
+	ctl.inferenceDir, _ = domain.NewDirectory(apiConfig.InferenceDir)
+	ctl.inferenceBootFile, _ = domain.NewFilePath(apiConfig.InferenceBootFile)
+
+	rg.POST("/v1/deployment", checkUserEmailMiddleware(&ctl.baseController), ctl.Create)
+	rg.GET("/v1/deployment", ctl.List)
+	rg.GET("/v1/deployment/:id", ctl.Get)
+	rg.PUT("/v1/deployment/:id/scaling", ctl.UpdateScaling)
+	rg.POST("/v1/deployment/:id/redeploy", ctl.Redeploy)
+	rg.DELETE("/v1/deployment/:id", ctl.Delete)
+	rg.POST("/v1/deployment/:id/key", ctl.CreateKey)
+	rg.DELETE("/v1/deployment/:id/key/:kid", ctl.DeleteKey)
+	rg.GET("/v1/deployment/:id/usage", ctl.GetUsage)
+	rg.Any("/v1/deployment/:id/serve/*path", ctl.Serve)
+}
+
+type DeploymentController struct {
+	baseController
+
+	s       app.DeploymentService
+	gateway app.GatewayService
+	project repository.Project
+
+	inferenceDir      domain.Directory
+	inferenceBootFile domain.FilePath
+}
+
+// @Summary		Create
+// @Description	deploy the inference app of project persistently
+// @Tags			Deployment
+// @Param			body	body	deploymentCreateRequest	true	"body of creating deployment"
+// @Accept			json
+// @Success		201	{object}			app.DeploymentDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/deployment [post]
+func (ctl *DeploymentController) Create(ctx *gin.Context) {
+	req := deploymentCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "create deployment")
+
+	scaling, err := req.toScalingPolicy()
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	owner := pl.DomainAccount()
+
+	v, err := ctl.project.GetSummary(owner, req.ProjectId)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	level, err := getProjectResourceLevel(ctl.project, owner, v.Id)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	cmd := app.DeploymentCreateCmd{
+		RepoCmd:       ctl.repoCmd(pl),
+		Owner:         owner,
+		ProjectId:     v.Id,
+		ProjectName:   v.Name,
+		ResourceLevel: level,
+		Scaling:       scaling,
+	}
+
+	if err := cmd.Validate(); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	dto, code, err := ctl.s.Create(&cmd)
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "create deployment",
+		fmt.Sprintf("projectid: %s, deploymentid: %s", v.Id, dto.Id), "success")
+
+	ctl.sendRespOfPost(ctx, dto)
+}
+
+// @Summary		List
+// @Description	list the deployments of user
+// @Tags			Deployment
+// @Accept			json
+// @Success		200	{object}		[]app.DeploymentDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/deployment [get]
+func (ctl *DeploymentController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, err := ctl.s.List(pl.DomainAccount()); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Get
+// @Description	get the deployment with its replicas and api keys
+// @Tags			Deployment
+// @Param			id	path	string	true	"deployment id"
+// @Accept			json
+// @Success		200	{object}		app.DeploymentDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/deployment/{id} [get]
+func (ctl *DeploymentController) Get(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	if v, code, err := ctl.s.Get(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		UpdateScaling
+// @Description	update the scaling policy of deployment
+// @Tags			Deployment
+// @Param			id		path	string						true	"deployment id"
+// @Param			body	body	deploymentScalingRequest	true	"body of scaling policy"
+// @Accept			json
+// @Success		202
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/deployment/{id}/scaling [put]
+func (ctl *DeploymentController) UpdateScaling(ctx *gin.Context) {
+	req := deploymentScalingRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "update scaling of deployment")
+
+	scaling, err := req.toScalingPolicy()
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd := app.ScalingUpdateCmd{
+		Owner:   pl.DomainAccount(),
+		Id:      ctx.Param("id"),
+		Scaling: scaling,
+	}
+
+	if code, err := ctl.s.UpdateScaling(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newResponseData("success"))
+}
+
+// @Summary		Redeploy
+// @Description	deploy the latest commit of project, the old replicas keep serving until the new ones are ready
+// @Tags			Deployment
+// @Param			id	path	string	true	"deployment id"
+// @Accept			json
+// @Success		202
+// @Failure		500	system_error	system	error
+// @Router			/v1/deployment/{id}/redeploy [post]
+func (ctl *DeploymentController) Redeploy(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "redeploy")
+
+	cmd := app.DeploymentRedeployCmd{
+		RepoCmd: ctl.repoCmd(pl),
+		Owner:   pl.DomainAccount(),
+		Id:      ctx.Param("id"),
+	}
+
+	if code, err := ctl.s.Redeploy(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "redeploy",
+		fmt.Sprintf("deploymentid: %s", cmd.Id), "success")
+
+	ctx.JSON(http.StatusAccepted, newResponseData("success"))
+}
+
+// @Summary		Delete
+// @Description	delete the deployment and stop its replicas
+// @Tags			Deployment
+// @Param			id	path	string	true	"deployment id"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/deployment/{id} [delete]
+func (ctl *DeploymentController) Delete(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "delete deployment")
+
+	if err := ctl.s.Delete(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "delete deployment",
+		fmt.Sprintf("deploymentid: %s", ctx.Param("id")), "success")
+
+	ctl.sendRespOfDelete(ctx)
+}
+
+// @Summary		CreateKey
+// @Description	create api key of deployment, the key is only shown once
+// @Tags			Deployment
+// @Param			id		path	string					true	"deployment id"
+// @Param			body	body	deploymentAPIKeyRequest	true	"body of creating api key"
+// @Accept			json
+// @Success		201	{object}			app.APIKeyCreatedDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/deployment/{id}/key [post]
+func (ctl *DeploymentController) CreateKey(ctx *gin.Context) {
+	req := deploymentAPIKeyRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "create api key of deployment")
+
+	cmd, err := req.toCmd(pl.DomainAccount(), ctx.Param("id"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	v, code, err := ctl.s.CreateKey(&cmd)
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "create api key of deployment",
+		fmt.Sprintf("deploymentid: %s, keyid: %s", cmd.Id, v.Id), "success")
+
+	ctl.sendRespOfPost(ctx, v)
+}
+
+// @Summary		DeleteKey
+// @Description	delete api key of deployment
+// @Tags			Deployment
+// @Param			id	path	string	true	"deployment id"
+// @Param			kid	path	string	true	"api key id"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/deployment/{id}/key/{kid} [delete]
+func (ctl *DeploymentController) DeleteKey(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "delete api key of deployment")
+
+	code, err := ctl.s.DeleteKey(pl.DomainAccount(), ctx.Param("id"), ctx.Param("kid"))
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "delete api key of deployment",
+		fmt.Sprintf("deploymentid: %s, keyid: %s", ctx.Param("id"), ctx.Param("kid")), "success")
+
+	ctl.sendRespOfDelete(ctx)
+}
+
+// @Summary		GetUsage
+// @Description	get the daily usage of each api key of deployment
+// @Tags			Deployment
+// @Param			id		path	string	true	"deployment id"
+// @Param			days	query	int		false	"the num of latest days"
+// @Accept			json
+// @Success		200	{object}		[]app.UsageDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/deployment/{id}/usage [get]
+func (ctl *DeploymentController) GetUsage(ctx *gin.Context) {
+	req := deploymentUsageRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	cmd := app.UsageQueryCmd{
+		Owner: pl.DomainAccount(),
+		Id:    ctx.Param("id"),
+		Days:  req.Days,
+	}
+
+	if v, code, err := ctl.s.GetUsage(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Serve
+// @Description	call the inference app of deployment with the api key in the header
+// @Description	of X-API-Key or Authorization as Bearer token
+// @Tags			Deployment
+// @Param			id		path	string	true	"deployment id"
+// @Param			path	path	string	true	"path of the inference app"
+// @Success		200
+// @Failure		401	deployment_invalid_key	invalid	api	key
+// @Failure		429	deployment_rate_limited	exceed	rate	limit
+// @Failure		503	deployment_unavailable	no		replica	is	serving
+// @Router			/v1/deployment/{id}/serve/{path} [get]
+func (ctl *DeploymentController) Serve(ctx *gin.Context) {
+	cmd := app.RouteCmd{
+		Id:  ctx.Param("id"),
+		Key: apiKeyOfRequest(ctx.Request),
+	}
+
+	v, code, err := ctl.gateway.Route(&cmd)
+	if err != nil {
+		ctl.sendGatewayError(ctx, code, err, deploymentGatewayStatus)
+
+		return
+	}
+
+	r, ok := ctl.proxyByGateway(ctx, v.AccessURL, "deployment("+cmd.Id+")")
+	if !ok {
+		return
+	}
+
+	err = ctl.gateway.Record(&app.RecordCmd{
+		Id:      cmd.Id,
+		KeyId:   v.KeyId,
+		Failed:  r.failed,
+		Latency: r.latency,
+	})
+	if err != nil {
+		logrus.Errorf("record usage of deployment(%s) failed, err:%s", cmd.Id, err.Error())
+	}
+}
+
+func (ctl *DeploymentController) repoCmd(pl *oldUserTokenPayload) app.RepoCmd {
+	return app.RepoCmd{
+		User:         pl.PlatformUserInfo(),
+		InferenceDir: ctl.inferenceDir,
+		BootFile:     ctl.inferenceBootFile,
+	}
+}
+
+func apiKeyOfRequest(r *http.Request) string {
+	if v := r.Header.Get(headerAPIKey); v != "" {
+		return v
+	}
+
+	return strings.TrimPrefix(r.Header.Get(headerAuthorization), bearerPrefix)
+}
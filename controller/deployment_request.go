@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-server/deployment/app"
+	deploymentdomain "github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/domain"
+)
+
+type deploymentScalingRequest struct {
+	MinReplicas int `json:"min_replicas"`
+	MaxReplicas int `json:"max_replicas"`
+	TargetRPM   int `json:"target_rpm"`
+}
+
+func (req *deploymentScalingRequest) toScalingPolicy() (deploymentdomain.ScalingPolicy, error) {
+	return deploymentdomain.NewScalingPolicy(req.MinReplicas, req.MaxReplicas, req.TargetRPM)
+}
+
+type deploymentCreateRequest struct {
+	ProjectId string `json:"project_id"`
+
+	deploymentScalingRequest
+}
+
+type deploymentAPIKeyRequest struct {
+	Name      string `json:"name"`
+	RateLimit int    `json:"rate_limit"`
+}
+
+func (req *deploymentAPIKeyRequest) toCmd(owner domain.Account, id string) (
+	cmd app.APIKeyCreateCmd, err error,
+) {
+	if cmd.Name, err = deploymentdomain.NewAPIKeyName(req.Name); err != nil {
+		return
+	}
+
+	if req.RateLimit < 0 {
+		err = errors.New("invalid rate limit")
+
+		return
+	}
+
+	cmd.Owner = owner
+	cmd.Id = id
+	cmd.RateLimit = req.RateLimit
+
+	return
+}
+
+type deploymentUsageRequest struct {
+	Days int `form:"days"`
+}
@@ -7,32 +7,39 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/opensourceways/community-robot-lib/utils"
-	"github.com/sirupsen/logrus"
 
 	"github.com/opensourceways/xihe-server/app"
 	"github.com/opensourceways/xihe-server/domain"
 	"github.com/opensourceways/xihe-server/domain/finetune"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/repository"
+	jobapp "github.com/opensourceways/xihe-server/job/app"
 )
 
 func AddRouterForFinetuneController(
 	rg *gin.RouterGroup,
 	fs finetune.Finetune,
 	repo repository.Finetune,
+	dataset repository.Dataset,
 	sender message.Sender,
+	downloader joblog.Downloader,
+	jobs jobapp.JobNotifier,
 ) {
 	ctl := FinetuneController{
 		fs: app.NewFinetuneService(
-			fs, repo, sender,
+			fs, repo, sender, downloader, jobs,
 		),
+		dataset: dataset,
 	}
 
 	rg.POST("/v1/finetune", ctl.Create)
 	rg.GET("/v1/finetune", ctl.List)
+	rg.GET("/v1/finetune/models", ctl.ListModels)
 	rg.GET("/v1/finetune/ws", ctl.WatchFinetunes)
 	rg.GET("/v1/finetune/:id/log", ctl.Log)
 	rg.GET("/v1/finetune/:id/log/ws", ctl.WatchSingle)
+	rg.GET("/v1/finetune/:id/log/sse", ctl.StreamLog)
 	rg.PUT("/v1/finetune/:id", ctl.Terminate)
 	rg.DELETE("v1/finetune/:id", ctl.Delete)
 }
@@ -40,7 +47,8 @@ func AddRouterForFinetuneController(
 type FinetuneController struct {
 	baseController
 
-	fs app.FinetuneService
+	fs      app.FinetuneService
+	dataset repository.Dataset
 }
 
 // @Summary		Create
@@ -73,6 +81,10 @@ func (ctl *FinetuneController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !ctl.setDataset(ctx, &cmd) {
+		return
+	}
+
 	if v, code, err := ctl.fs.Create(&cmd); err != nil {
 		ctl.sendCodeMessage(ctx, code, err)
 	} else {
@@ -80,6 +92,21 @@ func (ctl *FinetuneController) Create(ctx *gin.Context) {
 	}
 }
 
+// @Summary		ListModels
+// @Description	list the models which can be finetuned and their hyperparameters
+// @Tags			Finetune
+// @Accept			json
+// @Success		200	{object}		app.FinetuneModelDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/finetune/models [get]
+func (ctl *FinetuneController) ListModels(ctx *gin.Context) {
+	if _, _, ok := ctl.checkUserApiToken(ctx, false); !ok {
+		return
+	}
+
+	ctl.sendRespOfGet(ctx, ctl.fs.ListModels())
+}
+
 // @Summary		Delete
 // @Description	delete finetune
 // @Tags			Finetune
@@ -275,11 +302,16 @@ func (ctl *FinetuneController) watchFinetunes(ws *websocket.Conn, user domain.Ac
 }
 
 // @Summary		WatchSingle
-// @Description	watch single finetune
+// @Description	stream the log of finetune by websocket
 // @Tags			Finetune
-// @Param			id	path	string	true	"finetune id"
+// @Param			id		path	string	true	"finetune id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines of each message"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
 // @Accept			json
-// @Success		200	{object}		finetuneLog
+// @Success		200	{object}		app.JobLogDTO
 // @Failure		500	system_error	system	error
 // @Router			/v1/finetune/{id}/log/ws [get]
 func (ctl *FinetuneController) WatchSingle(ctx *gin.Context) {
@@ -293,71 +325,42 @@ func (ctl *FinetuneController) WatchSingle(ctx *gin.Context) {
 		Id:    ctx.Param("id"),
 	}
 
-	// setup websocket
-	upgrader := websocket.Upgrader{
-		Subprotocols: []string{csrftoken},
-		CheckOrigin: func(r *http.Request) bool {
-			return r.Header.Get(headerSecWebsocket) == csrftoken
-		},
-	}
-
-	ws, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
-	if err != nil {
-		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	ctl.streamJobLogByWS(ctx, csrftoken, ctl.logReader(&index), app.ErrorFinetuneNotFound)
+}
 
+// @Summary		StreamLog
+// @Description	stream the log of finetune by server-sent events
+// @Tags			Finetune
+// @Param			id		path	string	true	"finetune id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines of each event"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		app.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/finetune/{id}/log/sse [get]
+func (ctl *FinetuneController) StreamLog(ctx *gin.Context) {
+	index, ok := ctl.finetuneIndex(ctx)
+	if !ok {
 		return
 	}
 
-	defer ws.Close()
-
-	for {
-		v, code, err := ctl.fs.GetJobInfo(&index)
-		if err != nil {
-			logrus.Errorf(
-				"get finetune job failed, code=%s, err=%s",
-				code, err.Error(),
-			)
-			if code == app.ErrorFinetuneNotFound {
-				break
-			}
-
-			time.Sleep(time.Second)
-
-			continue
-		}
-
-		content, err := downloadLog(v.LogPreviewURL)
-		if err != nil {
-			logrus.Errorf(
-				"download finetune log failed, err=%s", err.Error(),
-			)
-			time.Sleep(time.Second)
-
-			continue
-		}
-
-		if len(content) > 0 {
-			data := newResponseData(finetuneLog{string(content)})
-
-			if err = ws.WriteJSON(data); err != nil {
-				break
-			}
-		}
-
-		if v.IsDone {
-			break
-		}
-
-		time.Sleep(5 * time.Second)
-	}
+	ctl.streamJobLogBySSE(ctx, ctl.logReader(&index), app.ErrorFinetuneNotFound)
 }
 
 // @Summary		Log
-// @Description	download finetune log
+// @Description	read the log of finetune from the offset
 // @Tags			Finetune
-// @Param			id	path	string	true	"finetune id"
+// @Param			id		path	string	true	"finetune id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
 // @Accept			json
-// @Success		200	{object}		finetuneLog
+// @Success		200	{object}		app.JobLogDTO
 // @Failure		500	system_error	system	error
 // @Router			/v1/finetune/{id}/log [get]
 func (ctl *FinetuneController) Log(ctx *gin.Context) {
@@ -366,18 +369,12 @@ func (ctl *FinetuneController) Log(ctx *gin.Context) {
 		return
 	}
 
-	v, code, err := ctl.fs.GetJobInfo(&index)
-	if err != nil {
-		ctl.sendCodeMessage(ctx, code, err)
-
-		return
-	}
+	ctl.getJobLog(ctx, ctl.logReader(&index))
+}
 
-	content, err := downloadLog(v.LogPreviewURL)
-	if err != nil {
-		ctl.sendCodeMessage(ctx, "", err)
-	} else {
-		ctl.sendRespOfGet(ctx, finetuneLog{string(content)})
+func (ctl *FinetuneController) logReader(index *domain.FinetuneIndex) jobLogReader {
+	return func(q *app.JobLogQuery) (app.JobLogDTO, string, error) {
+		return ctl.fs.GetLog(index, q)
 	}
 }
 
@@ -1,21 +1,48 @@
 package controller
 
 import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
 	"github.com/opensourceways/xihe-server/app"
 	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/repository"
 )
 
 type finetuneCreateResp = trainingCreateResp
 
-type finetuneLog struct {
-	Log string `json:"log"`
-}
-
 type FinetuneCreateRequest struct {
 	Name            string     `json:"name"`
 	Model           string     `json:"model"`
 	Task            string     `json:"task"`
 	Hyperparameters []KeyValue `json:"hyperparameter"`
+
+	Dataset *FinetuneDatasetRef `json:"dataset"`
+}
+
+type FinetuneDatasetRef struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	File  string `json:"file"`
+}
+
+func (t *FinetuneDatasetRef) toResourceRef() (r domain.ResourceRef, err error) {
+	if r.User, err = domain.NewAccount(t.Owner); err != nil {
+		return
+	}
+
+	if r.Name, err = domain.NewResourceName(t.Name); err != nil {
+		return
+	}
+
+	if r.File, err = domain.NewInputeFilePath(t.File); err != nil {
+		return
+	}
+
+	r.Type = domain.ResourceTypeDataset
+
+	return
 }
 
 func (req *FinetuneCreateRequest) toCmd(user domain.Account) (
@@ -39,10 +66,52 @@ func (req *FinetuneCreateRequest) toCmd(user domain.Account) (
 		}
 	}
 
-	cmd.Param, err = domain.NewFinetuneParameter(req.Model, req.Task, m)
-	if err == nil {
-		err = cmd.Validate()
+	if cmd.Param, err = domain.NewFinetuneParameter(req.Model, req.Task, m); err != nil {
+		return
+	}
+
+	if req.Dataset != nil {
+		v, err1 := req.Dataset.toResourceRef()
+		if err1 != nil {
+			return cmd, err1
+		}
+
+		cmd.Dataset = &v
 	}
 
+	err = cmd.Validate()
+
 	return
 }
+
+// setDataset fills the repo id of dataset and checks whether the user
+// can use it.
+func (ctl *FinetuneController) setDataset(
+	ctx *gin.Context, cmd *app.FinetuneCreateCmd,
+) (ok bool) {
+	ref := cmd.Dataset
+	if ref == nil {
+		return true
+	}
+
+	v, err := ctl.dataset.GetByName(ref.User, ref.Name)
+	if err != nil {
+		if repository.IsErrorResourceNotExists(err) {
+			ctl.sendBadRequestParam(ctx, errors.New("can't find the dataset"))
+		} else {
+			ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		}
+
+		return
+	}
+
+	if v.IsPrivate() && v.Owner.Account() != cmd.User.Account() {
+		ctl.sendBadRequestParam(ctx, errors.New("invalid dataset"))
+
+		return
+	}
+
+	ref.RepoId = v.RepoId
+
+	return true
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// gatewayResult is the result of the request proxied by the gateway.
+type gatewayResult struct {
+	failed  bool
+	latency int64
+}
+
+// proxyByGateway proxies the request to the path of the access url
+// and writes the response back. name is used to log the errors.
+func (ctl baseController) proxyByGateway(ctx *gin.Context, accessURL, name string) (
+	r gatewayResult, ok bool,
+) {
+	target, err := url.Parse(accessURL)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	start := time.Now()
+
+	proxy := newReverseProxy(target, ctx.Param("path"))
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		logrus.Errorf("proxy %s failed, err:%s", name, err.Error())
+
+		r.failed = true
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	proxy.ServeHTTP(ctx.Writer, ctx.Request)
+
+	r.failed = r.failed || ctx.Writer.Status() >= http.StatusInternalServerError
+	r.latency = time.Since(start).Milliseconds()
+	ok = true
+
+	return
+}
+
+// sendGatewayError sends the error of routing the request. status maps
+// the code of error to the http status which is 400 by default.
+func (ctl baseController) sendGatewayError(
+	ctx *gin.Context, code string, err error, status map[string]int,
+) {
+	if code == "" {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	v, ok := status[code]
+	if !ok {
+		v = http.StatusBadRequest
+	}
+
+	ctx.JSON(v, newResponseCodeError(code, err))
+}
+
+// sandboxPolicy runs the page served by the instance in an opaque origin,
+// so that its scripts can't read the cookies or call the apis of the site.
+const sandboxPolicy = "sandbox allow-scripts allow-forms allow-popups allow-downloads"
+
+// newReverseProxy proxies the request to the path of target. The
+// credentials of the request are removed before being sent, and the
+// response can't set the cookies of the site.
+func newReverseProxy(target *url.URL, path string) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			r.URL.Path = strings.TrimSuffix(target.Path, "/") + path
+			r.URL.RawPath = ""
+			r.Host = target.Host
+
+			r.Header.Del(headerAPIKey)
+			r.Header.Del(headerAuthorization)
+			r.Header.Del(PrivateToken)
+			r.Header.Del(csrfToken)
+			r.Header.Del("Cookie")
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Del("Set-Cookie")
+			resp.Header.Set("Content-Security-Policy", sandboxPolicy)
+
+			return nil
+		},
+	}
+}
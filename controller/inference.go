@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/opensourceways/xihe-server/app"
 	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/platform"
 	"github.com/opensourceways/xihe-server/domain/repository"
@@ -24,12 +26,15 @@ func AddRouterForInferenceController(
 	repo repository.Inference,
 	project repository.Project,
 	sender message.Sender,
+	downloader joblog.Downloader,
 	whitelist userapp.WhiteListService,
+	gateway app.InferenceGatewayService,
 ) {
 	ctl := InferenceController{
 		s: app.NewInferenceService(
-			p, repo, sender, apiConfig.MinSurvivalTimeOfInference,
+			p, repo, sender, downloader, apiConfig.MinSurvivalTimeOfInference,
 		),
+		gateway:   gateway,
 		project:   project,
 		whitelist: whitelist,
 	}
@@ -38,12 +43,25 @@ func AddRouterForInferenceController(
 	ctl.inferenceBootFile, _ = domain.NewFilePath(apiConfig.InferenceBootFile)
 
 	rg.GET("/v1/inference/project/:owner/:pid", ctl.Create)
+	rg.GET("/v1/inference/project/:owner/:pid/instances", ctl.List)
+	rg.PUT("/v1/inference/project/:owner/:pid/instances/:id", ctl.Stop)
+	rg.POST("/v1/inference/project/:owner/:pid/instances/:id", ctl.Restart)
+	rg.GET("/v1/inference/project/:owner/:pid/instances/:id/log", ctl.GetLog)
+	rg.Any("/v1/inference/project/:owner/:pid/instances/:id/serve/*path", ctl.Serve)
+	rg.GET("/v1/inference/project/:owner/:pid/usage", ctl.GetUsage)
+}
+
+var inferenceGatewayStatus = map[string]int{
+	app.ErrorInferenceNotFound:    http.StatusNotFound,
+	app.ErrorInferenceRateLimited: http.StatusTooManyRequests,
+	app.ErrorInferenceNotActive:   http.StatusServiceUnavailable,
 }
 
 type InferenceController struct {
 	baseController
 
-	s app.InferenceService
+	s       app.InferenceService
+	gateway app.InferenceGatewayService
 
 	project repository.Project
 
@@ -172,7 +190,7 @@ func (ctl *InferenceController) Create(ctx *gin.Context) {
 	utils.DoLog("", pl.Account, "create gradio",
 		fmt.Sprintf("projectid: %s", v.Id), "success")
 
-	if dto.Error != "" || dto.AccessURL != "" {
+	if dto.Error != "" || dto.URL != "" {
 		if wsErr := ws.WriteJSON(newResponseData(dto)); wsErr != nil {
 			log.Errorf("inference get | web socket write err:%s", wsErr.Error())
 		}
@@ -203,7 +221,7 @@ func (ctl *InferenceController) Create(ctx *gin.Context) {
 
 		log.Debugf("info dto:%v", dto)
 
-		if dto.Error != "" || dto.AccessURL != "" {
+		if dto.Error != "" || dto.URL != "" {
 			if wsErr := ws.WriteJSON(newResponseData(dto)); wsErr != nil {
 				log.Errorf("inference create | web socket write err:%s", wsErr.Error())
 			}
@@ -223,8 +241,14 @@ func (ctl *InferenceController) Create(ctx *gin.Context) {
 	}
 }
 
-func (ctl *InferenceController) getResourceLevel(owner domain.Account, pid string) (level string, err error) {
-	resources, err := ctl.project.FindUserProjects(
+func (ctl *InferenceController) getResourceLevel(owner domain.Account, pid string) (string, error) {
+	return getProjectResourceLevel(ctl.project, owner, pid)
+}
+
+func getProjectResourceLevel(project repository.Project, owner domain.Account, pid string) (
+	level string, err error,
+) {
+	resources, err := project.FindUserProjects(
 		[]repository.UserResourceListOption{
 			{
 				Owner: owner,
@@ -245,3 +269,323 @@ func (ctl *InferenceController) getResourceLevel(owner domain.Account, pid strin
 
 	return
 }
+
+// @Summary		List
+// @Description	list all the inference instances of project
+// @Tags			Inference
+// @Param			owner	path	string	true	"project owner"
+// @Param			pid		path	string	true	"project id"
+// @Accept			json
+// @Success		200	{object}			app.InferenceInstanceDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/inference/project/{owner}/{pid}/instances [get]
+func (ctl *InferenceController) List(ctx *gin.Context) {
+	_, project, ok := ctl.getProject(ctx)
+	if !ok {
+		return
+	}
+
+	if v, err := ctl.s.List(&project); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Stop
+// @Description	stop the inference instance
+// @Tags			Inference
+// @Param			owner	path	string	true	"project owner"
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"inference instance id"
+// @Accept			json
+// @Success		202
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/inference/project/{owner}/{pid}/instances/{id} [put]
+func (ctl *InferenceController) Stop(ctx *gin.Context) {
+	pl, project, ok := ctl.getProject(ctx)
+	if !ok {
+		return
+	}
+
+	v, err := ctl.project.GetSummary(project.Owner, project.Id)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "stop inference")
+
+	cmd := app.InferenceInstanceCmd{
+		Project:     project,
+		ProjectName: v.Name,
+		Id:          ctx.Param("id"),
+	}
+
+	if code, err := ctl.s.Stop(&cmd); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "stop gradio",
+		fmt.Sprintf("projectid: %s, inferenceid: %s", project.Id, cmd.Id), "success")
+
+	ctx.JSON(http.StatusAccepted, newResponseData("success"))
+}
+
+// @Summary		Restart
+// @Description	stop the inference instance and create a new one on the latest commit
+// @Tags			Inference
+// @Param			owner	path	string	true	"project owner"
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"inference instance id"
+// @Accept			json
+// @Success		201	{object}			app.InferenceDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/inference/project/{owner}/{pid}/instances/{id} [post]
+func (ctl *InferenceController) Restart(ctx *gin.Context) {
+	pl, project, ok := ctl.getProject(ctx)
+	if !ok {
+		return
+	}
+
+	v, err := ctl.project.GetSummary(project.Owner, project.Id)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	level, err := ctl.getResourceLevel(project.Owner, project.Id)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "restart inference")
+
+	cmd := app.InferenceRestartCmd{
+		InferenceCreateCmd: app.InferenceCreateCmd{
+			ProjectId:     v.Id,
+			ProjectName:   v.Name,
+			ProjectOwner:  project.Owner,
+			ResourceLevel: level,
+			InferenceDir:  ctl.inferenceDir,
+			BootFile:      ctl.inferenceBootFile,
+		},
+		Id: ctx.Param("id"),
+	}
+
+	u := pl.PlatformUserInfo()
+
+	dto, _, code, err := ctl.s.Restart(pl.Account, &u, &cmd)
+	if err != nil {
+		if code != "" {
+			ctl.sendCodeMessage(ctx, code, err)
+		} else {
+			ctl.sendRespWithInternalError(ctx, newResponseError(err))
+		}
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "restart gradio",
+		fmt.Sprintf("projectid: %s", v.Id), "success")
+
+	ctl.sendRespOfPost(ctx, dto)
+}
+
+// @Summary		GetLog
+// @Description	read the startup or runtime log of inference instance from the offset
+// @Tags			Inference
+// @Param			owner	path	string	true	"project owner"
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"inference instance id"
+// @Param			type	query	string	false	"startup or runtime, default is runtime"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		app.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/inference/project/{owner}/{pid}/instances/{id}/log [get]
+func (ctl *InferenceController) GetLog(ctx *gin.Context) {
+	_, project, ok := ctl.getProject(ctx)
+	if !ok {
+		return
+	}
+
+	cmd := app.InferenceLogCmd{
+		Project: project,
+		Id:      ctx.Param("id"),
+		Type:    ctx.DefaultQuery("type", domain.InferenceLogRuntime),
+	}
+
+	if err := cmd.Validate(); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	ctl.getJobLog(ctx, func(q *app.JobLogQuery) (app.JobLogDTO, string, error) {
+		return ctl.s.GetLog(&cmd, q)
+	})
+}
+
+// @Summary		Serve
+// @Description	call the inference instance through the gateway which meters the usage
+// @Tags			Inference
+// @Param			owner	path	string	true	"project owner"
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"inference instance id"
+// @Param			path	path	string	true	"path of the inference app"
+// @Success		200
+// @Failure		404	inference_not_found		not		found
+// @Failure		429	inference_rate_limited	exceed	limit
+// @Failure		503	inference_not_active	not		active
+// @Router			/v1/inference/project/{owner}/{pid}/instances/{id}/serve/{path} [get]
+func (ctl *InferenceController) Serve(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	v, err := ctl.project.GetSummary(owner, ctx.Param("pid"))
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	if v.IsPrivate() {
+		ctx.JSON(http.StatusNotFound, newResponseCodeMsg(
+			errorNotAllowed, "project is not found",
+		))
+
+		return
+	}
+
+	cmd := app.InferenceRouteCmd{
+		User: pl.DomainAccount(),
+		Project: domain.ResourceIndex{
+			Owner: owner,
+			Id:    v.Id,
+		},
+		Id:   ctx.Param("id"),
+		Path: ctx.Param("path"),
+	}
+
+	dto, code, err := ctl.gateway.Route(&cmd)
+	if err != nil {
+		ctl.sendGatewayError(ctx, code, err, inferenceGatewayStatus)
+
+		return
+	}
+
+	r, ok := ctl.proxyByGateway(ctx, dto.AccessURL, "inference("+cmd.Id+")")
+	if !ok || !dto.Metered {
+		return
+	}
+
+	err = ctl.gateway.Record(&app.InferenceRecordCmd{
+		User:    cmd.User,
+		Project: cmd.Project,
+		Failed:  r.failed,
+		Latency: r.latency,
+	})
+	if err != nil {
+		logrus.Errorf("record usage of inference(%s) failed, err:%s", cmd.Id, err.Error())
+	}
+}
+
+// @Summary		GetUsage
+// @Description	get the usage of the inference of project by each user in the recent days
+// @Tags			Inference
+// @Param			owner	path	string	true	"project owner"
+// @Param			pid		path	string	true	"project id"
+// @Param			days	query	int		false	"num of recent days, default is 7"
+// @Accept			json
+// @Success		200	{object}			app.InferenceUsageStatsDTO
+// @Failure		400	bad_request_param	some	parameter	of	body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/inference/project/{owner}/{pid}/usage [get]
+func (ctl *InferenceController) GetUsage(ctx *gin.Context) {
+	_, project, ok := ctl.getProject(ctx)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "7"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd := app.InferenceUsageQueryCmd{
+		Project: project,
+		Days:    days,
+	}
+
+	if err := cmd.Validate(); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if v, err := ctl.gateway.GetUsage(&cmd); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// getProject checks that the project is owned by the user who visits it.
+func (ctl *InferenceController) getProject(ctx *gin.Context) (
+	pl *oldUserTokenPayload, project domain.ResourceIndex, ok bool,
+) {
+	if pl, _, ok = ctl.checkUserApiToken(ctx, false); !ok {
+		return
+	}
+
+	owner, err := domain.NewAccount(ctx.Param("owner"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		ok = false
+
+		return
+	}
+
+	if pl.isNotMe(owner) {
+		ctx.JSON(http.StatusBadRequest, newResponseCodeMsg(
+			errorNotAllowed, "not allowed",
+		))
+
+		ok = false
+
+		return
+	}
+
+	project = domain.ResourceIndex{
+		Owner: owner,
+		Id:    ctx.Param("pid"),
+	}
+
+	return
+}
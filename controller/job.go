@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/job/app"
+)
+
+func AddRouterForJobController(
+	rg *gin.RouterGroup,
+	s app.JobService,
+	notifier app.JobNotifier,
+	cfg *app.Config,
+) {
+	ctl := JobController{
+		s:             s,
+		notifier:      notifier,
+		watchInterval: time.Duration(cfg.WatchInterval) * time.Second,
+	}
+
+	rg.GET("/v1/job", ctl.List)
+	rg.GET("/v1/job/ws", ctl.Watch)
+}
+
+type JobController struct {
+	baseController
+
+	s             app.JobService
+	notifier      app.JobNotifier
+	watchInterval time.Duration
+}
+
+// @Summary		List
+// @Description	list the training, finetune and aicc finetune jobs of user
+// @Tags			Job
+// @Param			kind			query	string	false	"kinds of job separated by comma: training, finetune, aicc_finetune"
+// @Param			state			query	string	false	"states of job separated by comma: scheduling, pending, running, succeeded, failed, terminated"
+// @Param			page_num		query	int		false	"page num which starts from 1"
+// @Param			count_per_page	query	int		false	"count per page"
+// @Accept			json
+// @Success		200	{object}			app.JobsDTO
+// @Failure		400	bad_request_param	some	parameter	of	query	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/job [get]
+func (ctl *JobController) List(ctx *gin.Context) {
+	req := jobListRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if v, err := ctl.s.List(&cmd); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Watch
+// @Description	watch the jobs of user, the jobs are pushed when any of them changes
+// @Tags			Job
+// @Param			kind			query	string	false	"kinds of job separated by comma: training, finetune, aicc_finetune"
+// @Param			state			query	string	false	"states of job separated by comma: scheduling, pending, running, succeeded, failed, terminated"
+// @Param			page_num		query	int		false	"page num which starts from 1"
+// @Param			count_per_page	query	int		false	"count per page"
+// @Accept			json
+// @Success		200	{object}		app.JobsDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/job/ws [get]
+func (ctl *JobController) Watch(ctx *gin.Context) {
+	pl, csrftoken, _, ok := ctl.checkTokenForWebsocket(ctx, false)
+	if !ok {
+		return
+	}
+
+	req := jobListRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	cmd, err := req.toCmd(pl.DomainAccount())
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	// setup websocket
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{csrftoken},
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get(headerSecWebsocket) == csrftoken
+		},
+	}
+
+	ws, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	defer ws.Close()
+
+	ctl.watch(ws, &cmd)
+}
+
+func (ctl *JobController) watch(ws *websocket.Conn, cmd *app.JobListCmd) {
+	// the client sends nothing, so the read will fail only when
+	// the connection is closed.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	changed, unsubscribe := ctl.notifier.Subscribe(cmd.Owner)
+	defer unsubscribe()
+
+	var last []byte
+
+	for {
+		v, err := ctl.s.List(cmd)
+		if err != nil {
+			logrus.Errorf("list jobs failed, err:%s", err.Error())
+		} else if b, err := json.Marshal(v); err == nil && string(b) != string(last) {
+			last = b
+
+			if err = ws.WriteJSON(newResponseData(v)); err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-closed:
+			return
+
+		case <-changed:
+
+		case <-time.After(ctl.watchInterval):
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/job/app"
+	jobdomain "github.com/opensourceways/xihe-server/job/domain"
+)
+
+type jobListRequest struct {
+	// Kind and State are separated by comma, such as training,finetune
+	Kind         string `form:"kind"`
+	State        string `form:"state"`
+	PageNum      int    `form:"page_num"`
+	CountPerPage int    `form:"count_per_page"`
+}
+
+func (req *jobListRequest) toCmd(owner domain.Account) (cmd app.JobListCmd, err error) {
+	if req.CountPerPage < 0 || req.CountPerPage > 100 {
+		err = errors.New("bad count_per_page")
+
+		return
+	}
+
+	if req.PageNum < 0 {
+		err = errors.New("bad page_num")
+
+		return
+	}
+
+	for _, v := range splitQueryValues(req.Kind) {
+		kind, err1 := jobdomain.NewJobKind(v)
+		if err1 != nil {
+			err = err1
+
+			return
+		}
+
+		cmd.Kinds = append(cmd.Kinds, kind)
+	}
+
+	for _, v := range splitQueryValues(req.State) {
+		state, err1 := jobdomain.NewJobState(v)
+		if err1 != nil {
+			err = err1
+
+			return
+		}
+
+		cmd.States = append(cmd.States, state)
+	}
+
+	cmd.Owner = owner
+	cmd.PageNum = req.PageNum
+	cmd.CountPerPage = req.CountPerPage
+
+	if cmd.CountPerPage == 0 {
+		cmd.CountPerPage = 10
+	}
+
+	if cmd.PageNum == 0 {
+		cmd.PageNum = 1
+	}
+
+	err = cmd.Validate()
+
+	return
+}
+
+func splitQueryValues(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/app"
+)
+
+const (
+	jobLogDefaultLimit = 500
+	jobLogMaxLimit     = 5000
+	jobLogMaxGrepLen   = 256
+
+	// jobLogPollInterval is the interval to check the new log of job.
+	jobLogPollInterval = 3 * time.Second
+)
+
+type jobLogRequest struct {
+	Offset int64  `form:"offset"`
+	Limit  int    `form:"limit"`
+	Grep   string `form:"grep"`
+	Since  int64  `form:"since"`
+	Until  int64  `form:"until"`
+}
+
+func (req *jobLogRequest) toQuery() (q app.JobLogQuery, err error) {
+	if req.Offset < 0 {
+		err = errors.New("invalid offset")
+
+		return
+	}
+
+	if req.Limit < 0 || req.Limit > jobLogMaxLimit {
+		err = fmt.Errorf("limit should be between 0 and %d", jobLogMaxLimit)
+
+		return
+	}
+
+	if req.Since < 0 || req.Until < 0 || (req.Until > 0 && req.Until < req.Since) {
+		err = errors.New("invalid time range")
+
+		return
+	}
+
+	q = app.JobLogQuery{
+		Offset: req.Offset,
+		Limit:  req.Limit,
+		Since:  req.Since,
+		Until:  req.Until,
+	}
+
+	if q.Limit == 0 {
+		q.Limit = jobLogDefaultLimit
+	}
+
+	if req.Grep != "" {
+		if len(req.Grep) > jobLogMaxGrepLen {
+			err = fmt.Errorf("the length of grep should be less than %d", jobLogMaxGrepLen)
+
+			return
+		}
+
+		if q.Grep, err = regexp.Compile(req.Grep); err != nil {
+			err = errors.New("invalid grep")
+		}
+	}
+
+	return
+}
+
+// jobLogReader reads the log of a certain job by the query.
+type jobLogReader func(*app.JobLogQuery) (app.JobLogDTO, string, error)
+
+func (ctl baseController) parseJobLogQuery(ctx *gin.Context) (app.JobLogQuery, bool) {
+	req := jobLogRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return app.JobLogQuery{}, false
+	}
+
+	q, err := req.toQuery()
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return q, false
+	}
+
+	return q, true
+}
+
+func (ctl baseController) getJobLog(ctx *gin.Context, read jobLogReader) {
+	q, ok := ctl.parseJobLogQuery(ctx)
+	if !ok {
+		return
+	}
+
+	if v, code, err := read(&q); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// streamJobLogBySSE pushes the new log of job as event of "log" until
+// the job is done and all of its log has been sent.
+func (ctl baseController) streamJobLogBySSE(
+	ctx *gin.Context, read jobLogReader, notFound string,
+) {
+	q, ok := ctl.parseJobLogQuery(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream; charset=utf-8")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+
+	ctx.Stream(func(w io.Writer) bool {
+		v, finished, err := pollJobLog(&q, read, notFound)
+		if err != nil {
+			ctx.SSEvent("error", err.Error())
+
+			return false
+		}
+
+		if shouldSendJobLog(&v) {
+			ctx.SSEvent("log", v)
+		}
+
+		if finished {
+			ctx.SSEvent("status", "done")
+
+			return false
+		}
+
+		// the rest of log of the finished job can be read at once.
+		if v.IsDone {
+			return true
+		}
+
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+
+		case <-time.After(jobLogPollInterval):
+			return true
+		}
+	})
+}
+
+// streamJobLogByWS is same as streamJobLogBySSE except that it pushes
+// the log by websocket. The token must be checked by the caller.
+func (ctl *baseController) streamJobLogByWS(
+	ctx *gin.Context, csrftoken string, read jobLogReader, notFound string,
+) {
+	q, ok := ctl.parseJobLogQuery(ctx)
+	if !ok {
+		return
+	}
+
+	// setup websocket
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{csrftoken},
+		CheckOrigin: func(r *http.Request) bool {
+			return r.Header.Get(headerSecWebsocket) == csrftoken
+		},
+	}
+
+	ws, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	defer ws.Close()
+
+	// the client sends nothing, so the read will fail only when
+	// the connection is closed.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(jobLogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		v, finished, err := pollJobLog(&q, read, notFound)
+		if err != nil {
+			_ = ws.WriteJSON(newResponseError(err))
+
+			return
+		}
+
+		if shouldSendJobLog(&v) || finished {
+			if err = ws.WriteJSON(newResponseData(v)); err != nil {
+				return
+			}
+		}
+
+		if finished {
+			return
+		}
+
+		// the rest of log of the job which is done is read at once.
+		if v.IsDone {
+			select {
+			case <-closed:
+				return
+			default:
+			}
+
+			continue
+		}
+
+		select {
+		case <-closed:
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// shouldSendJobLog checks whether the log should be sent to the client.
+// The empty log is also sent if it is truncated or a preview, because
+// the client has to drop the log it has got.
+func shouldSendJobLog(v *app.JobLogDTO) bool {
+	return v.Log != "" || v.Truncated || v.IsPreview
+}
+
+// pollJobLog reads the new log since the offset of query and moves
+// the offset forward. It returns error only when the job is not found,
+// and the other errors are regarded as temporary.
+func pollJobLog(q *app.JobLogQuery, read jobLogReader, notFound string) (
+	v app.JobLogDTO, finished bool, err error,
+) {
+	v, code, err := read(q)
+	if err != nil {
+		if code == notFound {
+			return
+		}
+
+		logrus.Errorf("read job log failed, code=%s, err=%s", code, err.Error())
+
+		return v, false, nil
+	}
+
+	moved := v.NextOffset != q.Offset
+
+	q.Offset = v.NextOffset
+
+	return v, v.IsDone && !moved, nil
+}
+
+// sendJobLogArchive sends the full log of the finished job compressed by gzip.
+func (ctl baseController) sendJobLogArchive(
+	ctx *gin.Context, name string, content []byte, code string, err error,
+) {
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	ctx.Header("Content-Type", "application/gzip")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.log.gz", name))
+	ctx.Status(http.StatusOK)
+
+	gw := gzip.NewWriter(ctx.Writer)
+
+	if _, err = gw.Write(content); err == nil {
+		err = gw.Close()
+	}
+
+	if err != nil {
+		logrus.Errorf("send job log archive failed, err=%s", err.Error())
+	}
+}
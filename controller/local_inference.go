@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/infrastructure/localinferenceimpl"
+)
+
+func AddRouterForLocalInferenceController(
+	rg *gin.RouterGroup,
+	is localinferenceimpl.Inference,
+) {
+	ctl := LocalInferenceController{
+		is: is,
+	}
+
+	rg.GET(localinferenceimpl.FileRoutePath+"/*file", ctl.GetFile)
+}
+
+type LocalInferenceController struct {
+	baseController
+
+	is localinferenceimpl.Inference
+}
+
+// @Summary		GetFile
+// @Description	download the log of local inference instance
+// @Tags			Inference
+// @Param			file	path	string	true	"path of file"
+// @Accept			json
+// @Success		200
+// @Failure		404	resource_not_exists	no	such	file
+// @Router			/v1/inference/local/file/{file} [get]
+func (ctl *LocalInferenceController) GetFile(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	p, err := ctl.is.LocalFile(pl.DomainAccount(), ctx.Param("file"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, newResponseCodeError(errorResourceNotExists, err))
+
+		return
+	}
+
+	ctx.File(p)
+}
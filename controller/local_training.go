@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/infrastructure/localtrainingimpl"
+)
+
+func AddRouterForLocalTrainingController(
+	rg *gin.RouterGroup,
+	ts localtrainingimpl.Training,
+) {
+	ctl := LocalTrainingController{
+		ts: ts,
+	}
+
+	rg.GET(localtrainingimpl.FileRoutePath+"/*file", ctl.GetFile)
+}
+
+type LocalTrainingController struct {
+	baseController
+
+	ts localtrainingimpl.Training
+}
+
+// @Summary		GetFile
+// @Description	download the file of local training job, such as log and output
+// @Tags			Training
+// @Param			file	path	string	true	"path of file"
+// @Accept			json
+// @Success		200
+// @Failure		404	resource_not_exists	no	such	file
+// @Router			/v1/train/local/file/{file} [get]
+func (ctl *LocalTrainingController) GetFile(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	f, err := ctl.ts.LocalFile(pl.DomainAccount(), ctx.Param("file"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, newResponseCodeError(errorResourceNotExists, err))
+
+		return
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, newResponseCodeError(errorResourceNotExists, err))
+
+		return
+	}
+
+	http.ServeContent(ctx.Writer, ctx.Request, info.Name(), info.ModTime(), f)
+}
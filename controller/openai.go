@@ -0,0 +1,456 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	"github.com/opensourceways/xihe-server/bigmodel/domain/moderation"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const openAIOwner = "xihe"
+
+var errOpenAIReplyUnchecked = errors.New("the reply is stopped for failing to check it")
+
+// AddRouterForOpenAIController serves the big models by the api of OpenAI
+// protocol. The api key is the token of model applied by user.
+func AddRouterForOpenAIController(
+	rg *gin.RouterGroup,
+	s app.OpenAIService,
+) {
+	ctl := OpenAIController{
+		s: s,
+	}
+
+	rg.GET("/v1/models", ctl.ListModels)
+	rg.POST("/v1/chat/completions", ctl.ChatCompletions)
+	rg.POST("/v1/completions", ctl.Completions)
+	rg.POST("/v1/images/generations", ctl.ImageGenerations)
+}
+
+type OpenAIController struct {
+	baseController
+
+	s app.OpenAIService
+}
+
+// @Summary		ListModels
+// @Description	list the models which can be called by the api token
+// @Tags			OpenAI
+// @Param			Authorization	header	string	true	"Bearer token"
+// @Accept			json
+// @Success		200	{object}	openAIModelListResp
+// @Failure		401	{object}	openAIErrorResp
+// @Router			/v1/models [get]
+func (ctl *OpenAIController) ListModels(ctx *gin.Context) {
+	_, models, ok := ctl.checkToken(ctx, "")
+	if !ok {
+		return
+	}
+
+	resp := openAIModelListResp{
+		Object: openAIObjectList,
+		Data:   make([]openAIModel, len(models)),
+	}
+
+	for i := range models {
+		resp.Data[i] = openAIModel{
+			Id:      models[i].Id,
+			Object:  openAIObjectModel,
+			Created: models[i].Created,
+			OwnedBy: openAIOwner,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary		ChatCompletions
+// @Description	chat with the model, the reply is streamed by SSE if stream is true
+// @Tags			OpenAI
+// @Param			Authorization	header	string				true	"Bearer token"
+// @Param			body			body	openAIChatRequest	true	"body of chat"
+// @Accept			json
+// @Success		200	{object}	openAIChatResp
+// @Failure		400	{object}	openAIErrorResp
+// @Failure		401	{object}	openAIErrorResp
+// @Router			/v1/chat/completions [post]
+func (ctl *OpenAIController) ChatCompletions(ctx *gin.Context) {
+	req := openAIChatRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, "can't parse request body")
+
+		return
+	}
+
+	user, _, ok := ctl.checkToken(ctx, req.Model)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, user.Account(), OPERATE_TYPE_USER, "launch bigmodel by openai chat api")
+
+	ch := make(chan string, chBufferSize)
+	cmd, err := req.toCmd(ch, user)
+	if err != nil {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, err.Error())
+
+		return
+	}
+
+	id := newRequestId("chatcmpl-")
+	cmd.RequestId = id
+
+	if !ctl.complete(ctx, &cmd) {
+		return
+	}
+
+	created := utils.Now()
+
+	if !req.Stream {
+		reply, finish, err := collectReply(ch)
+		if err != nil {
+			ctl.sendError(ctx, http.StatusInternalServerError, openAIErrorServer, err.Error())
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, openAIChatResp{
+			Id:      id,
+			Object:  openAIObjectChat,
+			Created: created,
+			Model:   req.Model,
+			Choices: []openAIChatChoice{{
+				Message: &openAIMessage{
+					Role:    domain.ChatRoleAssistant,
+					Content: reply,
+				},
+				FinishReason: &finish,
+			}},
+		})
+
+		return
+	}
+
+	ctl.stream(ctx, ch, func(content string, finish *string) interface{} {
+		return openAIChatResp{
+			Id:      id,
+			Object:  openAIObjectChatChunk,
+			Created: created,
+			Model:   req.Model,
+			Choices: []openAIChatChoice{{
+				Delta:        &openAIMessage{Content: content},
+				FinishReason: finish,
+			}},
+		}
+	})
+}
+
+// @Summary		Completions
+// @Description	complete the prompt by the model, the reply is streamed by SSE if stream is true
+// @Tags			OpenAI
+// @Param			Authorization	header	string					true	"Bearer token"
+// @Param			body			body	openAICompletionRequest	true	"body of completion"
+// @Accept			json
+// @Success		200	{object}	openAICompletionResp
+// @Failure		400	{object}	openAIErrorResp
+// @Failure		401	{object}	openAIErrorResp
+// @Router			/v1/completions [post]
+func (ctl *OpenAIController) Completions(ctx *gin.Context) {
+	req := openAICompletionRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, "can't parse request body")
+
+		return
+	}
+
+	user, _, ok := ctl.checkToken(ctx, req.Model)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, user.Account(), OPERATE_TYPE_USER, "launch bigmodel by openai completion api")
+
+	ch := make(chan string, chBufferSize)
+	cmd, err := req.toCmd(ch, user)
+	if err != nil {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, err.Error())
+
+		return
+	}
+
+	id := newRequestId("cmpl-")
+	cmd.RequestId = id
+
+	if !ctl.complete(ctx, &cmd) {
+		return
+	}
+
+	created := utils.Now()
+
+	resp := func(text string, finish *string) interface{} {
+		return openAICompletionResp{
+			Id:      id,
+			Object:  openAIObjectTextCompletion,
+			Created: created,
+			Model:   req.Model,
+			Choices: []openAICompletionChoice{{
+				Text:         text,
+				FinishReason: finish,
+			}},
+		}
+	}
+
+	if !req.Stream {
+		reply, finish, err := collectReply(ch)
+		if err != nil {
+			ctl.sendError(ctx, http.StatusInternalServerError, openAIErrorServer, err.Error())
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, resp(reply, &finish))
+
+		return
+	}
+
+	ctl.stream(ctx, ch, resp)
+}
+
+// @Summary		ImageGenerations
+// @Description	generate pictures by the prompt
+// @Tags			OpenAI
+// @Param			Authorization	header	string				true	"Bearer token"
+// @Param			body			body	openAIImageRequest	true	"body of image generation"
+// @Accept			json
+// @Success		200	{object}	openAIImageResp
+// @Failure		400	{object}	openAIErrorResp
+// @Failure		401	{object}	openAIErrorResp
+// @Router			/v1/images/generations [post]
+func (ctl *OpenAIController) ImageGenerations(ctx *gin.Context) {
+	req := openAIImageRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, "can't parse request body")
+
+		return
+	}
+
+	if req.Model == "" {
+		req.Model = string(domain.BigmodelWuKong)
+	}
+
+	if req.Model != string(domain.BigmodelWuKong) {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, "unsupported image model")
+
+		return
+	}
+
+	user, _, ok := ctl.checkToken(ctx, req.Model)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, user.Account(), OPERATE_TYPE_USER, "launch bigmodel by openai image api")
+
+	cmd, err := req.toCmd(user)
+	if err != nil {
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, err.Error())
+
+		return
+	}
+
+	urls, code, err := ctl.s.ImageGeneration(&cmd)
+	if err != nil {
+		ctl.sendCodeError(ctx, code, err)
+
+		return
+	}
+
+	resp := openAIImageResp{
+		Created: utils.Now(),
+		Data:    make([]openAIImage, len(urls)),
+	}
+
+	for i := range urls {
+		resp.Data[i] = openAIImage{URL: urls[i]}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// checkToken authenticates the api token of the model. It only checks the
+// owner of token if model is empty, and returns all the models of owner.
+func (ctl *OpenAIController) checkToken(ctx *gin.Context, model string) (
+	user types.Account, models []app.OpenAIModelDTO, ok bool,
+) {
+	token := strings.TrimPrefix(ctx.GetHeader(headerAuthorization), bearerPrefix)
+
+	invalid := func() {
+		ctl.sendError(ctx, http.StatusUnauthorized, openAIErrorAuthentication, "invalid api key")
+	}
+
+	v, err := ctl.parseBigmodelApiToken(token)
+	if err != nil {
+		invalid()
+
+		return
+	}
+
+	if user, err = types.NewAccount(v); err != nil {
+		invalid()
+
+		return
+	}
+
+	if models, err = ctl.s.ListModels(user); err != nil {
+		ctl.sendError(ctx, http.StatusInternalServerError, openAIErrorServer, err.Error())
+
+		return
+	}
+
+	for i := range models {
+		if model != "" && models[i].Id != model {
+			continue
+		}
+
+		if ctl.isBigmodelApiTokenOf(token, models[i].Token) {
+			ok = true
+
+			return
+		}
+	}
+
+	invalid()
+
+	return
+}
+
+// complete calls the model and sends the error if it fails.
+func (ctl *OpenAIController) complete(ctx *gin.Context, cmd *app.OpenAIChatCmd) bool {
+	cmd.Done = ctx.Request.Context().Done()
+
+	code, err := ctl.s.ChatCompletion(cmd)
+	if err != nil {
+		ctl.sendCodeError(ctx, code, err)
+
+		return false
+	}
+
+	return true
+}
+
+func (ctl *OpenAIController) stream(
+	ctx *gin.Context, ch chan string,
+	chunk func(content string, finish *string) interface{},
+) {
+	ctx.Header("Content-Type", "text/event-stream; charset=utf-8")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+
+	write := func(w io.Writer, data interface{}) {
+		if b, err := json.Marshal(data); err == nil {
+			fmt.Fprintf(w, "data: %s\n\n", b)
+		}
+	}
+
+	ctx.Stream(func(w io.Writer) bool {
+		msg, ok := <-ch
+		if ok && msg != "done" && msg != moderation.MsgRejected && msg != moderation.MsgFailed {
+			write(w, chunk(msg, nil))
+
+			return true
+		}
+
+		if msg == moderation.MsgFailed {
+			write(w, openAIErrorResp{
+				Error: openAIErrorDetail{
+					Message: errOpenAIReplyUnchecked.Error(),
+					Type:    openAIErrorServer,
+				},
+			})
+			fmt.Fprint(w, "data: [DONE]\n\n")
+
+			return false
+		}
+
+		finish := openAIFinishStop
+		if msg == moderation.MsgRejected {
+			finish = openAIFinishContentFilter
+		}
+
+		write(w, chunk("", &finish))
+		fmt.Fprint(w, "data: [DONE]\n\n")
+
+		return false
+	})
+}
+
+func (ctl *OpenAIController) sendCodeError(ctx *gin.Context, code string, err error) {
+	switch code {
+	case app.ErrorBigModelRecourseBusy, app.ErrorBigModelRateLimited:
+		ctl.sendError(ctx, http.StatusTooManyRequests, openAIErrorRateLimit, err.Error())
+
+	case app.ErrorBigModelQuotaExceeded:
+		ctl.sendError(ctx, http.StatusTooManyRequests, openAIErrorQuota, err.Error())
+
+	case app.ErrorBigModelSensitiveInfo:
+		ctl.sendError(ctx, http.StatusBadRequest, openAIErrorInvalidRequest, err.Error())
+
+	default:
+		ctl.sendError(ctx, http.StatusInternalServerError, openAIErrorServer, err.Error())
+	}
+}
+
+func (ctl *OpenAIController) sendError(ctx *gin.Context, status int, t, msg string) {
+	ctx.JSON(status, openAIErrorResp{
+		Error: openAIErrorDetail{
+			Message: msg,
+			Type:    t,
+		},
+	})
+}
+
+// collectReply waits for the whole reply of model, and returns it with
+// the reason why the model stops.
+func collectReply(ch chan string) (string, string, error) {
+	s := strings.Builder{}
+	finish := openAIFinishStop
+	failed := false
+
+	for msg := range ch {
+		switch msg {
+		case "done":
+		case moderation.MsgRejected:
+			finish = openAIFinishContentFilter
+		case moderation.MsgFailed:
+			failed = true
+		default:
+			s.WriteString(msg)
+		}
+	}
+
+	if failed {
+		return "", "", errOpenAIReplyUnchecked
+	}
+
+	return s.String(), finish, nil
+}
+
+// newRequestId returns a random id of request with the prefix.
+func newRequestId(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+
+	return prefix + hex.EncodeToString(b)
+}
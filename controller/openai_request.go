@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-server/bigmodel/app"
+	"github.com/opensourceways/xihe-server/bigmodel/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+const (
+	openAIFinishStop          = "stop"
+	openAIFinishContentFilter = "content_filter"
+
+	openAIObjectList           = "list"
+	openAIObjectModel          = "model"
+	openAIObjectChat           = "chat.completion"
+	openAIObjectChatChunk      = "chat.completion.chunk"
+	openAIObjectTextCompletion = "text_completion"
+
+	openAIErrorInvalidRequest = "invalid_request_error"
+	openAIErrorAuthentication = "authentication_error"
+	openAIErrorRateLimit      = "rate_limit_error"
+	openAIErrorQuota          = "insufficient_quota"
+	openAIErrorServer         = "server_error"
+)
+
+type openAIChatRequest struct {
+	Model       string               `json:"model"`
+	Messages    []chatMessageRequest `json:"messages"`
+	Stream      bool                 `json:"stream"`
+	Temperature *float64             `json:"temperature"`
+	TopP        *float64             `json:"top_p"`
+}
+
+func (req *openAIChatRequest) toCmd(ch chan string, user types.Account) (cmd app.OpenAIChatCmd, err error) {
+	if len(req.Messages) == 0 {
+		err = errors.New("no messages")
+
+		return
+	}
+
+	cmd.Messages = make([]domain.ChatMessage, len(req.Messages))
+	for i := range req.Messages {
+		item := &req.Messages[i]
+
+		if cmd.Messages[i], err = domain.NewChatMessage(item.Role, item.Content); err != nil {
+			return
+		}
+	}
+
+	err = toOpenAIChatCmd(&cmd, req.Model, req.Temperature, req.TopP)
+	cmd.CH = ch
+	cmd.User = user
+
+	return
+}
+
+type openAICompletionRequest struct {
+	Model       string   `json:"model"`
+	Prompt      string   `json:"prompt"`
+	Stream      bool     `json:"stream"`
+	Temperature *float64 `json:"temperature"`
+	TopP        *float64 `json:"top_p"`
+}
+
+func (req *openAICompletionRequest) toCmd(ch chan string, user types.Account) (cmd app.OpenAIChatCmd, err error) {
+	msg, err := domain.NewChatMessage(domain.ChatRoleUser, req.Prompt)
+	if err != nil {
+		return
+	}
+
+	cmd.Messages = []domain.ChatMessage{msg}
+
+	err = toOpenAIChatCmd(&cmd, req.Model, req.Temperature, req.TopP)
+	cmd.CH = ch
+	cmd.User = user
+
+	return
+}
+
+func toOpenAIChatCmd(cmd *app.OpenAIChatCmd, model string, temperature, topP *float64) (err error) {
+	if cmd.Model, err = domain.NewChatModel(model); err != nil {
+		return
+	}
+
+	var t domain.Temperature
+	if temperature != nil {
+		if t, err = domain.NewTemperature(*temperature); err != nil {
+			return
+		}
+	}
+
+	var p domain.TopP
+	if topP != nil {
+		if p, err = domain.NewTopP(*topP); err != nil {
+			return
+		}
+	}
+
+	cmd.SetDefault(t, p)
+
+	return
+}
+
+type openAIImageRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Style  string `json:"style"`
+}
+
+func (req *openAIImageRequest) toCmd(user types.Account) (cmd app.OpenAIImageCmd, err error) {
+	if cmd.Desc, err = domain.NewWuKongPictureDesc(req.Prompt); err != nil {
+		return
+	}
+
+	cmd.Style = req.Style
+	cmd.User = user
+
+	return
+}
+
+type openAIMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+type openAIChatChoice struct {
+	Index        int            `json:"index"`
+	Message      *openAIMessage `json:"message,omitempty"`
+	Delta        *openAIMessage `json:"delta,omitempty"`
+	FinishReason *string        `json:"finish_reason"`
+}
+
+type openAIChatResp struct {
+	Id      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []openAIChatChoice `json:"choices"`
+}
+
+type openAICompletionChoice struct {
+	Index        int     `json:"index"`
+	Text         string  `json:"text"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+type openAICompletionResp struct {
+	Id      string                   `json:"id"`
+	Object  string                   `json:"object"`
+	Created int64                    `json:"created"`
+	Model   string                   `json:"model"`
+	Choices []openAICompletionChoice `json:"choices"`
+}
+
+type openAIImage struct {
+	URL string `json:"url"`
+}
+
+type openAIImageResp struct {
+	Created int64         `json:"created"`
+	Data    []openAIImage `json:"data"`
+}
+
+type openAIModel struct {
+	Id      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type openAIModelListResp struct {
+	Object string        `json:"object"`
+	Data   []openAIModel `json:"data"`
+}
+
+type openAIErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+type openAIErrorResp struct {
+	Error openAIErrorDetail `json:"error"`
+}
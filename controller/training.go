@@ -10,10 +10,13 @@ import (
 
 	"github.com/opensourceways/xihe-server/app"
 	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/joblog"
 	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/repository"
 	"github.com/opensourceways/xihe-server/domain/training"
+	jobapp "github.com/opensourceways/xihe-server/job/app"
 	"github.com/opensourceways/xihe-server/utils"
+	webhookapp "github.com/opensourceways/xihe-server/webhook/app"
 )
 
 func AddRouterForTrainingController(
@@ -24,14 +27,18 @@ func AddRouterForTrainingController(
 	project repository.Project,
 	dataset repository.Dataset,
 	sender message.MessageProducer,
+	downloader joblog.Downloader,
+	webhook webhookapp.WebhookEventService,
+	jobs jobapp.JobNotifier,
 ) {
 	ctl := TrainingController{
 		ts: app.NewTrainingService(
-			ts, repo, sender, apiConfig.MaxTrainingRecordNum,
+			ts, repo, sender, downloader, webhook, jobs, apiConfig.MaxTrainingRecordNum,
 		),
-		model:   model,
-		project: project,
-		dataset: dataset,
+		model:      model,
+		project:    project,
+		dataset:    dataset,
+		downloader: downloader,
 	}
 
 	rg.POST("/v1/train/project/:pid/training", checkUserEmailMiddleware(&ctl.baseController), ctl.Create)
@@ -44,6 +51,10 @@ func AddRouterForTrainingController(
 		ctl.GetResultDownloadURL,
 	)
 	rg.GET("/v1/train/project/:pid/training/:id", ctl.Get)
+	rg.GET("/v1/train/project/:pid/training/:id/log", ctl.GetLog)
+	rg.GET("/v1/train/project/:pid/training/:id/log/sse", ctl.StreamLog)
+	rg.GET("/v1/train/project/:pid/training/:id/log/ws", ctl.WatchLog)
+	rg.GET("/v1/train/project/:pid/training/:id/log/archive", ctl.DownloadLogArchive)
 	rg.GET("/v1/train/project/:pid/config", ctl.GetLastTrainingConfig)
 	rg.DELETE("v1/train/project/:pid/training/:id", ctl.Delete)
 }
@@ -53,9 +64,10 @@ type TrainingController struct {
 
 	ts app.TrainingService
 
-	model   repository.Model
-	project repository.Project
-	dataset repository.Dataset
+	model      repository.Model
+	project    repository.Project
+	dataset    repository.Dataset
+	downloader joblog.Downloader
 }
 
 // @Summary		Create
@@ -299,7 +311,7 @@ func (ctl *TrainingController) watchTraining(ws *websocket.Conn, index *domain.T
 				data.Duration = duration
 			}
 
-			log, err := downloadLog(v.LogPreviewURL)
+			log, err := ctl.downloader.Download(v.LogPreviewURL)
 			if err == nil && len(log) > 0 {
 				data.Log = string(log)
 			}
@@ -542,6 +554,109 @@ func (ctl *TrainingController) GetResultDownloadURL(ctx *gin.Context) {
 	}
 }
 
+// @Summary		GetLog
+// @Description	read the log of training from the offset
+// @Tags			Training
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"training id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		app.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/training/{id}/log [get]
+func (ctl *TrainingController) GetLog(ctx *gin.Context) {
+	info, ok := ctl.getTrainingInfo(ctx)
+	if !ok {
+		return
+	}
+
+	ctl.getJobLog(ctx, ctl.logReader(&info))
+}
+
+// @Summary		StreamLog
+// @Description	stream the log of training by server-sent events
+// @Tags			Training
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"training id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines of each event"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		app.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/training/{id}/log/sse [get]
+func (ctl *TrainingController) StreamLog(ctx *gin.Context) {
+	info, ok := ctl.getTrainingInfo(ctx)
+	if !ok {
+		return
+	}
+
+	ctl.streamJobLogBySSE(ctx, ctl.logReader(&info), app.ErrorTrainNotFound)
+}
+
+// @Summary		WatchLog
+// @Description	stream the log of training by websocket
+// @Tags			Training
+// @Param			pid		path	string	true	"project id"
+// @Param			id		path	string	true	"training id"
+// @Param			offset	query	int		false	"byte offset of log to read from"
+// @Param			limit	query	int		false	"max num of lines of each message"
+// @Param			grep	query	string	false	"regular expression to filter lines"
+// @Param			since	query	int		false	"unix time from which the lines are printed"
+// @Param			until	query	int		false	"unix time until which the lines are printed"
+// @Accept			json
+// @Success		200	{object}		app.JobLogDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/training/{id}/log/ws [get]
+func (ctl *TrainingController) WatchLog(ctx *gin.Context) {
+	pl, csrftoken, _, ok := ctl.checkTokenForWebsocket(ctx, false)
+	if !ok {
+		return
+	}
+
+	info := domain.TrainingIndex{
+		Project: domain.ResourceIndex{
+			Owner: pl.DomainAccount(),
+			Id:    ctx.Param("pid"),
+		},
+		TrainingId: ctx.Param("id"),
+	}
+
+	ctl.streamJobLogByWS(ctx, csrftoken, ctl.logReader(&info), app.ErrorTrainNotFound)
+}
+
+// @Summary		DownloadLogArchive
+// @Description	download the full log of finished training compressed by gzip
+// @Tags			Training
+// @Param			pid	path	string	true	"project id"
+// @Param			id	path	string	true	"training id"
+// @Accept			json
+// @Success		200
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/training/{id}/log/archive [get]
+func (ctl *TrainingController) DownloadLogArchive(ctx *gin.Context) {
+	info, ok := ctl.getTrainingInfo(ctx)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.ts.GetFullLog(&info)
+
+	ctl.sendJobLogArchive(ctx, info.TrainingId, v, code, err)
+}
+
+func (ctl *TrainingController) logReader(info *domain.TrainingIndex) jobLogReader {
+	return func(q *app.JobLogQuery) (app.JobLogDTO, string, error) {
+		return ctl.ts.GetLog(info, q)
+	}
+}
+
 func (ctl *TrainingController) getTrainingInfo(ctx *gin.Context) (domain.TrainingIndex, bool) {
 	pl, _, ok := ctl.checkUserApiToken(ctx, false)
 	if !ok {
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/app"
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+func AddRouterForTrainingScheduleController(
+	rg *gin.RouterGroup,
+	s app.TrainingScheduleService,
+) {
+	ctl := TrainingScheduleController{
+		s: s,
+	}
+
+	rg.POST("/v1/train/project/:pid/schedule", checkUserEmailMiddleware(&ctl.baseController), ctl.Create)
+	rg.GET("/v1/train/project/:pid/schedule", ctl.List)
+	rg.GET("/v1/train/project/:pid/schedule/:id", ctl.Get)
+	rg.PUT("/v1/train/project/:pid/schedule/:id/pause", ctl.Pause)
+	rg.PUT("/v1/train/project/:pid/schedule/:id/resume", ctl.Resume)
+	rg.DELETE("/v1/train/project/:pid/schedule/:id", ctl.Delete)
+}
+
+type TrainingScheduleController struct {
+	baseController
+
+	s app.TrainingScheduleService
+}
+
+// @Summary		Create
+// @Description	create a schedule which creates the training periodically by the config of an existing training
+// @Tags			TrainingSchedule
+// @Param			pid		path	string							true	"project id"
+// @Param			body	body	TrainingScheduleCreateRequest	true	"body of creating training schedule"
+// @Accept			json
+// @Success		201	{object}			app.TrainingScheduleDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/train/project/{pid}/schedule [post]
+func (ctl *TrainingScheduleController) Create(ctx *gin.Context) {
+	req := TrainingScheduleCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "create training schedule")
+
+	cmd, err := req.toCmd(pl.DomainAccount(), ctx.Param("pid"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	v, code, err := ctl.s.Create(&cmd)
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "create training schedule",
+		fmt.Sprintf("projectid: %s, scheduleid: %s", ctx.Param("pid"), v.Id), "success")
+
+	ctl.sendRespOfPost(ctx, v)
+}
+
+// @Summary		List
+// @Description	list training schedules of the project
+// @Tags			TrainingSchedule
+// @Param			pid	path	string	true	"project id"
+// @Accept			json
+// @Success		200	{object}		app.TrainingScheduleDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/schedule [get]
+func (ctl *TrainingScheduleController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	v, err := ctl.s.List(pl.DomainAccount(), ctx.Param("pid"))
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Get
+// @Description	get training schedule and the history of its runs
+// @Tags			TrainingSchedule
+// @Param			pid	path	string	true	"project id"
+// @Param			id	path	string	true	"schedule id"
+// @Accept			json
+// @Success		200	{object}		app.TrainingScheduleDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/schedule/{id} [get]
+func (ctl *TrainingScheduleController) Get(ctx *gin.Context) {
+	index, ok := ctl.getScheduleIndex(ctx)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.s.Get(&index)
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Pause
+// @Description	pause training schedule
+// @Tags			TrainingSchedule
+// @Param			pid	path	string	true	"project id"
+// @Param			id	path	string	true	"schedule id"
+// @Accept			json
+// @Success		202
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/schedule/{id}/pause [put]
+func (ctl *TrainingScheduleController) Pause(ctx *gin.Context) {
+	ctl.updateState(ctx, "pause", ctl.s.Pause)
+}
+
+// @Summary		Resume
+// @Description	resume training schedule
+// @Tags			TrainingSchedule
+// @Param			pid	path	string	true	"project id"
+// @Param			id	path	string	true	"schedule id"
+// @Accept			json
+// @Success		202
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/schedule/{id}/resume [put]
+func (ctl *TrainingScheduleController) Resume(ctx *gin.Context) {
+	ctl.updateState(ctx, "resume", ctl.s.Resume)
+}
+
+func (ctl *TrainingScheduleController) updateState(
+	ctx *gin.Context, action string,
+	f func(*app.TrainingScheduleIndex) (string, error),
+) {
+	index, ok := ctl.getScheduleIndex(ctx)
+	if !ok {
+		return
+	}
+
+	user := index.Project.Owner.Account()
+	prepareOperateLog(ctx, user, OPERATE_TYPE_USER, action+" training schedule")
+
+	if code, err := f(&index); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", user, action+" training schedule",
+		fmt.Sprintf("projectid: %s, scheduleid: %s", index.Project.Id, index.ScheduleId), "success")
+
+	ctl.sendRespOfPut(ctx, "success")
+}
+
+// @Summary		Delete
+// @Description	delete training schedule
+// @Tags			TrainingSchedule
+// @Param			pid	path	string	true	"project id"
+// @Param			id	path	string	true	"schedule id"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/train/project/{pid}/schedule/{id} [delete]
+func (ctl *TrainingScheduleController) Delete(ctx *gin.Context) {
+	index, ok := ctl.getScheduleIndex(ctx)
+	if !ok {
+		return
+	}
+
+	user := index.Project.Owner.Account()
+	prepareOperateLog(ctx, user, OPERATE_TYPE_USER, "delete training schedule")
+
+	if code, err := ctl.s.Delete(&index); err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", user, "delete training schedule",
+		fmt.Sprintf("projectid: %s, scheduleid: %s", index.Project.Id, index.ScheduleId), "success")
+
+	ctl.sendRespOfDelete(ctx)
+}
+
+func (ctl *TrainingScheduleController) getScheduleIndex(ctx *gin.Context) (
+	app.TrainingScheduleIndex, bool,
+) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return app.TrainingScheduleIndex{}, ok
+	}
+
+	return app.TrainingScheduleIndex{
+		Project: domain.ResourceIndex{
+			Owner: pl.DomainAccount(),
+			Id:    ctx.Param("pid"),
+		},
+		ScheduleId: ctx.Param("id"),
+	}, true
+}
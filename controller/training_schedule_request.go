@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"github.com/opensourceways/xihe-server/app"
+	"github.com/opensourceways/xihe-server/domain"
+)
+
+type TrainingScheduleCreateRequest struct {
+	Name       string `json:"name"`
+	Spec       string `json:"spec"`
+	TrainingId string `json:"training_id"`
+}
+
+func (req *TrainingScheduleCreateRequest) toCmd(
+	user domain.Account, projectId string,
+) (cmd app.TrainingScheduleCreateCmd, err error) {
+	if cmd.Name, err = domain.NewTrainingScheduleName(req.Name); err != nil {
+		return
+	}
+
+	if cmd.Spec, err = domain.NewCronSpec(req.Spec); err != nil {
+		return
+	}
+
+	cmd.User = user
+	cmd.ProjectId = projectId
+	cmd.TrainingId = req.TrainingId
+
+	err = cmd.Validate()
+
+	return
+}
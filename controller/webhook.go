@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+	"github.com/opensourceways/xihe-server/webhook/app"
+)
+
+func AddRouterForWebhookController(
+	rg *gin.RouterGroup,
+	s app.WebhookService,
+	project repository.Project,
+) {
+	ctl := WebhookController{
+		s:       s,
+		project: project,
+	}
+
+	rg.POST("/v1/webhook/project/:pid", checkUserEmailMiddleware(&ctl.baseController), ctl.Create)
+	rg.GET("/v1/webhook/project/:pid", ctl.List)
+	rg.DELETE("/v1/webhook/:id", ctl.Delete)
+	rg.GET("/v1/webhook/:id/delivery", ctl.ListDeliveries)
+}
+
+type WebhookController struct {
+	baseController
+
+	s       app.WebhookService
+	project repository.Project
+}
+
+// @Summary		Create
+// @Description	create webhook of project which will receive the signed events of the state changes
+// @Tags			Webhook
+// @Param			pid		path	string					true	"project id"
+// @Param			body	body	webhookCreateRequest	true	"body of creating webhook"
+// @Accept			json
+// @Success		201	{object}			app.WebhookDTO
+// @Failure		400	bad_request_body	can't	parse		request	body
+// @Failure		401	bad_request_param	some	parameter	of		body	is	invalid
+// @Failure		500	system_error		system	error
+// @Router			/v1/webhook/project/{pid} [post]
+func (ctl *WebhookController) Create(ctx *gin.Context) {
+	req := webhookCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctl.sendBadRequestBody(ctx)
+
+		return
+	}
+
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "create webhook")
+
+	cmd, err := req.toCmd(pl.DomainAccount(), ctx.Param("pid"))
+	if err != nil {
+		ctl.sendBadRequestParam(ctx, err)
+
+		return
+	}
+
+	if _, err := ctl.project.GetSummary(cmd.Owner, cmd.ProjectId); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	v, code, err := ctl.s.Create(&cmd)
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "create webhook",
+		fmt.Sprintf("projectid: %s, webhookid: %s", cmd.ProjectId, v.Id), "success")
+
+	ctl.sendRespOfPost(ctx, v)
+}
+
+// @Summary		List
+// @Description	list webhooks of project
+// @Tags			Webhook
+// @Param			pid	path	string	true	"project id"
+// @Accept			json
+// @Success		200	{object}		[]app.WebhookDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/webhook/project/{pid} [get]
+func (ctl *WebhookController) List(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	v, err := ctl.s.List(pl.DomainAccount(), ctx.Param("pid"))
+	if err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
+
+// @Summary		Delete
+// @Description	delete webhook and its deliveries
+// @Tags			Webhook
+// @Param			id	path	string	true	"webhook id"
+// @Accept			json
+// @Success		204
+// @Failure		500	system_error	system	error
+// @Router			/v1/webhook/{id} [delete]
+func (ctl *WebhookController) Delete(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	prepareOperateLog(ctx, pl.Account, OPERATE_TYPE_USER, "delete webhook")
+
+	if err := ctl.s.Delete(pl.DomainAccount(), ctx.Param("id")); err != nil {
+		ctl.sendRespWithInternalError(ctx, newResponseError(err))
+
+		return
+	}
+
+	utils.DoLog("", pl.Account, "delete webhook",
+		fmt.Sprintf("webhookid: %s", ctx.Param("id")), "success")
+
+	ctl.sendRespOfDelete(ctx)
+}
+
+// @Summary		ListDeliveries
+// @Description	list the latest deliveries of webhook
+// @Tags			Webhook
+// @Param			id	path	string	true	"webhook id"
+// @Accept			json
+// @Success		200	{object}		[]app.DeliveryDTO
+// @Failure		500	system_error	system	error
+// @Router			/v1/webhook/{id}/delivery [get]
+func (ctl *WebhookController) ListDeliveries(ctx *gin.Context) {
+	pl, _, ok := ctl.checkUserApiToken(ctx, false)
+	if !ok {
+		return
+	}
+
+	v, code, err := ctl.s.ListDeliveries(pl.DomainAccount(), ctx.Param("id"))
+	if err != nil {
+		ctl.sendCodeMessage(ctx, code, err)
+	} else {
+		ctl.sendRespOfGet(ctx, v)
+	}
+}
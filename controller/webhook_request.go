@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/webhook/app"
+	webhookdomain "github.com/opensourceways/xihe-server/webhook/domain"
+)
+
+type webhookCreateRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+func (req *webhookCreateRequest) toCmd(owner domain.Account, projectId string) (
+	cmd app.WebhookCreateCmd, err error,
+) {
+	if cmd.URL, err = webhookdomain.NewWebhookURL(req.URL); err != nil {
+		return
+	}
+
+	if cmd.Secret, err = webhookdomain.NewWebhookSecret(req.Secret); err != nil {
+		return
+	}
+
+	cmd.Events = make([]webhookdomain.WebhookEvent, len(req.Events))
+	for i := range req.Events {
+		if cmd.Events[i], err = webhookdomain.NewWebhookEvent(req.Events[i]); err != nil {
+			return
+		}
+	}
+
+	cmd.Owner = owner
+	cmd.ProjectId = projectId
+
+	err = cmd.Validate()
+
+	return
+}
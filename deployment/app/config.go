@@ -0,0 +1,90 @@
+package app
+
+type Config struct {
+	// Endpoint is the prefix of the stable url of deployment.
+	Endpoint string `json:"endpoint"`
+
+	// MaxDeploymentNum is the max num of deployments of a user
+	MaxDeploymentNum int `json:"max_deployment_num"`
+
+	// MaxReplicas is the max num of replicas of a deployment
+	MaxReplicas int `json:"max_replicas"`
+
+	// MaxKeyNum is the max num of api keys of a deployment
+	MaxKeyNum int `json:"max_key_num"`
+
+	// DefaultRateLimit and MaxRateLimit are the num of requests
+	// per minute of an api key.
+	DefaultRateLimit int `json:"default_rate_limit"`
+	MaxRateLimit     int `json:"max_rate_limit"`
+
+	// MaxFailures is the num of failed replicas after which the
+	// deployment will not create replicas until it is redeployed.
+	MaxFailures int `json:"max_failures"`
+
+	// InactiveReplicaNum is the num of the latest inactive replicas
+	// kept for checking.
+	InactiveReplicaNum int `json:"inactive_replica_num"`
+
+	// Interval is the seconds between two checks of the scaling.
+	Interval int `json:"interval"`
+
+	// ScalingWindow is the minutes in which the rate of requests is measured.
+	ScalingWindow int `json:"scaling_window"`
+
+	// RenewBefore is the seconds before the expiry of replica to renew it.
+	RenewBefore int64 `json:"renew_before"`
+
+	// MaxUsageDays is the max days of usage can be queried.
+	MaxUsageDays int `json:"max_usage_days"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "/api/v1/deployment"
+	}
+
+	if cfg.MaxDeploymentNum <= 0 {
+		cfg.MaxDeploymentNum = 3
+	}
+
+	if cfg.MaxReplicas <= 0 {
+		cfg.MaxReplicas = 5
+	}
+
+	if cfg.MaxKeyNum <= 0 {
+		cfg.MaxKeyNum = 10
+	}
+
+	if cfg.DefaultRateLimit <= 0 {
+		cfg.DefaultRateLimit = 60
+	}
+
+	if cfg.MaxRateLimit <= 0 {
+		cfg.MaxRateLimit = 600
+	}
+
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 3
+	}
+
+	if cfg.InactiveReplicaNum <= 0 {
+		cfg.InactiveReplicaNum = 5
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30
+	}
+
+	if cfg.ScalingWindow <= 0 {
+		cfg.ScalingWindow = 5
+	}
+
+	if cfg.RenewBefore <= 0 {
+		cfg.RenewBefore = 600
+	}
+
+	if cfg.MaxUsageDays <= 0 {
+		cfg.MaxUsageDays = 30
+	}
+}
@@ -0,0 +1,399 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	common "github.com/opensourceways/xihe-server/common/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/inference"
+	"github.com/opensourceways/xihe-server/domain/platform"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	userrepo "github.com/opensourceways/xihe-server/user/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+type DeploymentService interface {
+	Create(*DeploymentCreateCmd) (DeploymentDTO, string, error)
+	List(owner types.Account) ([]DeploymentDTO, error)
+	Get(owner types.Account, id string) (DeploymentDTO, string, error)
+	UpdateScaling(*ScalingUpdateCmd) (string, error)
+	Redeploy(*DeploymentRedeployCmd) (string, error)
+	Delete(owner types.Account, id string) error
+
+	CreateKey(*APIKeyCreateCmd) (APIKeyCreatedDTO, string, error)
+	DeleteKey(owner types.Account, id, keyId string) (string, error)
+	GetUsage(*UsageQueryCmd) ([]UsageDTO, string, error)
+}
+
+func NewDeploymentService(
+	p platform.RepoFile,
+	repo repository.Deployment,
+	usage repository.Usage,
+	manager inference.Inference,
+	user userrepo.User,
+	whitelist userrepo.WhiteList,
+	cfg *Config,
+) DeploymentService {
+	return deploymentService{
+		p:      p,
+		repo:   repo,
+		usage:  usage,
+		cfg:    cfg,
+		scaler: newScaler(repo, manager, user, whitelist, cfg),
+	}
+}
+
+type deploymentService struct {
+	p      platform.RepoFile
+	repo   repository.Deployment
+	usage  repository.Usage
+	cfg    *Config
+	scaler scaler
+}
+
+func (s deploymentService) Create(cmd *DeploymentCreateCmd) (
+	dto DeploymentDTO, code string, err error,
+) {
+	if code, err = s.checkAllowed(cmd.Owner); err != nil {
+		return
+	}
+
+	if code, err = s.checkScaling(&cmd.Scaling); err != nil {
+		return
+	}
+
+	v, err := s.repo.FindAll(cmd.Owner)
+	if err != nil {
+		return
+	}
+
+	if len(v) >= s.cfg.MaxDeploymentNum {
+		code = ErrorDeploymentExccedMaxNum
+		err = errors.New("exceed max deployment num")
+
+		return
+	}
+
+	sha, code, err := s.getLastCommit(&cmd.RepoCmd, cmd.ProjectName)
+	if err != nil {
+		return
+	}
+
+	d := domain.Deployment{
+		Owner:         cmd.Owner,
+		ProjectId:     cmd.ProjectId,
+		ProjectName:   cmd.ProjectName,
+		ResourceLevel: cmd.ResourceLevel,
+		LastCommit:    sha,
+		Scaling:       cmd.Scaling,
+		CreatedAt:     utils.Now(),
+	}
+
+	if d.Id, err = s.repo.Add(&d); err != nil {
+		if repoerr.IsErrorDuplicateCreating(err) {
+			code = ErrorDeploymentExists
+		}
+
+		return
+	}
+
+	now := utils.Now()
+
+	// the replicas will be created at the next check if it fails.
+	if err1 := s.scaler.reconcile(&d, d.Scaling.MinReplicas, now); err1 != nil {
+		logrus.Errorf("create replicas of deployment(%s) failed, err:%s", d.Id, err1.Error())
+	}
+
+	dto = s.toDeploymentDTO(&d, now)
+
+	return
+}
+
+func (s deploymentService) List(owner types.Account) ([]DeploymentDTO, error) {
+	v, err := s.repo.FindAll(owner)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	now := utils.Now()
+	r := make([]DeploymentDTO, len(v))
+
+	for i := range v {
+		r[i] = s.toDeploymentDTO(&v[i], now)
+	}
+
+	return r, nil
+}
+
+func (s deploymentService) Get(owner types.Account, id string) (
+	dto DeploymentDTO, code string, err error,
+) {
+	d, code, err := s.find(owner, id)
+	if err == nil {
+		dto = s.toDeploymentDTO(&d, utils.Now())
+	}
+
+	return
+}
+
+func (s deploymentService) UpdateScaling(cmd *ScalingUpdateCmd) (code string, err error) {
+	if code, err = s.checkAllowed(cmd.Owner); err != nil {
+		return
+	}
+
+	if code, err = s.checkScaling(&cmd.Scaling); err != nil {
+		return
+	}
+
+	d, code, err := s.find(cmd.Owner, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	err = s.scaler.update(&d, func(d *domain.Deployment) bool {
+		d.Scaling = cmd.Scaling
+
+		return true
+	})
+
+	return
+}
+
+// Redeploy creates the replicas on the latest commit of project, and the
+// old replicas keep serving until the new ones are ready.
+func (s deploymentService) Redeploy(cmd *DeploymentRedeployCmd) (code string, err error) {
+	if code, err = s.checkAllowed(cmd.Owner); err != nil {
+		return
+	}
+
+	d, code, err := s.find(cmd.Owner, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	sha, code, err := s.getLastCommit(&cmd.RepoCmd, d.ProjectName)
+	if err != nil {
+		return
+	}
+
+	err = s.scaler.update(&d, func(d *domain.Deployment) bool {
+		d.LastCommit = sha
+		d.Failures = 0
+
+		return true
+	})
+	if err != nil {
+		return
+	}
+
+	err = s.scaler.reconcile(&d, d.Scaling.MinReplicas, utils.Now())
+
+	return
+}
+
+func (s deploymentService) Delete(owner types.Account, id string) error {
+	d, err := s.repo.Find(owner, id)
+	if err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := s.repo.Delete(owner, id); err != nil {
+		return err
+	}
+
+	s.scaler.stopAll(&d, utils.Now())
+
+	return s.usage.DeleteAll(id)
+}
+
+func (s deploymentService) CreateKey(cmd *APIKeyCreateCmd) (
+	dto APIKeyCreatedDTO, code string, err error,
+) {
+	d, code, err := s.find(cmd.Owner, cmd.Id)
+	if err != nil {
+		return
+	}
+
+	if len(d.Keys) >= s.cfg.MaxKeyNum {
+		code = ErrorDeploymentKeyExccedMaxNum
+		err = errors.New("exceed max api key num")
+
+		return
+	}
+
+	rateLimit := cmd.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = s.cfg.DefaultRateLimit
+	}
+
+	if rateLimit > s.cfg.MaxRateLimit {
+		rateLimit = s.cfg.MaxRateLimit
+	}
+
+	k, secret, err := domain.NewAPIKey(cmd.Name, rateLimit, utils.Now())
+	if err != nil {
+		return
+	}
+
+	err = s.scaler.update(&d, func(d *domain.Deployment) bool {
+		d.AddKey(&k)
+
+		return true
+	})
+	if err != nil {
+		return
+	}
+
+	dto.APIKeyDTO = toAPIKeyDTO(&k)
+	dto.Key = secret
+
+	return
+}
+
+func (s deploymentService) DeleteKey(owner types.Account, id, keyId string) (
+	code string, err error,
+) {
+	d, code, err := s.find(owner, id)
+	if err != nil {
+		return
+	}
+
+	found := false
+
+	err = s.scaler.update(&d, func(d *domain.Deployment) bool {
+		found = d.RemoveKey(keyId)
+
+		return found
+	})
+
+	if err == nil && !found {
+		code = ErrorDeploymentKeyNotFound
+		err = errors.New("api key is not found")
+	}
+
+	return
+}
+
+func (s deploymentService) GetUsage(cmd *UsageQueryCmd) (
+	dtos []UsageDTO, code string, err error,
+) {
+	if _, code, err = s.find(cmd.Owner, cmd.Id); err != nil {
+		return
+	}
+
+	days := cmd.Days
+	if days <= 0 || days > s.cfg.MaxUsageDays {
+		days = s.cfg.MaxUsageDays
+	}
+
+	since := common.UsageDate(utils.Now() - int64(days-1)*24*3600)
+
+	v, err := s.usage.FindAll(cmd.Id, since)
+	if err != nil || len(v) == 0 {
+		return
+	}
+
+	dtos = make([]UsageDTO, len(v))
+	for i := range v {
+		dtos[i] = toUsageDTO(&v[i])
+	}
+
+	return
+}
+
+func (s deploymentService) checkAllowed(owner types.Account) (string, error) {
+	b, err := s.scaler.isAllowed(owner)
+	if err != nil {
+		return "", err
+	}
+
+	if !b {
+		return ErrorDeploymentNotAllowed, errors.New("the user is not allowed to deploy")
+	}
+
+	return "", nil
+}
+
+func (s deploymentService) checkScaling(p *domain.ScalingPolicy) (string, error) {
+	if p.MaxReplicas > s.cfg.MaxReplicas {
+		return ErrorDeploymentExccedMaxReplicas, fmt.Errorf(
+			"the max replicas should not be greater than %d", s.cfg.MaxReplicas,
+		)
+	}
+
+	return "", nil
+}
+
+func (s deploymentService) find(owner types.Account, id string) (
+	d domain.Deployment, code string, err error,
+) {
+	if d, err = s.repo.Find(owner, id); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = ErrorDeploymentNotFound
+		}
+	}
+
+	return
+}
+
+func (s deploymentService) getLastCommit(cmd *RepoCmd, name types.ResourceName) (
+	sha string, code string, err error,
+) {
+	sha, b, err := s.p.GetDirFileInfo(&cmd.User, &platform.RepoDirFile{
+		RepoName: name,
+		Dir:      cmd.InferenceDir,
+		File:     cmd.BootFile,
+	})
+	if err == nil && !b {
+		code = ErrorDeploymentNoBootFile
+		err = errors.New("no boot file")
+	}
+
+	return
+}
+
+func (s deploymentService) toDeploymentDTO(d *domain.Deployment, now int64) DeploymentDTO {
+	dto := DeploymentDTO{
+		Id:          d.Id,
+		ProjectId:   d.ProjectId,
+		ProjectName: d.ProjectName.ResourceName(),
+		LastCommit:  d.LastCommit,
+		URL:         strings.TrimSuffix(s.cfg.Endpoint, "/") + "/" + d.Id + "/serve",
+		Status:      d.Status(now, s.cfg.MaxFailures),
+		MinReplicas: d.Scaling.MinReplicas,
+		MaxReplicas: d.Scaling.MaxReplicas,
+		TargetRPM:   d.Scaling.TargetRPM,
+		CreatedAt:   d.CreatedAt,
+	}
+
+	dto.Replicas = make([]ReplicaDTO, len(d.Replicas))
+	for i := range d.Replicas {
+		r := &d.Replicas[i]
+
+		dto.Replicas[i] = ReplicaDTO{
+			Id:         r.Id,
+			LastCommit: r.LastCommit,
+			Status:     r.StatusAt(now),
+			Expiry:     r.Expiry,
+			Error:      r.Error,
+			CreatedAt:  r.CreatedAt,
+		}
+	}
+
+	dto.Keys = make([]APIKeyDTO, len(d.Keys))
+	for i := range d.Keys {
+		dto.Keys[i] = toAPIKeyDTO(&d.Keys[i])
+	}
+
+	return dto
+}
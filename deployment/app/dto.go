@@ -0,0 +1,141 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/platform"
+)
+
+// RepoCmd locates the boot file of inference app in the project.
+type RepoCmd struct {
+	User         platform.UserInfo
+	InferenceDir types.Directory
+	BootFile     types.FilePath
+}
+
+func (cmd *RepoCmd) validate() bool {
+	return cmd.User.User != nil && cmd.InferenceDir != nil && cmd.BootFile != nil
+}
+
+type DeploymentCreateCmd struct {
+	RepoCmd
+
+	Owner         types.Account
+	ProjectId     string
+	ProjectName   types.ResourceName
+	ResourceLevel string
+	Scaling       domain.ScalingPolicy
+}
+
+func (cmd *DeploymentCreateCmd) Validate() error {
+	b := cmd.RepoCmd.validate() &&
+		cmd.Owner != nil &&
+		cmd.ProjectId != "" &&
+		cmd.ProjectName != nil
+
+	if !b {
+		return errors.New("invalid cmd of creating deployment")
+	}
+
+	return nil
+}
+
+type DeploymentRedeployCmd struct {
+	RepoCmd
+
+	Owner types.Account
+	Id    string
+}
+
+type ScalingUpdateCmd struct {
+	Owner   types.Account
+	Id      string
+	Scaling domain.ScalingPolicy
+}
+
+type APIKeyCreateCmd struct {
+	Owner     types.Account
+	Id        string
+	Name      domain.APIKeyName
+	RateLimit int
+}
+
+type UsageQueryCmd struct {
+	Owner types.Account
+	Id    string
+	Days  int
+}
+
+type DeploymentDTO struct {
+	Id          string       `json:"id"`
+	ProjectId   string       `json:"project_id"`
+	ProjectName string       `json:"project_name"`
+	LastCommit  string       `json:"commit"`
+	URL         string       `json:"url"`
+	Status      string       `json:"status"`
+	MinReplicas int          `json:"min_replicas"`
+	MaxReplicas int          `json:"max_replicas"`
+	TargetRPM   int          `json:"target_rpm"`
+	Replicas    []ReplicaDTO `json:"replicas"`
+	Keys        []APIKeyDTO  `json:"keys"`
+	CreatedAt   int64        `json:"created_at"`
+}
+
+type ReplicaDTO struct {
+	Id         string `json:"id"`
+	LastCommit string `json:"commit"`
+	Status     string `json:"status"`
+	Expiry     int64  `json:"expiry"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type APIKeyDTO struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Mask      string `json:"mask"`
+	RateLimit int    `json:"rate_limit"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// APIKeyCreatedDTO contains the plaintext of key which is only shown once.
+type APIKeyCreatedDTO struct {
+	APIKeyDTO
+
+	Key string `json:"key"`
+}
+
+type UsageDTO struct {
+	Date       string `json:"date"`
+	KeyId      string `json:"key_id"`
+	Requests   int    `json:"requests"`
+	Failures   int    `json:"failures"`
+	AvgLatency int64  `json:"avg_latency"`
+}
+
+func toAPIKeyDTO(k *domain.APIKey) APIKeyDTO {
+	return APIKeyDTO{
+		Id:        k.Id,
+		Name:      k.Name.APIKeyName(),
+		Mask:      k.Mask,
+		RateLimit: k.RateLimit,
+		CreatedAt: k.CreatedAt,
+	}
+}
+
+func toUsageDTO(u *domain.Usage) UsageDTO {
+	dto := UsageDTO{
+		Date:     u.Date,
+		KeyId:    u.KeyId,
+		Requests: u.Requests,
+		Failures: u.Failures,
+	}
+
+	if u.Requests > 0 {
+		dto.AvgLatency = u.Latency / int64(u.Requests)
+	}
+
+	return dto
+}
@@ -0,0 +1,17 @@
+package app
+
+const (
+	ErrorDeploymentNotFound          = "deployment_not_found"
+	ErrorDeploymentNotAllowed        = "deployment_not_allowed"
+	ErrorDeploymentExists            = "deployment_exists"
+	ErrorDeploymentExccedMaxNum      = "deployment_excced_max_num"
+	ErrorDeploymentExccedMaxReplicas = "deployment_excced_max_replicas"
+	ErrorDeploymentNoBootFile        = "deployment_no_boot_file"
+	ErrorDeploymentKeyNotFound       = "deployment_key_not_found"
+	ErrorDeploymentKeyExccedMaxNum   = "deployment_key_excced_max_num"
+
+	// the errors of calling the deployment by the api key
+	ErrorDeploymentInvalidKey  = "deployment_invalid_key"
+	ErrorDeploymentRateLimited = "deployment_rate_limited"
+	ErrorDeploymentUnavailable = "deployment_unavailable"
+)
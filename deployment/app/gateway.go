@@ -0,0 +1,120 @@
+package app
+
+import (
+	"errors"
+	"math/rand"
+
+	common "github.com/opensourceways/xihe-server/common/domain"
+	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain/repository"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// GatewayService routes the requests of applications to the replicas
+// of deployment and accounts the usage of api keys.
+type GatewayService interface {
+	Route(*RouteCmd) (RouteDTO, string, error)
+	Record(*RecordCmd) error
+}
+
+func NewGatewayService(
+	repo repository.Deployment,
+	usage repository.Usage,
+	counter commonrepo.UsageCounter,
+) GatewayService {
+	return gatewayService{
+		repo:    repo,
+		usage:   usage,
+		counter: counter,
+	}
+}
+
+type gatewayService struct {
+	repo    repository.Deployment
+	usage   repository.Usage
+	counter commonrepo.UsageCounter
+}
+
+type RouteCmd struct {
+	Id  string
+	Key string
+}
+
+// RouteDTO is the replica which will serve the request.
+type RouteDTO struct {
+	KeyId     string
+	AccessURL string
+}
+
+type RecordCmd struct {
+	Id      string
+	KeyId   string
+	Failed  bool
+	Latency int64
+}
+
+func (s gatewayService) Route(cmd *RouteCmd) (dto RouteDTO, code string, err error) {
+	d, err := s.repo.FindById(cmd.Id)
+	if err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = ErrorDeploymentNotFound
+		}
+
+		return
+	}
+
+	k := d.Authenticate(cmd.Key)
+	if k == nil {
+		code = ErrorDeploymentInvalidKey
+		err = errors.New("invalid api key")
+
+		return
+	}
+
+	now := utils.Now()
+
+	v := d.ServingReplicas(now)
+	if len(v) == 0 {
+		code = ErrorDeploymentUnavailable
+		err = errors.New("no replica is serving")
+
+		return
+	}
+
+	// only the admitted requests are counted, so the requests rejected
+	// by the rate limit don't scale out the deployment.
+	i, err := s.counter.Admit(domain.UsageLimits(d.Id, k, now))
+	if err != nil {
+		return
+	}
+
+	if i >= 0 {
+		code = ErrorDeploymentRateLimited
+		err = errors.New("exceed the rate limit of api key")
+
+		return
+	}
+
+	dto.KeyId = k.Id
+	dto.AccessURL = v[rand.Intn(len(v))].AccessURL
+
+	return
+}
+
+func (s gatewayService) Record(cmd *RecordCmd) error {
+	u := domain.Usage{
+		DeploymentId: cmd.Id,
+		KeyId:        cmd.KeyId,
+		Date:         common.UsageDate(utils.Now()),
+		Requests:     1,
+		Latency:      cmd.Latency,
+	}
+
+	if cmd.Failed {
+		u.Failures = 1
+	}
+
+	return s.usage.Record(&u)
+}
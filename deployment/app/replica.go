@@ -0,0 +1,69 @@
+package app
+
+import (
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+)
+
+// ReplicaService updates the replicas by the details which the inference
+// manager reports for the instances.
+type ReplicaService interface {
+	// UpdateReplica returns false if the instance is not a replica.
+	UpdateReplica(*types.InferenceIndex, *types.InferenceDetail) (bool, error)
+}
+
+func NewReplicaService(repo repository.Deployment) ReplicaService {
+	return replicaService{
+		scaler: scaler{repo: repo},
+	}
+}
+
+type replicaService struct {
+	scaler scaler
+}
+
+func (s replicaService) UpdateReplica(index *types.InferenceIndex, detail *types.InferenceDetail) (
+	bool, error,
+) {
+	id, ok := domain.ParseReplicaId(index.Id)
+	if !ok {
+		return false, nil
+	}
+
+	d, err := s.scaler.repo.Find(index.Project.Owner, id)
+	if err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			return false, nil
+		}
+
+		return true, err
+	}
+
+	if d.FindReplica(index.Id) == nil {
+		return false, nil
+	}
+
+	err = s.scaler.update(&d, func(d *domain.Deployment) bool {
+		r := d.FindReplica(index.Id)
+		if r == nil {
+			return false
+		}
+
+		failed := r.Error != ""
+		r.Update(detail)
+
+		if r.LastCommit == d.LastCommit {
+			if r.AccessURL != "" {
+				d.Failures = 0
+			} else if !failed && r.Error != "" {
+				d.Failures++
+			}
+		}
+
+		return true
+	})
+
+	return true, err
+}
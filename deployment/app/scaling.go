@@ -0,0 +1,390 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	common "github.com/opensourceways/xihe-server/common/domain"
+	commonrepo "github.com/opensourceways/xihe-server/common/domain/repository"
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/inference"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	userdomain "github.com/opensourceways/xihe-server/user/domain"
+	userrepo "github.com/opensourceways/xihe-server/user/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	maxUpdateTimes = 3
+
+	// minTimeToExtend is the min seconds worth extending the survival time.
+	minTimeToExtend = 10
+
+	errorCodeCreateReplica = "create_replica_failed"
+)
+
+// ScalingService checks all the deployments periodically and scales
+// their replicas by the rate of requests.
+type ScalingService interface {
+	Reconcile() error
+}
+
+func NewScalingService(
+	repo repository.Deployment,
+	counter commonrepo.UsageCounter,
+	manager inference.Inference,
+	user userrepo.User,
+	whitelist userrepo.WhiteList,
+	cfg *Config,
+) ScalingService {
+	return scalingService{
+		repo:    repo,
+		counter: counter,
+		window:  cfg.ScalingWindow,
+		scaler:  newScaler(repo, manager, user, whitelist, cfg),
+	}
+}
+
+type scalingService struct {
+	repo    repository.Deployment
+	counter commonrepo.UsageCounter
+	window  int
+	scaler  scaler
+}
+
+func (s scalingService) Reconcile() error {
+	v, err := s.repo.FindAllDeployments()
+	if err != nil {
+		return err
+	}
+
+	now := utils.Now()
+
+	// the counts of the minutes in the window, including the current one.
+	after := common.MinuteWindow(now).Expiry - int64(s.window)*60
+	allowed := map[string]bool{}
+
+	for i := range v {
+		d := &v[i]
+
+		b, ok := allowed[d.Owner.Account()]
+		if !ok {
+			if b, err = s.scaler.isAllowed(d.Owner); err != nil {
+				logrus.Errorf("check the whitelist of deployment(%s) failed, err:%s", d.Id, err.Error())
+
+				continue
+			}
+
+			allowed[d.Owner.Account()] = b
+		}
+
+		desired := 0
+
+		// the replicas of the owner who is not allowed any more are all
+		// stopped and never renewed.
+		if b {
+			n, err := s.counter.Sum(domain.UsageKey(d.Id), after)
+			if err != nil {
+				logrus.Errorf("sum the requests of deployment(%s) failed, err:%s", d.Id, err.Error())
+
+				continue
+			}
+
+			desired = d.Scaling.DesiredReplicas(float64(n) / float64(s.window))
+		}
+
+		if err := s.scaler.reconcile(d, desired, now); err != nil {
+			logrus.Errorf("scale deployment(%s) failed, err:%s", d.Id, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// scaler keeps the replicas of deployment as desired. It changes the
+// replicas in the repository first, then calls the inference manager
+// and saves the results, so that the details of new replicas reported
+// by the manager can always find them.
+type scaler struct {
+	repo      repository.Deployment
+	user      userrepo.User
+	manager   inference.Inference
+	whitelist userrepo.WhiteList
+
+	maxFailures        int
+	inactiveReplicaNum int
+	renewBefore        int64
+}
+
+func newScaler(
+	repo repository.Deployment,
+	manager inference.Inference,
+	user userrepo.User,
+	whitelist userrepo.WhiteList,
+	cfg *Config,
+) scaler {
+	return scaler{
+		repo:               repo,
+		user:               user,
+		manager:            manager,
+		whitelist:          whitelist,
+		maxFailures:        cfg.MaxFailures,
+		inactiveReplicaNum: cfg.InactiveReplicaNum,
+		renewBefore:        cfg.RenewBefore,
+	}
+}
+
+// isAllowed checks whether the owner is in the deployment whitelist.
+func (s scaler) isAllowed(owner types.Account) (bool, error) {
+	v, err := s.whitelist.FindByAccountAndWhitelistType(
+		owner, []string{userdomain.WhitelistTypeDeployment},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range v {
+		if v[i].Enable() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type scalingPlan struct {
+	create []string
+	stop   []string
+	renew  []string
+}
+
+func (p *scalingPlan) isEmpty() bool {
+	return len(p.create) == 0 && len(p.stop) == 0 && len(p.renew) == 0
+}
+
+type replicaResult struct {
+	id     string
+	expiry int64
+	err    error
+}
+
+func (s scaler) reconcile(d *domain.Deployment, desired int, now int64) error {
+	var p scalingPlan
+
+	err := s.update(d, func(d *domain.Deployment) bool {
+		n := len(d.Replicas)
+		p = s.plan(d, desired, now)
+		d.PruneReplicas(s.inactiveReplicaNum, now)
+
+		return !p.isEmpty() || n != len(d.Replicas)
+	})
+	if err != nil || p.isEmpty() {
+		return err
+	}
+
+	results := s.execute(d, &p, now)
+
+	return s.update(d, func(d *domain.Deployment) bool {
+		return s.apply(d, results)
+	})
+}
+
+// plan decides the replicas to create, stop and renew. The replicas to stop
+// are marked at once, and the new replicas are added as creating.
+// The replicas on the old commits are stopped only after the ones on the
+// LastCommit are all serving.
+func (s scaler) plan(d *domain.Deployment, desired int, now int64) (p scalingPlan) {
+	var current, outdated []*domain.Replica
+
+	for _, r := range d.ActiveReplicas(now) {
+		if r.LastCommit == d.LastCommit {
+			current = append(current, r)
+		} else {
+			outdated = append(outdated, r)
+		}
+	}
+
+	if n := len(current) - desired; n > 0 {
+		// stop the newest ones which are probably still starting up.
+		sort.SliceStable(current, func(i, j int) bool {
+			return current[i].CreatedAt > current[j].CreatedAt
+		})
+
+		for _, r := range current[:n] {
+			r.Stopped = true
+			p.stop = append(p.stop, r.Id)
+		}
+
+		current = current[n:]
+	}
+
+	serving := 0
+	for _, r := range current {
+		if r.StatusAt(now) == types.InferenceStatusRunning {
+			serving++
+		}
+	}
+
+	if serving >= desired {
+		for _, r := range outdated {
+			r.Stopped = true
+			p.stop = append(p.stop, r.Id)
+		}
+
+		outdated = nil
+	}
+
+	for _, r := range append(current, outdated...) {
+		if r.Expiry > 0 && r.Expiry-now < s.renewBefore {
+			p.renew = append(p.renew, r.Id)
+		}
+	}
+
+	if d.Failures >= s.maxFailures {
+		return
+	}
+
+	// it must be the last step, because the replicas may be reallocated.
+	for i := len(current); i < desired; i++ {
+		p.create = append(p.create, d.NewReplica(now).Id)
+	}
+
+	return
+}
+
+func (s scaler) execute(d *domain.Deployment, p *scalingPlan, now int64) []replicaResult {
+	for _, id := range p.stop {
+		if r := d.FindReplica(id); r != nil {
+			index := d.InferenceIndex(r)
+
+			if err := s.manager.Stop(&index); err != nil {
+				logrus.Errorf("stop replica(%s) failed, err:%s", id, err.Error())
+			}
+		}
+	}
+
+	results := make([]replicaResult, 0, len(p.create)+len(p.renew))
+
+	for _, id := range p.renew {
+		if r := d.FindReplica(id); r != nil {
+			results = append(results, s.renew(d, r, now))
+		}
+	}
+
+	if len(p.create) == 0 {
+		return results
+	}
+
+	u, err := s.user.GetByAccount(d.Owner)
+	if err != nil {
+		for _, id := range p.create {
+			results = append(results, replicaResult{id: id, err: err})
+		}
+
+		return results
+	}
+
+	for _, id := range p.create {
+		r := d.FindReplica(id)
+		if r == nil {
+			continue
+		}
+
+		info := d.InferenceInfo(r)
+
+		v, err := s.manager.Create(&inference.InferenceInfo{
+			InferenceInfo: &info,
+			UserToken:     u.PlatformToken.Token,
+		})
+
+		results = append(results, replicaResult{id: id, expiry: now + int64(v), err: err})
+	}
+
+	return results
+}
+
+func (s scaler) renew(d *domain.Deployment, r *domain.Replica, now int64) replicaResult {
+	info := d.InferenceInfo(r)
+	expiry := now + int64(s.manager.GetSurvivalTime(&info))
+
+	v := int(expiry - r.Expiry)
+	if v < minTimeToExtend {
+		return replicaResult{id: r.Id}
+	}
+
+	if err := s.manager.ExtendSurvivalTime(&info.InferenceIndex, v); err != nil {
+		logrus.Errorf("renew replica(%s) failed, err:%s", r.Id, err.Error())
+
+		return replicaResult{id: r.Id}
+	}
+
+	return replicaResult{id: r.Id, expiry: expiry}
+}
+
+func (s scaler) apply(d *domain.Deployment, results []replicaResult) (changed bool) {
+	for i := range results {
+		item := &results[i]
+
+		r := d.FindReplica(item.id)
+		if r == nil {
+			continue
+		}
+
+		if item.err != nil {
+			r.Error = item.err.Error()
+			r.Failure = &types.InferenceError{
+				Stage:   types.InferenceStageBuild,
+				Code:    errorCodeCreateReplica,
+				Message: r.Error,
+			}
+
+			if r.LastCommit == d.LastCommit {
+				d.Failures++
+			}
+
+			changed = true
+
+			continue
+		}
+
+		if item.expiry > r.Expiry {
+			r.Expiry = item.expiry
+			changed = true
+		}
+	}
+
+	return
+}
+
+// update changes the deployment by f and saves it. It reloads the deployment
+// and retries if it is updated concurrently. f returns false if nothing changed.
+func (s scaler) update(d *domain.Deployment, f func(*domain.Deployment) bool) error {
+	for i := 0; ; i++ {
+		if !f(d) {
+			return nil
+		}
+
+		err := s.repo.Save(d)
+		if err == nil || i+1 >= maxUpdateTimes || !repoerr.IsErrorConcurrentUpdating(err) {
+			return err
+		}
+
+		if *d, err = s.repo.Find(d.Owner, d.Id); err != nil {
+			return err
+		}
+	}
+}
+
+// stopAll stops all the active replicas, it is used before deleting.
+func (s scaler) stopAll(d *domain.Deployment, now int64) {
+	for _, r := range d.ActiveReplicas(now) {
+		index := d.InferenceIndex(r)
+
+		if err := s.manager.Stop(&index); err != nil {
+			logrus.Errorf("stop replica(%s) failed, err:%s", r.Id, err.Error())
+		}
+	}
+}
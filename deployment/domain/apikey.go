@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+const (
+	apiKeyPrefix     = "xhd_"
+	apiKeyMaskLength = 8
+)
+
+// APIKey is used by the applications to call the deployment.
+// Only the hash of key is stored, and the key is shown once when created.
+// RateLimit is the max num of requests per minute.
+type APIKey struct {
+	Id        string
+	Name      APIKeyName
+	Hash      string
+	Mask      string
+	RateLimit int
+	CreatedAt int64
+}
+
+func (k *APIKey) match(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(k.Hash)) == 1
+}
+
+// NewAPIKey generates a key and returns it with its plaintext.
+func NewAPIKey(name APIKeyName, rateLimit int, now int64) (APIKey, string, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	secret, err := randomHex(24)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	key := apiKeyPrefix + secret
+
+	return APIKey{
+		Id:        id,
+		Name:      name,
+		Hash:      hashAPIKey(key),
+		Mask:      key[:len(apiKeyPrefix)+apiKeyMaskLength] + "****",
+		RateLimit: rateLimit,
+		CreatedAt: now,
+	}, key, nil
+}
+
+func (d *Deployment) AddKey(k *APIKey) {
+	d.Keys = append(d.Keys, *k)
+}
+
+func (d *Deployment) RemoveKey(id string) bool {
+	for i := range d.Keys {
+		if d.Keys[i].Id == id {
+			d.Keys = append(d.Keys[:i], d.Keys[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authenticate returns the api key which matches the key.
+func (d *Deployment) Authenticate(key string) *APIKey {
+	for i := range d.Keys {
+		if d.Keys[i].match(key) {
+			return &d.Keys[i]
+		}
+	}
+
+	return nil
+}
+
+func hashAPIKey(key string) string {
+	v := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(v[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
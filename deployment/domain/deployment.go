@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+const (
+	DeploymentStatusPending  = "pending"
+	DeploymentStatusCreating = "creating"
+	DeploymentStatusRunning  = "running"
+	DeploymentStatusFailed   = "failed"
+
+	replicaIdSeparator = "-"
+)
+
+// Deployment serves the inference app of a project persistently by
+// several replicas which are the inference instances created on demand.
+type Deployment struct {
+	Id            string
+	Owner         types.Account
+	ProjectId     string
+	ProjectName   types.ResourceName
+	ResourceLevel string
+
+	// LastCommit is the commit the new replicas will be created on.
+	LastCommit string
+
+	Scaling  ScalingPolicy
+	Replicas []Replica
+	Keys     []APIKey
+
+	// ReplicaSeq is used to generate the id of replica.
+	ReplicaSeq int
+
+	// Failures is the num of replicas on the LastCommit which have
+	// failed since the last one started up successfully.
+	Failures int
+
+	CreatedAt int64
+	Version   int
+}
+
+// Replica is an inference instance of deployment.
+type Replica struct {
+	Id         string
+	LastCommit string
+	CreatedAt  int64
+
+	types.InferenceDetail
+}
+
+// Update updates the replica by the detail reported by the manager.
+// The empty fields of detail are ignored.
+func (r *Replica) Update(detail *types.InferenceDetail) {
+	if detail.Expiry > 0 {
+		r.Expiry = detail.Expiry
+	}
+
+	if detail.Error != "" {
+		r.Error = detail.Error
+	}
+
+	if detail.Failure != nil {
+		r.Failure = detail.Failure
+	}
+
+	if detail.AccessURL != "" {
+		r.AccessURL = detail.AccessURL
+	}
+
+	if detail.StartupLogURL != "" {
+		r.StartupLogURL = detail.StartupLogURL
+	}
+
+	if detail.RuntimeLogURL != "" {
+		r.RuntimeLogURL = detail.RuntimeLogURL
+	}
+}
+
+// ParseReplicaId returns the id of deployment the replica belongs to.
+// It returns false if the id is not of replica.
+func ParseReplicaId(id string) (string, bool) {
+	i := strings.LastIndex(id, replicaIdSeparator)
+	if i <= 0 {
+		return "", false
+	}
+
+	return id[:i], true
+}
+
+// NewReplica adds a replica on the LastCommit and returns it.
+func (d *Deployment) NewReplica(now int64) *Replica {
+	d.ReplicaSeq++
+
+	d.Replicas = append(d.Replicas, Replica{
+		Id:         fmt.Sprintf("%s%s%d", d.Id, replicaIdSeparator, d.ReplicaSeq),
+		LastCommit: d.LastCommit,
+		CreatedAt:  now,
+	})
+
+	return &d.Replicas[len(d.Replicas)-1]
+}
+
+func (d *Deployment) FindReplica(id string) *Replica {
+	for i := range d.Replicas {
+		if d.Replicas[i].Id == id {
+			return &d.Replicas[i]
+		}
+	}
+
+	return nil
+}
+
+func (d *Deployment) InferenceIndex(r *Replica) types.InferenceIndex {
+	return types.InferenceIndex{
+		Project: types.ResourceIndex{
+			Owner: d.Owner,
+			Id:    d.ProjectId,
+		},
+		Id:         r.Id,
+		LastCommit: r.LastCommit,
+	}
+}
+
+func (d *Deployment) InferenceInfo(r *Replica) types.InferenceInfo {
+	return types.InferenceInfo{
+		InferenceIndex: d.InferenceIndex(r),
+		ProjectName:    d.ProjectName,
+		ResourceLevel:  d.ResourceLevel,
+		Requester:      d.Owner.Account(),
+	}
+}
+
+// ActiveReplicas returns the replicas which are starting up or running.
+func (d *Deployment) ActiveReplicas(now int64) []*Replica {
+	return d.filterReplicas(func(r *Replica) bool {
+		return r.IsActive(now)
+	})
+}
+
+// ServingReplicas returns the running replicas which can serve requests.
+func (d *Deployment) ServingReplicas(now int64) []*Replica {
+	return d.filterReplicas(func(r *Replica) bool {
+		return r.StatusAt(now) == types.InferenceStatusRunning
+	})
+}
+
+func (d *Deployment) filterReplicas(f func(*Replica) bool) []*Replica {
+	var r []*Replica
+
+	for i := range d.Replicas {
+		if item := &d.Replicas[i]; f(item) {
+			r = append(r, item)
+		}
+	}
+
+	return r
+}
+
+// PruneReplicas removes the inactive replicas except the latest n ones.
+func (d *Deployment) PruneReplicas(n int, now int64) {
+	var active, inactive []Replica
+
+	for i := range d.Replicas {
+		if item := d.Replicas[i]; item.IsActive(now) {
+			active = append(active, item)
+		} else {
+			inactive = append(inactive, item)
+		}
+	}
+
+	if len(inactive) <= n {
+		return
+	}
+
+	sort.SliceStable(inactive, func(i, j int) bool {
+		return inactive[i].CreatedAt > inactive[j].CreatedAt
+	})
+
+	r := append(active, inactive[:n]...)
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].CreatedAt < r[j].CreatedAt
+	})
+
+	d.Replicas = r
+}
+
+// Status derives the state of deployment from its replicas.
+func (d *Deployment) Status(now int64, maxFailures int) string {
+	if len(d.ServingReplicas(now)) > 0 {
+		return DeploymentStatusRunning
+	}
+
+	if d.Failures >= maxFailures {
+		return DeploymentStatusFailed
+	}
+
+	if len(d.ActiveReplicas(now)) > 0 {
+		return DeploymentStatusCreating
+	}
+
+	return DeploymentStatusPending
+}
+
+// ScalingPolicy decides the num of replicas by the rate of requests.
+// TargetRPM is the num of requests per minute a replica can serve.
+type ScalingPolicy struct {
+	MinReplicas int
+	MaxReplicas int
+	TargetRPM   int
+}
+
+func NewScalingPolicy(min, max, targetRPM int) (ScalingPolicy, error) {
+	if min < 1 || max < min {
+		return ScalingPolicy{}, errors.New(
+			"min replicas should be positive and not be greater than max replicas",
+		)
+	}
+
+	if targetRPM <= 0 {
+		return ScalingPolicy{}, errors.New("target rpm should be positive")
+	}
+
+	return ScalingPolicy{
+		MinReplicas: min,
+		MaxReplicas: max,
+		TargetRPM:   targetRPM,
+	}, nil
+}
+
+func (p *ScalingPolicy) DesiredReplicas(rpm float64) int {
+	n := int(math.Ceil(rpm / float64(p.TargetRPM)))
+
+	if n < p.MinReplicas {
+		return p.MinReplicas
+	}
+
+	if n > p.MaxReplicas {
+		return p.MaxReplicas
+	}
+
+	return n
+}
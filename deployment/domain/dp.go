@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const apiKeyNameMaxLength = 50
+
+// APIKeyName
+type APIKeyName interface {
+	APIKeyName() string
+}
+
+func NewAPIKeyName(v string) (APIKeyName, error) {
+	if v == "" {
+		return nil, errors.New("empty name of api key")
+	}
+
+	if utils.StrLen(v) > apiKeyNameMaxLength {
+		return nil, fmt.Errorf(
+			"the length of name of api key should be less than %d",
+			apiKeyNameMaxLength,
+		)
+	}
+
+	return apiKeyName(v), nil
+}
+
+type apiKeyName string
+
+func (r apiKeyName) APIKeyName() string {
+	return string(r)
+}
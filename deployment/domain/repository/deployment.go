@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type Deployment interface {
+	Add(*domain.Deployment) (string, error)
+	Save(*domain.Deployment) error
+	Find(owner types.Account, id string) (domain.Deployment, error)
+	FindById(id string) (domain.Deployment, error)
+	FindAll(owner types.Account) ([]domain.Deployment, error)
+	FindAllDeployments() ([]domain.Deployment, error)
+	Delete(owner types.Account, id string) error
+}
+
+type Usage interface {
+	Record(*domain.Usage) error
+	FindAll(deploymentId string, since string) ([]domain.Usage, error)
+	DeleteAll(deploymentId string) error
+}
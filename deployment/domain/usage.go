@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"fmt"
+
+	common "github.com/opensourceways/xihe-server/common/domain"
+)
+
+// Usage is the statistics of the requests by an api key in a day.
+// Latency is the total milliseconds of the requests.
+type Usage struct {
+	DeploymentId string
+	KeyId        string
+	Date         string
+	Requests     int
+	Failures     int
+	Latency      int64
+}
+
+// UsageLimits returns the limits to count a request by the api key. The
+// requests of the key are limited by its rate limit, and the ones of the
+// deployment are only counted for scaling.
+func UsageLimits(deploymentId string, k *APIKey, now int64) []common.UsageLimit {
+	w := common.MinuteWindow(now)
+
+	return []common.UsageLimit{
+		{Key: fmt.Sprintf("deployment/%s/%s", deploymentId, k.Id), Window: w, Max: k.RateLimit},
+		{Key: UsageKey(deploymentId), Window: w},
+	}
+}
+
+// UsageKey is the key of the counts of requests to the deployment.
+func UsageKey(deploymentId string) string {
+	return "deployment/" + deploymentId
+}
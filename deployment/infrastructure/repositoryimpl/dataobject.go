@@ -0,0 +1,144 @@
+package repositoryimpl
+
+import (
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+func toDeploymentDoc(d *domain.Deployment) dDeployment {
+	replicas := make([]dReplica, len(d.Replicas))
+	for i := range d.Replicas {
+		replicas[i] = toReplicaDoc(&d.Replicas[i])
+	}
+
+	keys := make([]dAPIKey, len(d.Keys))
+	for i := range d.Keys {
+		k := &d.Keys[i]
+
+		keys[i] = dAPIKey{
+			Id:        k.Id,
+			Name:      k.Name.APIKeyName(),
+			Hash:      k.Hash,
+			Mask:      k.Mask,
+			RateLimit: k.RateLimit,
+			CreatedAt: k.CreatedAt,
+		}
+	}
+
+	return dDeployment{
+		Owner:         d.Owner.Account(),
+		ProjectId:     d.ProjectId,
+		ProjectName:   d.ProjectName.ResourceName(),
+		ResourceLevel: d.ResourceLevel,
+		LastCommit:    d.LastCommit,
+		MinReplicas:   d.Scaling.MinReplicas,
+		MaxReplicas:   d.Scaling.MaxReplicas,
+		TargetRPM:     d.Scaling.TargetRPM,
+		Replicas:      replicas,
+		Keys:          keys,
+		ReplicaSeq:    d.ReplicaSeq,
+		Failures:      d.Failures,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+func toReplicaDoc(r *domain.Replica) dReplica {
+	doc := dReplica{
+		Id:            r.Id,
+		LastCommit:    r.LastCommit,
+		CreatedAt:     r.CreatedAt,
+		Expiry:        r.Expiry,
+		Error:         r.Error,
+		AccessURL:     r.AccessURL,
+		StartupLogURL: r.StartupLogURL,
+		RuntimeLogURL: r.RuntimeLogURL,
+		Stopped:       r.Stopped,
+	}
+
+	if v := r.Failure; v != nil {
+		doc.Failure = &dInferenceError{
+			Stage:   v.Stage,
+			Code:    v.Code,
+			Message: v.Message,
+		}
+	}
+
+	return doc
+}
+
+func (doc *dDeployment) toDeployment(d *domain.Deployment) (err error) {
+	if d.Owner, err = types.NewAccount(doc.Owner); err != nil {
+		return
+	}
+
+	if d.ProjectName, err = types.NewResourceName(doc.ProjectName); err != nil {
+		return
+	}
+
+	d.Keys = make([]domain.APIKey, len(doc.Keys))
+	for i := range doc.Keys {
+		k := &doc.Keys[i]
+
+		if d.Keys[i].Name, err = domain.NewAPIKeyName(k.Name); err != nil {
+			return
+		}
+
+		d.Keys[i].Id = k.Id
+		d.Keys[i].Hash = k.Hash
+		d.Keys[i].Mask = k.Mask
+		d.Keys[i].RateLimit = k.RateLimit
+		d.Keys[i].CreatedAt = k.CreatedAt
+	}
+
+	d.Replicas = make([]domain.Replica, len(doc.Replicas))
+	for i := range doc.Replicas {
+		doc.Replicas[i].toReplica(&d.Replicas[i])
+	}
+
+	d.Id = doc.Id.Hex()
+	d.ProjectId = doc.ProjectId
+	d.ResourceLevel = doc.ResourceLevel
+	d.LastCommit = doc.LastCommit
+	d.Scaling = domain.ScalingPolicy{
+		MinReplicas: doc.MinReplicas,
+		MaxReplicas: doc.MaxReplicas,
+		TargetRPM:   doc.TargetRPM,
+	}
+	d.ReplicaSeq = doc.ReplicaSeq
+	d.Failures = doc.Failures
+	d.CreatedAt = doc.CreatedAt
+	d.Version = doc.Version
+
+	return
+}
+
+func (doc *dReplica) toReplica(r *domain.Replica) {
+	r.Id = doc.Id
+	r.LastCommit = doc.LastCommit
+	r.CreatedAt = doc.CreatedAt
+	r.Expiry = doc.Expiry
+	r.Error = doc.Error
+	r.AccessURL = doc.AccessURL
+	r.StartupLogURL = doc.StartupLogURL
+	r.RuntimeLogURL = doc.RuntimeLogURL
+	r.Stopped = doc.Stopped
+
+	if v := doc.Failure; v != nil {
+		r.Failure = &types.InferenceError{
+			Stage:   v.Stage,
+			Code:    v.Code,
+			Message: v.Message,
+		}
+	}
+}
+
+func (doc *dUsage) toUsage() domain.Usage {
+	return domain.Usage{
+		DeploymentId: doc.DeploymentId,
+		KeyId:        doc.Key,
+		Date:         doc.Date,
+		Requests:     doc.Requests,
+		Failures:     doc.Failures,
+		Latency:      doc.Latency,
+	}
+}
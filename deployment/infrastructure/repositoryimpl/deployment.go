@@ -0,0 +1,175 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+)
+
+func NewDeploymentRepo(m mongodbClient) repository.Deployment {
+	return deploymentRepoImpl{m}
+}
+
+type deploymentRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl deploymentRepoImpl) docFilter(owner types.Account, id string) (bson.M, error) {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter[fieldOwner] = owner.Account()
+
+	return filter, nil
+}
+
+// Add adds the deployment. There is at most one deployment for a project.
+func (impl deploymentRepoImpl) Add(d *domain.Deployment) (id string, err error) {
+	doc, err := genDoc(toDeploymentDoc(d))
+	if err != nil {
+		return
+	}
+	doc[fieldVersion] = 0
+
+	f := func(ctx context.Context) error {
+		id, err = impl.cli.NewDocIfNotExist(
+			ctx,
+			bson.M{
+				fieldOwner: d.Owner.Account(),
+				fieldPid:   d.ProjectId,
+			},
+			doc,
+		)
+
+		return err
+	}
+
+	if err = withContext(f); err != nil && impl.cli.IsDocExists(err) {
+		err = repoerr.NewErrorDuplicateCreating(err)
+	}
+
+	return
+}
+
+func (impl deploymentRepoImpl) Save(d *domain.Deployment) error {
+	filter, err := impl.docFilter(d.Owner, d.Id)
+	if err != nil {
+		return err
+	}
+
+	doc, err := genDoc(toDeploymentDoc(d))
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		return impl.cli.UpdateDoc(ctx, filter, doc, mongoCmdSet, d.Version)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorConcurrentUpdating(err)
+		}
+
+		return err
+	}
+
+	d.Version++
+
+	return nil
+}
+
+func (impl deploymentRepoImpl) Find(owner types.Account, id string) (
+	domain.Deployment, error,
+) {
+	filter, err := impl.docFilter(owner, id)
+	if err != nil {
+		return domain.Deployment{}, repoerr.NewErrorResourceNotExists(err)
+	}
+
+	return impl.find(filter)
+}
+
+func (impl deploymentRepoImpl) FindById(id string) (domain.Deployment, error) {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return domain.Deployment{}, repoerr.NewErrorResourceNotExists(err)
+	}
+
+	return impl.find(filter)
+}
+
+func (impl deploymentRepoImpl) find(filter bson.M) (d domain.Deployment, err error) {
+	var v dDeployment
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toDeployment(&d)
+
+	return
+}
+
+func (impl deploymentRepoImpl) FindAll(owner types.Account) ([]domain.Deployment, error) {
+	return impl.findAll(bson.M{fieldOwner: owner.Account()})
+}
+
+func (impl deploymentRepoImpl) FindAllDeployments() ([]domain.Deployment, error) {
+	return impl.findAll(bson.M{})
+}
+
+func (impl deploymentRepoImpl) findAll(filter bson.M) (r []domain.Deployment, err error) {
+	var v []dDeployment
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, filter,
+			options.Find().SetSort(bson.M{fieldCreatedAt: 1}), &v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.Deployment, len(v))
+	for i := range v {
+		if err = v[i].toDeployment(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl deploymentRepoImpl) Delete(owner types.Account, id string) error {
+	filter, err := impl.docFilter(owner, id)
+	if err != nil {
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().DeleteOne(ctx, filter)
+
+		return err
+	}
+
+	return withContext(f)
+}
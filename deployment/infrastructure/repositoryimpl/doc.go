@@ -0,0 +1,71 @@
+package repositoryimpl
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	fieldKey          = "key"
+	fieldPid          = "pid"
+	fieldDate         = "date"
+	fieldOwner        = "owner"
+	fieldLatency      = "latency"
+	fieldVersion      = "version"
+	fieldFailures     = "failures"
+	fieldRequests     = "requests"
+	fieldCreatedAt    = "created_at"
+	fieldDeploymentId = "deployment_id"
+)
+
+type dDeployment struct {
+	Id            primitive.ObjectID `bson:"_id"            json:"-"`
+	Owner         string             `bson:"owner"          json:"owner"`
+	ProjectId     string             `bson:"pid"            json:"pid"`
+	ProjectName   string             `bson:"name"           json:"name"`
+	ResourceLevel string             `bson:"level"          json:"level"`
+	LastCommit    string             `bson:"commit"         json:"commit"`
+	MinReplicas   int                `bson:"min_replicas"   json:"min_replicas"`
+	MaxReplicas   int                `bson:"max_replicas"   json:"max_replicas"`
+	TargetRPM     int                `bson:"target_rpm"     json:"target_rpm"`
+	Replicas      []dReplica         `bson:"replicas"       json:"replicas"`
+	Keys          []dAPIKey          `bson:"keys"           json:"keys"`
+	ReplicaSeq    int                `bson:"replica_seq"    json:"replica_seq"`
+	Failures      int                `bson:"failures"       json:"failures"`
+	CreatedAt     int64              `bson:"created_at"     json:"created_at"`
+	Version       int                `bson:"version"        json:"-"`
+}
+
+type dReplica struct {
+	Id            string           `bson:"id"             json:"id"`
+	LastCommit    string           `bson:"commit"         json:"commit"`
+	CreatedAt     int64            `bson:"created_at"     json:"created_at"`
+	Expiry        int64            `bson:"expiry"         json:"expiry"`
+	Error         string           `bson:"error"          json:"error"`
+	Failure       *dInferenceError `bson:"failure"        json:"failure,omitempty"`
+	AccessURL     string           `bson:"url"            json:"url"`
+	StartupLogURL string           `bson:"startup_log"    json:"startup_log"`
+	RuntimeLogURL string           `bson:"runtime_log"    json:"runtime_log"`
+	Stopped       bool             `bson:"stopped"        json:"stopped"`
+}
+
+type dInferenceError struct {
+	Stage   string `bson:"stage"          json:"stage"`
+	Code    string `bson:"code"           json:"code"`
+	Message string `bson:"message"        json:"message"`
+}
+
+type dAPIKey struct {
+	Id        string `bson:"id"             json:"id"`
+	Name      string `bson:"name"           json:"name"`
+	Hash      string `bson:"hash"           json:"hash"`
+	Mask      string `bson:"mask"           json:"mask"`
+	RateLimit int    `bson:"rate_limit"     json:"rate_limit"`
+	CreatedAt int64  `bson:"created_at"     json:"created_at"`
+}
+
+type dUsage struct {
+	DeploymentId string `bson:"deployment_id"  json:"deployment_id"`
+	Key          string `bson:"key"            json:"key"`
+	Date         string `bson:"date"           json:"date"`
+	Requests     int    `bson:"requests"       json:"requests"`
+	Failures     int    `bson:"failures"       json:"failures"`
+	Latency      int64  `bson:"latency"        json:"latency"`
+}
@@ -0,0 +1,83 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/deployment/domain"
+	"github.com/opensourceways/xihe-server/deployment/domain/repository"
+)
+
+func NewUsageRepo(m mongodbClient) repository.Usage {
+	return usageRepoImpl{m}
+}
+
+type usageRepoImpl struct {
+	usage mongodbClient
+}
+
+func (impl usageRepoImpl) Record(u *domain.Usage) error {
+	f := func(ctx context.Context) error {
+		_, err := impl.usage.Collection().UpdateOne(
+			ctx,
+			bson.M{
+				fieldDeploymentId: u.DeploymentId,
+				fieldKey:          u.KeyId,
+				fieldDate:         u.Date,
+			},
+			bson.M{mongoCmdInc: bson.M{
+				fieldRequests: u.Requests,
+				fieldFailures: u.Failures,
+				fieldLatency:  u.Latency,
+			}},
+			options.Update().SetUpsert(true),
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (impl usageRepoImpl) FindAll(deploymentId string, since string) (
+	r []domain.Usage, err error,
+) {
+	var v []dUsage
+
+	f := func(ctx context.Context) error {
+		return impl.usage.GetDocs(
+			ctx,
+			bson.M{
+				fieldDeploymentId: deploymentId,
+				fieldDate:         bson.M{mongoCmdGte: since},
+			},
+			options.Find().SetSort(bson.D{{Key: fieldDate, Value: 1}, {Key: fieldKey, Value: 1}}),
+			&v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.Usage, len(v))
+	for i := range v {
+		r[i] = v[i].toUsage()
+	}
+
+	return
+}
+
+func (impl usageRepoImpl) DeleteAll(deploymentId string) error {
+	filter := bson.M{fieldDeploymentId: deploymentId}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.usage.Collection().DeleteMany(ctx, filter)
+
+		return err
+	}
+
+	return withContext(f)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -38,6 +39,9 @@ type Config struct {
 	MinTrainingNameLength int `json:"min_training_name_length"`
 	MaxTrainingDescLength int `json:"max_training_desc_length"`
 
+	// MinTrainingScheduleInterval is the min seconds between two ticks of a training schedule
+	MinTrainingScheduleInterval int `json:"min_training_schedule_interval"`
+
 	MaxFinetuneNameLength int `json:"max_finetune_name_length"`
 	MinFinetuneNameLength int `json:"min_finetune_name_length"`
 
@@ -96,6 +100,10 @@ func (cfg *Config) SetDefault() {
 		cfg.MaxTrainingDescLength = 100
 	}
 
+	if cfg.MinTrainingScheduleInterval <= 0 {
+		cfg.MinTrainingScheduleInterval = 3600
+	}
+
 	if cfg.WuKongPictureMaxDescLength <= 0 {
 		cfg.WuKongPictureMaxDescLength = 75
 	}
@@ -116,6 +124,14 @@ func (r *Config) Validate() error {
 	r.trainingPlatform = sets.New[string](r.TrainingPlatform...)
 	r.avatarURL = sets.New[string](r.AvatarURL...)
 
+	for k, v := range r.Finetunes {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("invalid finetune model %s, %s", k, err.Error())
+		}
+
+		r.Finetunes[k] = v
+	}
+
 	return nil
 }
 
@@ -140,6 +156,73 @@ func (cfg *Config) HasAvatarURL(v string) bool {
 }
 
 type FinetuneParameterConfig struct {
-	Tasks           []string `json:"tasks"           required:"true"`
-	Hyperparameters []string `json:"hyperparameters" required:"true"`
+	Tasks []string `json:"tasks"           required:"true"`
+
+	// Hyperparameters are the names of hyperparameters whose value
+	// is a positive number. Declare the typed ones by HyperparameterSchemas.
+	Hyperparameters       []string               `json:"hyperparameters"`
+	HyperparameterSchemas []HyperparameterSchema `json:"hyperparameter_schemas"`
+
+	// EnableDataset means the model can be finetuned by the dataset of user.
+	// Enable it only when the finetune sdk can carry the dataset, or else
+	// the job will fail to be scheduled.
+	EnableDataset bool `json:"enable_dataset"`
+
+	schemas []HyperparameterSchema
+}
+
+func (cfg *FinetuneParameterConfig) validate() error {
+	if len(cfg.Tasks) == 0 {
+		return errors.New("missing tasks")
+	}
+
+	schemas := make(
+		[]HyperparameterSchema, 0,
+		len(cfg.Hyperparameters)+len(cfg.HyperparameterSchemas),
+	)
+
+	for _, name := range cfg.Hyperparameters {
+		min := float64(0)
+
+		schemas = append(schemas, HyperparameterSchema{
+			Name:     name,
+			Type:     HyperparameterTypeFloat,
+			Min:      &min,
+			positive: true,
+		})
+	}
+
+	schemas = append(schemas, cfg.HyperparameterSchemas...)
+
+	names := sets.New[string]()
+	for i := range schemas {
+		if err := schemas[i].validate(); err != nil {
+			return err
+		}
+
+		if names.Has(schemas[i].Name) {
+			return fmt.Errorf("duplicate hyperparameter %s", schemas[i].Name)
+		}
+		names.Insert(schemas[i].Name)
+	}
+
+	cfg.schemas = schemas
+
+	return nil
+}
+
+// Schemas returns all the hyperparameters of model including the ones
+// declared only by name.
+func (cfg FinetuneParameterConfig) Schemas() []HyperparameterSchema {
+	return cfg.schemas
+}
+
+func (cfg FinetuneParameterConfig) schema(name string) *HyperparameterSchema {
+	for i := range cfg.schemas {
+		if cfg.schemas[i].Name == name {
+			return &cfg.schemas[i]
+		}
+	}
+
+	return nil
 }
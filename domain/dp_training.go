@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"time"
+
+	"github.com/robfig/cron/v3"
 
 	"github.com/opensourceways/xihe-server/utils"
 )
@@ -54,6 +57,18 @@ func NewTrainingName(v string) (TrainingName, error) {
 
 type trainingName string
 
+// NewTrainingScheduleName checks the name of schedule which is used as the
+// prefix of the names of trainings it creates.
+func NewTrainingScheduleName(v string) (TrainingName, error) {
+	max := DomainConfig.MaxTrainingNameLength - trainingScheduleRunSuffixLen
+
+	if utils.StrLen(utils.XSSFilter(v)) > max {
+		return nil, fmt.Errorf("the length of schedule name should be less than %d", max)
+	}
+
+	return NewTrainingName(v)
+}
+
 func (r trainingName) TrainingName() string {
 	return string(r)
 }
@@ -312,3 +327,91 @@ type inputeFilePath string
 func (r inputeFilePath) InputeFilePath() string {
 	return string(r)
 }
+
+// CronSpec
+type CronSpec interface {
+	CronSpec() string
+	Next(int64) int64
+}
+
+func NewCronSpec(v string) (CronSpec, error) {
+	if v == "" {
+		return nil, errors.New("empty cron spec")
+	}
+
+	if max := 50; utils.StrLen(v) > max {
+		return nil, fmt.Errorf("the length of cron spec should be less than %d", max)
+	}
+
+	s, err := cron.ParseStandard(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron spec, %s", err.Error())
+	}
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, errors.New("the cron spec will never be triggered")
+	}
+
+	// the interval between two ticks must not be too short
+	// to avoid running trainings one after another.
+	if err := checkCronInterval(s); err != nil {
+		return nil, err
+	}
+
+	return cronSpec{spec: v, schedule: s}, nil
+}
+
+// cronCheckStart is the fixed time from which the intervals of cron spec
+// are checked, so that the result doesn't depend on when it is checked.
+var cronCheckStart = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local)
+
+const (
+	// cronCheckSpan covers the cycle of all the fields of cron spec.
+	cronCheckSpan = 366 * 24 * time.Hour
+
+	cronCheckMaxTicks = 100000
+)
+
+// checkCronInterval checks the consecutive intervals of the ticks in a year,
+// because the intervals may be different, such as "0 8,9 * * *".
+func checkCronInterval(s cron.Schedule) error {
+	min := time.Duration(DomainConfig.MinTrainingScheduleInterval) * time.Second
+	end := cronCheckStart.Add(cronCheckSpan)
+
+	prev := s.Next(cronCheckStart)
+	for i := 0; i < cronCheckMaxTicks && !prev.IsZero() && prev.Before(end); i++ {
+		next := s.Next(prev)
+		if next.IsZero() {
+			break
+		}
+
+		if next.Sub(prev) < min {
+			return fmt.Errorf(
+				"the interval of cron spec should be at least %d seconds",
+				DomainConfig.MinTrainingScheduleInterval,
+			)
+		}
+
+		prev = next
+	}
+
+	return nil
+}
+
+type cronSpec struct {
+	spec     string
+	schedule cron.Schedule
+}
+
+func (r cronSpec) CronSpec() string {
+	return r.spec
+}
+
+func (r cronSpec) Next(t int64) int64 {
+	v := r.schedule.Next(time.Unix(t, 0))
+	if v.IsZero() {
+		return 0
+	}
+
+	return v.Unix()
+}
@@ -2,8 +2,7 @@ package domain
 
 import (
 	"errors"
-
-	"github.com/opensourceways/xihe-server/utils"
+	"fmt"
 )
 
 type Finetune struct {
@@ -20,6 +19,17 @@ type Finetune struct {
 type FinetuneConfig struct {
 	Name  FinetuneName
 	Param FinetuneParameter
+
+	// Dataset is the training data supplied by user, it is optional.
+	Dataset *ResourceRef
+}
+
+// IsFinetuneDatasetEnabled checks whether the model can be finetuned
+// by the dataset of user.
+func IsFinetuneDatasetEnabled(model string) bool {
+	cfg, ok := DomainConfig.Finetunes[model]
+
+	return ok && cfg.EnableDataset
 }
 
 type FinetuneParameter interface {
@@ -49,37 +59,52 @@ func NewFinetuneParameter(model, task string, hyperparameters map[string]string)
 		return nil, errors.New("invalid task")
 	}
 
-	// check: if invalid key
-	f := func(key string) bool {
-		for i := range cfg.Hyperparameters {
-			if key == cfg.Hyperparameters[i] {
-				return true
-			}
+	v := make(map[string]string, len(cfg.schemas))
+
+	for k, item := range hyperparameters {
+		schema := cfg.schema(k)
+		if schema == nil {
+			return nil, fmt.Errorf("unknown hyperparameter %s", k)
+		}
+
+		if err := schema.check(item); err != nil {
+			return nil, fmt.Errorf("invalid hyperparameter %s, %s", k, err.Error())
 		}
 
-		return false
+		v[k] = item
 	}
 
-	for k, v := range hyperparameters {
-		if !f(k) {
-			return nil, errors.New("invalid hyperparameter")
+	for i := range cfg.schemas {
+		schema := &cfg.schemas[i]
+		if _, ok := v[schema.Name]; ok {
+			continue
 		}
 
-		// check: if invalid value
-		if !(utils.IsPositiveFloatPoint(v) ||
-			utils.IsPositiveScientificNotation(v) ||
-			utils.IsPositiveInteger(v)) {
-			return nil, errors.New("invalid hyperparameter")
+		if schema.Default != "" {
+			v[schema.Name] = schema.Default
+		} else if schema.Required {
+			return nil, fmt.Errorf("missing hyperparameter %s", schema.Name)
 		}
 	}
 
 	return finetuneParameter{
 		model:           model,
 		task:            task,
-		hyperparameters: hyperparameters,
+		hyperparameters: v,
 	}, nil
 }
 
+// RestoreFinetuneParameter restores the parameter which was validated when
+// the finetune was created. It is not validated again, because the config
+// of model may have been changed since then.
+func RestoreFinetuneParameter(model, task string, hyperparameters map[string]string) FinetuneParameter {
+	return finetuneParameter{
+		model:           model,
+		task:            task,
+		hyperparameters: hyperparameters,
+	}
+}
+
 type finetuneParameter struct {
 	model           string
 	task            string
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	HyperparameterTypeInt   = "int"
+	HyperparameterTypeFloat = "float"
+	HyperparameterTypeEnum  = "enum"
+	HyperparameterTypeBool  = "bool"
+)
+
+// HyperparameterSchema declares a hyperparameter of finetune model.
+// Min and Max are the inclusive range of int and float, and Options
+// are the values of enum. Default is applied when the value is missing.
+type HyperparameterSchema struct {
+	Name     string   `json:"name"     required:"true"`
+	Type     string   `json:"type"     required:"true"`
+	Desc     string   `json:"desc"`
+	Min      *float64 `json:"min"`
+	Max      *float64 `json:"max"`
+	Options  []string `json:"options"`
+	Default  string   `json:"default"`
+	Required bool     `json:"required"`
+
+	// positive is set for the hyperparameters which are only declared
+	// by name, whose value must be a positive number.
+	positive bool
+}
+
+func (s *HyperparameterSchema) validate() error {
+	if s.Name == "" {
+		return errors.New("missing name of hyperparameter")
+	}
+
+	switch s.Type {
+	case HyperparameterTypeInt, HyperparameterTypeFloat:
+		if s.Min != nil && s.Max != nil && *s.Min > *s.Max {
+			return fmt.Errorf("invalid range of hyperparameter %s", s.Name)
+		}
+
+	case HyperparameterTypeEnum:
+		if len(s.Options) == 0 {
+			return fmt.Errorf("missing options of hyperparameter %s", s.Name)
+		}
+
+	case HyperparameterTypeBool:
+
+	default:
+		return fmt.Errorf("unknown type of hyperparameter %s", s.Name)
+	}
+
+	if s.Default != "" {
+		if err := s.check(s.Default); err != nil {
+			return fmt.Errorf("invalid default of hyperparameter %s", s.Name)
+		}
+	}
+
+	return nil
+}
+
+func (s *HyperparameterSchema) check(v string) error {
+	switch s.Type {
+	case HyperparameterTypeInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+
+		return s.checkRange(float64(n))
+
+	case HyperparameterTypeFloat:
+		if s.positive {
+			if !(utils.IsPositiveFloatPoint(v) ||
+				utils.IsPositiveScientificNotation(v) ||
+				utils.IsPositiveInteger(v)) {
+				return errors.New("not a positive number")
+			}
+
+			return nil
+		}
+
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return errors.New("not a number")
+		}
+
+		return s.checkRange(f)
+
+	case HyperparameterTypeEnum:
+		for _, item := range s.Options {
+			if item == v {
+				return nil
+			}
+		}
+
+		return errors.New("not an option")
+
+	case HyperparameterTypeBool:
+		if v != "true" && v != "false" {
+			return errors.New("not a bool")
+		}
+	}
+
+	return nil
+}
+
+func (s *HyperparameterSchema) checkRange(f float64) error {
+	if s.Min != nil && f < *s.Min {
+		return fmt.Errorf("less than %v", *s.Min)
+	}
+
+	if s.Max != nil && f > *s.Max {
+		return fmt.Errorf("greater than %v", *s.Max)
+	}
+
+	return nil
+}
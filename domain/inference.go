@@ -1,5 +1,20 @@
 package domain
 
+const (
+	InferenceStatusCreating = "creating"
+	InferenceStatusRunning  = "running"
+	InferenceStatusFailed   = "failed"
+	InferenceStatusStopped  = "stopped"
+	InferenceStatusExpired  = "expired"
+
+	InferenceStageBuild   = "build"
+	InferenceStageStartup = "startup"
+	InferenceStageRuntime = "runtime"
+
+	InferenceLogStartup = "startup"
+	InferenceLogRuntime = "runtime"
+)
+
 type Inference struct {
 	InferenceInfo
 
@@ -22,8 +37,79 @@ type InferenceDetail struct {
 	// Error stores the message when the reference instance starts failed
 	Error string
 
+	// Failure stores the details of error reported by the manager.
+	// It may be nil even if Error is set, such as the old instances.
+	Failure *InferenceError
+
 	// AccessURL stores the url to access the inference service.
 	AccessURL string
+
+	// StartupLogURL and RuntimeLogURL store the links to read the log
+	// of starting up and running the inference service.
+	StartupLogURL string
+	RuntimeLogURL string
+
+	// Stopped is set when the instance is stopped or restarted by the owner.
+	// The stopped instance will not be reused.
+	Stopped bool
+}
+
+// InferenceError is the error of inference instance.
+// Stage is the stage at which the error happens, such as build.
+type InferenceError struct {
+	Stage   string
+	Code    string
+	Message string
+}
+
+// Status derives the state of the inference instance from the detail.
+func (d *InferenceDetail) Status() string {
+	if d.Error != "" {
+		return InferenceStatusFailed
+	}
+
+	if d.Stopped {
+		return InferenceStatusStopped
+	}
+
+	if d.AccessURL != "" {
+		return InferenceStatusRunning
+	}
+
+	return InferenceStatusCreating
+}
+
+// StatusAt is same as Status except that the instance which is
+// not stopped or failed but exits at the time of now is expired.
+func (d *InferenceDetail) StatusAt(now int64) string {
+	s := d.Status()
+
+	if (s == InferenceStatusCreating || s == InferenceStatusRunning) &&
+		d.Expiry > 0 && d.Expiry <= now {
+		return InferenceStatusExpired
+	}
+
+	return s
+}
+
+// IsActive checks whether the instance is starting up or running.
+func (d *InferenceDetail) IsActive(now int64) bool {
+	s := d.StatusAt(now)
+
+	return s == InferenceStatusCreating || s == InferenceStatusRunning
+}
+
+// LogURL returns the link of the log and whether the log is complete.
+func (d *InferenceDetail) LogURL(t string, now int64) (string, bool) {
+	if t == InferenceLogStartup {
+		return d.StartupLogURL, d.StatusAt(now) != InferenceStatusCreating
+	}
+
+	return d.RuntimeLogURL, !d.IsActive(now)
+}
+
+func IsValidInferenceLogType(t string) bool {
+	return t == InferenceLogStartup || t == InferenceLogRuntime
 }
 
 type InferenceIndex struct {
@@ -13,4 +13,5 @@ type Inference interface {
 	Create(*InferenceInfo) (int, error)
 	GetSurvivalTime(*domain.InferenceInfo) int
 	ExtendSurvivalTime(index *domain.InferenceIndex, timeToExtend int) error
+	Stop(*domain.InferenceIndex) error
 }
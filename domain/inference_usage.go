@@ -0,0 +1,26 @@
+package domain
+
+import common "github.com/opensourceways/xihe-server/common/domain"
+
+// InferenceUsage is the statistics of the requests to the inference
+// of a project by a user in a day. Latency is the total milliseconds
+// of the requests.
+type InferenceUsage struct {
+	Project  ResourceIndex
+	User     Account
+	Date     string
+	Requests int
+	Failures int
+	Latency  int64
+}
+
+// InferenceUsageLimits returns the limits of the requests of user to all
+// the inference instances through the gateway.
+func InferenceUsageLimits(user Account, now int64, rateLimit, dailyLimit int) []common.UsageLimit {
+	key := "inference/" + user.Account()
+
+	return []common.UsageLimit{
+		{Key: key, Window: common.DayWindow(now), Max: dailyLimit},
+		{Key: key, Window: common.MinuteWindow(now), Max: rateLimit},
+	}
+}
@@ -0,0 +1,165 @@
+package joblog
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"time"
+)
+
+// MaxDownloadSize is the max bytes of log which can be downloaded at once.
+const MaxDownloadSize = 64 << 20
+
+var ErrTooLarge = errors.New("the log is too large")
+
+// Downloader downloads the log of job by the link the backend provides.
+type Downloader interface {
+	// Download downloads the whole log. It returns ErrTooLarge if the log
+	// is larger than MaxDownloadSize.
+	Download(link string) ([]byte, error)
+
+	// DownloadRange downloads at most n bytes of log from the offset.
+	// It returns the size of the whole log as well.
+	DownloadRange(link string, offset, n int64) ([]byte, int64, error)
+}
+
+// Query selects the lines of log.
+// Offset is the byte offset in the log from which to read.
+// Limit is the max num of lines returned.
+// Grep filters the lines, and Since/Until filter the lines by the time
+// they were printed. Both of them are optional.
+type Query struct {
+	Offset int64
+	Limit  int
+	Grep   *regexp.Regexp
+	Since  int64
+	Until  int64
+}
+
+func (q *Query) filterByTime() bool {
+	return q.Since > 0 || q.Until > 0
+}
+
+func (q *Query) isInTimeRange(t int64) bool {
+	if t == 0 {
+		// can't decide the time of line, keep it.
+		return true
+	}
+
+	return (q.Since <= 0 || t >= q.Since) && (q.Until <= 0 || t <= q.Until)
+}
+
+// Chunk is the part of log read by a query.
+// NextOffset is the offset from which the next query should start.
+// Truncated means the log is shorter than the offset of query, because
+// it was truncated or rotated. No lines are read in that case, and the
+// log should be read again from NextOffset which is 0.
+type Chunk struct {
+	Lines      []string
+	Offset     int64
+	NextOffset int64
+	Size       int64
+	Truncated  bool
+}
+
+// Read reads the lines from the content which is the part of log starting
+// at the offset of query, and size is the size of the whole log.
+// The last line will not be read unless it is complete or the log is
+// complete, because the job may still be writing to it.
+func Read(content []byte, size int64, complete bool, q *Query) Chunk {
+	offset := q.Offset
+
+	chunk := Chunk{
+		Offset:     offset,
+		NextOffset: offset,
+		Size:       size,
+	}
+
+	if offset > size {
+		chunk.Truncated = true
+		chunk.NextOffset = 0
+
+		return chunk
+	}
+
+	n := int64(len(content))
+
+	// the content doesn't reach the end of log.
+	partial := offset+n < size
+
+	// the lines before the content are unknown, so the lines without
+	// time at the beginning of content are kept.
+	lastTime := int64(0)
+
+	for pos := int64(0); pos < n; {
+		if q.Limit > 0 && len(chunk.Lines) >= q.Limit {
+			break
+		}
+
+		line := content[pos:]
+		next := n
+
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+			next = pos + int64(i) + 1
+		} else if (partial && pos > 0) || (!partial && !complete) {
+			// the rest is an incomplete line which will be read next time.
+			// But the line longer than the whole content is split, otherwise
+			// it can never be read.
+			break
+		}
+
+		pos = next
+		chunk.NextOffset = offset + next
+
+		if q.filterByTime() {
+			if t := parseTime(line); t > 0 {
+				lastTime = t
+			}
+
+			if !q.isInTimeRange(lastTime) {
+				continue
+			}
+		}
+
+		if q.Grep != nil && !q.Grep.Match(line) {
+			continue
+		}
+
+		chunk.Lines = append(chunk.Lines, string(bytes.TrimSuffix(line, []byte{'\r'})))
+	}
+
+	return chunk
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+var reTimePrefix = regexp.MustCompile(
+	`^\[?(\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?)`,
+)
+
+// parseTime parses the time at the beginning of line and returns it in
+// unix seconds. It returns 0 if there is no time. The time without zone
+// is regarded as UTC.
+func parseTime(line []byte) int64 {
+	m := reTimePrefix.FindSubmatch(line)
+	if m == nil {
+		return 0
+	}
+
+	s := string(m[1])
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Unix()
+		}
+	}
+
+	return 0
+}
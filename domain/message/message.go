@@ -30,6 +30,7 @@ type Sender interface {
 
 	CreateInference(*domain.InferenceInfo) error
 	ExtendInferenceSurvivalTime(*InferenceExtendInfo) error
+	StopInference(*domain.InferenceInfo) error
 
 	CalcScore(*SubmissionInfo) error
 }
@@ -81,4 +82,5 @@ type FinetuneHandler interface {
 type InferenceHandler interface {
 	HandleEventCreateInference(*domain.InferenceInfo) error
 	HandleEventExtendInferenceSurvivalTime(*InferenceExtendInfo) error
+	HandleEventStopInference(*domain.InferenceInfo) error
 }
@@ -5,14 +5,22 @@ import (
 )
 
 type InferenceSummary struct {
-	Id string
+	Id        string
+	CreatedAt int64
 
 	domain.InferenceDetail
 }
 
+type InferenceInstance struct {
+	LastCommit string
+
+	InferenceSummary
+}
+
 type Inference interface {
 	Save(*domain.Inference, int) (string, error)
 	UpdateDetail(*domain.InferenceIndex, *domain.InferenceDetail) error
 	FindInstance(*domain.InferenceIndex) (InferenceSummary, error)
 	FindInstances(index *domain.ResourceIndex, lastCommit string) ([]InferenceSummary, int, error)
+	FindProjectInstances(*domain.ResourceIndex) ([]InferenceInstance, error)
 }
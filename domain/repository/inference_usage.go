@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/domain"
+)
+
+type InferenceUsage interface {
+	Record(*domain.InferenceUsage) error
+	FindAll(project *domain.ResourceIndex, since string) ([]domain.InferenceUsage, error)
+}
@@ -9,6 +9,7 @@ type Training interface {
 	Get(*domain.TrainingIndex) (domain.UserTraining, error)
 	Delete(*domain.TrainingIndex) error
 	List(user domain.Account, projectId string) ([]domain.TrainingSummary, int, error)
+	ListAll(user domain.Account) ([]domain.ProjectTrainingSummary, error)
 
 	GetTrainingConfig(*domain.TrainingIndex) (domain.TrainingConfig, error)
 	GetLastTrainingConfig(*domain.ResourceIndex) (domain.TrainingConfig, error)
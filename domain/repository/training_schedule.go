@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/opensourceways/xihe-server/domain"
+)
+
+type TrainingSchedule interface {
+	Save(*domain.TrainingSchedule) (string, error)
+	Get(*domain.TrainingScheduleIndex) (domain.TrainingSchedule, error)
+	Delete(*domain.TrainingScheduleIndex) error
+	List(user domain.Account, projectId string) ([]domain.TrainingSchedule, error)
+	FindDue(now int64) ([]domain.TrainingSchedule, error)
+
+	// UpdateState updates the pause flag and the next tick with the version.
+	UpdateState(*domain.TrainingSchedule) error
+	AddRun(*domain.TrainingScheduleIndex, *domain.TrainingScheduleRun) error
+}
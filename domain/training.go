@@ -84,6 +84,14 @@ type TrainingSummary struct {
 	CreatedAt int64
 }
 
+// ProjectTrainingSummary is the summary of training with the project
+// it belongs to.
+type ProjectTrainingSummary struct {
+	ProjectId string
+
+	TrainingSummary
+}
+
 type TrainingIndex struct {
 	Project    ResourceIndex
 	TrainingId string
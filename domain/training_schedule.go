@@ -0,0 +1,79 @@
+package domain
+
+import "strconv"
+
+// trainingScheduleRunSuffixLen is the length of "-" and the unix time
+// which are appended to the schedule name as the name of training.
+const trainingScheduleRunSuffixLen = 11
+
+const (
+	TrainingScheduleRunStatusTriggered = "triggered"
+	TrainingScheduleRunStatusSkipped   = "skipped"
+	TrainingScheduleRunStatusFailed    = "failed"
+)
+
+type TrainingSchedule struct {
+	Id        string
+	Owner     Account
+	ProjectId string
+
+	Name      TrainingName
+	Spec      CronSpec
+	Paused    bool
+	NextRunAt int64
+	CreatedAt int64
+	Version   int
+
+	TrainingConfig
+
+	// following fields is not under the controlling of version
+	Runs []TrainingScheduleRun
+}
+
+// IsDue returns true if the schedule should instantiate a training at the moment.
+func (s *TrainingSchedule) IsDue(now int64) bool {
+	return !s.Paused && s.NextRunAt > 0 && s.NextRunAt <= now
+}
+
+// LastTriggeredRun returns the latest run which created a training.
+func (s *TrainingSchedule) LastTriggeredRun() *TrainingScheduleRun {
+	for i := len(s.Runs) - 1; i >= 0; i-- {
+		if s.Runs[i].TrainingId != "" {
+			return &s.Runs[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *TrainingSchedule) Pause() {
+	s.Paused = true
+	s.NextRunAt = 0
+}
+
+func (s *TrainingSchedule) Resume(now int64) {
+	s.Paused = false
+	s.NextRunAt = s.Spec.Next(now)
+}
+
+// Advance moves the schedule to the next tick after now.
+func (s *TrainingSchedule) Advance(now int64) {
+	s.NextRunAt = s.Spec.Next(now)
+}
+
+// RunName returns the name of training created at the tick.
+func (s *TrainingSchedule) RunName(now int64) (TrainingName, error) {
+	return NewTrainingName(s.Name.TrainingName() + "-" + strconv.FormatInt(now, 10))
+}
+
+type TrainingScheduleRun struct {
+	TrainingId  string
+	Status      string
+	Error       string
+	TriggeredAt int64
+}
+
+type TrainingScheduleIndex struct {
+	Project    ResourceIndex
+	ScheduleId string
+}
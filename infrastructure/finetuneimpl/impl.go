@@ -1,6 +1,8 @@
 package finetuneimpl
 
 import (
+	"errors"
+
 	"github.com/opensourceways/xihe-finetune/sdk"
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -8,6 +10,10 @@ import (
 	"github.com/opensourceways/xihe-server/domain/finetune"
 )
 
+// errDatasetUnsupported is returned when the job is created with the dataset
+// of user, because the option of sdk has no field to carry it.
+var errDatasetUnsupported = errors.New("the finetune sdk can't create the job with dataset")
+
 func NewFinetune(cfg *Config) finetune.Finetune {
 	return &finetuneImpl{
 		cli:                sdk.New(cfg.Endpoint),
@@ -33,7 +39,14 @@ func (impl *finetuneImpl) CanTerminate(status string) bool {
 func (impl *finetuneImpl) CreateJob(info *domain.FinetuneIndex, cfg *domain.FinetuneConfig) (
 	job domain.FinetuneJobInfo, err error,
 ) {
+	if cfg.Dataset != nil {
+		err = errDatasetUnsupported
+
+		return
+	}
+
 	p := cfg.Param
+
 	opt := sdk.FinetuneCreateOption{
 		User:            info.Owner.Account(),
 		Id:              info.Id,
@@ -68,3 +68,12 @@ func (impl *inferenceImpl) ExtendSurvivalTime(index *domain.InferenceIndex, time
 
 	return impl.cli.ExtendExpiryOfInference(&opt)
 }
+
+// Stop can't make the instance exit at once, because the sdk has no api for it.
+// The stopped instance has been marked as expired, so the gateway refuses the
+// calls to it, and the container manager releases it when it expires.
+func (impl *inferenceImpl) Stop(index *domain.InferenceIndex) error {
+	logrus.Debugf("inference instance(%s) will be released when it expires", index.Id)
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package joblogimpl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/xihe-server/domain/joblog"
+)
+
+func NewDownloader() joblog.Downloader {
+	return downloader{
+		cli: http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+type downloader struct {
+	cli http.Client
+}
+
+func (impl downloader) Download(link string) ([]byte, error) {
+	if link == "" {
+		return nil, nil
+	}
+
+	resp, err := impl.cli.Get(link)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > joblog.MaxDownloadSize {
+		return nil, joblog.ErrTooLarge
+	}
+
+	v, err := io.ReadAll(io.LimitReader(resp.Body, joblog.MaxDownloadSize+1))
+	if err == nil && len(v) > joblog.MaxDownloadSize {
+		err = joblog.ErrTooLarge
+	}
+
+	return v, err
+}
+
+func (impl downloader) DownloadRange(link string, offset, n int64) ([]byte, int64, error) {
+	if link == "" {
+		return nil, 0, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+n-1))
+
+	resp, err := impl.cli.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		v, err := io.ReadAll(io.LimitReader(resp.Body, n))
+
+		return v, size, err
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the offset is not less than the size of log.
+		size, err := parseContentRange(resp.Header.Get("Content-Range"))
+
+		return nil, size, err
+
+	case http.StatusOK:
+		// the server doesn't support range, skip to the offset.
+		if m, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			if err == io.EOF {
+				// the log is shorter than the offset.
+				return nil, m, nil
+			}
+
+			return nil, 0, err
+		}
+
+		v, err := io.ReadAll(io.LimitReader(resp.Body, n))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		size := resp.ContentLength
+		if size < 0 {
+			size = offset + int64(len(v))
+		}
+
+		return v, size, nil
+
+	default:
+		return nil, 0, fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+}
+
+// parseContentRange parses the size of whole content from the header
+// of Content-Range, such as "bytes 0-99/1000" and "bytes */1000".
+func parseContentRange(v string) (int64, error) {
+	i := strings.LastIndexByte(v, '/')
+	if i < 0 || v[i+1:] == "*" {
+		return 0, fmt.Errorf("unknown content range: %s", v)
+	}
+
+	return strconv.ParseInt(v[i+1:], 10, 64)
+}
@@ -0,0 +1,160 @@
+package joblogimpl
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/opensourceways/xihe-server/domain/joblog"
+)
+
+// Opener opens the file which is the relative path of link to the file server.
+type Opener func(file string) (*os.File, error)
+
+// NewLocalDownloader reads the files served at fileServerURL from the disk
+// directly by open, because they can't be downloaded by the server without
+// the token of their owners. The other links are downloaded by d.
+func NewLocalDownloader(d joblog.Downloader, fileServerURL string, open Opener) joblog.Downloader {
+	return localDownloader{
+		Downloader: d,
+		prefix:     strings.TrimSuffix(fileServerURL, "/") + "/",
+		open:       open,
+	}
+}
+
+// DirOpener opens the files in dir by OpenInDir.
+func DirOpener(dir string) Opener {
+	return func(file string) (*os.File, error) {
+		return OpenInDir(dir, filepath.Join(dir, filepath.FromSlash(file)))
+	}
+}
+
+// OpenInDir opens the regular file p for reading only if it is still in dir
+// after the symbolic links are resolved, so that the file written by users
+// can't point to the other files of server. The file is opened without
+// following the symbolic link in case it is replaced after resolving, and
+// without blocking in case it is a pipe.
+func OpenInDir(dir, p string) (*os.File, error) {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	realPath, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isInDir(realDir, realPath) {
+		return nil, errors.New("file is out of the directory")
+	}
+
+	f, err := os.OpenFile(realPath, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+		f.Close()
+
+		return nil, errors.New("not a regular file")
+	}
+
+	return f, nil
+}
+
+type localDownloader struct {
+	joblog.Downloader
+
+	prefix string
+	open   Opener
+}
+
+// localFile returns the relative path of file the link points to.
+func (d localDownloader) localFile(link string) (string, bool) {
+	if !strings.HasPrefix(link, d.prefix) {
+		return "", false
+	}
+
+	file, err := url.PathUnescape(strings.TrimPrefix(link, d.prefix))
+	if err != nil {
+		return "", false
+	}
+
+	return path.Clean("/" + file)[1:], true
+}
+
+func (d localDownloader) Download(link string) ([]byte, error) {
+	file, ok := d.localFile(link)
+	if !ok {
+		return d.Downloader.Download(link)
+	}
+
+	f, err := d.open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	if info, err := f.Stat(); err != nil {
+		return nil, err
+	} else if info.Size() > joblog.MaxDownloadSize {
+		return nil, joblog.ErrTooLarge
+	}
+
+	// the file may grow after Stat.
+	v, err := io.ReadAll(io.LimitReader(f, joblog.MaxDownloadSize+1))
+	if err == nil && len(v) > joblog.MaxDownloadSize {
+		err = joblog.ErrTooLarge
+	}
+
+	return v, err
+}
+
+func (d localDownloader) DownloadRange(link string, offset, n int64) ([]byte, int64, error) {
+	file, ok := d.localFile(link)
+	if !ok {
+		return d.Downloader.DownloadRange(link, offset, n)
+	}
+
+	f, err := d.open(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size := info.Size()
+	if offset >= size {
+		return nil, size, nil
+	}
+
+	if size-offset < n {
+		n = size - offset
+	}
+
+	v := make([]byte, n)
+	m, err := f.ReadAt(v, offset)
+	if err == io.EOF {
+		err = nil
+	}
+
+	return v[:m], size, err
+}
+
+func isInDir(dir, p string) bool {
+	v, err := filepath.Rel(dir, p)
+
+	return err == nil && v != ".." && !strings.HasPrefix(v, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,205 @@
+package localinferenceimpl
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+const (
+	RuntimePodman = "podman"
+	RuntimeDocker = "docker"
+
+	// FileRoutePath is the route by which the server serves the logs
+	// of instances to the owners of projects.
+	FileRoutePath = "/v1/inference/local/file"
+
+	dirCode     = "code"
+	dirLog      = "log"
+	dirInstance = "instance"
+)
+
+type Config struct {
+	// Enable replaces the remote inference cluster with the local one.
+	// It is designed for self-hosted and testing environments.
+	Enable bool `json:"enable"`
+
+	// WorkDir is the directory where the codes and logs of instances are saved.
+	WorkDir string `json:"work_dir"`
+
+	// RepoDir is the directory where the git repos of projects are.
+	// A repo is located at RepoDir/owner/project_name.
+	RepoDir string `json:"repo_dir"`
+
+	// FileServerURL is the url by which the logs of instances can be
+	// visited. It must end with FileRoutePath, such as
+	// http://127.0.0.1:8000/api/v1/inference/local/file.
+	FileServerURL string `json:"file_server_url"`
+
+	// BootFile is the path of the boot file in the repo.
+	BootFile string `json:"boot_file"`
+
+	// Runtime is podman or docker which must run in the rootless mode.
+	// The app runs in a container of Image, in which the code is mounted
+	// at /app as read only.
+	Runtime string `json:"runtime"`
+	Image   string `json:"image"`
+
+	Interpreter string `json:"interpreter"`
+
+	// Path is the value of PATH passed to the runtime.
+	Path string `json:"path"`
+
+	// Uid and Gid are the user in the container which runs the app.
+	// Neither of them can be 0.
+	Uid uint32 `json:"uid"`
+	Gid uint32 `json:"gid"`
+
+	// CPUs, Memory and PidsLimit limit the resources of each container,
+	// such as 1.5 cpus and 2g memory.
+	CPUs      string `json:"cpus"`
+	Memory    string `json:"memory"`
+	PidsLimit int    `json:"pids_limit"`
+
+	// Network is the network of containers. The default one of podman
+	// doesn't allow the app to visit the services on the host, and so
+	// does the one of docker in the rootless mode.
+	Network string `json:"network"`
+
+	// Host is the address on which the app listens and is accessed.
+	Host string `json:"host"`
+
+	// PortMin and PortMax are the range of ports assigned to the apps.
+	PortMin int `json:"port_min"`
+	PortMax int `json:"port_max"`
+
+	MaxInstances int `json:"max_instances"`
+
+	// SurvivalTime is the seconds an instance lives before being extended.
+	SurvivalTime int `json:"survival_time"`
+
+	// StartupTimeout is the max seconds to wait for the app to be healthy.
+	StartupTimeout int `json:"startup_timeout"`
+
+	// HealthCheckInterval is the seconds between two health checks.
+	HealthCheckInterval int `json:"health_check_interval"`
+
+	// MaxLogSize is the max bytes of each log of an instance. The log
+	// is truncated to be empty when it reaches the size.
+	MaxLogSize int64 `json:"max_log_size"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = "/tmp/xihe-inference"
+	}
+
+	if cfg.BootFile == "" {
+		cfg.BootFile = "inference/app.py"
+	}
+
+	if cfg.Runtime == "" {
+		cfg.Runtime = RuntimePodman
+	}
+
+	if cfg.Uid == 0 {
+		cfg.Uid = 1000
+	}
+
+	if cfg.Gid == 0 {
+		cfg.Gid = 1000
+	}
+
+	if cfg.CPUs == "" {
+		cfg.CPUs = "1"
+	}
+
+	if cfg.Memory == "" {
+		cfg.Memory = "2g"
+	}
+
+	if cfg.PidsLimit <= 0 {
+		cfg.PidsLimit = 256
+	}
+
+	if cfg.Network == "" {
+		if cfg.Runtime == RuntimePodman {
+			cfg.Network = "slirp4netns:allow_host_loopback=false"
+		} else {
+			cfg.Network = "bridge"
+		}
+	}
+
+	if cfg.Interpreter == "" {
+		cfg.Interpreter = "python3"
+	}
+
+	if cfg.Path == "" {
+		cfg.Path = "/usr/local/bin:/usr/bin:/bin"
+	}
+
+	if cfg.Host == "" {
+		cfg.Host = "127.0.0.1"
+	}
+
+	if cfg.PortMin <= 0 {
+		cfg.PortMin = 17860
+	}
+
+	if cfg.PortMax <= 0 {
+		cfg.PortMax = cfg.PortMin + 99
+	}
+
+	if cfg.MaxInstances <= 0 {
+		cfg.MaxInstances = 5
+	}
+
+	if cfg.SurvivalTime <= 0 {
+		cfg.SurvivalTime = 3600
+	}
+
+	if cfg.StartupTimeout <= 0 {
+		cfg.StartupTimeout = 300
+	}
+
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = 3
+	}
+
+	if cfg.MaxLogSize <= 0 {
+		cfg.MaxLogSize = 10 << 20
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enable {
+		return nil
+	}
+
+	if cfg.RepoDir == "" {
+		return errors.New("missing repo_dir of local inference")
+	}
+
+	if cfg.FileServerURL == "" {
+		return errors.New("missing file_server_url of local inference")
+	}
+
+	if cfg.Runtime != RuntimePodman && cfg.Runtime != RuntimeDocker {
+		return errors.New("unknown runtime of local inference")
+	}
+
+	if cfg.Image == "" {
+		return errors.New("missing image of local inference")
+	}
+
+	if cfg.PortMin > cfg.PortMax || cfg.PortMax > 65535 {
+		return errors.New("invalid port range of local inference")
+	}
+
+	return nil
+}
+
+// LogDir returns the directory of logs which is served at FileRoutePath.
+// The logs of an instance are under owner/instance_id in it.
+func (cfg *Config) LogDir() string {
+	return filepath.Join(cfg.WorkDir, dirLog)
+}
@@ -0,0 +1,398 @@
+package localinferenceimpl
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/inference"
+	"github.com/opensourceways/xihe-server/domain/joblog"
+	"github.com/opensourceways/xihe-server/domain/message"
+	"github.com/opensourceways/xihe-server/infrastructure/joblogimpl"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+// MessageHandler handles the messages of inference as the consumer does.
+type MessageHandler interface {
+	CreateInferenceInstance(*domain.InferenceInfo) error
+	ExtendSurvivalTime(*message.InferenceExtendInfo) error
+	StopInstance(*domain.InferenceInfo) error
+}
+
+// DetailHandler saves the detail of instance when it changes.
+type DetailHandler interface {
+	UpdateDetail(*domain.InferenceIndex, *domain.InferenceDetail) error
+}
+
+// Inference runs the inference app in a rootless container on the server
+// host. It also acts as the sender of inference messages, because there is
+// no consumer of the messages in the local environment. The instances are
+// saved on the disk and started again after the server restarts.
+type Inference interface {
+	inference.Inference
+
+	// Sender returns the sender which handles the inference messages
+	// locally and sends the other messages by s.
+	Sender(s message.Sender) message.Sender
+
+	// SetHandlers must be called before any inference is created.
+	SetHandlers(MessageHandler, DetailHandler)
+
+	// RecoverInstances starts the instances which were running before the
+	// server restarted. It must be called after SetHandlers.
+	RecoverInstances() error
+
+	// LocalFile returns the local path of log if it belongs to the owner.
+	LocalFile(owner domain.Account, file string) (string, error)
+
+	// Downloader returns the downloader which reads the logs of instances
+	// from the disk directly and the other links by d.
+	Downloader(d joblog.Downloader) joblog.Downloader
+}
+
+func NewInference(cfg *Config) (Inference, error) {
+	// refuse to run the code of users with the privileges of server.
+	if err := checkRootless(cfg); err != nil {
+		return nil, err
+	}
+
+	// the code is read by the user of container which can only traverse
+	// the parents, but can't list them.
+	dirs := map[string]os.FileMode{
+		"":          0o711,
+		dirCode:     0o711,
+		dirLog:      0o750,
+		dirInstance: 0o750,
+	}
+
+	for dir, mode := range dirs {
+		if err := os.MkdirAll(filepath.Join(cfg.WorkDir, dir), mode); err != nil {
+			return nil, err
+		}
+	}
+
+	return &inferenceImpl{
+		cfg:       *cfg,
+		instances: map[string]*instance{},
+	}, nil
+}
+
+type inferenceImpl struct {
+	cfg Config
+
+	messageHandler MessageHandler
+	detailHandler  DetailHandler
+
+	lock      sync.Mutex
+	instances map[string]*instance
+}
+
+func (impl *inferenceImpl) SetHandlers(m MessageHandler, d DetailHandler) {
+	impl.messageHandler = m
+	impl.detailHandler = d
+}
+
+func (impl *inferenceImpl) Sender(s message.Sender) message.Sender {
+	return sender{
+		Sender: s,
+		impl:   impl,
+	}
+}
+
+func (impl *inferenceImpl) GetSurvivalTime(*domain.InferenceInfo) int {
+	return impl.cfg.SurvivalTime
+}
+
+func (impl *inferenceImpl) Create(info *inference.InferenceInfo) (int, error) {
+	err := impl.start(info.InferenceInfo, utils.Now()+int64(impl.cfg.SurvivalTime))
+	if err != nil {
+		return 0, err
+	}
+
+	return impl.cfg.SurvivalTime, nil
+}
+
+func (impl *inferenceImpl) start(info *domain.InferenceInfo, expiry int64) error {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+
+	if _, ok := impl.instances[info.Id]; ok {
+		return nil
+	}
+
+	if len(impl.instances) >= impl.cfg.MaxInstances {
+		return errors.New("too many local inference instances")
+	}
+
+	port, err := impl.allocPort()
+	if err != nil {
+		return err
+	}
+
+	inst := &instance{
+		info:    *info,
+		port:    port,
+		codeDir: filepath.Join(impl.cfg.WorkDir, dirCode, info.Id),
+		logDir:  filepath.Join(impl.cfg.LogDir(), info.Project.Owner.Account(), info.Id),
+		cfg:     &impl.cfg,
+		expiry:  expiry,
+		stopCh:  make(chan struct{}),
+	}
+
+	if err := impl.save(inst); err != nil {
+		return err
+	}
+
+	impl.instances[info.Id] = inst
+
+	go impl.run(inst)
+
+	return nil
+}
+
+func (impl *inferenceImpl) ExtendSurvivalTime(index *domain.InferenceIndex, timeToExtend int) error {
+	inst := impl.getInstance(index.Id)
+	if inst == nil {
+		return errors.New("the local inference instance is not running")
+	}
+
+	inst.extend(timeToExtend)
+
+	return impl.save(inst)
+}
+
+func (impl *inferenceImpl) Stop(index *domain.InferenceIndex) error {
+	if inst := impl.getInstance(index.Id); inst != nil {
+		inst.stop()
+	}
+
+	return nil
+}
+
+func (impl *inferenceImpl) getInstance(id string) *instance {
+	impl.lock.Lock()
+	defer impl.lock.Unlock()
+
+	return impl.instances[id]
+}
+
+// allocPort must be called with the lock held.
+func (impl *inferenceImpl) allocPort() (int, error) {
+	used := map[int]bool{}
+	for _, inst := range impl.instances {
+		used[inst.port] = true
+	}
+
+	for p := impl.cfg.PortMin; p <= impl.cfg.PortMax; p++ {
+		if used[p] {
+			continue
+		}
+
+		// the port may be occupied by the other programs.
+		l, err := net.Listen("tcp", net.JoinHostPort(impl.cfg.Host, strconv.Itoa(p)))
+		if err != nil {
+			continue
+		}
+
+		if err := l.Close(); err == nil {
+			return p, nil
+		}
+	}
+
+	return 0, errors.New("no available port for local inference")
+}
+
+func (impl *inferenceImpl) release(inst *instance) {
+	impl.lock.Lock()
+	delete(impl.instances, inst.info.Id)
+	impl.lock.Unlock()
+
+	if err := os.Remove(impl.instanceFile(inst.info.Id)); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf(
+			"remove local inference(%s) failed, err:%s",
+			inst.info.Id, err.Error(),
+		)
+	}
+
+	if err := os.RemoveAll(inst.codeDir); err != nil {
+		logrus.Errorf(
+			"remove code of local inference(%s) failed, err:%s",
+			inst.info.Id, err.Error(),
+		)
+	}
+}
+
+func (impl *inferenceImpl) report(inst *instance, detail *domain.InferenceDetail) {
+	if err := impl.detailHandler.UpdateDetail(&inst.info.InferenceIndex, detail); err != nil {
+		logrus.Errorf(
+			"update detail of local inference(%s) failed, err:%s",
+			inst.info.Id, err.Error(),
+		)
+	}
+}
+
+func (impl *inferenceImpl) reportFailure(inst *instance, stage, code, msg string) {
+	impl.report(inst, &domain.InferenceDetail{
+		Failure: &domain.InferenceError{
+			Stage:   stage,
+			Code:    code,
+			Message: msg,
+		},
+	})
+}
+
+func (impl *inferenceImpl) logURL(inst *instance, file string) string {
+	v, err := url.JoinPath(
+		impl.cfg.FileServerURL, inst.info.Project.Owner.Account(), inst.info.Id, file,
+	)
+	if err != nil {
+		logrus.Errorf("generate log url failed, err:%s", err.Error())
+	}
+
+	return v
+}
+
+func (impl *inferenceImpl) LocalFile(owner domain.Account, file string) (string, error) {
+	file = path.Clean("/" + file)[1:]
+
+	if v := strings.SplitN(file, "/", 2); len(v) != 2 || v[0] != owner.Account() {
+		return "", errors.New("no such file")
+	}
+
+	p := filepath.Join(impl.cfg.LogDir(), filepath.FromSlash(file))
+	if info, err := os.Stat(p); err != nil || !info.Mode().IsRegular() {
+		return "", errors.New("no such file")
+	}
+
+	return p, nil
+}
+
+func (impl *inferenceImpl) Downloader(d joblog.Downloader) joblog.Downloader {
+	return joblogimpl.NewLocalDownloader(
+		d, impl.cfg.FileServerURL, joblogimpl.DirOpener(impl.cfg.LogDir()),
+	)
+}
+
+func (impl *inferenceImpl) RecoverInstances() error {
+	files, err := filepath.Glob(filepath.Join(impl.cfg.WorkDir, dirInstance, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := impl.recover(f); err != nil {
+			logrus.Errorf("recover local inference(%s) failed, err:%s", f, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// recover removes the container left by the server before restarting,
+// and starts the instance again if it has not expired.
+func (impl *inferenceImpl) recover(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var do instanceDO
+	if err := json.Unmarshal(b, &do); err != nil {
+		return err
+	}
+
+	info, err := do.toInferenceInfo()
+	if err != nil {
+		return err
+	}
+
+	inst := instance{info: info, cfg: &impl.cfg}
+	inst.removeContainer()
+
+	if do.Expiry <= utils.Now() {
+		if err := os.RemoveAll(filepath.Join(impl.cfg.WorkDir, dirCode, info.Id)); err != nil {
+			return err
+		}
+
+		return os.Remove(file)
+	}
+
+	return impl.start(&info, do.Expiry)
+}
+
+func (impl *inferenceImpl) instanceFile(id string) string {
+	return filepath.Join(impl.cfg.WorkDir, dirInstance, id+".json")
+}
+
+// save writes the instance to the disk, so that it can be recovered after
+// the server restarts.
+func (impl *inferenceImpl) save(inst *instance) error {
+	inst.lock.Lock()
+	do := toInstanceDO(&inst.info, inst.expiry)
+	inst.lock.Unlock()
+
+	b, err := json.Marshal(do)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(impl.instanceFile(inst.info.Id), b, 0o640)
+}
+
+// sender handles the inference messages asynchronously like the consumer.
+type sender struct {
+	message.Sender
+
+	impl *inferenceImpl
+}
+
+func (s sender) CreateInference(info *domain.InferenceInfo) error {
+	v := *info
+
+	return s.handle("create", v.Id, func(h MessageHandler) error {
+		return h.CreateInferenceInstance(&v)
+	})
+}
+
+func (s sender) ExtendInferenceSurvivalTime(info *message.InferenceExtendInfo) error {
+	v := *info
+
+	return s.handle("extend", v.Id, func(h MessageHandler) error {
+		return h.ExtendSurvivalTime(&v)
+	})
+}
+
+func (s sender) StopInference(info *domain.InferenceInfo) error {
+	v := *info
+
+	return s.handle("stop", v.Id, func(h MessageHandler) error {
+		return h.StopInstance(&v)
+	})
+}
+
+func (s sender) handle(action, id string, f func(MessageHandler) error) error {
+	h := s.impl.messageHandler
+	if h == nil {
+		return errors.New("no message handler of local inference")
+	}
+
+	go func() {
+		if err := f(h); err != nil {
+			logrus.Errorf(
+				"%s local inference(%s) failed, err:%s", action, id, err.Error(),
+			)
+		}
+	}()
+
+	return nil
+}
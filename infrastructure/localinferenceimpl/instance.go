@@ -0,0 +1,564 @@
+package localinferenceimpl
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	fileStartupLog = "startup.log"
+	fileRuntimeLog = "runtime.log"
+
+	containerCodeDir = "/app"
+
+	failureCheckout = "checkout_failed"
+	failureStart    = "start_failed"
+	failureTimeout  = "startup_timeout"
+	failureExit     = "exited"
+)
+
+var reCommit = regexp.MustCompile("^[0-9a-f]{7,64}$")
+
+type instance struct {
+	info    domain.InferenceInfo
+	port    int
+	codeDir string
+	logDir  string
+	cfg     *Config
+
+	lock    sync.Mutex
+	expiry  int64
+	stopped bool
+	stopCh  chan struct{}
+}
+
+func (inst *instance) extend(n int) {
+	inst.lock.Lock()
+	inst.expiry += int64(n)
+	inst.lock.Unlock()
+}
+
+func (inst *instance) stop() {
+	inst.lock.Lock()
+	defer inst.lock.Unlock()
+
+	if !inst.stopped {
+		inst.stopped = true
+		close(inst.stopCh)
+	}
+}
+
+func (inst *instance) isStopped() bool {
+	inst.lock.Lock()
+	defer inst.lock.Unlock()
+
+	return inst.stopped
+}
+
+func (inst *instance) isExpired() bool {
+	inst.lock.Lock()
+	defer inst.lock.Unlock()
+
+	return inst.expiry <= utils.Now()
+}
+
+func (inst *instance) address() string {
+	return net.JoinHostPort(inst.cfg.Host, strconv.Itoa(inst.port))
+}
+
+func (inst *instance) containerName() string {
+	return "xihe-inference-" + inst.info.Id
+}
+
+func (inst *instance) removeContainer() {
+	cmd := exec.Command(inst.cfg.Runtime, "rm", "-f", inst.containerName())
+	cmd.Env = runtimeEnv(inst.cfg)
+
+	_ = cmd.Run()
+}
+
+func (impl *inferenceImpl) run(inst *instance) {
+	defer impl.release(inst)
+
+	if err := inst.checkout(); err != nil {
+		impl.reportFailure(inst, domain.InferenceStageBuild, failureCheckout, err.Error())
+
+		return
+	}
+
+	if err := os.MkdirAll(inst.logDir, 0o750); err != nil {
+		impl.reportFailure(inst, domain.InferenceStageStartup, failureStart, err.Error())
+
+		return
+	}
+
+	f, err := os.Create(filepath.Join(inst.logDir, fileStartupLog))
+	if err != nil {
+		impl.reportFailure(inst, domain.InferenceStageStartup, failureStart, err.Error())
+
+		return
+	}
+
+	w := &logWriter{f: f, max: impl.cfg.MaxLogSize}
+	defer w.close()
+
+	impl.report(inst, &domain.InferenceDetail{
+		StartupLogURL: impl.logURL(inst, fileStartupLog),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd, err := inst.command(ctx, w)
+	if err == nil {
+		err = cmd.Start()
+	}
+
+	if err != nil {
+		impl.reportFailure(inst, domain.InferenceStageStartup, failureStart, err.Error())
+
+		return
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	if !impl.waitHealthy(inst, exited, cancel) {
+		return
+	}
+
+	if f, err = os.Create(filepath.Join(inst.logDir, fileRuntimeLog)); err == nil {
+		w.switchTo(f)
+	}
+
+	impl.report(inst, &domain.InferenceDetail{
+		AccessURL:     "http://" + inst.address(),
+		RuntimeLogURL: impl.logURL(inst, fileRuntimeLog),
+	})
+
+	impl.waitExit(inst, exited, cancel)
+}
+
+// waitHealthy waits until the app responds. It returns false if the app
+// fails to start up or the instance is stopped before that.
+func (impl *inferenceImpl) waitHealthy(inst *instance, exited chan error, kill func()) bool {
+	ticker := time.NewTicker(time.Duration(inst.cfg.HealthCheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(time.Duration(inst.cfg.StartupTimeout) * time.Second)
+
+	for {
+		select {
+		case err := <-exited:
+			if !inst.isStopped() {
+				impl.reportFailure(
+					inst, domain.InferenceStageStartup, failureExit, exitMessage(err),
+				)
+			}
+
+			return false
+
+		case <-inst.stopCh:
+			kill()
+			<-exited
+
+			return false
+
+		case <-ticker.C:
+			if inst.isHealthy() {
+				return true
+			}
+
+			if inst.isExpired() {
+				kill()
+				<-exited
+
+				return false
+			}
+
+			if time.Now().After(deadline) {
+				kill()
+				<-exited
+
+				impl.reportFailure(
+					inst, domain.InferenceStageStartup, failureTimeout,
+					"the app is not ready in time",
+				)
+
+				return false
+			}
+		}
+	}
+}
+
+// waitExit waits until the app exits, or kills it when the instance
+// is stopped or expired.
+func (impl *inferenceImpl) waitExit(inst *instance, exited chan error, kill func()) {
+	ticker := time.NewTicker(time.Duration(inst.cfg.HealthCheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			if !inst.isStopped() && !inst.isExpired() {
+				impl.reportFailure(
+					inst, domain.InferenceStageRuntime, failureExit, exitMessage(err),
+				)
+			}
+
+			return
+
+		case <-inst.stopCh:
+			kill()
+			<-exited
+
+			return
+
+		case <-ticker.C:
+			if inst.isExpired() {
+				kill()
+				<-exited
+
+				return
+			}
+		}
+	}
+}
+
+func (inst *instance) isHealthy() bool {
+	cli := http.Client{Timeout: 2 * time.Second}
+
+	resp, err := cli.Get("http://" + inst.address() + "/")
+	if err != nil {
+		return false
+	}
+
+	resp.Body.Close()
+
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// checkout exports the files of the commit from the git repo of project.
+func (inst *instance) checkout() error {
+	commit := inst.info.LastCommit
+	if !reCommit.MatchString(commit) {
+		return errors.New("invalid commit")
+	}
+
+	repoDir := filepath.Join(
+		inst.cfg.RepoDir, inst.info.Project.Owner.Account(),
+		inst.info.ProjectName.ResourceName(),
+	)
+
+	if err := os.MkdirAll(inst.codeDir, 0o755); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", "-C", repoDir, "archive", "--format=tar", commit)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	err = extract(out, inst.codeDir)
+
+	// drain the output, so that git will not be blocked if extract fails.
+	_, _ = io.Copy(io.Discard, out)
+
+	if err1 := cmd.Wait(); err1 != nil {
+		return fmt.Errorf("%s %s", err1.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(inst.codeDir, inst.cfg.BootFile)); err != nil {
+		return errors.New("no boot file")
+	}
+
+	return nil
+}
+
+func extract(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+
+	for {
+		h, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if !isInDir(dir, p) {
+			return errors.New("file is out of the repo")
+		}
+
+		// the symbolic links are skipped, because they may point to
+		// the files out of the repo. The files must be readable by the
+		// user of container which is not the one of server.
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, 0o755); err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			if err := writeFile(p, tr, os.FileMode(h.Mode)&0o755); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(p string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0o644)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+
+	return err
+}
+
+// command builds the command to run the app. The app listens on the port
+// assigned by the env of Gradio or the args of Streamlit.
+func (inst *instance) command(ctx context.Context, w io.Writer) (*exec.Cmd, error) {
+	bootFile := filepath.Join(inst.codeDir, inst.cfg.BootFile)
+
+	b, err := os.ReadFile(bootFile)
+	if err != nil {
+		return nil, err
+	}
+
+	isStreamlit := bytes.Contains(b, []byte("streamlit"))
+
+	// the app listens on all the interfaces in the container and the port
+	// is published on the host. The container can't write anything but
+	// /tmp, and can't gain any privilege.
+	host := "0.0.0.0"
+	cfg := inst.cfg
+	workDir := path.Join(containerCodeDir, path.Dir(filepath.ToSlash(cfg.BootFile)))
+
+	args := []string{
+		"run", "--rm", "--name", inst.containerName(),
+		"--user", fmt.Sprintf("%d:%d", cfg.Uid, cfg.Gid),
+		"--cpus", cfg.CPUs,
+		"--memory", cfg.Memory,
+		"--pids-limit", strconv.Itoa(cfg.PidsLimit),
+		"--network", cfg.Network,
+		"--cap-drop=ALL",
+		"--security-opt=no-new-privileges",
+		"--read-only",
+		"--tmpfs", "/tmp",
+		"-p", inst.address() + ":" + strconv.Itoa(inst.port),
+		"-v", inst.codeDir + ":" + containerCodeDir + ":ro",
+		"-w", workDir,
+		"-e", "HOME=/tmp",
+	}
+
+	for _, kv := range appEnv(host, inst.port) {
+		args = append(args, "-e", kv)
+	}
+
+	args = append(args, cfg.Image, cfg.Interpreter)
+	args = append(args, appArgs(path.Base(cfg.BootFile), host, inst.port, isStreamlit)...)
+
+	cmd := exec.CommandContext(ctx, cfg.Runtime, args...)
+	cmd.Env = runtimeEnv(cfg)
+
+	// killing the client does not stop the container.
+	cmd.Cancel = func() error {
+		inst.removeContainer()
+
+		return cmd.Process.Kill()
+	}
+
+	cmd.Stdout = w
+	cmd.Stderr = w
+	cmd.WaitDelay = 5 * time.Second
+
+	return cmd, nil
+}
+
+func appArgs(bootFile, host string, port int, isStreamlit bool) []string {
+	if !isStreamlit {
+		return []string{bootFile}
+	}
+
+	return []string{
+		"-m", "streamlit", "run", bootFile,
+		"--server.address=" + host,
+		"--server.port=" + strconv.Itoa(port),
+		"--server.headless=true",
+	}
+}
+
+func appEnv(host string, port int) []string {
+	return []string{
+		"GRADIO_SERVER_NAME=" + host,
+		"GRADIO_SERVER_PORT=" + strconv.Itoa(port),
+	}
+}
+
+// runtimeEnv returns the env of runtime. The rootless runtime finds its
+// socket and storage by them.
+func runtimeEnv(cfg *Config) []string {
+	env := []string{"PATH=" + cfg.Path, "HOME=" + os.Getenv("HOME")}
+
+	for _, k := range []string{"XDG_RUNTIME_DIR", "DOCKER_HOST"} {
+		if v := os.Getenv(k); v != "" {
+			env = append(env, k+"="+v)
+		}
+	}
+
+	return env
+}
+
+// checkRootless makes sure the runtime runs in the rootless mode, so that
+// the root in the container is not the one of host.
+func checkRootless(cfg *Config) error {
+	format, expect := "{{.Host.Security.Rootless}}", "true"
+	if cfg.Runtime == RuntimeDocker {
+		format, expect = "{{.SecurityOptions}}", "rootless"
+	}
+
+	cmd := exec.Command(cfg.Runtime, "info", "--format", format)
+	cmd.Env = runtimeEnv(cfg)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("check the runtime of local inference failed, err:%s", err.Error())
+	}
+
+	if !strings.Contains(string(out), expect) {
+		return errors.New("the runtime of local inference must run in the rootless mode")
+	}
+
+	return nil
+}
+
+func exitMessage(err error) string {
+	if err == nil {
+		return "the app exited"
+	}
+
+	return "the app exited, " + err.Error()
+}
+
+func isInDir(dir, p string) bool {
+	v, err := filepath.Rel(dir, p)
+
+	return err == nil && v != ".." && !strings.HasPrefix(v, ".."+string(filepath.Separator))
+}
+
+// logWriter writes the output of app to the startup log until the app
+// is healthy, and then to the runtime log. The log is truncated when it
+// reaches max bytes, so that the app can't fill up the disk.
+type logWriter struct {
+	lock sync.Mutex
+	f    *os.File
+	max  int64
+	size int64
+}
+
+// Write always consumes p, or else the app would block on its output
+// once the log fails to be written.
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	n := len(p)
+
+	if w.size+int64(n) > w.max {
+		if err := w.truncate(); err != nil {
+			logrus.Errorf("truncate the log of local inference failed, err:%s", err.Error())
+
+			return n, nil
+		}
+
+		if int64(n) > w.max {
+			p = p[int64(n)-w.max:]
+		}
+	}
+
+	m, err := w.f.Write(p)
+	w.size += int64(m)
+
+	if err != nil {
+		logrus.Errorf("write the log of local inference failed, err:%s", err.Error())
+	}
+
+	return n, nil
+}
+
+func (w *logWriter) truncate() error {
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+
+	_, err := w.f.Seek(0, io.SeekStart)
+	if err == nil {
+		w.size = 0
+	}
+
+	return err
+}
+
+func (w *logWriter) switchTo(f *os.File) {
+	w.lock.Lock()
+	old := w.f
+	w.f = f
+	w.size = 0
+	w.lock.Unlock()
+
+	old.Close()
+}
+
+func (w *logWriter) close() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.f.Close()
+}
@@ -0,0 +1,46 @@
+package localinferenceimpl
+
+import "github.com/opensourceways/xihe-server/domain"
+
+// instanceDO is the instance saved on the disk.
+type instanceDO struct {
+	Owner         string `json:"owner"`
+	ProjectId     string `json:"project_id"`
+	ProjectName   string `json:"project_name"`
+	ResourceLevel string `json:"resource_level"`
+	Requester     string `json:"requester"`
+	Id            string `json:"id"`
+	LastCommit    string `json:"last_commit"`
+	Expiry        int64  `json:"expiry"`
+}
+
+func toInstanceDO(info *domain.InferenceInfo, expiry int64) instanceDO {
+	return instanceDO{
+		Owner:         info.Project.Owner.Account(),
+		ProjectId:     info.Project.Id,
+		ProjectName:   info.ProjectName.ResourceName(),
+		ResourceLevel: info.ResourceLevel,
+		Requester:     info.Requester,
+		Id:            info.Id,
+		LastCommit:    info.LastCommit,
+		Expiry:        expiry,
+	}
+}
+
+func (do *instanceDO) toInferenceInfo() (info domain.InferenceInfo, err error) {
+	if info.Project.Owner, err = domain.NewAccount(do.Owner); err != nil {
+		return
+	}
+
+	if info.ProjectName, err = domain.NewResourceName(do.ProjectName); err != nil {
+		return
+	}
+
+	info.Project.Id = do.ProjectId
+	info.Id = do.Id
+	info.LastCommit = do.LastCommit
+	info.ResourceLevel = do.ResourceLevel
+	info.Requester = do.Requester
+
+	return
+}
@@ -0,0 +1,122 @@
+package localtrainingimpl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	Endpoint = "local"
+
+	// FileRoutePath is the route by which the server serves the files
+	// of jobs to their owners.
+	FileRoutePath = "/v1/train/local/file"
+)
+
+type Config struct {
+	// Enable replaces the remote training center with the local one.
+	// It is only designed for development and testing.
+	Enable bool `json:"enable"`
+
+	// WorkDir is the directory where the jobs run and their files are saved.
+	// The files written by the jobs are under WorkDir/sandbox, and the logs
+	// and archives written by the server are under WorkDir/files which the
+	// jobs can't access.
+	WorkDir string `json:"work_dir"`
+
+	// RepoDir is the directory where the repos are checked out.
+	// A repo is located at RepoDir/owner/repo_id.
+	RepoDir string `json:"repo_dir"`
+
+	// FileServerURL is the url by which the files of jobs can be visited.
+	// It must end with FileRoutePath, such as
+	// http://127.0.0.1:8000/api/v1/train/local/file.
+	FileServerURL string `json:"file_server_url"`
+
+	Interpreter string `json:"interpreter"`
+
+	// Path is the value of PATH passed to the job.
+	Path string `json:"path"`
+
+	// Uid and Gid are used to run the job as a user without privileges.
+	// Neither of them can be 0 or the same as the server.
+	Uid uint32 `json:"uid"`
+	Gid uint32 `json:"gid"`
+
+	// EnvAllowlist is the keys of env and inputs which the user can set.
+	// The ones set by the server, such as PATH and HOME, can't be in it.
+	EnvAllowlist []string `json:"env_allowlist"`
+
+	MaxConcurrentJobs int `json:"max_concurrent_jobs"`
+
+	// MaxDuration is the max seconds a job can run.
+	MaxDuration int `json:"max_duration"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = "/tmp/xihe-training"
+	}
+
+	if cfg.Interpreter == "" {
+		cfg.Interpreter = "python3"
+	}
+
+	if cfg.Path == "" {
+		cfg.Path = "/usr/local/bin:/usr/bin:/bin"
+	}
+
+	if cfg.MaxConcurrentJobs <= 0 {
+		cfg.MaxConcurrentJobs = 1
+	}
+
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = 3600
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enable {
+		return nil
+	}
+
+	if cfg.RepoDir == "" {
+		return errors.New("missing repo_dir of local training")
+	}
+
+	if cfg.FileServerURL == "" {
+		return errors.New("missing file_server_url of local training")
+	}
+
+	return cfg.checkSandbox()
+}
+
+// checkSandbox checks that the job can't run with the privileges of server.
+func (cfg *Config) checkSandbox() error {
+	if cfg.Uid == 0 || cfg.Gid == 0 {
+		return errors.New("uid and gid of local training must not be 0")
+	}
+
+	if int(cfg.Uid) == os.Getuid() || int(cfg.Gid) == os.Getgid() {
+		return errors.New("uid and gid of local training must not be the same as the server")
+	}
+
+	for _, k := range cfg.EnvAllowlist {
+		if reservedEnv[k] {
+			return fmt.Errorf("env %s of local training can't be set by user", k)
+		}
+	}
+
+	return nil
+}
+
+func (cfg *Config) isEnvAllowed(k string) bool {
+	for _, v := range cfg.EnvAllowlist {
+		if v == k {
+			return true
+		}
+	}
+
+	return false
+}
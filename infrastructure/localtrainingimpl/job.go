@@ -0,0 +1,390 @@
+package localtrainingimpl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/infrastructure/joblogimpl"
+)
+
+const (
+	fileLog    = "train.log"
+	fileOutput = "output.tar.gz"
+	dirOutput  = "output"
+	dirAim     = "aim"
+	dirSandbox = "sandbox"
+	dirFiles   = "files"
+
+	envPath      = "PATH"
+	envHome      = "HOME"
+	envOutputDir = "OUTPUT_DIR"
+	envAimDir    = "AIM_DIR"
+)
+
+// reservedEnv is the env which is set by the server or may change
+// the programs the job loads, so the user can't set it.
+var reservedEnv = map[string]bool{
+	envPath:           true,
+	envHome:           true,
+	envOutputDir:      true,
+	envAimDir:         true,
+	"LD_PRELOAD":      true,
+	"LD_LIBRARY_PATH": true,
+	"LD_AUDIT":        true,
+}
+
+type job struct {
+	id    string
+	dir   string
+	index domain.TrainingIndex
+
+	// fileDir is where the server writes the log and the archive of
+	// output, it is out of the reach of job.
+	fileDir string
+
+	codeDir string
+	args    []string
+	env     []string
+
+	enableAim    bool
+	enableOutput bool
+
+	cfg *Config
+
+	lock       sync.Mutex
+	cancel     context.CancelFunc
+	terminated bool
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+func (impl *trainingImpl) newJob(jobId string, info *domain.TrainingIndex, t *domain.TrainingConfig) (
+	*job, error,
+) {
+	repoDir := impl.repoDir(info.Project.Owner.Account(), t.ProjectRepoId)
+
+	codeDir := repoDir
+	if !t.CodeDir.IsRootDir() {
+		codeDir = filepath.Join(repoDir, t.CodeDir.Directory())
+	}
+
+	bootFile := filepath.Join(codeDir, t.BootFile.FilePath())
+
+	if !isInDir(repoDir, bootFile) {
+		return nil, errors.New("boot file is out of the repo")
+	}
+
+	if _, err := os.Stat(bootFile); err != nil {
+		return nil, fmt.Errorf("boot file is unavailable, err:%s", err.Error())
+	}
+
+	dir := impl.jobDir(jobId)
+
+	j := &job{
+		id:           jobId,
+		dir:          dir,
+		fileDir:      impl.fileDir(jobId),
+		index:        *info,
+		codeDir:      codeDir,
+		enableAim:    t.EnableAim,
+		enableOutput: t.EnableOutput,
+		cfg:          &impl.cfg,
+	}
+
+	j.args = append([]string{bootFile}, toArgs(t.Hyperparameters)...)
+
+	env, err := impl.toEnv(j, t)
+	if err != nil {
+		return nil, err
+	}
+
+	j.env = env
+
+	// the job can only traverse the parents, but can't list them.
+	if err := os.MkdirAll(filepath.Dir(dir), 0o711); err != nil {
+		return nil, err
+	}
+
+	dirs := []string{dir, j.outputDir()}
+	if t.EnableAim {
+		dirs = append(dirs, j.aimDir())
+	}
+
+	// the job runs as the user of sandbox, so it owns the directories
+	// where it writes.
+	for _, d := range dirs {
+		if err := os.MkdirAll(d, 0o750); err != nil {
+			return nil, err
+		}
+
+		if err := os.Chown(d, int(impl.cfg.Uid), int(impl.cfg.Gid)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(j.fileDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	// the log is created in advance, so that it can be read once the job runs.
+	if err := os.WriteFile(filepath.Join(j.fileDir, fileLog), nil, 0o640); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (impl *trainingImpl) repoDir(owner, repoId string) string {
+	return filepath.Join(impl.cfg.RepoDir, owner, repoId)
+}
+
+// toEnv builds the environment of the job from scratch, so that
+// the environment of the server will not be leaked to the job.
+// Only the keys in the allowlist can be set by the user.
+func (impl *trainingImpl) toEnv(j *job, t *domain.TrainingConfig) ([]string, error) {
+	env := []string{
+		envPath + "=" + impl.cfg.Path,
+		envHome + "=" + j.dir,
+		envOutputDir + "=" + j.outputDir(),
+	}
+
+	if t.EnableAim {
+		env = append(env, envAimDir+"="+j.aimDir())
+	}
+
+	for i := range t.Env {
+		kv := &t.Env[i]
+
+		k := kv.Key.CustomizedKey()
+		if !impl.cfg.isEnvAllowed(k) {
+			return nil, fmt.Errorf("env %s is not allowed", k)
+		}
+
+		env = append(env, k+"="+kv.Value.CustomizedValue())
+	}
+
+	// the input is passed as the local path of the file referenced.
+	for i := range t.Inputs {
+		item := &t.Inputs[i]
+
+		k := item.Key.CustomizedKey()
+		if !impl.cfg.isEnvAllowed(k) {
+			return nil, fmt.Errorf("env %s is not allowed", k)
+		}
+
+		repoDir := impl.repoDir(item.User.Account(), item.RepoId)
+		p := filepath.Join(repoDir, item.File.InputeFilePath())
+
+		if !isInDir(repoDir, p) {
+			return nil, errors.New("input file is out of the repo")
+		}
+
+		env = append(env, k+"="+p)
+	}
+
+	return env, nil
+}
+
+func (j *job) outputDir() string {
+	return filepath.Join(j.dir, dirOutput)
+}
+
+func (j *job) aimDir() string {
+	return filepath.Join(j.dir, dirAim)
+}
+
+func (j *job) terminate() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.terminated = true
+
+	if j.cancel != nil {
+		j.cancel()
+	}
+}
+
+func (j *job) isTerminated() bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.terminated
+}
+
+func (j *job) duration() int {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.startedAt.IsZero() || j.finishedAt.IsZero() {
+		return 0
+	}
+
+	return int(j.finishedAt.Sub(j.startedAt).Seconds())
+}
+
+// run runs the job and returns the final status of it.
+func (j *job) run(maxDuration int) (string, string) {
+	logFile, err := os.Create(filepath.Join(j.fileDir, fileLog))
+	if err != nil {
+		return JobStatusFailed, err.Error()
+	}
+
+	defer logFile.Close()
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), time.Duration(maxDuration)*time.Second,
+	)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, j.cfg.Interpreter, j.args...)
+	cmd.Dir = j.codeDir
+	cmd.Env = j.env
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+		// the job must not outlive the server, otherwise it can't be
+		// managed any more after the server restarts.
+		Pdeathsig: syscall.SIGKILL,
+		Credential: &syscall.Credential{
+			Uid: j.cfg.Uid,
+			Gid: j.cfg.Gid,
+		},
+	}
+
+	// kill the whole process group, including the subprocesses
+	// started by the boot file.
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	j.lock.Lock()
+	if j.terminated {
+		j.lock.Unlock()
+
+		return JobStatusTerminated, ""
+	}
+
+	j.cancel = cancel
+	j.startedAt = time.Now()
+	j.lock.Unlock()
+
+	err = cmd.Run()
+
+	j.lock.Lock()
+	j.finishedAt = time.Now()
+	terminated := j.terminated
+	j.lock.Unlock()
+
+	switch {
+	case terminated:
+		return JobStatusTerminated, ""
+
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return JobStatusFailed, "exceed max duration"
+
+	case err != nil:
+		return JobStatusFailed, err.Error()
+
+	default:
+		return JobStatusCompleted, ""
+	}
+}
+
+// archiveOutput archives the regular files of output. The files are opened
+// in the directory of job, because the job can replace them with the
+// symbolic links to the other files after it is done.
+func (j *job) archiveOutput() error {
+	f, err := os.Create(filepath.Join(j.fileDir, fileOutput))
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	root := j.outputDir()
+
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// the symbolic link may point to the file out of the output.
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		name, err := filepath.Rel(root, p)
+		if err != nil || name == "." {
+			return err
+		}
+
+		h, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		h.Name = filepath.ToSlash(name)
+
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		src, err := joblogimpl.OpenInDir(j.dir, p)
+		if err != nil {
+			return err
+		}
+
+		defer src.Close()
+
+		_, err = io.Copy(tw, src)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+func toArgs(kv []domain.KeyValue) []string {
+	r := make([]string, len(kv))
+
+	for i := range kv {
+		r[i] = fmt.Sprintf(
+			"--%s=%s", kv[i].Key.CustomizedKey(), kv[i].Value.CustomizedValue(),
+		)
+	}
+
+	return r
+}
+
+func isInDir(dir, p string) bool {
+	v, err := filepath.Rel(dir, p)
+
+	return err == nil && v != ".." && !strings.HasPrefix(v, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,399 @@
+package localtrainingimpl
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/joblog"
+	"github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/domain/training"
+	"github.com/opensourceways/xihe-server/infrastructure/joblogimpl"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	JobStatusPending    = "Pending"
+	JobStatusRunning    = "Running"
+	JobStatusCompleted  = "Completed"
+	JobStatusFailed     = "Failed"
+	JobStatusTerminated = "Terminated"
+)
+
+// JobHandler is the one which creates the job when a training is created
+// and saves the job detail when the status of the job changes.
+type JobHandler interface {
+	CreateTrainingJob(*domain.TrainingIndex, string, bool) (bool, error)
+	UpdateJobDetail(*domain.TrainingIndex, *domain.JobDetail) error
+}
+
+// Training runs the training job as a subprocess of the server.
+// It also acts as the message producer of training, because there is
+// no consumer of the message in the local environment.
+type Training interface {
+	training.Training
+
+	SendTrainingCreated(*domain.TrainingCreatedEvent) error
+
+	// SetJobHandler must be called before any training is created.
+	SetJobHandler(JobHandler)
+
+	// RecoverJobs marks the jobs interrupted by the restart of server as
+	// failed. It must be called after SetJobHandler.
+	RecoverJobs() error
+
+	// LocalFile opens the file if it belongs to the owner. The file written
+	// by the job is opened only if it is in the directory of job after the
+	// symbolic links are resolved.
+	LocalFile(owner domain.Account, file string) (*os.File, error)
+
+	// Downloader returns the downloader which reads the files of jobs
+	// from the disk directly and the other links by d.
+	Downloader(d joblog.Downloader) joblog.Downloader
+}
+
+func NewTraining(cfg *Config, repo repository.Training) (Training, error) {
+	// refuse to run the code of users as the server.
+	if err := cfg.checkSandbox(); err != nil {
+		return nil, err
+	}
+
+	// the job can only traverse the work dir, but can't list it.
+	for _, d := range []string{cfg.WorkDir, filepath.Join(cfg.WorkDir, dirSandbox)} {
+		if err := os.MkdirAll(d, 0o711); err != nil {
+			return nil, err
+		}
+	}
+
+	// the files written by the server are out of the reach of jobs.
+	d := filepath.Join(cfg.WorkDir, dirFiles)
+	if err := os.MkdirAll(d, 0o700); err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(d, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &trainingImpl{
+		cfg:   *cfg,
+		repo:  repo,
+		jobs:  map[string]*job{},
+		slots: make(chan struct{}, cfg.MaxConcurrentJobs),
+	}, nil
+}
+
+type trainingImpl struct {
+	cfg     Config
+	repo    repository.Training
+	handler JobHandler
+
+	lock sync.Mutex
+	jobs map[string]*job
+
+	// slots limits the num of jobs running concurrently.
+	slots chan struct{}
+}
+
+func (impl *trainingImpl) SetJobHandler(h JobHandler) {
+	impl.handler = h
+}
+
+func (impl *trainingImpl) SendTrainingCreated(v *domain.TrainingCreatedEvent) error {
+	if impl.handler == nil {
+		return errors.New("no job handler of local training")
+	}
+
+	index := v.TrainingIndex
+
+	go func() {
+		if _, err := impl.handler.CreateTrainingJob(&index, Endpoint, true); err != nil {
+			logrus.Errorf(
+				"create local training job for %s failed, err:%s",
+				index.TrainingId, err.Error(),
+			)
+		}
+	}()
+
+	return nil
+}
+
+func (impl *trainingImpl) IsJobDone(status string) bool {
+	return status == JobStatusCompleted ||
+		status == JobStatusFailed ||
+		status == JobStatusTerminated
+}
+
+func (impl *trainingImpl) CreateJob(endpoint string, info *domain.TrainingIndex, t *domain.TrainingConfig) (
+	v domain.JobInfo, err error,
+) {
+	// the job id is also the directory of job whose first part is the owner,
+	// so that the owner of file can be checked by its path.
+	jobId := path.Join(
+		info.Project.Owner.Account(), info.Project.Id,
+		info.TrainingId+"-"+strconv.FormatInt(utils.Now(), 10),
+	)
+
+	j, err := impl.newJob(jobId, info, t)
+	if err != nil {
+		return
+	}
+
+	impl.lock.Lock()
+	impl.jobs[jobId] = j
+	impl.lock.Unlock()
+
+	go impl.run(j)
+
+	v = domain.JobInfo{
+		Endpoint:  endpoint,
+		JobId:     jobId,
+		LogDir:    jobId,
+		OutputDir: path.Join(jobId, dirOutput),
+	}
+
+	if t.EnableAim {
+		v.AimDir = path.Join(jobId, dirAim)
+	}
+
+	return
+}
+
+func (impl *trainingImpl) DeleteJob(endpoint, jobId string) error {
+	if err := impl.TerminateJob(endpoint, jobId); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(impl.jobDir(jobId)); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(impl.fileDir(jobId))
+}
+
+func (impl *trainingImpl) TerminateJob(endpoint, jobId string) error {
+	impl.lock.Lock()
+	j := impl.jobs[jobId]
+	impl.lock.Unlock()
+
+	if j != nil {
+		j.terminate()
+	}
+
+	return nil
+}
+
+func (impl *trainingImpl) GetLogPreviewURL(endpoint, jobId string) (string, error) {
+	return impl.fileURL(path.Join(jobId, fileLog))
+}
+
+func (impl *trainingImpl) GetFileDownloadURL(endpoint, file string) (string, error) {
+	return impl.fileURL(file)
+}
+
+func (impl *trainingImpl) fileURL(file string) (string, error) {
+	file = filepath.Clean(file)
+	if filepath.IsAbs(file) || strings.HasPrefix(file, "..") {
+		return "", errors.New("invalid file path")
+	}
+
+	return url.JoinPath(impl.cfg.FileServerURL, filepath.ToSlash(file))
+}
+
+// jobDir is the directory of job owned by the user of sandbox.
+func (impl *trainingImpl) jobDir(jobId string) string {
+	return filepath.Join(impl.cfg.WorkDir, dirSandbox, filepath.FromSlash(jobId))
+}
+
+// fileDir is the directory of the files of job written by the server.
+func (impl *trainingImpl) fileDir(jobId string) string {
+	return filepath.Join(impl.cfg.WorkDir, dirFiles, filepath.FromSlash(jobId))
+}
+
+func (impl *trainingImpl) LocalFile(owner domain.Account, file string) (*os.File, error) {
+	file = path.Clean("/" + file)[1:]
+
+	if v := strings.SplitN(file, "/", 2); len(v) != 2 || v[0] != owner.Account() {
+		return nil, errors.New("no such file")
+	}
+
+	f, err := impl.openFile(file)
+	if err != nil {
+		return nil, errors.New("no such file")
+	}
+
+	return f, nil
+}
+
+// openFile opens the file whose path is job_id/name. The log and the archive
+// of output are written by the server, and the others are written by the job.
+func (impl *trainingImpl) openFile(file string) (*os.File, error) {
+	v := strings.SplitN(path.Clean("/" + file)[1:], "/", 4)
+	if len(v) != 4 {
+		return nil, errors.New("no such file")
+	}
+
+	jobId := path.Join(v[0], v[1], v[2])
+
+	dir := impl.jobDir(jobId)
+	if v[3] == fileLog || v[3] == fileOutput {
+		dir = impl.fileDir(jobId)
+	}
+
+	return joblogimpl.OpenInDir(dir, filepath.Join(dir, filepath.FromSlash(v[3])))
+}
+
+func (impl *trainingImpl) Downloader(d joblog.Downloader) joblog.Downloader {
+	return joblogimpl.NewLocalDownloader(d, impl.cfg.FileServerURL, impl.openFile)
+}
+
+func (impl *trainingImpl) RecoverJobs() error {
+	root := filepath.Join(impl.cfg.WorkDir, dirFiles)
+
+	dirs, err := filepath.Glob(filepath.Join(root, "*", "*", "*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			continue
+		}
+
+		jobId := filepath.ToSlash(rel)
+
+		index, ok := toTrainingIndex(jobId)
+		if !ok {
+			continue
+		}
+
+		if err := impl.recoverJob(&index, jobId); err != nil {
+			logrus.Errorf("recover local training job(%s) failed, err:%s", jobId, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (impl *trainingImpl) recoverJob(index *domain.TrainingIndex, jobId string) error {
+	job, err := impl.repo.GetJob(index)
+	if err != nil {
+		if repository.IsErrorResourceNotExists(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if job.Endpoint != Endpoint || job.JobId != jobId {
+		return nil
+	}
+
+	detail, _, err := impl.repo.GetJobDetail(index)
+	if err != nil || impl.IsJobDone(detail.Status) {
+		return err
+	}
+
+	return impl.handler.UpdateJobDetail(index, &domain.JobDetail{
+		Status: JobStatusFailed,
+		Error:  "interrupted by the restart of server",
+	})
+}
+
+// toTrainingIndex parses the job id which is owner/project_id/training_id-timestamp.
+func toTrainingIndex(jobId string) (index domain.TrainingIndex, ok bool) {
+	v := strings.Split(jobId, "/")
+	if len(v) != 3 {
+		return
+	}
+
+	i := strings.LastIndex(v[2], "-")
+	if i <= 0 {
+		return
+	}
+
+	owner, err := domain.NewAccount(v[0])
+	if err != nil {
+		return
+	}
+
+	index.Project.Owner = owner
+	index.Project.Id = v[1]
+	index.TrainingId = v[2][:i]
+
+	return index, true
+}
+
+func (impl *trainingImpl) run(j *job) {
+	impl.report(j, JobStatusPending, "")
+
+	impl.slots <- struct{}{}
+
+	defer func() {
+		<-impl.slots
+
+		impl.lock.Lock()
+		delete(impl.jobs, j.id)
+		impl.lock.Unlock()
+	}()
+
+	if j.isTerminated() {
+		impl.report(j, JobStatusTerminated, "")
+
+		return
+	}
+
+	impl.report(j, JobStatusRunning, "")
+
+	status, errMsg := j.run(impl.cfg.MaxDuration)
+
+	impl.report(j, status, errMsg)
+}
+
+func (impl *trainingImpl) report(j *job, status, errMsg string) {
+	detail := domain.JobDetail{
+		Status: status,
+		Error:  errMsg,
+	}
+
+	// the log is written to the file since the job runs, so it is the full
+	// log even if the job is not done.
+	if _, err := os.Stat(filepath.Join(j.fileDir, fileLog)); err == nil {
+		detail.LogPath = path.Join(j.id, fileLog)
+	}
+
+	if impl.IsJobDone(status) {
+		detail.Duration = j.duration()
+
+		if j.enableAim {
+			detail.AimPath = path.Join(j.id, dirAim)
+		}
+
+		if j.enableOutput {
+			if err := j.archiveOutput(); err != nil {
+				logrus.Errorf(
+					"archive output of local training job(%s) failed, err:%s",
+					j.id, err.Error(),
+				)
+			} else {
+				detail.OutputPath = path.Join(j.id, fileOutput)
+			}
+		}
+	}
+
+	if err := impl.handler.UpdateJobDetail(&j.index, &detail); err != nil {
+		logrus.Errorf(
+			"update detail of local training job(%s) failed, err:%s",
+			j.id, err.Error(),
+		)
+	}
+}
@@ -11,6 +11,7 @@ const (
 	actionRemove = "remove"
 	actionCreate = "create"
 	actionExtend = "extend"
+	actionStop   = "stop"
 )
 
 type MsgOperateLog struct {
@@ -171,6 +172,7 @@ type msgPodCreate struct {
 	CloudName     string `json:"cloud_name"`
 	CloudImage    string `json:"cloud_image"`
 	CloudCardsNum int    `json:"cloud_cards_num"`
+	Expiry        int64  `json:"expiry"`
 }
 
 type ReleasePodMsg struct {
@@ -58,6 +58,15 @@ func (s *sender) ExtendInferenceSurvivalTime(info *message.InferenceExtendInfo)
 	return s.send(s.topics.Inference, &v)
 }
 
+func (s *sender) StopInference(info *domain.InferenceInfo) error {
+	v := s.toInferenceMsg(&info.InferenceIndex)
+	v.Action = actionStop
+	v.ProjectName = info.ProjectName.ResourceName()
+	v.ResourceLevel = info.ResourceLevel
+
+	return s.send(s.topics.Inference, &v)
+}
+
 func (s *sender) toInferenceMsg(index *domain.InferenceIndex) msgInference {
 	return msgInference{
 		ProjectId:    index.Project.Id,
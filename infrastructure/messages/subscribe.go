@@ -267,6 +267,9 @@ func (r *register) registerHandlerForInference(handler interface{}) error {
 				},
 			)
 
+		case actionStop:
+			return h.HandleEventStopInference(&info)
+
 		default:
 			logrus.Warn("unknown action")
 			return nil
@@ -317,7 +320,7 @@ func (r *register) registerHandlerForCloud(handler interface{}) error {
 			},
 			CardsNum: cloudCardsNum,
 		}
-		if err = v.SetDefaultExpiry(); err != nil {
+		if err = v.SetExpiry(body.Expiry); err != nil {
 			return
 		}
 
@@ -61,6 +61,12 @@ const (
 	fieldPictures       = "pictures"
 	fieldChoices        = "choices"
 	fieldCompletions    = "completions"
+	fieldRuns           = "runs"
+	fieldPaused         = "paused"
+	fieldNextRunAt      = "next_run_at"
+	fieldRequests       = "requests"
+	fieldFailures       = "failures"
+	fieldLatency        = "latency"
 )
 
 type dProject struct {
@@ -363,10 +369,21 @@ type dInference struct {
 }
 
 type inferenceItem struct {
-	Id        string `bson:"id"          json:"id,omitempty"`
-	Expiry    int64  `bson:"expiry"      json:"expiry,omitempty"`
-	Error     string `bson:"error"       json:"error,omitempty"`
-	AccessURL string `bson:"url"         json:"url,omitempty"`
+	Id            string           `bson:"id"           json:"id,omitempty"`
+	Expiry        int64            `bson:"expiry"       json:"expiry,omitempty"`
+	Error         string           `bson:"error"        json:"error,omitempty"`
+	Failure       *dInferenceError `bson:"failure"      json:"failure,omitempty"`
+	AccessURL     string           `bson:"url"          json:"url,omitempty"`
+	StartupLogURL string           `bson:"startup_log"  json:"startup_log,omitempty"`
+	RuntimeLogURL string           `bson:"runtime_log"  json:"runtime_log,omitempty"`
+	Stopped       bool             `bson:"stopped"      json:"stopped,omitempty"`
+	CreatedAt     int64            `bson:"created_at"   json:"created_at,omitempty"`
+}
+
+type dInferenceError struct {
+	Stage   string `bson:"stage"   json:"stage"`
+	Code    string `bson:"code"    json:"code"`
+	Message string `bson:"message" json:"message"`
 }
 
 type DCompetition struct {
@@ -525,10 +542,18 @@ type finetuneItem struct {
 	Model           string             `bson:"model"         json:"model"`
 	CreatedAt       int64              `bson:"created_at"    json:"created_at"`
 	Hyperparameters map[string]string  `bson:"parameters"    json:"parameters,omitempty"`
+	Dataset         *dFinetuneDataset  `bson:"dataset"       json:"dataset,omitempty"`
 	Job             dFinetuneJobInfo   `bson:"job"           json:"-"`
 	JobDetail       dFinetuneJobDetail `bson:"detail"        json:"-"`
 }
 
+type dFinetuneDataset struct {
+	User   string `bson:"user"           json:"user"`
+	RepoId string `bson:"rid"            json:"rid"`
+	File   string `bson:"file"           json:"file"`
+	Name   string `bson:"name"           json:"name"`
+}
+
 type dFinetuneJobInfo struct {
 	Endpoint string `bson:"endpoint"    json:"endpoint"`
 	JobId    string `bson:"job_id"      json:"job_id"`
@@ -539,3 +564,36 @@ type dFinetuneJobDetail struct {
 	Error    string `bson:"error"      json:"error,omitempty"`
 	Status   string `bson:"status"     json:"status,omitempty"`
 }
+
+type dTrainingSchedule struct {
+	Id            string                 `bson:"id"            json:"id"`
+	Owner         string                 `bson:"owner"         json:"owner"`
+	ProjectId     string                 `bson:"pid"           json:"pid"`
+	ProjectName   string                 `bson:"pname"         json:"pname"`
+	ProjectRepoId string                 `bson:"rid"           json:"rid"`
+	Name          string                 `bson:"name"          json:"name"`
+	Spec          string                 `bson:"spec"          json:"spec"`
+	Paused        bool                   `bson:"paused"        json:"paused"`
+	NextRunAt     int64                  `bson:"next_run_at"   json:"next_run_at"`
+	CreatedAt     int64                  `bson:"created_at"    json:"created_at"`
+	Config        trainingItem           `bson:"config"        json:"config"`
+	Runs          []dTrainingScheduleRun `bson:"runs"          json:"runs"`
+	Version       int                    `bson:"version"       json:"-"`
+}
+
+type dTrainingScheduleRun struct {
+	TrainingId  string `bson:"tid"           json:"tid,omitempty"`
+	Status      string `bson:"status"        json:"status"`
+	Error       string `bson:"error"         json:"error,omitempty"`
+	TriggeredAt int64  `bson:"triggered_at"  json:"triggered_at"`
+}
+
+type dInferenceUsage struct {
+	Owner     string `bson:"owner"     json:"owner"`
+	ProjectId string `bson:"pid"       json:"pid"`
+	User      string `bson:"account"   json:"account"`
+	Date      string `bson:"date"      json:"date"`
+	Requests  int    `bson:"requests"  json:"requests"`
+	Failures  int    `bson:"failures"  json:"failures"`
+	Latency   int64  `bson:"latency"   json:"latency"`
+}
@@ -16,6 +16,15 @@ func (col finetuneCol) toFinetuneDoc(do *repositories.UserFinetuneDO) (bson.M, e
 		Hyperparameters: do.Hyperparameters,
 	}
 
+	if v := do.Dataset; v != nil {
+		doc.Dataset = &dFinetuneDataset{
+			User:   v.User,
+			RepoId: v.RepoId,
+			File:   v.File,
+			Name:   v.Name,
+		}
+	}
+
 	return genDoc(&doc)
 }
 
@@ -57,4 +66,13 @@ func (col finetuneCol) toFinetuneDetailDO(doc *finetuneItem, do *repositories.Fi
 			Duration: detail.Duration,
 		},
 	}
+
+	if v := doc.Dataset; v != nil {
+		do.Dataset = &repositories.FinetuneDatasetDO{
+			User:   v.User,
+			RepoId: v.RepoId,
+			File:   v.File,
+			Name:   v.Name,
+		}
+	}
 }
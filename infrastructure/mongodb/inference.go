@@ -5,8 +5,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/opensourceways/xihe-server/domain"
 	"github.com/opensourceways/xihe-server/infrastructure/repositories"
+	"github.com/opensourceways/xihe-server/utils"
 )
 
 func NewInferenceMapper(name string) repositories.InferenceMapper {
@@ -75,7 +78,10 @@ func (col inference) Insert(do *repositories.InferenceDO, version int) (
 func (col inference) insert(do *repositories.InferenceDO, version int) (identity string, err error) {
 	identity = newId()
 
-	doc := bson.M{fieldId: identity}
+	doc := bson.M{
+		fieldId:        identity,
+		fieldCreatedAt: utils.Now(),
+	}
 
 	f := func(ctx context.Context) error {
 		return cli.updateDoc(
@@ -95,9 +101,20 @@ func (col inference) UpdateDetail(
 	detail *repositories.InferenceDetailDO,
 ) error {
 	data := inferenceItem{
-		Expiry:    detail.Expiry,
-		Error:     detail.Error,
-		AccessURL: detail.AccessURL,
+		Expiry:        detail.Expiry,
+		Error:         detail.Error,
+		AccessURL:     detail.AccessURL,
+		StartupLogURL: detail.StartupLogURL,
+		RuntimeLogURL: detail.RuntimeLogURL,
+		Stopped:       detail.Stopped,
+	}
+
+	if v := detail.Failure; v != nil {
+		data.Failure = &dInferenceError{
+			Stage:   v.Stage,
+			Code:    v.Code,
+			Message: v.Message,
+		}
 	}
 
 	doc, err := genDoc(data)
@@ -191,9 +208,65 @@ func (col inference) List(index *repositories.ResourceIndexDO, lastCommit string
 	return r, v.Version, nil
 }
 
+func (col inference) ListAll(index *repositories.ResourceIndexDO) (
+	[]repositories.InferenceInstanceDO, error,
+) {
+	var v []dInference
+
+	f := func(ctx context.Context) error {
+		return cli.getDocs(
+			ctx, col.collectionName,
+			bson.M{
+				fieldPId:   index.Id,
+				fieldOwner: index.Owner,
+			},
+			options.Find().SetProjection(bson.M{
+				fieldCommit: 1,
+				fieldItems:  1,
+			}), &v,
+		)
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for i := range v {
+		n += len(v[i].Items)
+	}
+
+	r := make([]repositories.InferenceInstanceDO, 0, n)
+
+	for i := range v {
+		for j := range v[i].Items {
+			item := repositories.InferenceInstanceDO{
+				LastCommit: v[i].LastCommit,
+			}
+			col.toInferenceSummaryDO(&v[i].Items[j], &item.InferenceSummaryDO)
+
+			r = append(r, item)
+		}
+	}
+
+	return r, nil
+}
+
 func (col inference) toInferenceSummaryDO(doc *inferenceItem, r *repositories.InferenceSummaryDO) {
 	r.Id = doc.Id
+	r.CreatedAt = doc.CreatedAt
 	r.Error = doc.Error
 	r.Expiry = doc.Expiry
 	r.AccessURL = doc.AccessURL
+	r.StartupLogURL = doc.StartupLogURL
+	r.RuntimeLogURL = doc.RuntimeLogURL
+	r.Stopped = doc.Stopped
+
+	if v := doc.Failure; v != nil {
+		r.Failure = &domain.InferenceError{
+			Stage:   v.Stage,
+			Code:    v.Code,
+			Message: v.Message,
+		}
+	}
 }
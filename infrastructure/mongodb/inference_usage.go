@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/infrastructure/repositories"
+)
+
+func NewInferenceUsageMapper(name string) repositories.InferenceUsageMapper {
+	return inferenceUsage{name}
+}
+
+type inferenceUsage struct {
+	usage string
+}
+
+func (col inferenceUsage) Record(do *repositories.InferenceUsageDO) error {
+	f := func(ctx context.Context) error {
+		_, err := cli.collection(col.usage).UpdateOne(
+			ctx,
+			bson.M{
+				fieldOwner:   do.Owner,
+				fieldPId:     do.ProjectId,
+				fieldAccount: do.User,
+				fieldDate:    do.Date,
+			},
+			bson.M{mongoCmdInc: bson.M{
+				fieldRequests: do.Requests,
+				fieldFailures: do.Failures,
+				fieldLatency:  do.Latency,
+			}},
+			options.Update().SetUpsert(true),
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (col inferenceUsage) List(owner, projectId, since string) (
+	[]repositories.InferenceUsageDO, error,
+) {
+	var v []dInferenceUsage
+
+	f := func(ctx context.Context) error {
+		return cli.getDocs(
+			ctx, col.usage,
+			bson.M{
+				fieldOwner: owner,
+				fieldPId:   projectId,
+				fieldDate:  bson.M{mongoCmdGte: since},
+			},
+			options.Find().SetSort(bson.D{
+				{Key: fieldDate, Value: 1},
+				{Key: fieldAccount, Value: 1},
+			}),
+			&v,
+		)
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	r := make([]repositories.InferenceUsageDO, len(v))
+	for i := range v {
+		item := &v[i]
+
+		r[i] = repositories.InferenceUsageDO{
+			Owner:     item.Owner,
+			ProjectId: item.ProjectId,
+			User:      item.User,
+			Date:      item.Date,
+			Requests:  item.Requests,
+			Failures:  item.Failures,
+			Latency:   item.Latency,
+		}
+	}
+
+	return r, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/opensourceways/xihe-server/infrastructure/repositories"
 )
@@ -131,6 +132,45 @@ func (col training) List(user, projectId string) ([]repositories.TrainingSummary
 	return r, v.Version, nil
 }
 
+func (col training) ListAll(user string) ([]repositories.TrainingSummaryDO, error) {
+	var v []dTraining
+
+	f := func(ctx context.Context) error {
+		return cli.getDocs(
+			ctx, col.collectionName,
+			bson.M{fieldOwner: user},
+			options.Find().SetProjection(bson.M{
+				fieldPId:                        1,
+				subfieldOfItems(fieldId):        1,
+				subfieldOfItems(fieldName):      1,
+				subfieldOfItems(fieldDesc):      1,
+				subfieldOfItems(fieldDetail):    1,
+				subfieldOfItems(fieldCreatedAt): 1,
+			}), &v,
+		)
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	var r []repositories.TrainingSummaryDO
+
+	for i := range v {
+		t := v[i].Items
+
+		for j := range t {
+			item := repositories.TrainingSummaryDO{}
+			col.toTrainingSummary(&t[j], &item)
+			item.ProjectId = v[i].ProjectId
+
+			r = append(r, item)
+		}
+	}
+
+	return r, nil
+}
+
 func (col training) Delete(info *repositories.TrainingIndexDO) error {
 	f := func(ctx context.Context) error {
 		return cli.pullArrayElem(
@@ -0,0 +1,293 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/opensourceways/xihe-server/infrastructure/repositories"
+)
+
+func NewTrainingScheduleMapper(name string, keep int) repositories.TrainingScheduleMapper {
+	return trainingSchedule{
+		collectionName: name,
+		keep:           keep,
+	}
+}
+
+func trainingScheduleDocFilter(index *repositories.TrainingScheduleIndexDO) bson.M {
+	return bson.M{
+		fieldOwner: index.Owner,
+		fieldPId:   index.ProjectId,
+		fieldId:    index.ScheduleId,
+	}
+}
+
+type trainingSchedule struct {
+	collectionName string
+	keep           int
+}
+
+func (col trainingSchedule) Insert(do *repositories.TrainingScheduleDO) (
+	identity string, err error,
+) {
+	identity = newId()
+	do.Id = identity
+
+	doc, err := col.toTrainingScheduleDoc(do)
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := cli.newDocIfNotExist(
+			ctx, col.collectionName,
+			trainingScheduleDocFilter(&repositories.TrainingScheduleIndexDO{
+				Owner:      do.Owner,
+				ProjectId:  do.ProjectId,
+				ScheduleId: identity,
+			}),
+			doc,
+		)
+
+		return err
+	}
+
+	if err = withContext(f); err != nil && isDocExists(err) {
+		err = repositories.NewErrorDuplicateCreating(err)
+	}
+
+	return
+}
+
+func (col trainingSchedule) Get(index *repositories.TrainingScheduleIndexDO) (
+	do repositories.TrainingScheduleDO, err error,
+) {
+	var v dTrainingSchedule
+
+	f := func(ctx context.Context) error {
+		return cli.getDoc(
+			ctx, col.collectionName,
+			trainingScheduleDocFilter(index), nil, &v,
+		)
+	}
+
+	if err = withContext(f); err != nil {
+		if isDocNotExists(err) {
+			err = repositories.NewErrorDataNotExists(err)
+		}
+
+		return
+	}
+
+	col.toTrainingScheduleDO(&v, &do)
+
+	return
+}
+
+func (col trainingSchedule) Delete(index *repositories.TrainingScheduleIndexDO) error {
+	f := func(ctx context.Context) error {
+		_, err := cli.collection(col.collectionName).DeleteOne(
+			ctx, trainingScheduleDocFilter(index),
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (col trainingSchedule) List(user, projectId string) (
+	[]repositories.TrainingScheduleDO, error,
+) {
+	return col.list(bson.M{
+		fieldOwner: user,
+		fieldPId:   projectId,
+	})
+}
+
+func (col trainingSchedule) ListDue(now int64) ([]repositories.TrainingScheduleDO, error) {
+	return col.list(bson.M{
+		fieldPaused:    false,
+		fieldNextRunAt: bson.M{"$gt": 0, "$lte": now},
+	})
+}
+
+func (col trainingSchedule) list(filter bson.M) ([]repositories.TrainingScheduleDO, error) {
+	var v []dTrainingSchedule
+
+	f := func(ctx context.Context) error {
+		opts := options.FindOptions{}
+
+		return cli.getDocs(
+			ctx, col.collectionName, filter,
+			opts.SetSort(bson.M{fieldCreatedAt: 1}), &v,
+		)
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	r := make([]repositories.TrainingScheduleDO, len(v))
+	for i := range v {
+		col.toTrainingScheduleDO(&v[i], &r[i])
+	}
+
+	return r, nil
+}
+
+func (col trainingSchedule) UpdateState(
+	index *repositories.TrainingScheduleIndexDO,
+	state *repositories.TrainingScheduleStateDO, version int,
+) error {
+	f := func(ctx context.Context) error {
+		return cli.updateDoc(
+			ctx, col.collectionName,
+			trainingScheduleDocFilter(index),
+			bson.M{
+				fieldPaused:    state.Paused,
+				fieldNextRunAt: state.NextRunAt,
+			},
+			mongoCmdSet, version,
+		)
+	}
+
+	if err := withContext(f); err != nil {
+		if isDocNotExists(err) {
+			return repositories.NewErrorConcurrentUpdating(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (col trainingSchedule) AddRun(
+	index *repositories.TrainingScheduleIndexDO, run *repositories.TrainingScheduleRunDO,
+) error {
+	doc, err := genDoc(dTrainingScheduleRun{
+		TrainingId:  run.TrainingId,
+		Status:      run.Status,
+		Error:       run.Error,
+		TriggeredAt: run.TriggeredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		return cli.pushElemToLimitedArray(
+			ctx, col.collectionName, fieldRuns, -col.keep,
+			trainingScheduleDocFilter(index), doc,
+		)
+	}
+
+	if err := withContext(f); err != nil {
+		if isDocNotExists(err) {
+			return repositories.NewErrorDataNotExists(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (col trainingSchedule) toTrainingScheduleDoc(do *repositories.TrainingScheduleDO) (bson.M, error) {
+	cfg := &do.TrainingConfigDO
+	c := &cfg.Compute
+	t := training{}
+
+	doc, err := genDoc(dTrainingSchedule{
+		Id:            do.Id,
+		Owner:         do.Owner,
+		ProjectId:     do.ProjectId,
+		ProjectName:   cfg.ProjectName,
+		ProjectRepoId: cfg.ProjectRepoId,
+		Name:          do.Name,
+		Spec:          do.Spec,
+		Paused:        do.Paused,
+		NextRunAt:     do.NextRunAt,
+		CreatedAt:     do.CreatedAt,
+		Config: trainingItem{
+			Name:            cfg.Name,
+			Desc:            cfg.Desc,
+			CodeDir:         cfg.CodeDir,
+			BootFile:        cfg.BootFile,
+			Inputs:          t.toInputDoc(cfg.Inputs),
+			EnableAim:       cfg.EnableAim,
+			EnableOutput:    cfg.EnableOutput,
+			Env:             t.toKeyValueDoc(cfg.Env),
+			Hyperparameters: t.toKeyValueDoc(cfg.Hyperparameters),
+			Compute: dCompute{
+				Type:    c.Type,
+				Flavor:  c.Flavor,
+				Version: c.Version,
+			},
+		},
+		Runs: []dTrainingScheduleRun{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc[fieldVersion] = 0
+
+	return doc, nil
+}
+
+func (col trainingSchedule) toTrainingScheduleDO(
+	doc *dTrainingSchedule, do *repositories.TrainingScheduleDO,
+) {
+	t := training{}
+	item := &doc.Config
+	c := &item.Compute
+
+	*do = repositories.TrainingScheduleDO{
+		Id:        doc.Id,
+		Owner:     doc.Owner,
+		ProjectId: doc.ProjectId,
+		Name:      doc.Name,
+		Spec:      doc.Spec,
+		Paused:    doc.Paused,
+		NextRunAt: doc.NextRunAt,
+		CreatedAt: doc.CreatedAt,
+		Version:   doc.Version,
+		TrainingConfigDO: repositories.TrainingConfigDO{
+			ProjectName:     doc.ProjectName,
+			ProjectRepoId:   doc.ProjectRepoId,
+			Name:            item.Name,
+			Desc:            item.Desc,
+			CodeDir:         item.CodeDir,
+			BootFile:        item.BootFile,
+			Inputs:          t.toInputs(item.Inputs),
+			EnableAim:       item.EnableAim,
+			EnableOutput:    item.EnableOutput,
+			Env:             t.toKeyValues(item.Env),
+			Hyperparameters: t.toKeyValues(item.Hyperparameters),
+			Compute: repositories.ComputeDO{
+				Type:    c.Type,
+				Flavor:  c.Flavor,
+				Version: c.Version,
+			},
+		},
+	}
+
+	if n := len(doc.Runs); n > 0 {
+		do.Runs = make([]repositories.TrainingScheduleRunDO, n)
+
+		for i := range doc.Runs {
+			r := &doc.Runs[i]
+
+			do.Runs[i] = repositories.TrainingScheduleRunDO{
+				TrainingId:  r.TrainingId,
+				Status:      r.Status,
+				Error:       r.Error,
+				TriggeredAt: r.TriggeredAt,
+			}
+		}
+	}
+}
@@ -17,6 +17,7 @@ const (
 	mongoCmdAll         = "$all"
 	mongoCmdSet         = "$set"
 	mongoCmdInc         = "$inc"
+	mongoCmdGte         = "$gte"
 	mongoCmdPush        = "$push"
 	mongoCmdPull        = "$pull"
 	mongoCmdMatch       = "$match"
@@ -37,6 +37,7 @@ func (impl finetuneImpl) toUserFinetuneDO(
 			Model:           p.Model(),
 			Task:            p.Task(),
 			Hyperparameters: p.Hyperparameters(),
+			Dataset:         impl.toFinetuneDatasetDO(obj.Dataset),
 		},
 		CreatedAt: obj.CreatedAt,
 	}
@@ -47,6 +48,7 @@ type FinetuneConfigDO struct {
 	Task            string
 	Model           string
 	Hyperparameters map[string]string
+	Dataset         *FinetuneDatasetDO
 }
 
 func (do *FinetuneConfigDO) toFinetuneConfig(cfg *domain.FinetuneConfig) (err error) {
@@ -54,10 +56,54 @@ func (do *FinetuneConfigDO) toFinetuneConfig(cfg *domain.FinetuneConfig) (err er
 		return
 	}
 
-	cfg.Param, err = domain.NewFinetuneParameter(
+	cfg.Param = domain.RestoreFinetuneParameter(
 		do.Model, do.Task, do.Hyperparameters,
 	)
 
+	if do.Dataset != nil {
+		cfg.Dataset = new(domain.ResourceRef)
+		err = do.Dataset.toResourceRef(cfg.Dataset)
+	}
+
+	return
+}
+
+type FinetuneDatasetDO struct {
+	User   string
+	RepoId string
+	File   string
+	Name   string
+}
+
+func (impl finetuneImpl) toFinetuneDatasetDO(ref *domain.ResourceRef) *FinetuneDatasetDO {
+	if ref == nil {
+		return nil
+	}
+
+	return &FinetuneDatasetDO{
+		User:   ref.User.Account(),
+		RepoId: ref.RepoId,
+		File:   ref.File.InputeFilePath(),
+		Name:   ref.Name.ResourceName(),
+	}
+}
+
+func (do *FinetuneDatasetDO) toResourceRef(r *domain.ResourceRef) (err error) {
+	if r.User, err = domain.NewAccount(do.User); err != nil {
+		return
+	}
+
+	if r.File, err = domain.NewInputeFilePath(do.File); err != nil {
+		return
+	}
+
+	if r.Name, err = domain.NewResourceName(do.Name); err != nil {
+		return
+	}
+
+	r.Type = domain.ResourceTypeDataset
+	r.RepoId = do.RepoId
+
 	return
 }
 
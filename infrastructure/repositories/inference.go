@@ -12,6 +12,7 @@ type InferenceMapper interface {
 	Get(*InferenceIndexDO) (InferenceSummaryDO, error)
 	UpdateDetail(*InferenceIndexDO, *InferenceDetailDO) error
 	List(*ResourceIndexDO, string) ([]InferenceSummaryDO, int, error)
+	ListAll(*ResourceIndexDO) ([]InferenceInstanceDO, error)
 }
 
 func NewInferenceRepository(mapper InferenceMapper) repository.Inference {
@@ -48,8 +49,7 @@ func (impl inference) FindInstance(info *domain.InferenceIndex) (
 		return
 	}
 
-	r.InferenceDetail = v.InferenceDetailDO
-	r.Id = v.Id
+	r = impl.toInferenceSummary(&v)
 
 	return
 }
@@ -68,8 +68,7 @@ func (impl inference) FindInstances(info *domain.ResourceIndex, lastCommit strin
 	r = make([]repository.InferenceSummary, len(v))
 
 	for i := range v {
-		r[i].InferenceDetail = v[i].InferenceDetailDO
-		r[i].Id = v[i].Id
+		r[i] = impl.toInferenceSummary(&v[i])
 	}
 
 	return
@@ -86,3 +85,30 @@ func (impl inference) UpdateDetail(
 
 	return nil
 }
+
+func (impl inference) FindProjectInstances(info *domain.ResourceIndex) (
+	[]repository.InferenceInstance, error,
+) {
+	index := toResourceIndexDO(info)
+	v, err := impl.mapper.ListAll(&index)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	r := make([]repository.InferenceInstance, len(v))
+
+	for i := range v {
+		r[i].LastCommit = v[i].LastCommit
+		r[i].InferenceSummary = impl.toInferenceSummary(&v[i].InferenceSummaryDO)
+	}
+
+	return r, nil
+}
+
+func (impl inference) toInferenceSummary(v *InferenceSummaryDO) repository.InferenceSummary {
+	return repository.InferenceSummary{
+		Id:              v.Id,
+		CreatedAt:       v.CreatedAt,
+		InferenceDetail: v.InferenceDetailDO,
+	}
+}
@@ -21,11 +21,18 @@ func (impl inference) toInferenceIndexDO(index *domain.InferenceIndex) Inference
 type InferenceDetailDO = domain.InferenceDetail
 
 type InferenceSummaryDO struct {
-	Id string
+	Id        string
+	CreatedAt int64
 
 	InferenceDetailDO
 }
 
+type InferenceInstanceDO struct {
+	LastCommit string
+
+	InferenceSummaryDO
+}
+
 type InferenceDO struct {
 	Id           string
 	ProjectId    string
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/repository"
+)
+
+type InferenceUsageMapper interface {
+	Record(*InferenceUsageDO) error
+	List(owner, projectId, since string) ([]InferenceUsageDO, error)
+}
+
+func NewInferenceUsageRepository(mapper InferenceUsageMapper) repository.InferenceUsage {
+	return inferenceUsage{mapper}
+}
+
+type inferenceUsage struct {
+	mapper InferenceUsageMapper
+}
+
+func (impl inferenceUsage) Record(u *domain.InferenceUsage) error {
+	do := InferenceUsageDO{
+		Owner:     u.Project.Owner.Account(),
+		ProjectId: u.Project.Id,
+		User:      u.User.Account(),
+		Date:      u.Date,
+		Requests:  u.Requests,
+		Failures:  u.Failures,
+		Latency:   u.Latency,
+	}
+
+	if err := impl.mapper.Record(&do); err != nil {
+		return convertError(err)
+	}
+
+	return nil
+}
+
+func (impl inferenceUsage) FindAll(project *domain.ResourceIndex, since string) (
+	[]domain.InferenceUsage, error,
+) {
+	v, err := impl.mapper.List(project.Owner.Account(), project.Id, since)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	r := make([]domain.InferenceUsage, len(v))
+	for i := range v {
+		if err = v[i].toInferenceUsage(&r[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import "github.com/opensourceways/xihe-server/domain"
+
+type InferenceUsageDO struct {
+	Owner     string
+	ProjectId string
+	User      string
+	Date      string
+	Requests  int
+	Failures  int
+	Latency   int64
+}
+
+func (do *InferenceUsageDO) toInferenceUsage(u *domain.InferenceUsage) (err error) {
+	if u.Project.Owner, err = domain.NewAccount(do.Owner); err != nil {
+		return
+	}
+
+	if u.User, err = domain.NewAccount(do.User); err != nil {
+		return
+	}
+
+	u.Project.Id = do.ProjectId
+	u.Date = do.Date
+	u.Requests = do.Requests
+	u.Failures = do.Failures
+	u.Latency = do.Latency
+
+	return
+}
@@ -14,6 +14,7 @@ type TrainingMapper interface {
 	GetTrainingConfig(*TrainingIndexDO) (TrainingConfigDO, error)
 	GetLastTrainingConfig(*ResourceIndexDO) (TrainingConfigDO, error)
 	List(user, projectId string) ([]TrainingSummaryDO, int, error)
+	ListAll(user string) ([]TrainingSummaryDO, error)
 	UpdateJobInfo(*TrainingIndexDO, *TrainingJobInfoDO) error
 	GetJobInfo(*TrainingIndexDO) (TrainingJobInfoDO, error)
 	UpdateJobDetail(*TrainingIndexDO, *TrainingJobDetailDO) error
@@ -111,6 +112,32 @@ func (impl training) List(user domain.Account, projectId string) (
 	return
 }
 
+func (impl training) ListAll(user domain.Account) (
+	r []domain.ProjectTrainingSummary, err error,
+) {
+	v, err := impl.mapper.ListAll(user.Account())
+	if err != nil {
+		err = convertError(err)
+
+		return
+	}
+
+	if len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.ProjectTrainingSummary, len(v))
+	for i := range v {
+		r[i].ProjectId = v[i].ProjectId
+
+		if err = v[i].toTrainingSummary(&r[i].TrainingSummary); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
 func (impl training) SaveJob(info *domain.TrainingIndex, job *domain.JobInfo) error {
 	do := impl.toTrainingIndexDO(info)
 
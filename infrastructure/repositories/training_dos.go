@@ -181,39 +181,42 @@ func (do *InputDO) toInput() (r domain.Input, err error) {
 }
 
 func (impl training) toUserTrainingDO(ut *domain.UserTraining) UserTrainingDO {
-	t := &ut.TrainingConfig
-	c := &t.Compute
-
-	do := UserTrainingDO{
+	return UserTrainingDO{
 		Id:        ut.Id,
 		Owner:     ut.Owner.Account(),
 		ProjectId: ut.ProjectId,
 		CreatedAt: ut.CreatedAt,
 
-		TrainingConfigDO: TrainingConfigDO{
-			Name:          t.Name.TrainingName(),
-			ProjectName:   t.ProjectName.ResourceName(),
-			ProjectRepoId: t.ProjectRepoId,
-
-			CodeDir:  t.CodeDir.Directory(),
-			BootFile: t.BootFile.FilePath(),
-
-			Hyperparameters: impl.toKeyValueDOs(t.Hyperparameters),
-			Env:             impl.toKeyValueDOs(t.Env),
-			Inputs:          impl.toInputDOs(t.Inputs),
-			EnableAim:       t.EnableAim,
-			EnableOutput:    t.EnableOutput,
-
-			Compute: ComputeDO{
-				Type:    c.Type.ComputeType(),
-				Flavor:  c.Flavor.ComputeFlavor(),
-				Version: c.Version.ComputeVersion(),
-			},
+		TrainingConfigDO: impl.toTrainingConfigDO(&ut.TrainingConfig),
+	}
+}
+
+func (impl training) toTrainingConfigDO(t *domain.TrainingConfig) TrainingConfigDO {
+	c := &t.Compute
+
+	do := TrainingConfigDO{
+		Name:          t.Name.TrainingName(),
+		ProjectName:   t.ProjectName.ResourceName(),
+		ProjectRepoId: t.ProjectRepoId,
+
+		CodeDir:  t.CodeDir.Directory(),
+		BootFile: t.BootFile.FilePath(),
+
+		Hyperparameters: impl.toKeyValueDOs(t.Hyperparameters),
+		Env:             impl.toKeyValueDOs(t.Env),
+		Inputs:          impl.toInputDOs(t.Inputs),
+		EnableAim:       t.EnableAim,
+		EnableOutput:    t.EnableOutput,
+
+		Compute: ComputeDO{
+			Type:    c.Type.ComputeType(),
+			Flavor:  c.Flavor.ComputeFlavor(),
+			Version: c.Version.ComputeVersion(),
 		},
 	}
 
 	if t.Desc != nil {
-		do.TrainingConfigDO.Desc = t.Desc.TrainingDesc()
+		do.Desc = t.Desc.TrainingDesc()
 	}
 
 	return do
@@ -262,7 +265,10 @@ func (impl training) toInputDOs(v []domain.Input) []InputDO {
 	return r
 }
 
+// TrainingSummaryDO.ProjectId is only set when listing the trainings
+// of all the projects.
 type TrainingSummaryDO struct {
+	ProjectId string
 	Id        string
 	Name      string
 	Desc      string
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/domain/repository"
+)
+
+type TrainingScheduleMapper interface {
+	Insert(*TrainingScheduleDO) (string, error)
+	Get(*TrainingScheduleIndexDO) (TrainingScheduleDO, error)
+	Delete(*TrainingScheduleIndexDO) error
+	List(user, projectId string) ([]TrainingScheduleDO, error)
+	ListDue(now int64) ([]TrainingScheduleDO, error)
+	UpdateState(*TrainingScheduleIndexDO, *TrainingScheduleStateDO, int) error
+	AddRun(*TrainingScheduleIndexDO, *TrainingScheduleRunDO) error
+}
+
+func NewTrainingScheduleRepository(mapper TrainingScheduleMapper) repository.TrainingSchedule {
+	return trainingSchedule{mapper}
+}
+
+type trainingSchedule struct {
+	mapper TrainingScheduleMapper
+}
+
+func (impl trainingSchedule) Save(s *domain.TrainingSchedule) (string, error) {
+	if s.Id != "" {
+		return "", errors.New("must be a new training schedule")
+	}
+
+	do := impl.toTrainingScheduleDO(s)
+
+	v, err := impl.mapper.Insert(&do)
+	if err != nil {
+		return "", convertError(err)
+	}
+
+	return v, nil
+}
+
+func (impl trainingSchedule) Get(index *domain.TrainingScheduleIndex) (
+	r domain.TrainingSchedule, err error,
+) {
+	do := impl.toTrainingScheduleIndexDO(index)
+
+	v, err := impl.mapper.Get(&do)
+	if err != nil {
+		err = convertError(err)
+	} else {
+		err = v.toTrainingSchedule(&r)
+	}
+
+	return
+}
+
+func (impl trainingSchedule) Delete(index *domain.TrainingScheduleIndex) error {
+	do := impl.toTrainingScheduleIndexDO(index)
+
+	if err := impl.mapper.Delete(&do); err != nil {
+		return convertError(err)
+	}
+
+	return nil
+}
+
+func (impl trainingSchedule) List(user domain.Account, projectId string) (
+	[]domain.TrainingSchedule, error,
+) {
+	v, err := impl.mapper.List(user.Account(), projectId)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	return impl.toTrainingSchedules(v)
+}
+
+func (impl trainingSchedule) FindDue(now int64) ([]domain.TrainingSchedule, error) {
+	v, err := impl.mapper.ListDue(now)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	return impl.toTrainingSchedules(v)
+}
+
+func (impl trainingSchedule) UpdateState(s *domain.TrainingSchedule) error {
+	index := TrainingScheduleIndexDO{
+		Owner:      s.Owner.Account(),
+		ProjectId:  s.ProjectId,
+		ScheduleId: s.Id,
+	}
+
+	state := TrainingScheduleStateDO{
+		Paused:    s.Paused,
+		NextRunAt: s.NextRunAt,
+	}
+
+	if err := impl.mapper.UpdateState(&index, &state, s.Version); err != nil {
+		return convertError(err)
+	}
+
+	return nil
+}
+
+func (impl trainingSchedule) AddRun(
+	index *domain.TrainingScheduleIndex, run *domain.TrainingScheduleRun,
+) error {
+	do := impl.toTrainingScheduleIndexDO(index)
+
+	if err := impl.mapper.AddRun(&do, run); err != nil {
+		return convertError(err)
+	}
+
+	return nil
+}
+
+func (impl trainingSchedule) toTrainingSchedules(v []TrainingScheduleDO) (
+	r []domain.TrainingSchedule, err error,
+) {
+	if len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.TrainingSchedule, len(v))
+	for i := range v {
+		if err = v[i].toTrainingSchedule(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
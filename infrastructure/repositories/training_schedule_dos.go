@@ -0,0 +1,86 @@
+package repositories
+
+import "github.com/opensourceways/xihe-server/domain"
+
+type TrainingScheduleRunDO = domain.TrainingScheduleRun
+
+type TrainingScheduleDO struct {
+	Id        string
+	Owner     string
+	ProjectId string
+
+	Name      string
+	Spec      string
+	Paused    bool
+	NextRunAt int64
+	CreatedAt int64
+	Version   int
+
+	TrainingConfigDO
+
+	Runs []TrainingScheduleRunDO
+}
+
+func (do *TrainingScheduleDO) toTrainingSchedule(s *domain.TrainingSchedule) (err error) {
+	if s.Owner, err = domain.NewAccount(do.Owner); err != nil {
+		return
+	}
+
+	if s.Name, err = domain.NewTrainingName(do.Name); err != nil {
+		return
+	}
+
+	if s.Spec, err = domain.NewCronSpec(do.Spec); err != nil {
+		return
+	}
+
+	if s.TrainingConfig, err = do.TrainingConfigDO.toTrainingConfig(); err != nil {
+		return
+	}
+
+	s.Id = do.Id
+	s.ProjectId = do.ProjectId
+	s.Paused = do.Paused
+	s.NextRunAt = do.NextRunAt
+	s.CreatedAt = do.CreatedAt
+	s.Version = do.Version
+	s.Runs = do.Runs
+
+	return
+}
+
+func (impl trainingSchedule) toTrainingScheduleDO(s *domain.TrainingSchedule) TrainingScheduleDO {
+	return TrainingScheduleDO{
+		Id:        s.Id,
+		Owner:     s.Owner.Account(),
+		ProjectId: s.ProjectId,
+		Name:      s.Name.TrainingName(),
+		Spec:      s.Spec.CronSpec(),
+		Paused:    s.Paused,
+		NextRunAt: s.NextRunAt,
+		CreatedAt: s.CreatedAt,
+
+		TrainingConfigDO: training{}.toTrainingConfigDO(&s.TrainingConfig),
+	}
+}
+
+type TrainingScheduleIndexDO struct {
+	Owner      string
+	ProjectId  string
+	ScheduleId string
+}
+
+func (impl trainingSchedule) toTrainingScheduleIndexDO(
+	index *domain.TrainingScheduleIndex,
+) TrainingScheduleIndexDO {
+	return TrainingScheduleIndexDO{
+		Owner:      index.Project.Owner.Account(),
+		ProjectId:  index.Project.Id,
+		ScheduleId: index.ScheduleId,
+	}
+}
+
+type TrainingScheduleStateDO struct {
+	Paused    bool
+	NextRunAt int64
+}
@@ -0,0 +1,50 @@
+package app
+
+import "github.com/opensourceways/xihe-server/job/domain"
+
+type Config struct {
+	// the statuses reported by the backends of job which mean
+	// the job is running, succeeded, failed or terminated.
+	// The other statuses mean the job is pending.
+	RunningStatus    []string `json:"running_status"`
+	SucceededStatus  []string `json:"succeeded_status"`
+	FailedStatus     []string `json:"failed_status"`
+	TerminatedStatus []string `json:"terminated_status"`
+
+	// WatchInterval is the seconds between two checks of the jobs by the
+	// status websocket. The websocket is also woken up as soon as the jobs
+	// are changed on the same instance, so the interval only bounds the
+	// delay of the changes made on the other instances.
+	WatchInterval int `json:"watch_interval"`
+}
+
+func (cfg *Config) SetDefault() {
+	if len(cfg.RunningStatus) == 0 {
+		cfg.RunningStatus = []string{"Running", "running"}
+	}
+
+	if len(cfg.SucceededStatus) == 0 {
+		cfg.SucceededStatus = []string{"Completed", "completed", "Succeeded", "succeeded"}
+	}
+
+	if len(cfg.FailedStatus) == 0 {
+		cfg.FailedStatus = []string{"Failed", "failed", "Abnormal", "abnormal", "Timeout", "timeout"}
+	}
+
+	if len(cfg.TerminatedStatus) == 0 {
+		cfg.TerminatedStatus = []string{"Terminated", "terminated", "Stopped", "stopped"}
+	}
+
+	if cfg.WatchInterval <= 0 {
+		cfg.WatchInterval = 30
+	}
+}
+
+func (cfg *Config) stateClassifier() domain.StateClassifier {
+	return domain.StateClassifier{
+		Running:    cfg.RunningStatus,
+		Succeeded:  cfg.SucceededStatus,
+		Failed:     cfg.FailedStatus,
+		Terminated: cfg.TerminatedStatus,
+	}
+}
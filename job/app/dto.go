@@ -0,0 +1,57 @@
+package app
+
+import (
+	"errors"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/job/domain"
+	"github.com/opensourceways/xihe-server/job/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+type JobListCmd struct {
+	Owner types.Account
+
+	repository.JobListOption
+}
+
+func (cmd *JobListCmd) Validate() error {
+	if cmd.Owner == nil {
+		return errors.New("invalid cmd of listing jobs")
+	}
+
+	return nil
+}
+
+type JobDTO struct {
+	Id        string `json:"id"`
+	Kind      string `json:"kind"`
+	Scope     string `json:"scope"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+	IsDone    bool   `json:"is_done"`
+	Duration  int    `json:"duration"`
+	CreatedAt string `json:"created_at"`
+}
+
+type JobsDTO struct {
+	Total int      `json:"total"`
+	Jobs  []JobDTO `json:"jobs"`
+}
+
+func toJobDTO(j *domain.Job) JobDTO {
+	return JobDTO{
+		Id:        j.Id,
+		Kind:      j.Kind.JobKind(),
+		Scope:     j.Scope,
+		Name:      j.Name,
+		State:     j.State.JobState(),
+		Status:    j.Status,
+		Error:     j.Error,
+		IsDone:    j.State.IsFinal(),
+		Duration:  j.Duration,
+		CreatedAt: utils.ToDate(j.CreatedAt),
+	}
+}
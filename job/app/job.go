@@ -0,0 +1,115 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/opensourceways/xihe-server/job/domain"
+	"github.com/opensourceways/xihe-server/job/domain/repository"
+)
+
+// JobService lists the jobs of all kinds a user owns.
+type JobService interface {
+	List(*JobListCmd) (JobsDTO, error)
+}
+
+func NewJobService(sources []repository.JobSource, cfg *Config) JobService {
+	return jobService{
+		sources:    sources,
+		classifier: cfg.stateClassifier(),
+	}
+}
+
+type jobService struct {
+	sources    []repository.JobSource
+	classifier domain.StateClassifier
+}
+
+// List reads the jobs from each source and pages them in memory. It is
+// acceptable because the number of records of each kind a user can keep
+// is limited.
+func (s jobService) List(cmd *JobListCmd) (dto JobsDTO, err error) {
+	var jobs []domain.Job
+
+	for _, source := range s.sources {
+		if !hasKind(cmd.Kinds, source.Kind()) {
+			continue
+		}
+
+		v, err := source.ListJobs(cmd.Owner)
+		if err != nil {
+			return dto, err
+		}
+
+		for i := range v {
+			v[i].State = s.classifier.StateOf(v[i].Status)
+
+			if hasState(cmd.States, v[i].State) {
+				jobs = append(jobs, v[i])
+			}
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt > jobs[j].CreatedAt
+	})
+
+	dto.Total = len(jobs)
+
+	if cmd.CountPerPage > 0 {
+		jobs = page(jobs, cmd.PageNum, cmd.CountPerPage)
+	}
+
+	dto.Jobs = make([]JobDTO, len(jobs))
+	for i := range jobs {
+		dto.Jobs[i] = toJobDTO(&jobs[i])
+	}
+
+	return
+}
+
+func page(jobs []domain.Job, num, count int) []domain.Job {
+	if num < 1 {
+		num = 1
+	}
+
+	start := (num - 1) * count
+	if start >= len(jobs) {
+		return nil
+	}
+
+	if end := start + count; end < len(jobs) {
+		return jobs[start:end]
+	}
+
+	return jobs[start:]
+}
+
+// hasKind returns true if the kinds is empty which means all kinds.
+func hasKind(kinds []domain.JobKind, kind domain.JobKind) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+
+	for _, v := range kinds {
+		if v.JobKind() == kind.JobKind() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasState returns true if the states is empty which means all states.
+func hasState(states []domain.JobState, state domain.JobState) bool {
+	if len(states) == 0 {
+		return true
+	}
+
+	for _, v := range states {
+		if v.JobState() == state.JobState() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"sync"
+
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+// JobNotifier wakes up the watchers of the jobs of a user when any of
+// them is created, updated or deleted. It only reaches the watchers on the
+// same instance of server, so the watchers still check the jobs at
+// intervals to find the changes made by the other instances.
+type JobNotifier interface {
+	Notify(owner types.Account)
+	Subscribe(owner types.Account) (<-chan struct{}, func())
+}
+
+func NewJobNotifier() JobNotifier {
+	return &jobNotifier{
+		watchers: map[string]map[chan struct{}]struct{}{},
+	}
+}
+
+type jobNotifier struct {
+	lock     sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+}
+
+func (n *jobNotifier) Notify(owner types.Account) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for ch := range n.watchers[owner.Account()] {
+		// the watcher has not handled the last notification,
+		// one pending notification is enough.
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the channel of notification and the function
+// which must be called to unsubscribe.
+func (n *jobNotifier) Subscribe(owner types.Account) (<-chan struct{}, func()) {
+	k := owner.Account()
+	ch := make(chan struct{}, 1)
+
+	n.lock.Lock()
+	if n.watchers[k] == nil {
+		n.watchers[k] = map[chan struct{}]struct{}{}
+	}
+	n.watchers[k][ch] = struct{}{}
+	n.lock.Unlock()
+
+	return ch, func() {
+		n.lock.Lock()
+		defer n.lock.Unlock()
+
+		delete(n.watchers[k], ch)
+
+		if len(n.watchers[k]) == 0 {
+			delete(n.watchers, k)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package config
+
+import "github.com/opensourceways/xihe-server/job/app"
+
+type Config struct {
+	App app.Config `json:"app"`
+}
+
+func (cfg *Config) ConfigItems() []interface{} {
+	return []interface{}{
+		&cfg.App,
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import "errors"
+
+var (
+	JobKindTraining     = jobKind("training")
+	JobKindFinetune     = jobKind("finetune")
+	JobKindAICCFinetune = jobKind("aicc_finetune")
+
+	jobKinds = map[string]JobKind{
+		JobKindTraining.JobKind():     JobKindTraining,
+		JobKindFinetune.JobKind():     JobKindFinetune,
+		JobKindAICCFinetune.JobKind(): JobKindAICCFinetune,
+	}
+)
+
+var (
+	JobStateScheduling = jobState("scheduling")
+	JobStatePending    = jobState("pending")
+	JobStateRunning    = jobState("running")
+	JobStateSucceeded  = jobState("succeeded")
+	JobStateFailed     = jobState("failed")
+	JobStateTerminated = jobState("terminated")
+
+	jobStates = map[string]JobState{
+		JobStateScheduling.JobState(): JobStateScheduling,
+		JobStatePending.JobState():    JobStatePending,
+		JobStateRunning.JobState():    JobStateRunning,
+		JobStateSucceeded.JobState():  JobStateSucceeded,
+		JobStateFailed.JobState():     JobStateFailed,
+		JobStateTerminated.JobState(): JobStateTerminated,
+	}
+)
+
+// JobKind
+type JobKind interface {
+	JobKind() string
+}
+
+func NewJobKind(v string) (JobKind, error) {
+	if r, ok := jobKinds[v]; ok {
+		return r, nil
+	}
+
+	return nil, errors.New("unknown job kind")
+}
+
+type jobKind string
+
+func (r jobKind) JobKind() string {
+	return string(r)
+}
+
+// JobState
+type JobState interface {
+	JobState() string
+	IsFinal() bool
+}
+
+func NewJobState(v string) (JobState, error) {
+	if r, ok := jobStates[v]; ok {
+		return r, nil
+	}
+
+	return nil, errors.New("unknown job state")
+}
+
+type jobState string
+
+func (r jobState) JobState() string {
+	return string(r)
+}
+
+func (r jobState) IsFinal() bool {
+	return r == JobStateSucceeded || r == JobStateFailed || r == JobStateTerminated
+}
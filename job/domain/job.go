@@ -0,0 +1,65 @@
+package domain
+
+import types "github.com/opensourceways/xihe-server/domain"
+
+// Job is the common view of training, finetune and aicc finetune.
+// Id is the id of the training, finetune or aicc finetune.
+// Scope is where the job belongs to. It is the project id for training,
+// the model name for aicc finetune and empty for finetune.
+type Job struct {
+	Id        string
+	Kind      JobKind
+	Owner     types.Account
+	Scope     string
+	Name      string
+	State     JobState
+	Status    string
+	Error     string
+	Duration  int
+	CreatedAt int64
+}
+
+// StateClassifier maps the status reported by the backend of job to the state.
+type StateClassifier struct {
+	Running    []string
+	Succeeded  []string
+	Failed     []string
+	Terminated []string
+}
+
+const (
+	statusScheduling     = "scheduling"
+	statusScheduleFailed = "schedule_failed"
+)
+
+func (c *StateClassifier) StateOf(status string) JobState {
+	switch {
+	case status == "" || status == statusScheduling:
+		return JobStateScheduling
+
+	case status == statusScheduleFailed || has(c.Failed, status):
+		return JobStateFailed
+
+	case has(c.Succeeded, status):
+		return JobStateSucceeded
+
+	case has(c.Terminated, status):
+		return JobStateTerminated
+
+	case has(c.Running, status):
+		return JobStateRunning
+
+	default:
+		return JobStatePending
+	}
+}
+
+func has(items []string, v string) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+
+	return false
+}
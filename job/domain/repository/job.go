@@ -0,0 +1,22 @@
+package repository
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/job/domain"
+)
+
+type JobListOption struct {
+	Kinds  []domain.JobKind
+	States []domain.JobState
+
+	PageNum      int
+	CountPerPage int
+}
+
+// JobSource lists the jobs of one kind from where they are stored,
+// so the jobs are always the same as the training, finetune and
+// aicc finetune themselves. The State of job is not set by it.
+type JobSource interface {
+	Kind() domain.JobKind
+	ListJobs(owner types.Account) ([]domain.Job, error)
+}
@@ -0,0 +1,46 @@
+package sourceadapter
+
+import (
+	aiccrepo "github.com/opensourceways/xihe-server/aiccfinetune/domain/repository"
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/job/domain"
+	"github.com/opensourceways/xihe-server/job/domain/repository"
+)
+
+func NewAICCFinetuneSource(repo aiccrepo.AICCFinetune) repository.JobSource {
+	return aiccFinetuneSource{repo}
+}
+
+type aiccFinetuneSource struct {
+	repo aiccrepo.AICCFinetune
+}
+
+func (s aiccFinetuneSource) Kind() domain.JobKind {
+	return domain.JobKindAICCFinetune
+}
+
+func (s aiccFinetuneSource) ListJobs(owner types.Account) ([]domain.Job, error) {
+	v, err := s.repo.ListAll(owner)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]domain.Job, len(v))
+	for i := range v {
+		item := &v[i]
+
+		r[i] = domain.Job{
+			Id:        item.Id,
+			Kind:      domain.JobKindAICCFinetune,
+			Owner:     owner,
+			Scope:     item.Model.ModelName(),
+			Name:      item.Name.FinetuneName(),
+			Status:    item.Status,
+			Error:     item.Error,
+			Duration:  item.Duration,
+			CreatedAt: item.CreatedAt,
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,45 @@
+package sourceadapter
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+	orepo "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/job/domain"
+	"github.com/opensourceways/xihe-server/job/domain/repository"
+)
+
+func NewFinetuneSource(repo orepo.Finetune) repository.JobSource {
+	return finetuneSource{repo}
+}
+
+type finetuneSource struct {
+	repo orepo.Finetune
+}
+
+func (s finetuneSource) Kind() domain.JobKind {
+	return domain.JobKindFinetune
+}
+
+func (s finetuneSource) ListJobs(owner types.Account) ([]domain.Job, error) {
+	v, err := s.repo.List(owner)
+	if err != nil || len(v.Data) == 0 {
+		return nil, err
+	}
+
+	r := make([]domain.Job, len(v.Data))
+	for i := range v.Data {
+		item := &v.Data[i]
+
+		r[i] = domain.Job{
+			Id:        item.Id,
+			Kind:      domain.JobKindFinetune,
+			Owner:     owner,
+			Name:      item.Name.FinetuneName(),
+			Status:    item.Status,
+			Error:     item.Error,
+			Duration:  item.Duration,
+			CreatedAt: item.CreatedAt,
+		}
+	}
+
+	return r, nil
+}
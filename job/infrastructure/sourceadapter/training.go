@@ -0,0 +1,46 @@
+package sourceadapter
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+	orepo "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/job/domain"
+	"github.com/opensourceways/xihe-server/job/domain/repository"
+)
+
+func NewTrainingSource(repo orepo.Training) repository.JobSource {
+	return trainingSource{repo}
+}
+
+type trainingSource struct {
+	repo orepo.Training
+}
+
+func (s trainingSource) Kind() domain.JobKind {
+	return domain.JobKindTraining
+}
+
+func (s trainingSource) ListJobs(owner types.Account) ([]domain.Job, error) {
+	v, err := s.repo.ListAll(owner)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]domain.Job, len(v))
+	for i := range v {
+		item := &v[i]
+
+		r[i] = domain.Job{
+			Id:        item.Id,
+			Kind:      domain.JobKindTraining,
+			Owner:     owner,
+			Scope:     item.ProjectId,
+			Name:      item.Name.TrainingName(),
+			Status:    item.Status,
+			Error:     item.Error,
+			Duration:  item.Duration,
+			CreatedAt: item.CreatedAt,
+		}
+	}
+
+	return r, nil
+}
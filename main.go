@@ -12,6 +12,7 @@ import (
 	"github.com/opensourceways/xihe-server/agreement/app"
 	"github.com/opensourceways/xihe-server/aiccfinetune/infrastructure/aiccfinetuneimpl"
 	"github.com/opensourceways/xihe-server/bigmodel/infrastructure/bigmodels"
+	bigmodelrepo "github.com/opensourceways/xihe-server/bigmodel/infrastructure/repositoryimpl"
 	"github.com/opensourceways/xihe-server/common/infrastructure/kafka"
 	"github.com/opensourceways/xihe-server/common/infrastructure/pgsql"
 	"github.com/opensourceways/xihe-server/common/infrastructure/redis"
@@ -79,11 +80,6 @@ func main() {
 		logrus.Fatalf("config file delete failed, err:%s", err.Error())
 	}
 
-	// bigmodel
-	if err := bigmodels.Init(&cfg.BigModel.Config); err != nil {
-		logrus.Fatalf("initialize big model failed, err:%s", err.Error())
-	}
-
 	// gitlab
 	if err := gitlab.Init(&cfg.Gitlab); err != nil {
 		logrus.Fatalf("initialize gitlab failed, err:%s", err.Error())
@@ -133,6 +129,17 @@ func main() {
 
 	defer mongodb.Close()
 
+	// bigmodel
+	err = bigmodels.Init(
+		&cfg.BigModel.Config,
+		bigmodelrepo.NewModerationDecisionRepo(
+			mongodb.NewCollection(m.Collections.ModerationAudit),
+		),
+	)
+	if err != nil {
+		logrus.Fatalf("initialize big model failed, err:%s", err.Error())
+	}
+
 	// postgresql
 	if err := pgsql.Init(&cfg.Postgresql.DB); err != nil {
 		logrus.Fatalf("init postgresql failed, err:%s", err.Error())
@@ -27,6 +27,7 @@ import (
 	cloudmsg "github.com/opensourceways/xihe-server/cloud/infrastructure/messageadapter"
 	cloudrepo "github.com/opensourceways/xihe-server/cloud/infrastructure/repositoryimpl"
 	"github.com/opensourceways/xihe-server/common/infrastructure/kafka"
+	"github.com/opensourceways/xihe-server/common/infrastructure/usageimpl"
 	competitionapp "github.com/opensourceways/xihe-server/competition/app"
 	competitionmsg "github.com/opensourceways/xihe-server/competition/infrastructure/messageadapter"
 	competitionrepo "github.com/opensourceways/xihe-server/competition/infrastructure/repositoryimpl"
@@ -37,17 +38,28 @@ import (
 	coursemsg "github.com/opensourceways/xihe-server/course/infrastructure/messageadapter"
 	courserepo "github.com/opensourceways/xihe-server/course/infrastructure/repositoryimpl"
 	courseusercli "github.com/opensourceways/xihe-server/course/infrastructure/usercli"
+	deploymentapp "github.com/opensourceways/xihe-server/deployment/app"
+	deploymentrepo "github.com/opensourceways/xihe-server/deployment/infrastructure/repositoryimpl"
 	"github.com/opensourceways/xihe-server/docs"
+	inferencedomain "github.com/opensourceways/xihe-server/domain/inference"
+	"github.com/opensourceways/xihe-server/domain/message"
 	"github.com/opensourceways/xihe-server/domain/platform"
 	"github.com/opensourceways/xihe-server/infrastructure/authingimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/challengeimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/competitionimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/finetuneimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/gitlab"
+	"github.com/opensourceways/xihe-server/infrastructure/inferenceimpl"
+	"github.com/opensourceways/xihe-server/infrastructure/joblogimpl"
+	"github.com/opensourceways/xihe-server/infrastructure/localinferenceimpl"
+	"github.com/opensourceways/xihe-server/infrastructure/localtrainingimpl"
 	"github.com/opensourceways/xihe-server/infrastructure/messages"
 	"github.com/opensourceways/xihe-server/infrastructure/mongodb"
 	"github.com/opensourceways/xihe-server/infrastructure/repositories"
 	"github.com/opensourceways/xihe-server/infrastructure/trainingimpl"
+	jobapp "github.com/opensourceways/xihe-server/job/app"
+	jobrepo "github.com/opensourceways/xihe-server/job/domain/repository"
+	jobsource "github.com/opensourceways/xihe-server/job/infrastructure/sourceadapter"
 	pointsapp "github.com/opensourceways/xihe-server/points/app"
 	pointsservice "github.com/opensourceways/xihe-server/points/domain/service"
 	pointsrepo "github.com/opensourceways/xihe-server/points/infrastructure/repositoryadapter"
@@ -59,6 +71,10 @@ import (
 	userapp "github.com/opensourceways/xihe-server/user/app"
 	usermsg "github.com/opensourceways/xihe-server/user/infrastructure/messageadapter"
 	userrepoimpl "github.com/opensourceways/xihe-server/user/infrastructure/repositoryimpl"
+	"github.com/opensourceways/xihe-server/utils"
+	webhookapp "github.com/opensourceways/xihe-server/webhook/app"
+	"github.com/opensourceways/xihe-server/webhook/infrastructure/delivererimpl"
+	webhookrepo "github.com/opensourceways/xihe-server/webhook/infrastructure/repositoryimpl"
 )
 
 func StartWebServer(port int, timeout time.Duration, cfg *config.Config) {
@@ -174,12 +190,23 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 	promotionTaskRepo := promotionadapter.TaskAdapter(mongodb.NewCollection(collections.PromotionTask))
 
 	bigmodel := bigmodels.NewBigModelService()
+
+	interrupts.TickLiteral(
+		bigmodels.MaintainEndpointPools,
+		cfg.BigModel.Config.EndpointPool.MaintainInterval(),
+	)
+
+	interrupts.TickLiteral(
+		bigmodels.ReloadModerationBlocklists,
+		cfg.BigModel.Config.Moderation.ReloadInterval(),
+	)
 	gitlabUser := gitlab.NewUserService()
 	gitlabRepo := gitlab.NewRepoFile()
 	authingUser := authingimpl.NewAuthingUser()
 	publisher := kafka.PublisherAdapter()
 	operator := kafka.OperateLogPublisherAdapter(cfg.MQTopics.OperateLog, publisher)
 	trainingAdapter := trainingimpl.NewTraining(&cfg.Training.Config)
+	logDownloader := joblogimpl.NewDownloader()
 	repoAdapter := messages.NewDownloadMessageAdapter(cfg.MQTopics.Download, &cfg.Download, publisher, operator)
 	finetuneImpl := finetuneimpl.NewFinetune(&cfg.Finetune)
 	uploader := competitionimpl.NewCompetitionService()
@@ -190,7 +217,7 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 	aiccFinetune := aiccimpl.NewAICCFinetune(&cfg.AICCFinetune.Config)
 
 	// sender
-	sender := messages.NewMessageSender(&cfg.MQTopics, publisher)
+	var sender message.Sender = messages.NewMessageSender(&cfg.MQTopics, publisher)
 	// resource producer
 	resProducer := messages.NewResourceMessageAdapter(&cfg.Resource, publisher, operator)
 
@@ -231,12 +258,52 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		user,
 	)
 
+	cloudRepo := cloudrepo.NewCloudRepo(mongodb.NewCollection(collections.CloudConf))
+	cloudPodRepo := cloudrepo.NewPodRepo(&cfg.Postgresql.Cloud)
+	cloudReservationRepo := cloudrepo.NewReservationRepo(mongodb.NewCollection(collections.CloudReservation))
 	cloudAppService := cloudapp.NewCloudService(
-		cloudrepo.NewCloudRepo(mongodb.NewCollection(collections.CloudConf)),
-		cloudrepo.NewPodRepo(&cfg.Postgresql.Cloud),
+		cloudRepo,
+		cloudPodRepo,
+		cloudReservationRepo,
 		cloudmsg.NewPublisher(&cfg.Cloud, publisher),
 		whitelist,
 	)
+	cloudConfService := cloudapp.NewCloudConfService(cloudRepo, cloudPodRepo)
+
+	usageCounter := usageimpl.NewUsageCounter(
+		mongodb.NewCollection(collections.UsageCounter),
+	)
+
+	// the counts are kept for a day after they expire, so that the rate
+	// of requests in the recent minutes can be measured for scaling.
+	interrupts.TickLiteral(
+		func() {
+			if err := usageCounter.Clean(utils.Now() - 86400); err != nil {
+				logrus.Errorf("clean usage counters failed, err:%s", err.Error())
+			}
+		},
+		time.Hour,
+	)
+
+	cloudReservationService := cloudapp.NewReservationService(
+		cloudAppService, cloudReservationRepo, usageCounter, &cfg.CloudReserve,
+	)
+
+	interrupts.TickLiteral(
+		cloudReservationService.Schedule,
+		cfg.CloudReserve.ScheduleInterval(),
+	)
+
+	apiUsageRepo := bigmodelrepo.NewApiUsageRepo(mongodb.NewCollection(collections.ApiUsage))
+	bigmodelQuota := bigmodelapp.NewApiQuota(apiUsageRepo, usageCounter, &cfg.BigModel.Quota)
+	bigmodelIncident := bigmodelapp.NewModerationRecorder(
+		bigmodelrepo.NewModerationIncidentRepo(mongodb.NewCollection(collections.Moderation)),
+	)
+
+	err = bigmodelrepo.CreateWuKongPictureIndexes(mongodb.NewCollection(collections.WuKongPicture))
+	if err != nil {
+		return err
+	}
 
 	bigmodelAppService := bigmodelapp.NewBigModelService(
 		bigmodel, user,
@@ -248,6 +315,55 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		bigmodelrepo.NewApiService(mongodb.NewCollection(collections.ApiApply)),
 		bigmodelrepo.NewApiInfo(mongodb.NewCollection(collections.ApiInfo)),
 		userRegService,
+		bigmodelQuota,
+		bigmodelIncident,
+	)
+
+	bigmodelChatService := bigmodelapp.NewChatService(
+		bigmodel,
+		bigmodelrepo.NewChatSessionRepo(mongodb.NewCollection(collections.ChatSession)),
+		bigmodelmsg.NewMessageAdapter(&cfg.BigModel.Message, publisher),
+		bigmodelQuota,
+		bigmodelIncident,
+		&cfg.BigModel.Chat,
+	)
+
+	bigmodelQueueService := bigmodelapp.NewQueueService(
+		bigmodel,
+		bigmodelrepo.NewQueueTaskRepo(mongodb.NewCollection(collections.QueueTask)),
+		bigmodelmsg.NewMessageAdapter(&cfg.BigModel.Message, publisher),
+		bigmodelQuota,
+		bigmodelIncident,
+		&cfg.BigModel.Queue,
+	)
+	interrupts.Run(bigmodelQueueService.Run)
+
+	openAIService := bigmodelapp.NewOpenAIService(
+		bigmodel,
+		bigmodelrepo.NewApiService(mongodb.NewCollection(collections.ApiApply)),
+		bigmodelmsg.NewMessageAdapter(&cfg.BigModel.Message, publisher),
+		bigmodelQuota,
+		bigmodelIncident,
+	)
+
+	bigmodelBatchService := bigmodelapp.NewWuKongBatchService(
+		bigmodel,
+		bigmodelrepo.NewWuKongBatchJobRepo(mongodb.NewCollection(collections.WuKongBatch)),
+		bigmodelQuota,
+		&cfg.BigModel.Batch,
+	)
+	interrupts.Run(bigmodelBatchService.Run)
+
+	err = bigmodelrepo.CreatePromptTemplateIndexes(mongodb.NewCollection(collections.PromptTemplate))
+	if err != nil {
+		return err
+	}
+
+	bigmodelPromptService := bigmodelapp.NewPromptService(
+		bigmodel,
+		bigmodelrepo.NewPromptTemplateRepo(mongodb.NewCollection(collections.PromptTemplate)),
+		usageCounter,
+		&cfg.BigModel.Prompt,
 	)
 
 	projectService := app.NewProjectService(user, proj, model, dataset, activity, nil, resProducer)
@@ -275,11 +391,26 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		promotionRepo,
 	)
 
+	aiccFinetuneRepo := aiccrepo.NewAICCFinetuneRepo(mongodb.NewCollection(collections.AICCFinetune))
+
+	jobNotifier := jobapp.NewJobNotifier()
+	jobAppService := jobapp.NewJobService(
+		[]jobrepo.JobSource{
+			jobsource.NewTrainingSource(training),
+			jobsource.NewFinetuneSource(finetune),
+			jobsource.NewAICCFinetuneSource(aiccFinetuneRepo),
+		},
+		&cfg.Job.App,
+	)
+
 	aiccAppService := aiccapp.NewAICCFinetuneService(
 		aiccFinetune,
 		aiccmsg.NewMessageAdapter(&cfg.AICCFinetune.Message, publisher),
 		aiccUploader,
-		aiccrepo.NewAICCFinetuneRepo(mongodb.NewCollection(collections.AICCFinetune)),
+		aiccFinetuneRepo,
+		logDownloader,
+		jobNotifier,
+		&cfg.AICCFinetune.Data,
 		5,
 	)
 
@@ -289,6 +420,150 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		whitelist,
 	)
 
+	webhookRepo := webhookrepo.NewWebhookRepo(mongodb.NewCollection(collections.Webhook))
+	webhookDeliveryRepo := webhookrepo.NewDeliveryRepo(mongodb.NewCollection(collections.WebhookDelivery))
+
+	webhookAppService := webhookapp.NewWebhookService(
+		webhookRepo, webhookDeliveryRepo, &cfg.Webhook.App,
+	)
+
+	webhookEventService := webhookapp.NewWebhookEventService(
+		webhookRepo, webhookDeliveryRepo,
+		delivererimpl.NewDeliverer(&cfg.Webhook.Deliverer),
+		&cfg.Webhook.App,
+	)
+
+	interrupts.TickLiteral(
+		func() {
+			if err := webhookEventService.DeliverDue(); err != nil {
+				logrus.Errorf("deliver webhook events failed, err:%s", err.Error())
+			}
+		},
+		time.Duration(cfg.Webhook.App.Interval)*time.Second,
+	)
+
+	inferenceGatewayService := app.NewInferenceGatewayService(
+		inference,
+		repositories.NewInferenceUsageRepository(
+			mongodb.NewInferenceUsageMapper(collections.InferenceUsage),
+		),
+		usageCounter,
+	)
+
+	deploymentRepo := deploymentrepo.NewDeploymentRepo(
+		mongodb.NewCollection(collections.Deployment),
+	)
+	deploymentUsage := deploymentrepo.NewUsageRepo(
+		mongodb.NewCollection(collections.DeploymentUsage),
+	)
+	var inferenceManager inferencedomain.Inference = inferenceimpl.NewInference(&cfg.Inference.Config)
+
+	inferenceDownloader := logDownloader
+
+	var localInference localinferenceimpl.Inference
+	if cfg.Inference.Local.Enable {
+		if localInference, err = localinferenceimpl.NewInference(&cfg.Inference.Local); err != nil {
+			return err
+		}
+
+		inferenceManager = localInference
+		sender = localInference.Sender(sender)
+		inferenceDownloader = localInference.Downloader(logDownloader)
+	}
+
+	deploymentAppService := deploymentapp.NewDeploymentService(
+		gitlabRepo, deploymentRepo, deploymentUsage, inferenceManager, user, whitelist,
+		&cfg.Deployment.App,
+	)
+
+	deploymentGatewayService := deploymentapp.NewGatewayService(
+		deploymentRepo, deploymentUsage, usageCounter,
+	)
+
+	deploymentScalingService := deploymentapp.NewScalingService(
+		deploymentRepo, usageCounter, inferenceManager, user, whitelist,
+		&cfg.Deployment.App,
+	)
+
+	interrupts.TickLiteral(
+		func() {
+			if err := deploymentScalingService.Reconcile(); err != nil {
+				logrus.Errorf("reconcile deployments failed, err:%s", err.Error())
+			}
+		},
+		time.Duration(cfg.Deployment.App.Interval)*time.Second,
+	)
+
+	if localInference != nil {
+		localInference.SetHandlers(
+			app.NewInferenceMessageService(inference, user, inferenceManager),
+			app.NewInferenceInternalService(
+				inference, webhookEventService,
+				deploymentapp.NewReplicaService(deploymentRepo),
+			),
+		)
+
+		if err := localInference.RecoverInstances(); err != nil {
+			return err
+		}
+
+		controller.AddRouterForLocalInferenceController(v1, localInference)
+	}
+
+	var trainingSender message.MessageProducer = messages.NewTrainingMessageAdapter(
+		&cfg.Training.Message, publisher,
+	)
+
+	trainingDownloader := logDownloader
+
+	var localTraining localtrainingimpl.Training
+	if cfg.Training.Local.Enable {
+		localTraining, err = localtrainingimpl.NewTraining(&cfg.Training.Local, training)
+		if err != nil {
+			return err
+		}
+
+		trainingAdapter = localTraining
+		trainingSender = localTraining
+		trainingDownloader = localTraining.Downloader(logDownloader)
+	}
+
+	trainingService := app.NewTrainingService(
+		trainingAdapter, training, trainingSender, trainingDownloader,
+		webhookEventService, jobNotifier, cfg.API.MaxTrainingRecordNum,
+	)
+
+	if localTraining != nil {
+		localTraining.SetJobHandler(trainingService)
+
+		if err := localTraining.RecoverJobs(); err != nil {
+			return err
+		}
+
+		controller.AddRouterForLocalTrainingController(v1, localTraining)
+	}
+
+	trainingScheduleService := app.NewTrainingScheduleService(
+		trainingService,
+		trainingAdapter, training,
+		repositories.NewTrainingScheduleRepository(
+			mongodb.NewTrainingScheduleMapper(
+				collections.TrainingSchedule,
+				cfg.Training.Schedule.RunsKeepNum,
+			),
+		),
+		cfg.Training.Schedule.MaxScheduleNum,
+	)
+
+	interrupts.TickLiteral(
+		func() {
+			if err := trainingScheduleService.TriggerDueSchedules(); err != nil {
+				logrus.Errorf("trigger training schedules failed, err:%s", err.Error())
+			}
+		},
+		time.Duration(cfg.Training.Schedule.Interval)*time.Second,
+	)
+
 	{
 		controller.AddRouterForProjectController(
 			v1, user, proj, model, dataset, activity, tags, like, resProducer,
@@ -331,18 +606,68 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		)
 
 		controller.AddRouterForBigModelController(
-			v1, bigmodelAppService, userRegService,
+			v1, bigmodelAppService, userRegService, bigmodelPromptService,
+		)
+
+		controller.AddRouterForBigModelChatController(
+			v1, bigmodelChatService, bigmodelPromptService,
+		)
+
+		controller.AddRouterForBigModelPromptController(
+			v1, bigmodelPromptService,
+		)
+
+		controller.AddRouterForBigModelWuKongBatchController(
+			v1, bigmodelBatchService,
+		)
+
+		controller.AddRouterForOpenAIController(
+			v1, openAIService,
+		)
+
+		controller.AddRouterForBigModelQueueController(
+			v1, bigmodelQueueService,
+		)
+
+		controller.AddRouterForBigModelEndpointController(
+			v1, bigmodelAppService, userWhiteListService,
+		)
+
+		controller.AddRouterForBigModelWuKongGalleryController(
+			v1, bigmodelapp.NewWuKongGalleryService(
+				bigmodel,
+				bigmodelrepo.NewWuKongPictureRepo(mongodb.NewCollection(collections.WuKongPicture)),
+				bigmodelrepo.NewWuKongCommentRepo(mongodb.NewCollection(collections.WuKongComment)),
+				bigmodelrepo.NewWuKongReportRepo(mongodb.NewCollection(collections.WuKongReport)),
+				&cfg.BigModel.Gallery,
+			),
+			userWhiteListService,
+		)
+
+		controller.AddRouterForBigModelUsageController(
+			v1, bigmodelapp.NewApiUsageService(apiUsageRepo, &cfg.BigModel.Quota),
+			userWhiteListService,
 		)
 
 		controller.AddRouterForTrainingController(
 			v1, trainingAdapter, training, model, proj, dataset,
-			messages.NewTrainingMessageAdapter(
-				&cfg.Training.Message, publisher,
-			),
+			trainingSender, trainingDownloader, webhookEventService, jobNotifier,
+		)
+
+		controller.AddRouterForTrainingScheduleController(
+			v1, trainingScheduleService,
+		)
+
+		controller.AddRouterForWebhookController(
+			v1, webhookAppService, proj,
 		)
 
 		controller.AddRouterForFinetuneController(
-			v1, finetuneImpl, finetune, sender,
+			v1, finetuneImpl, finetune, dataset, sender, logDownloader, jobNotifier,
+		)
+
+		controller.AddRouterForJobController(
+			v1, jobAppService, jobNotifier, &cfg.Job.App,
 		)
 
 		controller.AddRouterForRepoFileController(
@@ -350,7 +675,12 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		)
 
 		controller.AddRouterForInferenceController(
-			v1, gitlabRepo, inference, proj, sender, userWhiteListService,
+			v1, gitlabRepo, inference, proj, sender, inferenceDownloader, userWhiteListService,
+			inferenceGatewayService,
+		)
+
+		controller.AddRouterForDeploymentController(
+			v1, deploymentAppService, deploymentGatewayService, proj,
 		)
 
 		controller.AddRouterForSearchController(
@@ -380,6 +710,14 @@ func setRouter(engine *gin.Engine, cfg *config.Config) error {
 		controller.AddRouterForCloudController(
 			v1, cloudAppService, userWhiteListService,
 		)
+
+		controller.AddRouterForCloudConfController(
+			v1, cloudConfService, userWhiteListService,
+		)
+
+		controller.AddRouterForCloudReservationController(
+			v1, cloudReservationService,
+		)
 	}
 
 	engine.UseRawPath = true
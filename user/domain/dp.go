@@ -18,6 +18,8 @@ const (
 	WhitelistTypeCloud      = "cloud"
 	WhitelistTypeMultiCloud = "multi-cloud"
 	WhitelistTypeInference  = "inference"
+	WhitelistTypeAdmin      = "admin"
+	WhitelistTypeDeployment = "deployment"
 )
 
 // DomainValue
@@ -278,7 +280,8 @@ type WhiteListType interface {
 }
 
 func NewWhiteListType(w string) (WhiteListType, error) {
-	b := w == WhitelistTypeCloud || w == WhitelistTypeMultiCloud || w == WhitelistTypeInference
+	b := w == WhitelistTypeCloud || w == WhitelistTypeMultiCloud || w == WhitelistTypeInference ||
+		w == WhitelistTypeAdmin || w == WhitelistTypeDeployment
 
 	if !b {
 		return nil, errors.New("invalid type")
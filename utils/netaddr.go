@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var nonPublicNets = parseCIDRs(
+	"0.0.0.0/8",      // this network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved
+	"64:ff9b::/96",   // NAT64 which may map to the internal IPv4
+	"64:ff9b:1::/48", // local-use NAT64
+	"2001:db8::/32",  // documentation
+	"fec0::/10",      // site-local
+)
+
+func parseCIDRs(v ...string) []*net.IPNet {
+	r := make([]*net.IPNet, len(v))
+	for i := range v {
+		_, n, err := net.ParseCIDR(v[i])
+		if err != nil {
+			panic(err)
+		}
+
+		r[i] = n
+	}
+
+	return r
+}
+
+// IsPublicIP checks whether the ip is a public unicast address. The loopback,
+// private, link-local (including the metadata service of clouds such as
+// 169.254.169.254) and other special addresses are not public.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	if v := ip.To4(); v != nil {
+		ip = v
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		ip.Equal(net.IPv4bcast) {
+		return false
+	}
+
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckPublicHost resolves the host and checks that all the addresses of it
+// are public.
+func CheckPublicHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return errors.New("the address of host is not public")
+		}
+
+		return nil
+	}
+
+	if h := strings.ToLower(host); h == "localhost" || strings.HasSuffix(h, ".localhost") {
+		return errors.New("the address of host is not public")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("can't resolve the host, %s", err.Error())
+	}
+
+	for i := range ips {
+		if !IsPublicIP(ips[i].IP) {
+			return errors.New("the address of host is not public")
+		}
+	}
+
+	return nil
+}
+
+// PublicDialer returns the dialer which refuses to connect to the addresses
+// which are not public. The address is checked after it is resolved, so the
+// host can't point to the internal address by changing its DNS record.
+func PublicDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("%s is not a public address", host)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package app
+
+import "github.com/opensourceways/xihe-server/webhook/domain"
+
+type Config struct {
+	// MaxWebhookNum is the max num of webhooks of a project
+	MaxWebhookNum int `json:"max_webhook_num"`
+
+	// DeliveryLogNum is the num of the latest deliveries returned for a webhook
+	DeliveryLogNum int `json:"delivery_log_num"`
+
+	// Interval is the seconds between two checks of the pending deliveries
+	Interval int `json:"interval"`
+
+	// BatchNum is the max num of deliveries handled at each check
+	BatchNum int `json:"batch_num"`
+
+	// MaxAttempts is the max times to deliver an event
+	MaxAttempts int `json:"max_attempts"`
+
+	// Backoff is the seconds to wait before the first retry, it doubles after each retry
+	Backoff int64 `json:"backoff"`
+
+	// MaxBackoff is the max seconds to wait before a retry
+	MaxBackoff int64 `json:"max_backoff"`
+
+	// ClaimTimeout is the seconds after which a delivery claimed by an instance
+	// can be picked up by the others if it has not been done.
+	ClaimTimeout int64 `json:"claim_timeout"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.MaxWebhookNum <= 0 {
+		cfg.MaxWebhookNum = 5
+	}
+
+	if cfg.DeliveryLogNum <= 0 {
+		cfg.DeliveryLogNum = 50
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10
+	}
+
+	if cfg.BatchNum <= 0 {
+		cfg.BatchNum = 100
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 6
+	}
+
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 30
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 3600
+	}
+
+	if cfg.ClaimTimeout <= 0 {
+		cfg.ClaimTimeout = 300
+	}
+}
+
+func (cfg *Config) retryPolicy() domain.RetryPolicy {
+	return domain.RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     cfg.Backoff,
+		MaxBackoff:  cfg.MaxBackoff,
+	}
+}
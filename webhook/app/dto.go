@@ -0,0 +1,117 @@
+package app
+
+import (
+	"errors"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/utils"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+)
+
+type WebhookCreateCmd struct {
+	Owner     types.Account
+	ProjectId string
+	URL       domain.WebhookURL
+	Secret    domain.WebhookSecret
+	Events    []domain.WebhookEvent
+}
+
+func (cmd *WebhookCreateCmd) Validate() error {
+	b := cmd.Owner != nil &&
+		cmd.ProjectId != "" &&
+		cmd.URL != nil &&
+		cmd.Secret != nil &&
+		len(cmd.Events) > 0
+
+	if !b {
+		return errors.New("invalid cmd of creating webhook")
+	}
+
+	return nil
+}
+
+func (cmd *WebhookCreateCmd) toWebhook() domain.Webhook {
+	return domain.Webhook{
+		Owner:     cmd.Owner,
+		ProjectId: cmd.ProjectId,
+		URL:       cmd.URL,
+		Secret:    cmd.Secret,
+		Events:    cmd.Events,
+		CreatedAt: utils.Now(),
+	}
+}
+
+// WebhookEventCmd
+type WebhookEventCmd = domain.Event
+
+type WebhookDTO struct {
+	Id        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	CreatedAt string   `json:"created_at"`
+}
+
+func toWebhookDTO(w *domain.Webhook) WebhookDTO {
+	events := make([]string, len(w.Events))
+	for i := range w.Events {
+		events[i] = w.Events[i].WebhookEvent()
+	}
+
+	return WebhookDTO{
+		Id:        w.Id,
+		URL:       w.URL.WebhookURL(),
+		Events:    events,
+		CreatedAt: utils.ToDate(w.CreatedAt),
+	}
+}
+
+type DeliveryDTO struct {
+	Id            string `json:"id"`
+	Event         string `json:"event"`
+	Payload       string `json:"payload"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	ResponseCode  int    `json:"response_code"`
+	Error         string `json:"error"`
+	NextAttemptAt int64  `json:"next_attempt_at"`
+	CreatedAt     int64  `json:"created_at"`
+	UpdatedAt     int64  `json:"updated_at"`
+}
+
+func toDeliveryDTO(d *domain.Delivery) DeliveryDTO {
+	return DeliveryDTO{
+		Id:            d.Id,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+// eventPayload is the body posted to the webhook
+type eventPayload struct {
+	Event      string `json:"event"`
+	Owner      string `json:"owner"`
+	ProjectId  string `json:"project_id,omitempty"`
+	ResourceId string `json:"resource_id"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func toEventPayload(e *domain.Event) eventPayload {
+	return eventPayload{
+		Event:      e.Type.WebhookEvent(),
+		Owner:      e.Owner.Account(),
+		ProjectId:  e.ProjectId,
+		ResourceId: e.ResourceId,
+		Status:     e.Status,
+		Error:      e.Error,
+		OccurredAt: e.OccurredAt,
+	}
+}
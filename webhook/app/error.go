@@ -0,0 +1,7 @@
+package app
+
+const (
+	errorWebhookNotFound     = "webhook_not_found"
+	errorWebhookExccedMaxNum = "webhook_excced_max_num"
+	errorWebhookInvalidURL   = "webhook_invalid_url"
+)
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/utils"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+	"github.com/opensourceways/xihe-server/webhook/domain/deliverer"
+	"github.com/opensourceways/xihe-server/webhook/domain/repository"
+)
+
+// WebhookEventService is used by the other modules to notify the state changes
+// of their resources, and it delivers them to the webhooks in the background.
+type WebhookEventService interface {
+	Notify(*WebhookEventCmd) error
+	DeliverDue() error
+}
+
+func NewWebhookEventService(
+	repo repository.Webhook,
+	deliveryRepo repository.Delivery,
+	d deliverer.Deliverer,
+	cfg *Config,
+) WebhookEventService {
+	return webhookEventService{
+		repo:         repo,
+		deliveryRepo: deliveryRepo,
+		deliverer:    d,
+		policy:       cfg.retryPolicy(),
+		batchNum:     cfg.BatchNum,
+		claimTimeout: cfg.ClaimTimeout,
+	}
+}
+
+type webhookEventService struct {
+	repo         repository.Webhook
+	deliveryRepo repository.Delivery
+	deliverer    deliverer.Deliverer
+	policy       domain.RetryPolicy
+	batchNum     int
+	claimTimeout int64
+}
+
+func (s webhookEventService) Notify(e *WebhookEventCmd) error {
+	if e.OccurredAt == 0 {
+		e.OccurredAt = utils.Now()
+	}
+
+	v, err := s.repo.FindSubscribed(e.Owner, e.Type)
+	if err != nil || len(v) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(toEventPayload(e))
+	if err != nil {
+		return err
+	}
+
+	now := utils.Now()
+
+	for i := range v {
+		if !v[i].IsInterestedIn(e) {
+			continue
+		}
+
+		d := domain.NewDelivery(&v[i], e, string(payload), now)
+
+		if err := s.deliveryRepo.Add(&d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s webhookEventService) DeliverDue() error {
+	now := utils.Now()
+
+	v, err := s.deliveryRepo.FindDue(now, s.batchNum)
+	if err != nil {
+		return err
+	}
+
+	for i := range v {
+		if err := s.deliver(&v[i], now); err != nil {
+			logrus.Errorf(
+				"deliver webhook event(%s) failed, err:%s", v[i].Id, err.Error(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s webhookEventService) deliver(d *domain.Delivery, now int64) error {
+	// claim it first, so that it will not be delivered by the other instances.
+	d.Claim(now, s.claimTimeout)
+
+	if err := s.deliveryRepo.Save(d); err != nil {
+		if repoerr.IsErrorConcurrentUpdating(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	owner, err := types.NewAccount(d.Owner)
+	if err != nil {
+		return err
+	}
+
+	w, err := s.repo.Find(owner, d.WebhookId)
+	if err != nil {
+		if !repoerr.IsErrorResourceNotExists(err) {
+			return err
+		}
+
+		d.Abandon("the webhook has been deleted", utils.Now())
+
+		return s.deliveryRepo.Save(d)
+	}
+
+	code, err := s.deliverer.Deliver(&deliverer.Request{
+		URL:        w.URL.WebhookURL(),
+		Secret:     w.Secret.WebhookSecret(),
+		Event:      d.Event,
+		DeliveryId: d.Id,
+		Payload:    d.Payload,
+	})
+	if err != nil {
+		d.Fail(code, err.Error(), utils.Now(), &s.policy)
+	} else {
+		d.Succeed(code, utils.Now())
+	}
+
+	return s.deliveryRepo.Save(d)
+}
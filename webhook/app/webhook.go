@@ -0,0 +1,123 @@
+package app
+
+import (
+	"errors"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+	"github.com/opensourceways/xihe-server/webhook/domain/repository"
+)
+
+type WebhookService interface {
+	Create(*WebhookCreateCmd) (WebhookDTO, string, error)
+	List(owner types.Account, projectId string) ([]WebhookDTO, error)
+	Delete(owner types.Account, id string) error
+	ListDeliveries(owner types.Account, id string) ([]DeliveryDTO, string, error)
+}
+
+func NewWebhookService(
+	repo repository.Webhook,
+	deliveryRepo repository.Delivery,
+	cfg *Config,
+) WebhookService {
+	return webhookService{
+		repo:         repo,
+		deliveryRepo: deliveryRepo,
+
+		maxWebhookNum:  cfg.MaxWebhookNum,
+		deliveryLogNum: cfg.DeliveryLogNum,
+	}
+}
+
+type webhookService struct {
+	repo         repository.Webhook
+	deliveryRepo repository.Delivery
+
+	maxWebhookNum  int
+	deliveryLogNum int
+}
+
+func (s webhookService) Create(cmd *WebhookCreateCmd) (dto WebhookDTO, code string, err error) {
+	v, err := s.repo.FindAll(cmd.Owner, cmd.ProjectId)
+	if err != nil {
+		return
+	}
+
+	if len(v) >= s.maxWebhookNum {
+		code = errorWebhookExccedMaxNum
+		err = errors.New("exceed max webhook num")
+
+		return
+	}
+
+	if err = domain.CheckWebhookURLHost(cmd.URL); err != nil {
+		code = errorWebhookInvalidURL
+
+		return
+	}
+
+	w := cmd.toWebhook()
+
+	if w.Id, err = s.repo.Add(&w); err != nil {
+		return
+	}
+
+	dto = toWebhookDTO(&w)
+
+	return
+}
+
+func (s webhookService) List(owner types.Account, projectId string) ([]WebhookDTO, error) {
+	v, err := s.repo.FindAll(owner, projectId)
+	if err != nil || len(v) == 0 {
+		return nil, err
+	}
+
+	r := make([]WebhookDTO, len(v))
+	for i := range v {
+		r[i] = toWebhookDTO(&v[i])
+	}
+
+	return r, nil
+}
+
+func (s webhookService) Delete(owner types.Account, id string) error {
+	if _, err := s.repo.Find(owner, id); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := s.repo.Delete(owner, id); err != nil {
+		return err
+	}
+
+	return s.deliveryRepo.DeleteAll(id)
+}
+
+func (s webhookService) ListDeliveries(owner types.Account, id string) (
+	dtos []DeliveryDTO, code string, err error,
+) {
+	if _, err = s.repo.Find(owner, id); err != nil {
+		if repoerr.IsErrorResourceNotExists(err) {
+			code = errorWebhookNotFound
+		}
+
+		return
+	}
+
+	v, err := s.deliveryRepo.FindAll(id, s.deliveryLogNum)
+	if err != nil || len(v) == 0 {
+		return
+	}
+
+	dtos = make([]DeliveryDTO, len(v))
+	for i := range v {
+		dtos[i] = toDeliveryDTO(&v[i])
+	}
+
+	return
+}
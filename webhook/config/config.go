@@ -0,0 +1,18 @@
+package config
+
+import (
+	"github.com/opensourceways/xihe-server/webhook/app"
+	"github.com/opensourceways/xihe-server/webhook/infrastructure/delivererimpl"
+)
+
+type Config struct {
+	App       app.Config           `json:"app"`
+	Deliverer delivererimpl.Config `json:"deliverer"`
+}
+
+func (cfg *Config) ConfigItems() []interface{} {
+	return []interface{}{
+		&cfg.App,
+		&cfg.Deliverer,
+	}
+}
@@ -0,0 +1,14 @@
+package deliverer
+
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryId string
+	Payload    string
+}
+
+type Deliverer interface {
+	// Deliver posts the signed payload and returns the status code of the response.
+	Deliver(*Request) (int, error)
+}
@@ -0,0 +1,99 @@
+package domain
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// RetryPolicy decides when the failed delivery will be retried.
+// The interval doubles after each attempt, starting at Backoff seconds
+// and capped at MaxBackoff seconds.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     int64
+	MaxBackoff  int64
+}
+
+func (p *RetryPolicy) interval(attempts int) int64 {
+	v := p.Backoff
+	for i := 1; i < attempts && v < p.MaxBackoff; i++ {
+		v *= 2
+	}
+
+	if v > p.MaxBackoff {
+		v = p.MaxBackoff
+	}
+
+	return v
+}
+
+type Delivery struct {
+	Id            string
+	Owner         string
+	WebhookId     string
+	Event         string
+	Payload       string
+	Status        string
+	Attempts      int
+	ResponseCode  int
+	Error         string
+	NextAttemptAt int64
+	CreatedAt     int64
+	UpdatedAt     int64
+	Version       int
+}
+
+func NewDelivery(w *Webhook, e *Event, payload string, now int64) Delivery {
+	return Delivery{
+		Owner:         w.Owner.Account(),
+		WebhookId:     w.Id,
+		Event:         e.Type.WebhookEvent(),
+		Payload:       payload,
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func (d *Delivery) IsPending() bool {
+	return d.Status == DeliveryStatusPending
+}
+
+// Claim postpones the next attempt, so that the delivery will not be
+// picked up again by the other instances during delivering.
+func (d *Delivery) Claim(now, timeout int64) {
+	d.NextAttemptAt = now + timeout
+}
+
+func (d *Delivery) Succeed(code int, now int64) {
+	d.Attempts++
+	d.Status = DeliveryStatusSucceeded
+	d.ResponseCode = code
+	d.Error = ""
+	d.NextAttemptAt = 0
+	d.UpdatedAt = now
+}
+
+func (d *Delivery) Fail(code int, err string, now int64, policy *RetryPolicy) {
+	d.Attempts++
+	d.ResponseCode = code
+	d.Error = err
+	d.UpdatedAt = now
+
+	if d.Attempts >= policy.MaxAttempts {
+		d.Status = DeliveryStatusFailed
+		d.NextAttemptAt = 0
+	} else {
+		d.NextAttemptAt = now + policy.interval(d.Attempts)
+	}
+}
+
+// Abandon stops the delivery forever.
+func (d *Delivery) Abandon(err string, now int64) {
+	d.Status = DeliveryStatusFailed
+	d.Error = err
+	d.NextAttemptAt = 0
+	d.UpdatedAt = now
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/opensourceways/xihe-server/utils"
+)
+
+const (
+	webhookURLMaxLength    = 512
+	webhookSecretMinLength = 8
+	webhookSecretMaxLength = 64
+)
+
+var (
+	WebhookEventTraining              = webhookEvent("training")
+	WebhookEventFinetune              = webhookEvent("finetune")
+	WebhookEventInference             = webhookEvent("inference")
+	WebhookEventCompetitionSubmission = webhookEvent("competition_submission")
+
+	webhookEvents = map[string]WebhookEvent{
+		WebhookEventTraining.WebhookEvent():              WebhookEventTraining,
+		WebhookEventFinetune.WebhookEvent():              WebhookEventFinetune,
+		WebhookEventInference.WebhookEvent():             WebhookEventInference,
+		WebhookEventCompetitionSubmission.WebhookEvent(): WebhookEventCompetitionSubmission,
+	}
+)
+
+// WebhookURL
+type WebhookURL interface {
+	WebhookURL() string
+}
+
+func NewWebhookURL(v string) (WebhookURL, error) {
+	if v == "" {
+		return nil, errors.New("empty url")
+	}
+
+	if len(v) > webhookURLMaxLength {
+		return nil, fmt.Errorf("the length of url should be less than %d", webhookURLMaxLength)
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, errors.New("invalid url")
+	}
+
+	return webhookURL(v), nil
+}
+
+// CheckWebhookURLHost resolves the host of url and checks it is public, so
+// that the webhook can't be used to access the internal services.
+func CheckWebhookURLHost(v WebhookURL) error {
+	u, err := url.Parse(v.WebhookURL())
+	if err != nil {
+		return errors.New("invalid url")
+	}
+
+	return utils.CheckPublicHost(u.Hostname())
+}
+
+type webhookURL string
+
+func (r webhookURL) WebhookURL() string {
+	return string(r)
+}
+
+// WebhookSecret
+type WebhookSecret interface {
+	WebhookSecret() string
+}
+
+func NewWebhookSecret(v string) (WebhookSecret, error) {
+	if n := utils.StrLen(v); n < webhookSecretMinLength || n > webhookSecretMaxLength {
+		return nil, fmt.Errorf(
+			"the length of secret should be between %d to %d",
+			webhookSecretMinLength, webhookSecretMaxLength,
+		)
+	}
+
+	return webhookSecret(v), nil
+}
+
+type webhookSecret string
+
+func (r webhookSecret) WebhookSecret() string {
+	return string(r)
+}
+
+// WebhookEvent
+type WebhookEvent interface {
+	WebhookEvent() string
+}
+
+func NewWebhookEvent(v string) (WebhookEvent, error) {
+	if e, ok := webhookEvents[v]; ok {
+		return e, nil
+	}
+
+	return nil, errors.New("unsupported event")
+}
+
+type webhookEvent string
+
+func (r webhookEvent) WebhookEvent() string {
+	return string(r)
+}
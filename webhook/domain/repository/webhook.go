@@ -0,0 +1,22 @@
+package repository
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+)
+
+type Webhook interface {
+	Add(*domain.Webhook) (string, error)
+	Find(owner types.Account, id string) (domain.Webhook, error)
+	FindAll(owner types.Account, projectId string) ([]domain.Webhook, error)
+	FindSubscribed(owner types.Account, e domain.WebhookEvent) ([]domain.Webhook, error)
+	Delete(owner types.Account, id string) error
+}
+
+type Delivery interface {
+	Add(*domain.Delivery) error
+	Save(*domain.Delivery) error
+	FindDue(now int64, limit int) ([]domain.Delivery, error)
+	FindAll(webhookId string, limit int) ([]domain.Delivery, error)
+	DeleteAll(webhookId string) error
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+)
+
+type Webhook struct {
+	Id        string
+	Owner     types.Account
+	ProjectId string
+	URL       WebhookURL
+	Secret    WebhookSecret
+	Events    []WebhookEvent
+	CreatedAt int64
+}
+
+// IsInterestedIn returns true if the event should be delivered to the webhook.
+// The events which don't belong to any project, such as finetune, are delivered
+// to all the webhooks of the owner which subscribe them.
+func (w *Webhook) IsInterestedIn(e *Event) bool {
+	if w.Owner.Account() != e.Owner.Account() {
+		return false
+	}
+
+	if e.ProjectId != "" && e.ProjectId != w.ProjectId {
+		return false
+	}
+
+	return w.hasSubscribed(e.Type)
+}
+
+func (w *Webhook) hasSubscribed(t WebhookEvent) bool {
+	for _, v := range w.Events {
+		if v.WebhookEvent() == t.WebhookEvent() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is the state change of a resource which will be delivered to the webhooks.
+type Event struct {
+	Type       WebhookEvent
+	Owner      types.Account
+	ProjectId  string
+	ResourceId string
+	Status     string
+	Error      string
+	OccurredAt int64
+}
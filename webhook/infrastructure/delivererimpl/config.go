@@ -0,0 +1,12 @@
+package delivererimpl
+
+type Config struct {
+	// Timeout is the seconds to wait for the response of webhook
+	Timeout int `json:"timeout"`
+}
+
+func (cfg *Config) SetDefault() {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10
+	}
+}
@@ -0,0 +1,91 @@
+package delivererimpl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/xihe-server/utils"
+	"github.com/opensourceways/xihe-server/webhook/domain/deliverer"
+)
+
+const (
+	headerEvent     = "X-Xihe-Event"
+	headerDelivery  = "X-Xihe-Delivery"
+	headerSignature = "X-Xihe-Signature-256"
+	signaturePrefix = "sha256="
+
+	// maxDiscardLength is the max bytes of response body read to reuse
+	// the connection.
+	maxDiscardLength = 4096
+)
+
+func NewDeliverer(cfg *Config) deliverer.Deliverer {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+
+	return &delivererImpl{
+		cli: http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				// don't go through the proxy, otherwise the address
+				// dialed is the proxy rather than the webhook.
+				Proxy:               nil,
+				DialContext:         utils.PublicDialer(timeout).DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// the redirect may point to the internal address.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+type delivererImpl struct {
+	cli http.Client
+}
+
+func (impl *delivererImpl) Deliver(r *deliverer.Request) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, r.URL, strings.NewReader(r.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "xihe-webhook")
+	req.Header.Set(headerEvent, r.Event)
+	req.Header.Set(headerDelivery, r.DeliveryId)
+	req.Header.Set(headerSignature, signaturePrefix+sign(r.Secret, r.Payload))
+
+	resp, err := impl.cli.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	// the body is not kept, otherwise the content of any service the
+	// webhook points to can be read from the delivery log.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardLength))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the payload with the secret.
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package repositoryimpl
+
+import (
+	types "github.com/opensourceways/xihe-server/domain"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+)
+
+func toWebhookDoc(w *domain.Webhook) dWebhook {
+	events := make([]string, len(w.Events))
+	for i := range w.Events {
+		events[i] = w.Events[i].WebhookEvent()
+	}
+
+	return dWebhook{
+		Owner:     w.Owner.Account(),
+		ProjectId: w.ProjectId,
+		URL:       w.URL.WebhookURL(),
+		Secret:    w.Secret.WebhookSecret(),
+		Events:    events,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func (doc *dWebhook) toWebhook(w *domain.Webhook) (err error) {
+	if w.Owner, err = types.NewAccount(doc.Owner); err != nil {
+		return
+	}
+
+	if w.URL, err = domain.NewWebhookURL(doc.URL); err != nil {
+		return
+	}
+
+	if w.Secret, err = domain.NewWebhookSecret(doc.Secret); err != nil {
+		return
+	}
+
+	w.Events = make([]domain.WebhookEvent, len(doc.Events))
+	for i := range doc.Events {
+		if w.Events[i], err = domain.NewWebhookEvent(doc.Events[i]); err != nil {
+			return
+		}
+	}
+
+	w.Id = doc.Id.Hex()
+	w.ProjectId = doc.ProjectId
+	w.CreatedAt = doc.CreatedAt
+
+	return
+}
+
+func toDeliveryDoc(d *domain.Delivery) dDelivery {
+	return dDelivery{
+		Owner:         d.Owner,
+		WebhookId:     d.WebhookId,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+func (doc *dDelivery) toDelivery() domain.Delivery {
+	return domain.Delivery{
+		Id:            doc.Id.Hex(),
+		Owner:         doc.Owner,
+		WebhookId:     doc.WebhookId,
+		Event:         doc.Event,
+		Payload:       doc.Payload,
+		Status:        doc.Status,
+		Attempts:      doc.Attempts,
+		ResponseCode:  doc.ResponseCode,
+		Error:         doc.Error,
+		NextAttemptAt: doc.NextAttemptAt,
+		CreatedAt:     doc.CreatedAt,
+		UpdatedAt:     doc.UpdatedAt,
+		Version:       doc.Version,
+	}
+}
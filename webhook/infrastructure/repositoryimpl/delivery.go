@@ -0,0 +1,118 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+	"github.com/opensourceways/xihe-server/webhook/domain/repository"
+)
+
+func NewDeliveryRepo(m mongodbClient) repository.Delivery {
+	return deliveryRepoImpl{m}
+}
+
+type deliveryRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl deliveryRepoImpl) Add(d *domain.Delivery) error {
+	doc, err := genDoc(toDeliveryDoc(d))
+	if err != nil {
+		return err
+	}
+	doc[fieldVersion] = 0
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().InsertOne(ctx, doc)
+
+		return err
+	}
+
+	return withContext(f)
+}
+
+func (impl deliveryRepoImpl) Save(d *domain.Delivery) error {
+	filter, err := impl.cli.ObjectIdFilter(d.Id)
+	if err != nil {
+		return err
+	}
+
+	doc, err := genDoc(toDeliveryDoc(d))
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		return impl.cli.UpdateDoc(ctx, filter, doc, mongoCmdSet, d.Version)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorConcurrentUpdating(err)
+		}
+
+		return err
+	}
+
+	d.Version++
+
+	return nil
+}
+
+func (impl deliveryRepoImpl) FindDue(now int64, limit int) ([]domain.Delivery, error) {
+	filter := bson.M{
+		fieldStatus:        domain.DeliveryStatusPending,
+		fieldNextAttemptAt: bson.M{"$lte": now},
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{fieldNextAttemptAt: 1}).
+		SetLimit(int64(limit))
+
+	return impl.find(filter, opts)
+}
+
+func (impl deliveryRepoImpl) FindAll(webhookId string, limit int) ([]domain.Delivery, error) {
+	opts := options.Find().
+		SetSort(bson.M{fieldCreatedAt: -1}).
+		SetLimit(int64(limit))
+
+	return impl.find(bson.M{fieldWebhookId: webhookId}, opts)
+}
+
+func (impl deliveryRepoImpl) find(filter bson.M, opts *options.FindOptions) (
+	r []domain.Delivery, err error,
+) {
+	var v []dDelivery
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(ctx, filter, opts, &v)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.Delivery, len(v))
+	for i := range v {
+		r[i] = v[i].toDelivery()
+	}
+
+	return
+}
+
+func (impl deliveryRepoImpl) DeleteAll(webhookId string) error {
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().DeleteMany(
+			ctx, bson.M{fieldWebhookId: webhookId},
+		)
+
+		return err
+	}
+
+	return withContext(f)
+}
@@ -0,0 +1,41 @@
+package repositoryimpl
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	fieldPid           = "pid"
+	fieldUrl           = "url"
+	fieldOwner         = "owner"
+	fieldEvents        = "events"
+	fieldStatus        = "status"
+	fieldVersion       = "version"
+	fieldWebhookId     = "webhook_id"
+	fieldCreatedAt     = "created_at"
+	fieldNextAttemptAt = "next_attempt_at"
+)
+
+type dWebhook struct {
+	Id        primitive.ObjectID `bson:"_id"            json:"-"`
+	Owner     string             `bson:"owner"          json:"owner"`
+	ProjectId string             `bson:"pid"            json:"pid"`
+	URL       string             `bson:"url"            json:"url"`
+	Secret    string             `bson:"secret"         json:"secret"`
+	Events    []string           `bson:"events"         json:"events"`
+	CreatedAt int64              `bson:"created_at"     json:"created_at"`
+}
+
+type dDelivery struct {
+	Id            primitive.ObjectID `bson:"_id"               json:"-"`
+	Owner         string             `bson:"owner"             json:"owner"`
+	WebhookId     string             `bson:"webhook_id"        json:"webhook_id"`
+	Event         string             `bson:"event"             json:"event"`
+	Payload       string             `bson:"payload"           json:"payload"`
+	Status        string             `bson:"status"            json:"status"`
+	Attempts      int                `bson:"attempts"          json:"attempts"`
+	ResponseCode  int                `bson:"response_code"     json:"response_code"`
+	Error         string             `bson:"error"             json:"error"`
+	NextAttemptAt int64              `bson:"next_attempt_at"   json:"next_attempt_at"`
+	CreatedAt     int64              `bson:"created_at"        json:"created_at"`
+	UpdatedAt     int64              `bson:"updated_at"        json:"updated_at"`
+	Version       int                `bson:"version"           json:"-"`
+}
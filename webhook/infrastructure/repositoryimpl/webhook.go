@@ -0,0 +1,145 @@
+package repositoryimpl
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	types "github.com/opensourceways/xihe-server/domain"
+	repoerr "github.com/opensourceways/xihe-server/domain/repository"
+	"github.com/opensourceways/xihe-server/webhook/domain"
+	"github.com/opensourceways/xihe-server/webhook/domain/repository"
+)
+
+func NewWebhookRepo(m mongodbClient) repository.Webhook {
+	return webhookRepoImpl{m}
+}
+
+type webhookRepoImpl struct {
+	cli mongodbClient
+}
+
+func (impl webhookRepoImpl) docFilter(owner types.Account, id string) (bson.M, error) {
+	filter, err := impl.cli.ObjectIdFilter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter[fieldOwner] = owner.Account()
+
+	return filter, nil
+}
+
+func (impl webhookRepoImpl) Add(w *domain.Webhook) (id string, err error) {
+	doc, err := genDoc(toWebhookDoc(w))
+	if err != nil {
+		return
+	}
+
+	f := func(ctx context.Context) error {
+		id, err = impl.cli.NewDocIfNotExist(
+			ctx,
+			bson.M{
+				fieldOwner: w.Owner.Account(),
+				fieldPid:   w.ProjectId,
+				fieldUrl:   w.URL.WebhookURL(),
+			},
+			doc,
+		)
+
+		return err
+	}
+
+	if err = withContext(f); err != nil && impl.cli.IsDocExists(err) {
+		err = repoerr.NewErrorDuplicateCreating(err)
+	}
+
+	return
+}
+
+func (impl webhookRepoImpl) Find(owner types.Account, id string) (
+	w domain.Webhook, err error,
+) {
+	filter, err := impl.docFilter(owner, id)
+	if err != nil {
+		err = repoerr.NewErrorResourceNotExists(err)
+
+		return
+	}
+
+	var v dWebhook
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDoc(ctx, filter, nil, &v)
+	}
+
+	if err = withContext(f); err != nil {
+		if impl.cli.IsDocNotExists(err) {
+			err = repoerr.NewErrorResourceNotExists(err)
+		}
+
+		return
+	}
+
+	err = v.toWebhook(&w)
+
+	return
+}
+
+func (impl webhookRepoImpl) FindAll(owner types.Account, projectId string) (
+	[]domain.Webhook, error,
+) {
+	return impl.find(bson.M{
+		fieldOwner: owner.Account(),
+		fieldPid:   projectId,
+	})
+}
+
+func (impl webhookRepoImpl) FindSubscribed(owner types.Account, e domain.WebhookEvent) (
+	[]domain.Webhook, error,
+) {
+	return impl.find(bson.M{
+		fieldOwner:  owner.Account(),
+		fieldEvents: e.WebhookEvent(),
+	})
+}
+
+func (impl webhookRepoImpl) find(filter bson.M) (r []domain.Webhook, err error) {
+	var v []dWebhook
+
+	f := func(ctx context.Context) error {
+		return impl.cli.GetDocs(
+			ctx, filter,
+			options.Find().SetSort(bson.M{fieldCreatedAt: 1}), &v,
+		)
+	}
+
+	if err = withContext(f); err != nil || len(v) == 0 {
+		return
+	}
+
+	r = make([]domain.Webhook, len(v))
+	for i := range v {
+		if err = v[i].toWebhook(&r[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (impl webhookRepoImpl) Delete(owner types.Account, id string) error {
+	filter, err := impl.docFilter(owner, id)
+	if err != nil {
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		_, err := impl.cli.Collection().DeleteOne(ctx, filter)
+
+		return err
+	}
+
+	return withContext(f)
+}